@@ -17,11 +17,15 @@ limitations under the License.
 package algorithm
 
 import (
+	"context"
+	"math"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/Fedosin/libkpa/api"
 	libkpaconfig "github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/transmitter"
 )
 
 // mockMetricSnapshot implements api.MetricSnapshot for testing
@@ -202,6 +206,34 @@ func TestSlidingWindowAutoscaler_Scale_BasicScaling(t *testing.T) {
 			},
 			expectedPodCount: 3, // ceil(5 * 500/1000) = 3
 		},
+		{
+			name: "utilization - scale up",
+			config: func() api.AutoscalerConfig {
+				c := *libkpaconfig.NewDefaultAutoscalerConfigForMetric(api.ScalingMetricUtilization)
+				c.TargetValue = 70 // 70% of the pod request
+				return c
+			}(),
+			snapshot: mockMetricSnapshot{
+				stableValue:   140, // average utilization of 140%
+				burstValue:    140,
+				readyPodCount: 4,
+			},
+			expectedPodCount: 8, // ceil(4 * 140/70) = 8
+		},
+		{
+			name: "utilization - scale down",
+			config: func() api.AutoscalerConfig {
+				c := *libkpaconfig.NewDefaultAutoscalerConfigForMetric(api.ScalingMetricUtilization)
+				c.TargetValue = 80
+				return c
+			}(),
+			snapshot: mockMetricSnapshot{
+				stableValue:   50, // average utilization of 50%
+				burstValue:    50,
+				readyPodCount: 8,
+			},
+			expectedPodCount: 5, // ceil(8 * 50/80) = 5
+		},
 		{
 			name: "total target value with activation scale",
 			config: func() api.AutoscalerConfig {
@@ -331,6 +363,48 @@ func TestSlidingWindowAutoscaler_Scale_BurstMode_TotalTargetValue(t *testing.T)
 	}
 }
 
+func TestSlidingWindowAutoscaler_Scale_BurstMode_AbsoluteThreshold(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.BurstAbsoluteThreshold = 500
+	autoscaler, err := NewSlidingWindowAutoscaler(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Move past the initial burst period the autoscaler starts in.
+	now := time.Now().Add(config.StableWindow + time.Second)
+
+	// A large deployment where the pod ratio stays far below the 2x threshold.
+	snapshot := &mockMetricSnapshot{
+		stableValue:   10000,
+		burstValue:    10400, // +400, below the absolute threshold
+		readyPodCount: 100,
+		timestamp:     now,
+	}
+
+	recommendation := autoscaler.Scale(snapshot, now)
+	if recommendation.InBurstMode {
+		t.Error("expected not to enter burst mode below the absolute threshold")
+	}
+
+	now = now.Add(time.Second)
+	snapshot = &mockMetricSnapshot{
+		stableValue:   10000,
+		burstValue:    10600, // +600, above the absolute threshold
+		readyPodCount: 100,
+		timestamp:     now,
+	}
+
+	recommendation = autoscaler.Scale(snapshot, now)
+	if !recommendation.InBurstMode {
+		t.Error("expected to enter burst mode above the absolute threshold")
+	}
+	// ceil(10600/100) = 106
+	if recommendation.DesiredPodCount != 106 {
+		t.Errorf("expected pod count 106, got %d", recommendation.DesiredPodCount)
+	}
+}
+
 func TestSlidingWindowAutoscaler_Scale_RateLimits(t *testing.T) {
 	config := *libkpaconfig.NewDefaultAutoscalerConfig()
 	config.MaxScaleUpRate = 2.0   // Can double
@@ -370,6 +444,411 @@ func TestSlidingWindowAutoscaler_Scale_RateLimits(t *testing.T) {
 	}
 }
 
+func TestSlidingWindowAutoscaler_Scale_ScaleDownSoak(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.ScaleDownSoakTicks = 3
+
+	autoscaler, err := NewSlidingWindowAutoscaler(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Move past the initial burst period the autoscaler starts in.
+	now := time.Now().Add(config.StableWindow + time.Second)
+
+	steps := []struct {
+		value    float64
+		expected int32
+	}{
+		{1000, 10}, // baseline
+		{500, 10},  // 1st low reading, hold
+		{500, 10},  // 2nd low reading, hold
+		{1000, 10}, // load is back, soak restarts
+		{600, 10},  // 1st low reading, hold
+		{500, 10},  // 2nd low reading, hold
+		{500, 6},   // 3rd low reading, scale down to the highest low reading
+		{500, 6},   // 1st low reading below 6, hold
+	}
+
+	for i, step := range steps {
+		now = now.Add(time.Second)
+		snapshot := &mockMetricSnapshot{
+			stableValue:   step.value,
+			burstValue:    step.value,
+			readyPodCount: 10,
+			timestamp:     now,
+		}
+		recommendation := autoscaler.Scale(snapshot, now)
+		if recommendation.DesiredPodCount != step.expected {
+			t.Errorf("step %d: expected pod count %d, got %d", i, step.expected, recommendation.DesiredPodCount)
+		}
+	}
+}
+
+func TestSlidingWindowAutoscaler_Scale_BurstEntry(t *testing.T) {
+	// 2500 in the burst window is 25 pods for 10 ready pods, over the burst
+	// threshold of 200%. The first evaluation leaves the initial burst mode.
+	spikes := []float64{1000, 2500, 1000, 2500, 2500, 2500, 2500}
+	tests := []struct {
+		name     string
+		ticks    int32
+		delay    time.Duration
+		expected []bool
+	}{
+		{"disabled", 0, 0, []bool{false, true, true, true, true, true, true}},
+		{"ticks", 3, 0, []bool{false, false, false, false, false, true, true}},
+		{"delay", 0, 2 * time.Second, []bool{false, false, false, false, false, true, true}},
+		{"ticks and delay", 2, 3 * time.Second, []bool{false, false, false, false, false, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := *libkpaconfig.NewDefaultAutoscalerConfig()
+			config.BurstEntryTicks = tt.ticks
+			config.BurstEntryDelay = tt.delay
+
+			start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			autoscaler, err := NewSlidingWindowAutoscalerAt(config, start)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Move past the initial burst period the autoscaler starts in.
+			now := start.Add(config.StableWindow + time.Second)
+			for i, burst := range spikes {
+				now = now.Add(time.Second)
+				snapshot := &mockMetricSnapshot{
+					stableValue:   1000,
+					burstValue:    burst,
+					readyPodCount: 10,
+					timestamp:     now,
+				}
+				recommendation := autoscaler.Scale(snapshot, now)
+				if recommendation.InBurstMode != tt.expected[i] {
+					t.Errorf("step %d: expected burst mode %v, got %v", i, tt.expected[i], recommendation.InBurstMode)
+				}
+			}
+		})
+	}
+}
+
+func TestSlidingWindowAutoscaler_Scale_Zones(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          float64
+		minPodsPerZone int32
+		maxScale       int32
+		expected       int32
+		zonePods       []int32
+	}{
+		{"rounded up to a multiple of the zones", 700, 0, 0, 9, []int32{3, 3, 3}},
+		{"already a multiple of the zones", 600, 0, 0, 6, []int32{2, 2, 2}},
+		{"single pod", 50, 0, 0, 3, []int32{1, 1, 1}},
+		{"per-zone floors", 300, 2, 0, 6, []int32{2, 2, 2}},
+		{"per-zone floors at zero load", 0, 2, 0, 6, []int32{2, 2, 2}},
+		{"rounded down within max scale", 1000, 0, 10, 9, []int32{3, 3, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := *libkpaconfig.NewDefaultAutoscalerConfig()
+			config.TargetValue = 100
+			config.Zones = 3
+			config.MinPodsPerZone = tt.minPodsPerZone
+			config.MaxScale = tt.maxScale
+
+			start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			autoscaler, err := NewSlidingWindowAutoscalerAt(config, start)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Move past the initial burst period the autoscaler starts in.
+			now := start.Add(config.StableWindow + time.Second)
+			snapshot := &mockMetricSnapshot{
+				stableValue:   tt.value,
+				burstValue:    tt.value,
+				readyPodCount: 5,
+				timestamp:     now,
+			}
+			recommendation := autoscaler.Scale(snapshot, now)
+			if recommendation.DesiredPodCount != tt.expected {
+				t.Errorf("expected %d pods, got %d", tt.expected, recommendation.DesiredPodCount)
+			}
+			if got := recommendation.ZonePodCounts(); !slices.Equal(got, tt.zonePods) {
+				t.Errorf("expected zone pod counts %v, got %v", tt.zonePods, got)
+			}
+		})
+	}
+}
+
+func TestSlidingWindowAutoscaler_Scale_ProvisioningRate(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 100
+	config.ProvisioningRate = 60
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	autoscaler, err := NewSlidingWindowAutoscalerAt(config, start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Move past the initial burst period the autoscaler starts in.
+	now := start.Add(config.StableWindow + time.Second)
+
+	// 60 pods per minute are 10 pods per 10s, on top of a minute's worth of
+	// credit for the first step.
+	steps := []struct {
+		advance  time.Duration
+		value    float64
+		ready    int32
+		expected int32
+		pending  int32
+	}{
+		{0, 1000, 10, 10, 0},
+		{10 * time.Second, 10000, 10, 70, 30},
+		{10 * time.Second, 10000, 70, 80, 20},
+		{5 * time.Second, 10000, 80, 85, 15},
+		{15 * time.Second, 10000, 85, 100, 0},
+		// The credit is capped at a minute's worth of pods.
+		{10 * time.Minute, 20000, 100, 160, 40},
+	}
+	for i, s := range steps {
+		now = now.Add(s.advance)
+		snapshot := &mockMetricSnapshot{
+			stableValue:   s.value,
+			burstValue:    s.value,
+			readyPodCount: s.ready,
+			timestamp:     now,
+		}
+		recommendation := autoscaler.Scale(snapshot, now)
+		if recommendation.DesiredPodCount != s.expected || recommendation.PendingPodCount != s.pending {
+			t.Errorf("step %d: expected %d pods with %d pending, got %d with %d pending",
+				i, s.expected, s.pending, recommendation.DesiredPodCount, recommendation.PendingPodCount)
+		}
+		if limited := recommendation.LimitedBy == api.LimitProvisioningRate; limited != (s.pending > 0) {
+			t.Errorf("step %d: expected limited by the provisioning rate %v, got limited by %q", i, s.pending > 0, recommendation.LimitedBy)
+		}
+	}
+}
+
+func TestSlidingWindowAutoscaler_Scale_MaxScaleDownFraction(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 100
+	config.MaxScaleDownFraction = 0.25
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	autoscaler, err := NewSlidingWindowAutoscalerAt(config, start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Move past the initial burst period the autoscaler starts in.
+	now := start.Add(config.StableWindow + time.Second)
+
+	steps := []struct {
+		value     float64
+		ready     int32
+		expected  int32
+		limitedBy api.ScaleLimit
+	}{
+		// A quarter of 20 pods may be removed, less than the halving allowed
+		// by MaxScaleDownRate.
+		{200, 20, 15, api.LimitScaleDownFraction},
+		// A quarter of 15 pods is rounded up to 4 pods.
+		{200, 15, 11, api.LimitScaleDownFraction},
+		{1000, 11, 10, api.LimitNone},
+		// Scale-ups are not limited.
+		{3000, 10, 30, api.LimitNone},
+	}
+	for i, s := range steps {
+		now = now.Add(time.Second)
+		snapshot := &mockMetricSnapshot{
+			stableValue:   s.value,
+			burstValue:    s.value,
+			readyPodCount: s.ready,
+			timestamp:     now,
+		}
+		recommendation := autoscaler.Scale(snapshot, now)
+		if recommendation.DesiredPodCount != s.expected || recommendation.LimitedBy != s.limitedBy {
+			t.Errorf("step %d: expected %d pods limited by %q, got %d limited by %q",
+				i, s.expected, s.limitedBy, recommendation.DesiredPodCount, recommendation.LimitedBy)
+		}
+	}
+}
+
+func TestSlidingWindowAutoscaler_Scale_BurstBlending(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// The stable window asks for 10 pods. The burst ratio of 10 ready pods
+	// weighs the burst pod count from 0 at a ratio of 1 to 1 at the burst
+	// threshold of 2.
+	steps := []struct {
+		burstValue float64
+		blended    int32
+		binary     int32
+	}{
+		{1000, 10, 10},
+		{1500, 13, 10},
+		{1800, 17, 10},
+		{2000, 20, 20},
+		{3000, 30, 30},
+		{1500, 13, 30},
+		{800, 10, 30},
+	}
+
+	for _, blending := range []bool{true, false} {
+		config := *libkpaconfig.NewDefaultAutoscalerConfig()
+		config.TargetValue = 100
+		config.BurstBlending = blending
+
+		autoscaler, err := NewSlidingWindowAutoscalerAt(config, start)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		now := start.Add(config.StableWindow + time.Second)
+
+		for i, step := range steps {
+			now = now.Add(time.Second)
+			snapshot := &mockMetricSnapshot{stableValue: 1000, burstValue: step.burstValue, readyPodCount: 10, timestamp: now}
+			want := step.binary
+			if blending {
+				want = step.blended
+			}
+			if got := autoscaler.Scale(snapshot, now).DesiredPodCount; got != want {
+				t.Errorf("blending = %v, step %d: DesiredPodCount = %d, want %d", blending, i, got, want)
+			}
+		}
+	}
+}
+
+func TestSlidingWindowAutoscaler_Scale_ScaleToZeroDeadBand(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 100
+	config.LastPodStickiness = true
+	config.ActivationThreshold = 10
+
+	autoscaler, err := NewSlidingWindowAutoscalerAt(config, start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := start.Add(config.StableWindow + time.Second)
+
+	// The last pod is kept until the load was zero for the grace period of
+	// 30s, and scaling from zero needs a burst value above 10.
+	steps := []struct {
+		elapsed       time.Duration
+		value         float64
+		readyPodCount int32
+		want          int32
+	}{
+		{0, 0, 1, 1},
+		{20 * time.Second, 0, 1, 1},
+		{25 * time.Second, 5, 1, 1},
+		{30 * time.Second, 0, 1, 1},
+		{59 * time.Second, 0, 1, 1},
+		{60 * time.Second, 0, 1, 0},
+		{61 * time.Second, 5, 0, 0},
+		{62 * time.Second, 10, 0, 0},
+		{63 * time.Second, 20, 0, 1},
+	}
+	for i, step := range steps {
+		at := now.Add(step.elapsed)
+		snapshot := &mockMetricSnapshot{stableValue: step.value, burstValue: step.value, readyPodCount: step.readyPodCount, timestamp: at}
+		if got := autoscaler.Scale(snapshot, at).DesiredPodCount; got != step.want {
+			t.Errorf("step %d: DesiredPodCount = %d, want %d", i, got, step.want)
+		}
+	}
+}
+
+func TestSlidingWindowAutoscaler_Scale_ValidUntil(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 100
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(config.StableWindow + time.Second)
+
+	// The burst window of 10% of 60s moves past the data after 6s.
+	tests := []struct {
+		name     string
+		snapshot *mockMetricSnapshot
+		want     time.Time
+	}{
+		{
+			name:     "fresh data",
+			snapshot: &mockMetricSnapshot{stableValue: 100, burstValue: 100, readyPodCount: 1, timestamp: now},
+			want:     now.Add(6 * time.Second),
+		},
+		{
+			name:     "data recorded 2s ago",
+			snapshot: &mockMetricSnapshot{stableValue: 100, burstValue: 100, readyPodCount: 1, timestamp: now.Add(-2 * time.Second)},
+			want:     now.Add(4 * time.Second),
+		},
+		{
+			name:     "no timestamp",
+			snapshot: &mockMetricSnapshot{stableValue: 100, burstValue: 100, readyPodCount: 1},
+			want:     now.Add(6 * time.Second),
+		},
+		{
+			name:     "invalid",
+			snapshot: &mockMetricSnapshot{stableValue: -1, burstValue: -1, readyPodCount: 1, timestamp: now},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			autoscaler, err := NewSlidingWindowAutoscalerAt(config, start)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			rec := autoscaler.Scale(tt.snapshot, now)
+			if !rec.ValidUntil.Equal(tt.want) {
+				t.Errorf("ValidUntil = %v, want %v", rec.ValidUntil, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlidingWindowAutoscaler_Scale_Direction(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 100
+	autoscaler, err := NewSlidingWindowAutoscaler(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Move past the initial burst period the autoscaler starts in.
+	now := time.Now().Add(config.StableWindow + time.Second)
+
+	steps := []struct {
+		value     float64
+		ready     int32
+		previous  int32
+		desired   int32
+		direction api.ScaleDirection
+	}{
+		{value: 500, ready: 3, previous: 3, desired: 5, direction: api.ScaleUp}, // the first previous is the ready pod count
+		{value: 500, ready: 5, previous: 5, desired: 5, direction: api.ScaleNone},
+		{value: 300, ready: 5, previous: 5, desired: 3, direction: api.ScaleDown},
+		{value: -1, ready: 3}, // invalid, doesn't count as previous
+		{value: 300, ready: 4, previous: 3, desired: 3, direction: api.ScaleNone}, // not the ready pod count
+	}
+
+	for i, step := range steps {
+		now = now.Add(time.Second)
+		rec := autoscaler.Scale(&mockMetricSnapshot{
+			stableValue:   step.value,
+			burstValue:    step.value,
+			readyPodCount: step.ready,
+			timestamp:     now,
+		}, now)
+		if rec.PreviousDesiredPodCount != step.previous || rec.DesiredPodCount != step.desired || rec.Direction != step.direction {
+			t.Errorf("step %d: got %d -> %d (%v), want %d -> %d (%v)", i,
+				rec.PreviousDesiredPodCount, rec.DesiredPodCount, rec.Direction, step.previous, step.desired, step.direction)
+		}
+	}
+}
+
 func TestSlidingWindowAutoscaler_Update(t *testing.T) {
 	autoscaler, err := NewSlidingWindowAutoscaler(*libkpaconfig.NewDefaultAutoscalerConfig())
 	if err != nil {
@@ -583,6 +1062,58 @@ func TestBurstModeCalculator_ShouldEnterBurstMode(t *testing.T) {
 	}
 }
 
+func TestBurstModeCalculator_ShouldEnterBurstModeAbsolute(t *testing.T) {
+	tests := []struct {
+		name        string
+		threshold   float64
+		stableValue float64
+		burstValue  float64
+		shouldEnter bool
+	}{
+		{
+			name:        "disabled",
+			threshold:   0,
+			stableValue: 100,
+			burstValue:  10000,
+			shouldEnter: false,
+		},
+		{
+			name:        "below threshold",
+			threshold:   500,
+			stableValue: 1000,
+			burstValue:  1499,
+			shouldEnter: false,
+		},
+		{
+			name:        "at threshold",
+			threshold:   500,
+			stableValue: 1000,
+			burstValue:  1500,
+			shouldEnter: true,
+		},
+		{
+			name:        "burst below stable",
+			threshold:   500,
+			stableValue: 2000,
+			burstValue:  1000,
+			shouldEnter: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := *libkpaconfig.NewDefaultAutoscalerConfig()
+			config.BurstAbsoluteThreshold = tt.threshold
+			calculator := NewBurstModeCalculator(&config)
+
+			result := calculator.ShouldEnterBurstModeAbsolute(tt.stableValue, tt.burstValue)
+			if result != tt.shouldEnter {
+				t.Errorf("expected %v, got %v", tt.shouldEnter, result)
+			}
+		})
+	}
+}
+
 func TestBurstModeCalculator_ShouldExitBurstMode(t *testing.T) {
 	config := *libkpaconfig.NewDefaultAutoscalerConfig()
 	config.StableWindow = 60 * time.Second
@@ -686,3 +1217,743 @@ func TestBurstModeCalculator_CalculateDesiredPods(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkSlidingWindowAutoscalerScale(b *testing.B) {
+	for _, c := range []struct {
+		name string
+		new  func(api.AutoscalerConfig, time.Time) (*SlidingWindowAutoscaler, error)
+	}{
+		{"synchronized", NewSlidingWindowAutoscalerAt},
+		{"unsynchronized", NewSlidingWindowAutoscalerUnsynchronized},
+	} {
+		b.Run(c.name, func(b *testing.B) {
+			config := libkpaconfig.NewDefaultAutoscalerConfig()
+			now := time.Now()
+			autoscaler, err := c.new(*config, now)
+			if err != nil {
+				b.Fatalf("NewSlidingWindowAutoscaler failed: %v", err)
+			}
+			snapshot := &mockMetricSnapshot{
+				stableValue:   1000,
+				burstValue:    1000,
+				readyPodCount: 10,
+				timestamp:     now,
+			}
+			i := 0
+			for b.Loop() {
+				autoscaler.Scale(snapshot, now.Add(time.Duration(i)*time.Second))
+				i++
+			}
+		})
+	}
+}
+
+func TestNewSlidingWindowAutoscalerUnsynchronized(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 10
+	config.StableWindow = 10 * time.Second
+	config.ScaleDownDelay = 5 * time.Second
+
+	invalid := config
+	invalid.StableWindow = time.Second
+	if _, err := NewSlidingWindowAutoscalerUnsynchronized(invalid, time.Now()); err == nil {
+		t.Error("expected error for an invalid config")
+	}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	synced, _ := NewSlidingWindowAutoscalerAt(config, start)
+	unsynced, err := NewSlidingWindowAutoscalerUnsynchronized(config, start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Without locking the autoscaler makes the same decisions.
+	for i, load := range []float64{100, 100, 300, 300, 50, 50, 50, 50, 10, 10, 10, 10, 10, 10, 10, 10} {
+		now := start.Add(time.Duration(i) * time.Second)
+		snapshot := &mockMetricSnapshot{stableValue: load, burstValue: load, readyPodCount: 10, timestamp: now}
+		if got, want := unsynced.Scale(snapshot, now), synced.Scale(snapshot, now); got != want {
+			t.Errorf("Scale() at %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestPredictiveAutoscaler_Scale(t *testing.T) {
+	tests := []struct {
+		name       string
+		forecaster api.Forecaster
+		maxScale   int32
+		want       int32
+	}{{
+		name: "no forecaster",
+		want: 5,
+	}, {
+		name:       "confident forecast",
+		forecaster: api.ForecasterFunc(func(time.Duration) (float64, float64) { return 1000, 1 }),
+		want:       10,
+	}, {
+		name:       "blended by confidence",
+		forecaster: api.ForecasterFunc(func(time.Duration) (float64, float64) { return 1000, 0.5 }),
+		want:       8,
+	}, {
+		name:       "zero confidence",
+		forecaster: api.ForecasterFunc(func(time.Duration) (float64, float64) { return 1000, 0 }),
+		want:       5,
+	}, {
+		name:       "lower forecast does not scale down",
+		forecaster: api.ForecasterFunc(func(time.Duration) (float64, float64) { return 100, 1 }),
+		want:       5,
+	}, {
+		name:       "invalid forecast",
+		forecaster: api.ForecasterFunc(func(time.Duration) (float64, float64) { return math.NaN(), 1 }),
+		want:       5,
+	}, {
+		name:       "bounded by max scale",
+		forecaster: api.ForecasterFunc(func(time.Duration) (float64, float64) { return 1000, 1 }),
+		maxScale:   7,
+		want:       7,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := *libkpaconfig.NewDefaultAutoscalerConfig()
+			config.MaxScale = tt.maxScale
+
+			autoscaler, err := NewPredictiveAutoscaler(config, tt.forecaster, 30*time.Second)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Skip the initial burst mode.
+			now := time.Now().Add(config.StableWindow + time.Second)
+			snapshot := &mockMetricSnapshot{
+				stableValue:   500,
+				burstValue:    500,
+				readyPodCount: 5,
+				timestamp:     now,
+			}
+
+			recommendation := autoscaler.Scale(snapshot, now)
+			if !recommendation.ScaleValid {
+				t.Fatal("expected valid recommendation")
+			}
+			if recommendation.DesiredPodCount != tt.want {
+				t.Errorf("expected %d pods, got %d", tt.want, recommendation.DesiredPodCount)
+			}
+			if want := api.DirectionOf(5, tt.want); recommendation.Direction != want {
+				t.Errorf("expected direction %v, got %v", want, recommendation.Direction)
+			}
+
+			// The predicted pod count is the previous one of the next recommendation.
+			if next := autoscaler.Scale(snapshot, now.Add(time.Second)); next.PreviousDesiredPodCount != tt.want {
+				t.Errorf("expected previous pod count %d, got %d", tt.want, next.PreviousDesiredPodCount)
+			}
+		})
+	}
+}
+
+func TestPredictiveAutoscaler_Horizon(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	if _, err := NewPredictiveAutoscaler(config, nil, -time.Second); err == nil {
+		t.Error("expected error for a negative horizon")
+	}
+
+	var gotHorizon time.Duration
+	autoscaler, err := NewPredictiveAutoscaler(config, api.ForecasterFunc(func(h time.Duration) (float64, float64) {
+		gotHorizon = h
+		return 0, 1
+	}), time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := autoscaler.SetHorizon(-time.Second); err == nil {
+		t.Error("expected error for a negative horizon")
+	}
+	if err := autoscaler.SetHorizon(2 * time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now := time.Now()
+	autoscaler.Scale(&mockMetricSnapshot{stableValue: 100, burstValue: 100, readyPodCount: 1, timestamp: now}, now)
+	if gotHorizon != 2*time.Minute {
+		t.Errorf("expected forecast for a 2m horizon, got %v", gotHorizon)
+	}
+}
+
+func TestSlidingWindowAutoscaler_Scale_FromZero(t *testing.T) {
+	tests := []struct {
+		name            string
+		metricType      api.ScalingMetricType
+		targetValue     float64
+		totalTarget     float64
+		activationScale int32
+		stableValue     float64
+		burstValue      float64
+		readyPodCount   int32
+		wantValid       bool
+		wantPods        int32
+	}{{
+		name:          "total target with load",
+		totalTarget:   1000,
+		stableValue:   5000,
+		burstValue:    5000,
+		readyPodCount: 0,
+		wantValid:     true,
+		wantPods:      1,
+	}, {
+		name:          "total target with small load",
+		totalTarget:   1000,
+		stableValue:   0,
+		burstValue:    1,
+		readyPodCount: 0,
+		wantValid:     true,
+		wantPods:      1,
+	}, {
+		name:          "total target without load",
+		totalTarget:   1000,
+		readyPodCount: 0,
+		wantValid:     true,
+		wantPods:      0,
+	}, {
+		name:            "total target with activation scale",
+		totalTarget:     1000,
+		activationScale: 3,
+		stableValue:     5000,
+		burstValue:      5000,
+		readyPodCount:   0,
+		wantValid:       true,
+		wantPods:        3,
+	}, {
+		name:          "utilization with load",
+		metricType:    api.ScalingMetricUtilization,
+		targetValue:   50,
+		stableValue:   400,
+		burstValue:    400,
+		readyPodCount: 0,
+		wantValid:     true,
+		wantPods:      1,
+	}, {
+		name:          "per-pod target is not proportional",
+		targetValue:   100,
+		stableValue:   500,
+		burstValue:    500,
+		readyPodCount: 0,
+		wantValid:     true,
+		wantPods:      5,
+	}, {
+		name:          "total target with pods",
+		totalTarget:   1000,
+		stableValue:   2000,
+		burstValue:    2000,
+		readyPodCount: 2,
+		wantValid:     true,
+		wantPods:      4,
+	}, {
+		name:          "negative pod count",
+		totalTarget:   1000,
+		stableValue:   100,
+		burstValue:    100,
+		readyPodCount: -1,
+		wantValid:     false,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := *libkpaconfig.NewDefaultAutoscalerConfig()
+			if tt.metricType != "" {
+				config.ScalingMetricType = tt.metricType
+			}
+			config.TargetValue = tt.targetValue
+			config.TotalTargetValue = tt.totalTarget
+			config.MaxScaleUpRate = 10
+			if tt.activationScale > 0 {
+				config.ActivationScale = tt.activationScale
+			}
+
+			autoscaler, err := NewSlidingWindowAutoscaler(config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			now := time.Now().Add(config.StableWindow + time.Second)
+			recommendation := autoscaler.Scale(&mockMetricSnapshot{
+				stableValue:   tt.stableValue,
+				burstValue:    tt.burstValue,
+				readyPodCount: tt.readyPodCount,
+				timestamp:     now,
+			}, now)
+
+			if recommendation.ScaleValid != tt.wantValid {
+				t.Fatalf("ScaleValid = %v, want %v", recommendation.ScaleValid, tt.wantValid)
+			}
+			if tt.wantValid && recommendation.DesiredPodCount != tt.wantPods {
+				t.Errorf("DesiredPodCount = %d, want %d", recommendation.DesiredPodCount, tt.wantPods)
+			}
+		})
+	}
+}
+
+// TestSlidingWindowAutoscaler_Scale_BoundsPrecedence documents the order in
+// which the bounds apply: the rate limits first, then ActivationScale, then
+// MinScale and finally MaxScale.
+func TestSlidingWindowAutoscaler_Scale_BoundsPrecedence(t *testing.T) {
+	tests := []struct {
+		name                  string
+		minScale              int32
+		maxScale              int32
+		activationScale       int32
+		ignoreActivationScale bool
+		maxScaleUpRate        float64
+		value                 float64
+		readyPodCount         int32
+		want                  int32
+	}{{
+		name:            "activation scale raises tiny load",
+		activationScale: 3,
+		value:           1,
+		readyPodCount:   1,
+		want:            3,
+	}, {
+		name:            "activation scale applies above min scale",
+		minScale:        2,
+		activationScale: 3,
+		value:           1,
+		readyPodCount:   2,
+		want:            3,
+	}, {
+		name:                  "activation scale ignored with min scale",
+		minScale:              2,
+		activationScale:       3,
+		ignoreActivationScale: true,
+		value:                 1,
+		readyPodCount:         2,
+		want:                  2,
+	}, {
+		name:                  "activation scale not ignored without min scale",
+		activationScale:       3,
+		ignoreActivationScale: true,
+		value:                 1,
+		readyPodCount:         1,
+		want:                  3,
+	}, {
+		name:            "activation scale doesn't block scale to zero",
+		activationScale: 3,
+		value:           0,
+		readyPodCount:   1,
+		want:            0,
+	}, {
+		name:            "activation scale overrides scale up rate",
+		activationScale: 5,
+		maxScaleUpRate:  2,
+		value:           1,
+		readyPodCount:   1,
+		want:            5,
+	}, {
+		name:            "max scale overrides activation scale",
+		maxScale:        3,
+		activationScale: 5,
+		value:           1,
+		readyPodCount:   1,
+		want:            3,
+	}, {
+		name:          "scale down rate",
+		value:         0,
+		readyPodCount: 10,
+		want:          5,
+	}, {
+		name:          "min scale overrides scale down rate",
+		minScale:      8,
+		value:         0,
+		readyPodCount: 10,
+		want:          8,
+	}, {
+		name:           "scale up rate",
+		maxScaleUpRate: 2,
+		value:          100,
+		readyPodCount:  2,
+		want:           4,
+	}, {
+		name:           "max scale overrides scale up rate",
+		maxScale:       3,
+		maxScaleUpRate: 2,
+		value:          100,
+		readyPodCount:  2,
+		want:           3,
+	}, {
+		name:          "min scale equal to max scale",
+		minScale:      4,
+		maxScale:      4,
+		value:         100,
+		readyPodCount: 2,
+		want:          4,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := *libkpaconfig.NewDefaultAutoscalerConfig()
+			config.TargetValue = 10
+			config.MinScale = tt.minScale
+			config.MaxScale = tt.maxScale
+			if tt.activationScale > 0 {
+				config.ActivationScale = tt.activationScale
+			}
+			config.IgnoreActivationScaleWithMinScale = tt.ignoreActivationScale
+			if tt.maxScaleUpRate > 0 {
+				config.MaxScaleUpRate = tt.maxScaleUpRate
+			}
+
+			autoscaler, err := NewSlidingWindowAutoscaler(config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			now := time.Now().Add(config.StableWindow + time.Second)
+			recommendation := autoscaler.Scale(&mockMetricSnapshot{
+				stableValue:   tt.value,
+				burstValue:    tt.value,
+				readyPodCount: tt.readyPodCount,
+				timestamp:     now,
+			}, now)
+
+			if !recommendation.ScaleValid {
+				t.Fatal("expected valid recommendation")
+			}
+			if recommendation.DesiredPodCount != tt.want {
+				t.Errorf("DesiredPodCount = %d, want %d", recommendation.DesiredPodCount, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlidingWindowAutoscaler_Scale_Deterministic(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 10
+	config.StableWindow = 10 * time.Second
+	config.ScaleDownDelay = 4 * time.Second
+	config.ScaleDownSoakTicks = 2
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	values := []float64{10, 50, 200, 200, 30, 30, 30, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+
+	run := func(invalid bool) []api.ScaleRecommendation {
+		autoscaler, err := NewSlidingWindowAutoscalerAt(config, start)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var recs []api.ScaleRecommendation
+		pods := int32(1)
+		for i, v := range values {
+			now := start.Add(time.Duration(i) * time.Second)
+			if invalid {
+				// Invalid snapshots must not change the state.
+				rec := autoscaler.Scale(&mockMetricSnapshot{stableValue: -1, burstValue: -1, readyPodCount: pods, timestamp: now}, now)
+				if rec.ScaleValid {
+					t.Fatal("expected invalid recommendation")
+				}
+			}
+			rec := autoscaler.Scale(&mockMetricSnapshot{stableValue: v, burstValue: v, readyPodCount: pods, timestamp: now}, now)
+			pods = rec.DesiredPodCount
+			recs = append(recs, rec)
+		}
+		return recs
+	}
+
+	want := run(false)
+	for i := range 3 {
+		got := run(i%2 == 1)
+		if len(got) != len(want) {
+			t.Fatalf("got %d recommendations, want %d", len(got), len(want))
+		}
+		for j := range want {
+			if got[j] != want[j] {
+				t.Errorf("run %d, step %d: recommendation = %+v, want %+v", i, j, got[j], want[j])
+			}
+		}
+	}
+}
+
+func TestSlidingWindowAutoscaler_Scale_StaleTimestamp(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 10
+	config.StableWindow = 10 * time.Second
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	autoscaler, err := NewSlidingWindowAutoscalerAt(config, start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Extend burst mode at start+5s, then call late with start+1s.
+	now := start.Add(5 * time.Second)
+	autoscaler.Scale(&mockMetricSnapshot{stableValue: 100, burstValue: 100, readyPodCount: 1, timestamp: now}, now)
+	late := start.Add(time.Second)
+	autoscaler.Scale(&mockMetricSnapshot{stableValue: 100, burstValue: 100, readyPodCount: 1, timestamp: late}, late)
+
+	// Burst mode lasts a stable window after start+5s, not after start+1s.
+	now = start.Add(12 * time.Second)
+	rec := autoscaler.Scale(&mockMetricSnapshot{stableValue: 10, burstValue: 10, readyPodCount: 10, timestamp: now}, now)
+	if !rec.InBurstMode {
+		t.Error("expected to still be in burst mode")
+	}
+
+	now = start.Add(16 * time.Second)
+	rec = autoscaler.Scale(&mockMetricSnapshot{stableValue: 10, burstValue: 10, readyPodCount: 10, timestamp: now}, now)
+	if rec.InBurstMode {
+		t.Error("expected burst mode to be over")
+	}
+}
+
+func TestSlidingWindowAutoscaler_PeekScale(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 10
+	config.StableWindow = 10 * time.Second
+	config.ScaleDownDelay = 4 * time.Second
+	config.ScaleDownSoakTicks = 2
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	peeking, err := NewSlidingWindowAutoscalerAt(config, start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reference, err := NewSlidingWindowAutoscalerAt(config, start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pods := int32(1)
+	for i, v := range []float64{10, 200, 200, 30, 30, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0} {
+		now := start.Add(time.Duration(i) * time.Second)
+		snapshot := &mockMetricSnapshot{stableValue: v, burstValue: v, readyPodCount: pods, timestamp: now}
+
+		// Peek a few times, also at another load, before scaling.
+		peeked := peeking.PeekScale(snapshot, now)
+		peeking.PeekScale(&mockMetricSnapshot{stableValue: 1000, burstValue: 1000, readyPodCount: pods, timestamp: now}, now)
+		if again := peeking.PeekScale(snapshot, now); again != peeked {
+			t.Errorf("step %d: PeekScale() = %+v, then %+v", i, peeked, again)
+		}
+
+		got := peeking.Scale(snapshot, now)
+		want := reference.Scale(snapshot, now)
+		if got != want {
+			t.Errorf("step %d: Scale() after PeekScale() = %+v, want %+v", i, got, want)
+		}
+		if peeked != want {
+			t.Errorf("step %d: PeekScale() = %+v, want %+v", i, peeked, want)
+		}
+		pods = want.DesiredPodCount
+	}
+}
+
+func TestPredictiveAutoscaler_PeekScale(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 10
+
+	forecaster := api.ForecasterFunc(func(time.Duration) (float64, float64) { return 100, 1 })
+	autoscaler, err := NewPredictiveAutoscaler(config, forecaster, 30*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now := time.Now().Add(config.StableWindow + time.Second)
+	snapshot := &mockMetricSnapshot{stableValue: 20, burstValue: 20, readyPodCount: 2, timestamp: now}
+
+	peeked := autoscaler.PeekScale(snapshot, now)
+	if peeked.DesiredPodCount != 10 || peeked.Direction != api.ScaleUp {
+		t.Errorf("PeekScale() = %+v, want 10 pods up", peeked)
+	}
+	if got := autoscaler.Scale(snapshot, now); got != peeked {
+		t.Errorf("Scale() = %+v, want %+v", got, peeked)
+	}
+}
+
+func TestSlidingWindowAutoscaler_Scale_SameTick(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 10
+	config.StableWindow = 10 * time.Second
+	config.ScaleDownSoakTicks = 3
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	autoscaler, err := NewSlidingWindowAutoscalerAt(config, start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Leave the initial burst mode at 10 pods.
+	now := start.Add(11 * time.Second)
+	if rec := autoscaler.Scale(&mockMetricSnapshot{stableValue: 100, burstValue: 100, readyPodCount: 10, timestamp: now}, now); rec.DesiredPodCount != 10 {
+		t.Fatalf("DesiredPodCount = %d, want 10", rec.DesiredPodCount)
+	}
+
+	// Many low readings within one tick count as one towards the soak.
+	now = now.Add(time.Second)
+	low := &mockMetricSnapshot{stableValue: 50, burstValue: 50, readyPodCount: 10, timestamp: now}
+	first := autoscaler.Scale(low, now)
+	for i := range 5 {
+		if rec := autoscaler.Scale(low, now.Add(time.Duration(i)*time.Millisecond)); rec != first {
+			t.Errorf("repeated Scale() = %+v, want %+v", rec, first)
+		}
+	}
+	if first.DesiredPodCount != 10 {
+		t.Errorf("DesiredPodCount = %d, want 10 while soaking", first.DesiredPodCount)
+	}
+
+	// Another snapshot within the tick replaces the decision.
+	lower := &mockMetricSnapshot{stableValue: 40, burstValue: 40, readyPodCount: 10, timestamp: now}
+	if rec := autoscaler.Scale(lower, now); rec.DesiredPodCount != 10 || rec.PreviousDesiredPodCount != 10 {
+		t.Errorf("replaced Scale() = %+v, want 10 pods after 10", rec)
+	}
+
+	// Only the third tick with low readings scales down.
+	now = now.Add(time.Second)
+	if rec := autoscaler.Scale(lower, now); rec.DesiredPodCount != 10 {
+		t.Errorf("DesiredPodCount = %d at the second low tick, want 10", rec.DesiredPodCount)
+	}
+	now = now.Add(time.Second)
+	if rec := autoscaler.Scale(lower, now); rec.DesiredPodCount != 5 {
+		t.Errorf("DesiredPodCount = %d at the third low tick, want 5", rec.DesiredPodCount)
+	}
+}
+
+func TestSlidingWindowAutoscaler_Scale_TickFollowsGranularity(t *testing.T) {
+	tests := []struct {
+		name        string
+		granularity time.Duration
+		interval    time.Duration
+		want        []int32
+	}{
+		{name: "sub-second ticks", granularity: 250 * time.Millisecond, interval: 250 * time.Millisecond, want: []int32{10, 10, 5}},
+		{name: "readings within a tick", granularity: 2 * time.Second, interval: time.Second, want: []int32{10, 10, 10, 10, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := *libkpaconfig.NewDefaultAutoscalerConfig()
+			config.TargetValue = 10
+			config.StableWindow = time.Minute
+			config.WindowGranularity = tt.granularity
+			config.ScaleDownSoakTicks = 3
+
+			start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			autoscaler, err := NewSlidingWindowAutoscalerAt(config, start)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Leave the initial burst mode at 10 pods, in the second half of a
+			// 2s tick.
+			now := start.Add(config.StableWindow + 3*time.Second)
+			autoscaler.Scale(&mockMetricSnapshot{stableValue: 100, burstValue: 100, readyPodCount: 10, timestamp: now}, now)
+
+			// Every tick with low readings counts once towards the soak.
+			for i, want := range tt.want {
+				now = now.Add(tt.interval)
+				low := &mockMetricSnapshot{stableValue: 50, burstValue: 50, readyPodCount: 10, timestamp: now}
+				if rec := autoscaler.Scale(low, now); rec.DesiredPodCount != want {
+					t.Errorf("reading %d: DesiredPodCount = %d, want %d", i, rec.DesiredPodCount, want)
+				}
+			}
+		})
+	}
+}
+
+// histogramTransmitter records the histogram observations it receives.
+type histogramTransmitter struct {
+	transmitter.NoOpTransmitter
+	observations map[string][]float64
+}
+
+func (h *histogramTransmitter) RecordHistogram(ctx context.Context, name string, value float64) {
+	h.observations[name] = append(h.observations[name], value)
+}
+
+func TestSlidingWindowAutoscaler_SetLatencyTransmitter(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	autoscaler, err := NewSlidingWindowAutoscalerAt(config, start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	h := &histogramTransmitter{observations: map[string][]float64{}}
+	autoscaler.SetLatencyTransmitter(h)
+	for i := range 3 {
+		now := start.Add(time.Duration(i) * time.Second)
+		autoscaler.Scale(&mockMetricSnapshot{stableValue: 100, burstValue: 100, readyPodCount: 1, timestamp: now}, now)
+	}
+	// PeekScale isn't a scaling decision.
+	autoscaler.PeekScale(&mockMetricSnapshot{stableValue: 100, burstValue: 100, readyPodCount: 1, timestamp: start}, start)
+
+	latencies := h.observations[transmitter.ScaleLatencyMetric]
+	if len(latencies) != 3 {
+		t.Fatalf("got %d latency observations, want 3", len(latencies))
+	}
+	for _, l := range latencies {
+		if l < 0 {
+			t.Errorf("latency = %v, want >= 0", l)
+		}
+	}
+
+	autoscaler.SetLatencyTransmitter(nil)
+	autoscaler.Scale(&mockMetricSnapshot{stableValue: 100, burstValue: 100, readyPodCount: 1, timestamp: start}, start.Add(time.Minute))
+	if got := len(h.observations[transmitter.ScaleLatencyMetric]); got != 3 {
+		t.Errorf("got %d latency observations after disabling, want 3", got)
+	}
+}
+
+// panickingSnapshot is a snapshot whose values panic when read.
+type panickingSnapshot struct {
+	mockMetricSnapshot
+}
+
+func (p *panickingSnapshot) StableValue() float64 { panic("stable value") }
+
+func TestSlidingWindowAutoscaler_Scale_RecoveredPanic(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	autoscaler, err := NewSlidingWindowAutoscalerAt(config, start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("Scale didn't panic")
+			}
+		}()
+		autoscaler.Scale(&panickingSnapshot{mockMetricSnapshot{readyPodCount: 1, timestamp: start}}, start)
+	}()
+
+	done := make(chan api.ScaleRecommendation)
+	go func() {
+		autoscaler.GetConfig()
+		now := start.Add(time.Second)
+		done <- autoscaler.Scale(&mockMetricSnapshot{stableValue: 100, burstValue: 100, readyPodCount: 1, timestamp: now}, now)
+	}()
+	select {
+	case rec := <-done:
+		if !rec.ScaleValid {
+			t.Error("Scale after a recovered panic returned an invalid recommendation")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the autoscaler is still locked after a recovered panic")
+	}
+}
+
+func TestSlidingWindowAutoscaler_SizeBytes(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	withoutDelay, _ := NewSlidingWindowAutoscaler(config)
+	config.ScaleDownDelay = 10 * time.Minute
+	withDelay, _ := NewSlidingWindowAutoscaler(config)
+
+	if got := withoutDelay.SizeBytes(); got <= 0 {
+		t.Errorf("SizeBytes() = %d, want positive", got)
+	}
+	// The delay window holds a bucket per 2s.
+	if withDelay.SizeBytes() <= withoutDelay.SizeBytes()+300*8 {
+		t.Errorf("SizeBytes() with a scale-down delay = %d, want more than %d plus the delay window", withDelay.SizeBytes(), withoutDelay.SizeBytes())
+	}
+
+	predictive, _ := NewPredictiveAutoscaler(config, nil, time.Minute)
+	if predictive.SizeBytes() <= withDelay.SizeBytes() {
+		t.Errorf("SizeBytes() of a predictive autoscaler = %d, want more than %d", predictive.SizeBytes(), withDelay.SizeBytes())
+	}
+}
@@ -50,6 +50,17 @@ func (p *BurstModeCalculator) ShouldEnterBurstMode(desiredPodCount, currentPodCo
 	return desiredPodCount/currentPodCount >= p.config.BurstThreshold
 }
 
+// ShouldEnterBurstModeAbsolute determines if the autoscaler should enter burst mode
+// because the burst window value exceeds the stable window value by at least
+// the configured absolute threshold. It always returns false if the absolute
+// threshold is disabled.
+func (p *BurstModeCalculator) ShouldEnterBurstModeAbsolute(stableValue, burstValue float64) bool {
+	if p.config.BurstAbsoluteThreshold <= 0 {
+		return false
+	}
+	return burstValue-stableValue >= p.config.BurstAbsoluteThreshold
+}
+
 // ShouldExitBurstMode determines if the autoscaler should exit burst mode.
 func (p *BurstModeCalculator) ShouldExitBurstMode(burstStartTime time.Time, now time.Time, isOverThreshold bool) bool {
 	// Exit burst mode if:
@@ -0,0 +1,156 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package algorithm
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+	libkpaconfig "github.com/Fedosin/libkpa/config"
+)
+
+// scaleOnce returns the pod count recommended by a new autoscaler right after
+// its initial burst period.
+func scaleOnce(t *testing.T, config api.AutoscalerConfig, stable, burst float64, readyPods int32) int32 {
+	t.Helper()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	autoscaler, err := NewSlidingWindowAutoscalerAt(config, start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := start.Add(config.StableWindow + time.Second)
+	rec := autoscaler.Scale(&mockMetricSnapshot{
+		stableValue:   stable,
+		burstValue:    burst,
+		readyPodCount: readyPods,
+		timestamp:     now,
+	}, now)
+	if !rec.ScaleValid {
+		t.Fatalf("unexpected invalid recommendation for %v/%v at %d pods", stable, burst, readyPods)
+	}
+	return rec.DesiredPodCount
+}
+
+// invarianceConfigs returns scale invariant configurations of all modes
+// computing pod counts.
+func invarianceConfigs(target float64) map[string]api.AutoscalerConfig {
+	perPod := *libkpaconfig.NewDefaultAutoscalerConfig()
+	perPod.TargetValue = target
+
+	total := perPod
+	total.TargetValue = 0
+	total.TotalTargetValue = target
+
+	utilization := *libkpaconfig.NewDefaultAutoscalerConfigForMetric(api.ScalingMetricUtilization)
+	utilization.TargetValue = target
+
+	configs := map[string]api.AutoscalerConfig{"per-pod": perPod, "total": total, "utilization": utilization}
+	for name, config := range configs {
+		config.ScaleInvariant = true
+		config.ActivationScale = 3
+		configs[name] = config
+	}
+	return configs
+}
+
+// TestScaleInvariance verifies that k times the load at k times the ready
+// pods is recommended k times the pods, up to less than k pods of rounding.
+func TestScaleInvariance(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for range 200 {
+		target := 0.1 + rng.Float64()*100
+		readyPods := int32(1 + rng.Intn(50))
+		k := int32(2 + rng.Intn(4))
+		stable := rng.Float64() * target * 100
+		burst := stable * (0.5 + rng.Float64()*3)
+
+		for name, config := range invarianceConfigs(target) {
+			// The total target and utilization modes take the metric per pod,
+			// which doesn't change with the load per pod.
+			stable, burst, scale := stable, burst, float64(k)
+			if name != "per-pod" {
+				stable, burst, scale = stable/100, burst/100, 1
+			}
+			want := k * scaleOnce(t, config, stable, burst, readyPods)
+			got := scaleOnce(t, config, stable*scale, burst*scale, k*readyPods)
+			if got <= want-k || got >= want+k {
+				t.Errorf("%s: %vx load of %v/%v at %d pods = %d pods, want %d ± %d",
+					name, k, stable, burst, readyPods, got, want, k-1)
+			}
+		}
+	}
+}
+
+// TestScaleInvarianceWholePods verifies that recommendations are exactly
+// proportional if the load is a whole number of pods.
+func TestScaleInvarianceWholePods(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for range 200 {
+		// Targets like 0.1 and 0.7 are not exact in floating point.
+		target := float64(1+rng.Intn(9)) / 10
+		readyPods := int32(1 + rng.Intn(50))
+		k := int32(2 + rng.Intn(4))
+		pods := float64(1 + rng.Intn(100))
+
+		for name, config := range invarianceConfigs(target) {
+			// Avoid the scale-down limit, which rounds half pods.
+			config.MaxScaleDownRate = 1000
+
+			stable, scale := pods*target, float64(k)
+			if name != "per-pod" {
+				stable, scale = pods*target/float64(readyPods), 1
+			}
+			want := k * scaleOnce(t, config, stable, stable, readyPods)
+			if got := scaleOnce(t, config, stable*scale, stable*scale, k*readyPods); got != want {
+				t.Errorf("%s: %vx load of %v pods at %d pods = %d pods, want %d", name, k, pods, readyPods, got, want)
+			}
+		}
+	}
+}
+
+func TestScaleInvariantRounding(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 0.1
+	config.ActivationScale = 3
+
+	// 0.1 * 3 / 0.1 is 3.0000000000000004 in floating point, and the
+	// activation scale raises a single pod to 3.
+	tenth := config.TargetValue
+	tests := []struct {
+		name           string
+		scaleInvariant bool
+		stable         float64
+		readyPods      int32
+		want           int32
+	}{
+		{"floating point error", false, tenth * 3, 3, 4},
+		{"floating point error ignored", true, tenth * 3, 3, 3},
+		{"activation scale", false, tenth, 1, 3},
+		{"activation scale ignored", true, tenth, 1, 1},
+		{"activation scale from zero", true, tenth, 0, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.ScaleInvariant = tt.scaleInvariant
+			if got := scaleOnce(t, config, tt.stable, tt.stable, tt.readyPods); got != tt.want {
+				t.Errorf("DesiredPodCount = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
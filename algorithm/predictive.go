@@ -0,0 +1,168 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package algorithm
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// PredictiveAutoscaler extends the sliding window algorithm with a forecast
+// of the scaling metric. The reactive recommendation is raised towards the
+// pod count needed for the predicted value, weighted by the confidence of
+// the forecast, so that pods are ready by the time the load arrives. The
+// forecast never lowers the reactive recommendation.
+type PredictiveAutoscaler struct {
+	*SlidingWindowAutoscaler
+
+	mu         sync.RWMutex
+	forecaster api.Forecaster
+	horizon    time.Duration
+}
+
+// NewPredictiveAutoscaler creates a new predictive autoscaler. The horizon is
+// how far ahead the forecast is requested, typically the time it takes for a
+// new pod to become ready. A nil forecaster makes the autoscaler behave like
+// the sliding window autoscaler until one is set.
+func NewPredictiveAutoscaler(config api.AutoscalerConfig, forecaster api.Forecaster, horizon time.Duration) (*PredictiveAutoscaler, error) {
+	if horizon < 0 {
+		return nil, fmt.Errorf("horizon = %v, must be at least 0", horizon)
+	}
+
+	sw, err := NewSlidingWindowAutoscaler(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PredictiveAutoscaler{
+		SlidingWindowAutoscaler: sw,
+		forecaster:              forecaster,
+		horizon:                 horizon,
+	}, nil
+}
+
+// SetForecaster replaces the forecaster. A nil forecaster disables the
+// predictive adjustment.
+func (a *PredictiveAutoscaler) SetForecaster(forecaster api.Forecaster) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.forecaster = forecaster
+}
+
+// SetHorizon changes how far ahead the forecast is requested.
+func (a *PredictiveAutoscaler) SetHorizon(horizon time.Duration) error {
+	if horizon < 0 {
+		return fmt.Errorf("horizon = %v, must be at least 0", horizon)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.horizon = horizon
+	return nil
+}
+
+// Horizon returns how far ahead the forecast is requested.
+func (a *PredictiveAutoscaler) Horizon() time.Duration {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.horizon
+}
+
+// Scale calculates the desired scale based on current metrics and the forecast.
+func (a *PredictiveAutoscaler) Scale(snapshot api.MetricSnapshot, now time.Time) api.ScaleRecommendation {
+	rec := a.SlidingWindowAutoscaler.Scale(snapshot, now)
+	if desired, ok := a.predict(snapshot, rec); ok {
+		a.amend(&rec, desired)
+	}
+	return rec
+}
+
+// PeekScale returns the recommendation Scale would return for the snapshot
+// at now, including the forecast, without changing the state of the
+// autoscaler.
+func (a *PredictiveAutoscaler) PeekScale(snapshot api.MetricSnapshot, now time.Time) api.ScaleRecommendation {
+	rec := a.SlidingWindowAutoscaler.PeekScale(snapshot, now)
+	if desired, ok := a.predict(snapshot, rec); ok {
+		rec.DesiredPodCount = desired
+		rec.Direction = api.DirectionOf(rec.PreviousDesiredPodCount, desired)
+	}
+	return rec
+}
+
+// SizeBytes returns the approximate memory used by the autoscaler in bytes.
+func (a *PredictiveAutoscaler) SizeBytes() int64 {
+	return int64(unsafe.Sizeof(*a)) + a.SlidingWindowAutoscaler.SizeBytes()
+}
+
+// predict returns the pod count of the reactive recommendation raised by
+// the forecast, and false if the forecast doesn't raise it.
+func (a *PredictiveAutoscaler) predict(snapshot api.MetricSnapshot, rec api.ScaleRecommendation) (int32, bool) {
+	if !rec.ScaleValid {
+		return 0, false
+	}
+
+	a.mu.RLock()
+	forecaster, horizon := a.forecaster, a.horizon
+	a.mu.RUnlock()
+	if forecaster == nil {
+		return 0, false
+	}
+
+	value, confidence := forecaster.Predict(horizon)
+	if !(confidence > 0) || !(value >= 0) || math.IsInf(value, 1) {
+		return 0, false
+	}
+	confidence = math.Min(confidence, 1)
+
+	config := a.GetConfig()
+	if snapshot.ReadyPodCount() == 0 && proportional(config) {
+		// Forecasts can't be converted to pods without ready pods.
+		return 0, false
+	}
+	readyPodCount := max(snapshot.ReadyPodCount(), 1)
+	predicted := rawPodCount(config, value, readyPodCount)
+	blended := int32(math.Ceil(confidence*predicted + (1-confidence)*float64(rec.DesiredPodCount)))
+	if blended <= rec.DesiredPodCount {
+		return 0, false
+	}
+
+	// The predicted pod count is subject to the same limits as the reactive one.
+	blended = min(blended, int32(math.Ceil(config.MaxScaleUpRate*float64(readyPodCount))))
+	if config.MaxScale > 0 {
+		blended = min(blended, config.MaxScale)
+	}
+	return max(rec.DesiredPodCount, blended), true
+}
+
+// rawPodCount returns the number of pods needed for the given metric value,
+// prior to applying any limits.
+func rawPodCount(config api.AutoscalerConfig, value float64, readyPodCount int32) float64 {
+	switch {
+	case config.ScalingMetricType == api.ScalingMetricUtilization:
+		return float64(readyPodCount) * value / config.TargetValue
+	case config.TargetValue > 0:
+		return value / config.TargetValue
+	case config.TotalTargetValue > 0:
+		return float64(readyPodCount) * value / config.TotalTargetValue
+	}
+	return 0
+}
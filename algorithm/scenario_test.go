@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package algorithm
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	libkpaconfig "github.com/Fedosin/libkpa/config"
+)
+
+var update = flag.Bool("update", false, "rewrite the expected recommendations of the scenarios")
+
+// scenario is a golden test of the sliding window autoscaler. The config is
+// a configuration map as accepted by config.LoadFromMap, and every step is
+// evaluated at its offset from the start of the autoscaler.
+type scenario struct {
+	Description string            `json:"description"`
+	Config      map[string]string `json:"config"`
+	Steps       []scenarioStep    `json:"steps"`
+}
+
+type scenarioStep struct {
+	At        string       `json:"at"`
+	Stable    float64      `json:"stable"`
+	Burst     float64      `json:"burst"`
+	ReadyPods int32        `json:"readyPods"`
+	Want      scenarioWant `json:"want"`
+}
+
+type scenarioWant struct {
+	Valid   bool  `json:"valid"`
+	Desired int32 `json:"desired"`
+	Burst   bool  `json:"burst"`
+}
+
+// scenarioStart is the start of the autoscaler in all scenarios.
+var scenarioStart = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// TestScenarios replays the traces in testdata/scenarios and compares the
+// recommendations with the expected timelines. Run with -update to record
+// the current behavior after an intended change, and review the diff.
+func TestScenarios(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "scenarios", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no scenarios found")
+	}
+
+	for _, file := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".json"), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var sc scenario
+			if err := json.Unmarshal(data, &sc); err != nil {
+				t.Fatalf("invalid scenario: %v", err)
+			}
+
+			cfg, err := libkpaconfig.LoadFromMapStrict(sc.Config)
+			if err != nil {
+				t.Fatalf("invalid config: %v", err)
+			}
+			autoscaler, err := NewSlidingWindowAutoscalerAt(*cfg, scenarioStart)
+			if err != nil {
+				t.Fatalf("NewSlidingWindowAutoscalerAt() error = %v", err)
+			}
+
+			for i := range sc.Steps {
+				step := &sc.Steps[i]
+				at, err := time.ParseDuration(step.At)
+				if err != nil {
+					t.Fatalf("step %d: invalid offset: %v", i, err)
+				}
+				now := scenarioStart.Add(at)
+				rec := autoscaler.Scale(&mockMetricSnapshot{
+					stableValue:   step.Stable,
+					burstValue:    step.Burst,
+					readyPodCount: step.ReadyPods,
+					timestamp:     now,
+				}, now)
+
+				got := scenarioWant{Valid: rec.ScaleValid, Desired: rec.DesiredPodCount, Burst: rec.InBurstMode}
+				if *update {
+					step.Want = got
+				} else if got != step.Want {
+					t.Errorf("step %d at %s: got %+v, want %+v", i, step.At, got, step.Want)
+				}
+			}
+
+			if *update {
+				data, err := json.MarshalIndent(sc, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(file, append(data, '\n'), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -18,38 +18,104 @@ limitations under the License.
 package algorithm
 
 import (
+	"context"
 	"fmt"
 	"math"
-	"sync"
 	"time"
+	"unsafe"
 
 	"github.com/Fedosin/libkpa/api"
 	libkpaconfig "github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/internal/syncutil"
 	"github.com/Fedosin/libkpa/maxtimewindow"
+	"github.com/Fedosin/libkpa/transmitter"
 )
 
 // SlidingWindowAutoscaler implements the sliding window autoscaling algorithm
 // used by Knative's KPA (Knative Pod Autoscaler).
 type SlidingWindowAutoscaler struct {
-	mu sync.RWMutex
+	mu syncutil.RWMutex
 
 	// Configuration
 	config api.AutoscalerConfig
 
+	scaleState
+
+	// The decision of the latest tick and the state before it, so repeated
+	// calls within a tick replace the decision instead of adding another.
+	tick      tickKey
+	tickRec   api.ScaleRecommendation
+	tickState scaleState
+	hasTick   bool
+
+	// latencyTransmitter receives the duration of every Scale call, see
+	// SetLatencyTransmitter.
+	latencyTransmitter transmitter.MetricTransmitter
+}
+
+var _ api.Recommender = (*SlidingWindowAutoscaler)(nil)
+
+// scaleState is the state Scale carries from one decision to the next.
+type scaleState struct {
 	// State for burst mode
 	burstTime    time.Time
 	maxBurstPods int32
 
+	// State for the burst entry confirmation
+	overBurstSince time.Time
+	overBurstTicks int32
+
 	// Delay window for scale-down decisions
 	maxTimeWindow *maxtimewindow.TimeWindow
+
+	// State for the scale-down soak requirement
+	lastDesiredPodCount int32
+	hasLastDesired      bool
+	lowReadings         int32
+	lowReadingsMax      int32
+
+	// The previous recommended pod count
+	lastRecommended    int32
+	hasLastRecommended bool
+
+	// State for pacing scale-ups to the provisioning rate
+	provisioningCredit float64
+	provisionedAt      time.Time
+
+	// Start of the current run of zero load for LastPodStickiness
+	zeroSince time.Time
 }
 
-const (
-	scaleDownDelayGranularity = 2 * time.Second
-)
+// clone returns a copy of the state that shares nothing with s.
+func (s *scaleState) clone() scaleState {
+	c := *s
+	if s.maxTimeWindow != nil {
+		c.maxTimeWindow = s.maxTimeWindow.Clone()
+	}
+	return c
+}
+
+// tickKey identifies the inputs of a decision within a tick.
+type tickKey struct {
+	tick          int64
+	stableValue   float64
+	burstValue    float64
+	readyPodCount int32
+}
+
+const scaleDownDelayGranularity = 2 * time.Second
 
 // NewSlidingWindowAutoscaler creates a new sliding window autoscaler.
 func NewSlidingWindowAutoscaler(config api.AutoscalerConfig) (*SlidingWindowAutoscaler, error) {
+	return NewSlidingWindowAutoscalerAt(config, time.Now())
+}
+
+// NewSlidingWindowAutoscalerAt creates a new sliding window autoscaler that
+// starts at the given time rather than the current one, e.g. in simulations
+// and tests driven by a fake clock. The recommendations of such an autoscaler
+// only depend on its configuration, start and the valid snapshots and
+// timestamps passed to Scale.
+func NewSlidingWindowAutoscalerAt(config api.AutoscalerConfig, start time.Time) (*SlidingWindowAutoscaler, error) {
 	if err := libkpaconfig.Validate(&config); err != nil {
 		return nil, err
 	}
@@ -60,27 +126,140 @@ func NewSlidingWindowAutoscaler(config api.AutoscalerConfig) (*SlidingWindowAuto
 	}
 
 	result := &SlidingWindowAutoscaler{
-		config:        config,
-		maxTimeWindow: maxTimeWindow,
+		config: config,
 	}
+	result.maxTimeWindow = maxTimeWindow
 
 	// We always start in the burst mode.
 	// When Autoscaler restarts we lose metric history, which causes us to
 	// momentarily scale down, and that is not a desired behavior.
 	// Thus, we're keeping at least the current scale until we
 	// accumulate enough data to make conscious decisions.
-	result.burstTime = time.Now()
+	result.burstTime = start
 
 	return result, nil
 }
 
+// NewSlidingWindowAutoscalerUnsynchronized is NewSlidingWindowAutoscalerAt
+// for autoscalers only ever used by a single goroutine, e.g. in tight
+// simulation loops. The autoscaler doesn't lock at all, so concurrent calls
+// of any of its methods are a data race. Use NewSlidingWindowAutoscaler
+// whenever the autoscaler is shared.
+func NewSlidingWindowAutoscalerUnsynchronized(config api.AutoscalerConfig, start time.Time) (*SlidingWindowAutoscaler, error) {
+	a, err := NewSlidingWindowAutoscalerAt(config, start)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Disable()
+	return a, nil
+}
+
 // Scale calculates the desired scale based on current metrics.
+//
+// The recommendation is deterministic: it only depends on the configuration,
+// the start of the autoscaler and the sequence of valid snapshots and
+// timestamps passed so far. Invalid snapshots don't change the state, and a
+// timestamp older than a previous one never moves the burst mode back in
+// time, so late calls can't shorten burst mode.
+//
+// Calls within the same tick, a bucket of WindowGranularity, are one
+// decision: repeating a call with the same snapshot returns the same
+// recommendation, and a call with another snapshot replaces the decision of
+// the previous one. So callers evaluating
+// several times per tick neither count extra readings towards the scale-down
+// delay and soak nor extend burst mode.
 func (a *SlidingWindowAutoscaler) Scale(snapshot api.MetricSnapshot, now time.Time) api.ScaleRecommendation {
+	start := time.Now()
+	rec, latencyTransmitter := a.lockedScale(snapshot, now)
+	if latencyTransmitter != nil {
+		transmitter.RecordHistogram(context.Background(), latencyTransmitter, transmitter.ScaleLatencyMetric, time.Since(start).Seconds())
+	}
+	return rec
+}
+
+// lockedScale runs scale under the lock and returns the latency transmitter
+// to report the call to. The lock is released even if scale panics, so a
+// recovered panic doesn't block later calls.
+func (a *SlidingWindowAutoscaler) lockedScale(snapshot api.MetricSnapshot, now time.Time) (api.ScaleRecommendation, transmitter.MetricTransmitter) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.scale(snapshot, now), a.latencyTransmitter
+}
+
+// SetLatencyTransmitter sets the transmitter the duration of every Scale call
+// is reported to as the transmitter.ScaleLatencyMetric histogram, in seconds
+// of wall clock time. The duration includes waiting for concurrent calls, so
+// it also reveals lock contention. Transmitters that don't record histograms
+// receive it as a gauge. Nil disables reporting.
+func (a *SlidingWindowAutoscaler) SetLatencyTransmitter(t transmitter.MetricTransmitter) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	a.latencyTransmitter = t
+}
+
+// PeekScale returns the recommendation Scale would return for the snapshot
+// at now, without changing the burst mode, scale-down delay and soak state
+// of the autoscaler. It answers what the autoscaler would recommend, e.g.
+// for dry runs and debugging.
+func (a *SlidingWindowAutoscaler) PeekScale(snapshot api.MetricSnapshot, now time.Time) api.ScaleRecommendation {
+	a.mu.RLock()
+	peek := a.clone()
+	a.mu.RUnlock()
+	return peek.scale(snapshot, now)
+}
+
+// clone returns a copy of the autoscaler with independent state.
+// The caller must hold the lock.
+func (a *SlidingWindowAutoscaler) clone() *SlidingWindowAutoscaler {
+	return &SlidingWindowAutoscaler{
+		config:     a.config,
+		scaleState: a.scaleState.clone(),
+		tick:       a.tick,
+		tickRec:    a.tickRec,
+		tickState:  a.tickState,
+		hasTick:    a.hasTick,
+	}
+}
+
+// scale implements Scale, making one decision per tick.
+// The caller must hold the lock.
+func (a *SlidingWindowAutoscaler) scale(snapshot api.MetricSnapshot, now time.Time) api.ScaleRecommendation {
+	key := tickKey{
+		tick:          now.Truncate(a.tickGranularity()).UnixNano(),
+		stableValue:   snapshot.StableValue(),
+		burstValue:    snapshot.BurstValue(),
+		readyPodCount: snapshot.ReadyPodCount(),
+	}
+	if a.hasTick && a.tick.tick == key.tick {
+		if a.tick == key {
+			return a.tickRec
+		}
+		// Replace the decision made earlier in this tick.
+		a.scaleState = a.tickState.clone()
+	} else {
+		a.tickState = a.scaleState.clone()
+	}
+
+	rec := a.decide(snapshot, now)
+	a.tick, a.tickRec, a.hasTick = key, rec, true
+	return rec
+}
 
+// tickGranularity is the resolution of the timestamps passed to Scale, the
+// WindowGranularity of the metric windows. Calls within the same bucket of
+// the windows are treated as one decision. The caller must hold the lock.
+func (a *SlidingWindowAutoscaler) tickGranularity() time.Duration {
+	if a.config.WindowGranularity > 0 {
+		return a.config.WindowGranularity
+	}
+	return time.Second
+}
+
+// decide makes a scaling decision. The caller must hold the lock.
+func (a *SlidingWindowAutoscaler) decide(snapshot api.MetricSnapshot, now time.Time) api.ScaleRecommendation {
 	// Get current ready pod count
 	readyPodCount := snapshot.ReadyPodCount()
+	scaleFromZero := readyPodCount == 0
 	if readyPodCount == 0 {
 		readyPodCount = 1 // Avoid division by zero
 	}
@@ -89,34 +268,68 @@ func (a *SlidingWindowAutoscaler) Scale(snapshot api.MetricSnapshot, now time.Ti
 	observedStableValue := snapshot.StableValue()
 	observedBurstValue := snapshot.BurstValue()
 
-	// If no data, return invalid recommendation
-	if observedStableValue < 0 || observedBurstValue < 0 {
+	// If no data or a negative pod count, return invalid recommendation
+	if observedStableValue < 0 || observedBurstValue < 0 || readyPodCount < 0 {
 		return api.ScaleRecommendation{
 			ScaleValid: false,
 		}
 	}
 
 	// Calculate scale limits based on current pod count
-	maxScaleUp := int32(math.Ceil(a.config.MaxScaleUpRate * float64(readyPodCount)))
-	maxScaleDown := int32(math.Floor(float64(readyPodCount) / a.config.MaxScaleDownRate))
+	maxScaleUp := int32(a.ceil(a.config.MaxScaleUpRate * float64(readyPodCount)))
+	maxScaleDown := int32(a.floor(float64(readyPodCount) / a.config.MaxScaleDownRate))
+
+	// Pod counts calculated directly from metrics, prior to rounding and
+	// applying any rate limits. In the per-pod mode the observed value is
+	// the total across all pods (this is always the case for concurrency and
+	// RPS metrics), so dividing it by the per-pod target gives the pod count.
+	// Validation guarantees request based metric types never use the total
+	// target mode.
+	var stablePods, burstPods float64
+
+	if a.config.ScalingMetricType == api.ScalingMetricUtilization {
+		// The observed value is the average utilization percentage across the
+		// ready pods, so the pod count is scaled proportionally like HPA does.
+		stablePods = float64(readyPodCount) * observedStableValue / a.config.TargetValue
+		burstPods = float64(readyPodCount) * observedBurstValue / a.config.TargetValue
+	} else if a.config.TargetValue > 0 {
+		stablePods = observedStableValue / a.config.TargetValue
+		burstPods = observedBurstValue / a.config.TargetValue
+	} else if a.config.TotalTargetValue > 0 {
+		stablePods = float64(readyPodCount) * observedStableValue / a.config.TotalTargetValue
+		burstPods = float64(readyPodCount) * observedBurstValue / a.config.TotalTargetValue
+	}
 
-	// raw pod counts calculated directly from metrics, prior to applying any rate limits.
-	var rawStablePodCount, rawBurstPodCount int32
+	// raw pod counts, prior to applying any rate limits.
+	rawStablePodCount := int32(a.ceil(stablePods))
+	rawBurstPodCount := int32(a.ceil(burstPods))
+
+	if scaleFromZero && proportional(a.config) {
+		// The pod count is proportional to the ready pods, which is meaningless
+		// without any. Any recent load scales from zero to a single pod, raised
+		// to the activation scale below, and the next evaluation with ready
+		// pods scales proportionally again.
+		rawStablePodCount, rawBurstPodCount = 0, 0
+		if observedBurstValue > 0 {
+			rawStablePodCount, rawBurstPodCount = 1, 1
+		}
+	}
 
-	if a.config.TargetValue > 0 {
-		rawStablePodCount = int32(math.Ceil(observedStableValue / a.config.TargetValue))
-		rawBurstPodCount = int32(math.Ceil(observedBurstValue / a.config.TargetValue))
-	} else if a.config.TotalTargetValue > 0 {
-		rawStablePodCount = int32(math.Ceil(float64(readyPodCount) * observedStableValue / a.config.TotalTargetValue))
-		rawBurstPodCount = int32(math.Ceil(float64(readyPodCount) * observedBurstValue / a.config.TotalTargetValue))
+	if scaleFromZero && a.config.ActivationThreshold > 0 && observedBurstValue <= a.config.ActivationThreshold {
+		// The load is within the dead band around zero, so stay at zero.
+		burstPods = 0
+		rawStablePodCount, rawBurstPodCount = 0, 0
 	}
 
 	// Apply scale limits
 	desiredStablePodCount := min(max(rawStablePodCount, maxScaleDown), maxScaleUp)
 	desiredBurstPodCount := min(max(rawBurstPodCount, maxScaleDown), maxScaleUp)
 
-	// Apply activation scale if needed
-	if a.config.ActivationScale > 1 {
+	// Apply activation scale if needed. Scale invariant autoscalers only
+	// apply it when scaling from zero, where there is nothing to be
+	// proportional to.
+	if a.config.ActivationScale > 1 && !(a.config.IgnoreActivationScaleWithMinScale && a.config.MinScale > 0) &&
+		(scaleFromZero || !a.config.ScaleInvariant) {
 		// Activation scale should apply only when there is actual demand (i.e. raw counts > 0).
 		// This prevents the activation scale from blocking scale-to-zero.
 		if rawStablePodCount > 0 && a.config.ActivationScale > desiredStablePodCount {
@@ -127,17 +340,29 @@ func (a *SlidingWindowAutoscaler) Scale(snapshot api.MetricSnapshot, now time.Ti
 		}
 	}
 
-	// Check burst mode conditions
-	isOverBurstThreshold := float64(rawBurstPodCount)/float64(readyPodCount) >= a.config.BurstThreshold
+	// Check burst mode conditions. Scale invariant autoscalers compare the
+	// unrounded pod count, so rounding can't flip the decision.
+	burstRatio := float64(rawBurstPodCount) / float64(readyPodCount)
+	if a.config.ScaleInvariant {
+		burstRatio = burstPods / float64(readyPodCount)
+	}
+	isOverBurstThreshold := burstRatio >= a.config.BurstThreshold
+	if NewBurstModeCalculator(&a.config).ShouldEnterBurstModeAbsolute(observedStableValue, observedBurstValue) {
+		// The burst window exceeds the stable window by an absolute amount.
+		isOverBurstThreshold = true
+	}
 	inBurstMode := !a.burstTime.IsZero()
+	if !isOverBurstThreshold {
+		a.overBurstTicks = 0
+	}
 
 	// Update burst mode state
 	switch {
-	case !inBurstMode && isOverBurstThreshold:
+	case !inBurstMode && isOverBurstThreshold && a.confirmBurst(now):
 		// Enter burst mode
 		a.burstTime = now
 		inBurstMode = true
-	case isOverBurstThreshold:
+	case inBurstMode && isOverBurstThreshold && now.After(a.burstTime):
 		// Extend burst mode
 		a.burstTime = now
 	case inBurstMode && !isOverBurstThreshold && a.burstTime.Add(a.config.StableWindow).Before(now):
@@ -149,7 +374,9 @@ func (a *SlidingWindowAutoscaler) Scale(snapshot api.MetricSnapshot, now time.Ti
 
 	// Determine final desired pod count
 	desiredPodCount := desiredStablePodCount
-	if inBurstMode {
+	if a.config.BurstBlending {
+		desiredPodCount = a.blend(desiredStablePodCount, desiredBurstPodCount, burstRatio)
+	} else if inBurstMode {
 		// Use the higher of stable or burst pod count
 		if desiredBurstPodCount > desiredPodCount {
 			desiredPodCount = desiredBurstPodCount
@@ -162,25 +389,330 @@ func (a *SlidingWindowAutoscaler) Scale(snapshot api.MetricSnapshot, now time.Ti
 		}
 	}
 
+	// Keep the last pod until the load has been zero for the grace period
+	desiredPodCount = a.applyLastPodStickiness(desiredPodCount, observedStableValue, observedBurstValue, snapshot.ReadyPodCount(), now)
+
 	// Apply scale-down delay if configured
 	if a.maxTimeWindow != nil {
 		a.maxTimeWindow.Record(now, desiredPodCount)
 		desiredPodCount = a.maxTimeWindow.Current()
 	}
 
-	// Apply min/max scale bounds
+	// Apply scale-down soak requirement if configured
+	desiredPodCount = a.applyScaleDownSoak(desiredPodCount)
+
+	wantedPodCount := desiredPodCount
+
+	// Limit the pods removed per evaluation if configured
+	if a.config.MaxScaleDownFraction > 0 {
+		readyPods := snapshot.ReadyPodCount()
+		removable := int32(math.Ceil(a.config.MaxScaleDownFraction * float64(readyPods)))
+		desiredPodCount = max(desiredPodCount, readyPods-removable)
+	}
+
+	// Pace scale-ups to the provisioning rate if configured
+	desiredPodCount = a.pace(desiredPodCount, snapshot.ReadyPodCount(), now)
+
+	rec := api.ScaleRecommendation{
+		DesiredPodCount: a.bound(desiredPodCount),
+		ScaleValid:      true,
+		InBurstMode:     inBurstMode,
+		Zones:           a.config.Zones,
+		ValidUntil:      a.validUntil(snapshot, now),
+	}
+
+	// Tell which constraint held the recommendation back, if the bounds
+	// didn't override it anyway.
+	wantedPodCount = a.bound(wantedPodCount)
+	switch {
+	case rec.DesiredPodCount < wantedPodCount:
+		rec.PendingPodCount = wantedPodCount - rec.DesiredPodCount
+		rec.LimitedBy = api.LimitProvisioningRate
+	case rec.DesiredPodCount > wantedPodCount:
+		rec.LimitedBy = api.LimitScaleDownFraction
+	}
+	a.setPrevious(&rec, snapshot.ReadyPodCount())
+	return rec
+}
+
+// blend combines the stable and burst pod counts for BurstBlending. The
+// weight of the burst pod count grows linearly from 0 at a burst ratio of 1,
+// where the burst window asks for the ready pods, to 1 at BurstThreshold.
+// Unlike burst mode, blending doesn't hold the pod count, so it follows the
+// burst window down as a burst fades.
+func (a *SlidingWindowAutoscaler) blend(stablePodCount, burstPodCount int32, burstRatio float64) int32 {
+	var w float64
+	switch {
+	case burstRatio >= a.config.BurstThreshold:
+		w = 1
+	case a.config.BurstThreshold > 1:
+		w = max(0, (burstRatio-1)/(a.config.BurstThreshold-1))
+	}
+	return int32(a.ceil(w*float64(burstPodCount) + (1-w)*float64(stablePodCount)))
+}
+
+// validUntil returns when a recommendation for the snapshot becomes stale:
+// once the burst window has moved past the data of the snapshot, the
+// recommendation no longer reflects any of the current burst data. Snapshots
+// without a timestamp are assumed to be taken at now.
+func (a *SlidingWindowAutoscaler) validUntil(snapshot api.MetricSnapshot, now time.Time) time.Time {
+	taken := snapshot.Timestamp()
+	if taken.IsZero() || taken.After(now) {
+		taken = now
+	}
+	burstWindow := max(time.Second, time.Duration(float64(a.config.StableWindow)*a.config.BurstWindowPercentage/100))
+	return taken.Add(burstWindow)
+}
+
+// bound applies the min/max scale bounds and spreads the pods across the
+// zones.
+func (a *SlidingWindowAutoscaler) bound(desiredPodCount int32) int32 {
 	if a.config.MinScale > 0 && desiredPodCount < a.config.MinScale {
 		desiredPodCount = a.config.MinScale
 	}
 	if a.config.MaxScale > 0 && desiredPodCount > a.config.MaxScale {
 		desiredPodCount = a.config.MaxScale
 	}
+	return a.spreadAcrossZones(desiredPodCount)
+}
 
-	return api.ScaleRecommendation{
-		DesiredPodCount: desiredPodCount,
-		ScaleValid:      true,
-		InBurstMode:     inBurstMode,
+// Activate raises the recommendation rec to at least desiredPodCount pods,
+// e.g. to activate a deployment at zero for requests waiting for it. The pod
+// count is bounded and spread across the zones like any recommendation, and
+// becomes the previous recommendation of the next decision, so that scale-ups
+// are paced from it. Recommendations of at least as many pods are returned
+// unchanged.
+func (a *SlidingWindowAutoscaler) Activate(rec api.ScaleRecommendation, desiredPodCount, readyPodCount int32) api.ScaleRecommendation {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.activate(rec, desiredPodCount, readyPodCount)
+}
+
+// PeekActivate returns the recommendation Activate would return, without
+// changing the state of the autoscaler.
+func (a *SlidingWindowAutoscaler) PeekActivate(rec api.ScaleRecommendation, desiredPodCount, readyPodCount int32) api.ScaleRecommendation {
+	a.mu.RLock()
+	peek := a.clone()
+	a.mu.RUnlock()
+	return peek.activate(rec, desiredPodCount, readyPodCount)
+}
+
+// activate implements Activate. The caller must hold the lock.
+func (a *SlidingWindowAutoscaler) activate(rec api.ScaleRecommendation, desiredPodCount, readyPodCount int32) api.ScaleRecommendation {
+	desiredPodCount = a.bound(desiredPodCount)
+	if rec.ScaleValid && rec.DesiredPodCount >= desiredPodCount {
+		return rec
+	}
+
+	rec.DesiredPodCount = desiredPodCount
+	rec.Zones = a.config.Zones
+	rec.PendingPodCount = 0
+	rec.LimitedBy = api.LimitNone
+	if rec.ScaleValid {
+		rec.Direction = api.DirectionOf(rec.PreviousDesiredPodCount, rec.DesiredPodCount)
+		a.lastRecommended = rec.DesiredPodCount
+		return rec
+	}
+	rec.ScaleValid = true
+	a.setPrevious(&rec, readyPodCount)
+	return rec
+}
+
+// pace limits a scale-up above the previous recommendation to the pods the
+// cluster can provision since then at ProvisioningRate. The pods provisioned
+// per minute accumulate as credit, up to a minute's worth or one pod, so a
+// scale-up after a quiet period can start with a step.
+// The caller must hold the lock.
+func (a *SlidingWindowAutoscaler) pace(desiredPodCount, readyPodCount int32, now time.Time) int32 {
+	rate := a.config.ProvisioningRate
+	if rate <= 0 {
+		return desiredPodCount
+	}
+
+	capacity := max(rate, 1)
+	if a.provisionedAt.IsZero() {
+		a.provisioningCredit = capacity
+	} else if elapsed := now.Sub(a.provisionedAt); elapsed > 0 {
+		a.provisioningCredit = min(capacity, a.provisioningCredit+rate*elapsed.Minutes())
+	}
+	if now.After(a.provisionedAt) {
+		a.provisionedAt = now
+	}
+
+	previous := readyPodCount
+	if a.hasLastRecommended {
+		previous = a.lastRecommended
+	}
+	if desiredPodCount <= previous {
+		return desiredPodCount
+	}
+	return previous + a.takeProvisioningCredit(desiredPodCount-previous)
+}
+
+// takeProvisioningCredit returns how many of the additional pods can be
+// provisioned and takes them from the credit.
+// The caller must hold the lock.
+func (a *SlidingWindowAutoscaler) takeProvisioningCredit(pods int32) int32 {
+	pods = min(pods, int32(a.provisioningCredit))
+	a.provisioningCredit -= float64(pods)
+	return pods
+}
+
+// spreadAcrossZones rounds the pod count up to a multiple of the zones that
+// holds the minimum pods per zone, or down to one within MaxScale.
+func (a *SlidingWindowAutoscaler) spreadAcrossZones(desiredPodCount int32) int32 {
+	zones := a.config.Zones
+	if zones <= 0 {
+		return desiredPodCount
+	}
+	desiredPodCount = max(desiredPodCount, zones*a.config.MinPodsPerZone)
+	desiredPodCount = (desiredPodCount + zones - 1) / zones * zones
+	if a.config.MaxScale > 0 && desiredPodCount > a.config.MaxScale {
+		desiredPodCount = a.config.MaxScale / zones * zones
+	}
+	return desiredPodCount
+}
+
+// setPrevious sets the previous pod count and the direction of a valid
+// recommendation and remembers its pod count for the next one.
+// The caller must hold the lock.
+func (a *SlidingWindowAutoscaler) setPrevious(rec *api.ScaleRecommendation, readyPodCount int32) {
+	rec.PreviousDesiredPodCount = readyPodCount
+	if a.hasLastRecommended {
+		rec.PreviousDesiredPodCount = a.lastRecommended
+	}
+	rec.Direction = api.DirectionOf(rec.PreviousDesiredPodCount, rec.DesiredPodCount)
+
+	a.lastRecommended = rec.DesiredPodCount
+	a.hasLastRecommended = true
+}
+
+// amend replaces the pod count of the last recommendation, e.g. after a
+// predictive adjustment, and updates its direction. An increase is paced to
+// the provisioning rate like the recommendation itself.
+func (a *SlidingWindowAutoscaler) amend(rec *api.ScaleRecommendation, desiredPodCount int32) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if desiredPodCount > rec.DesiredPodCount {
+		// The increase overrides a limited scale-down.
+		rec.LimitedBy = api.LimitNone
+		if a.config.ProvisioningRate > 0 {
+			wanted := max(desiredPodCount, rec.DesiredPodCount+rec.PendingPodCount)
+			desiredPodCount = rec.DesiredPodCount + a.takeProvisioningCredit(desiredPodCount-rec.DesiredPodCount)
+			rec.PendingPodCount = wanted - desiredPodCount
+		}
+	}
+	rec.DesiredPodCount = a.spreadAcrossZones(desiredPodCount)
+	rec.PendingPodCount = max(0, rec.PendingPodCount-(rec.DesiredPodCount-desiredPodCount))
+	if rec.PendingPodCount > 0 {
+		rec.LimitedBy = api.LimitProvisioningRate
+	}
+	rec.Direction = api.DirectionOf(rec.PreviousDesiredPodCount, rec.DesiredPodCount)
+	a.lastRecommended = rec.DesiredPodCount
+}
+
+// invariantTolerance is the relative distance to an integer within which
+// scale invariant autoscalers treat a pod count as that integer, so that
+// floating point errors, e.g. 3 * 0.7 / 0.7 = 3.0000000000000004, don't add
+// a pod.
+const invariantTolerance = 1e-9
+
+// ceil rounds a pod count up, treating counts within invariantTolerance of
+// an integer as that integer if the autoscaler is scale invariant.
+func (a *SlidingWindowAutoscaler) ceil(pods float64) float64 {
+	if a.config.ScaleInvariant {
+		if r := math.Round(pods); math.Abs(pods-r) <= invariantTolerance*math.Max(1, math.Abs(pods)) {
+			return r
+		}
 	}
+	return math.Ceil(pods)
+}
+
+// floor rounds a pod count down like ceil rounds it up.
+func (a *SlidingWindowAutoscaler) floor(pods float64) float64 {
+	return -a.ceil(-pods)
+}
+
+// proportional reports whether the desired pod count is computed
+// proportionally to the ready pods, i.e. for utilization and total targets.
+func proportional(config api.AutoscalerConfig) bool {
+	return config.ScalingMetricType == api.ScalingMetricUtilization ||
+		(config.TargetValue <= 0 && config.TotalTargetValue > 0)
+}
+
+// confirmBurst counts the evaluations over the burst threshold outside of
+// burst mode and reports whether they satisfy BurstEntryTicks and
+// BurstEntryDelay. The caller must hold the lock.
+func (a *SlidingWindowAutoscaler) confirmBurst(now time.Time) bool {
+	if a.overBurstTicks == 0 {
+		a.overBurstSince = now
+	}
+	a.overBurstTicks++
+	if a.overBurstTicks < a.config.BurstEntryTicks || now.Sub(a.overBurstSince) < a.config.BurstEntryDelay {
+		return false
+	}
+	a.overBurstTicks = 0
+	return true
+}
+
+// applyLastPodStickiness keeps a single pod instead of scaling to zero until
+// both the stable and burst values have been exactly zero for
+// ScaleToZeroGracePeriod. It never scales up from zero.
+// The caller must hold the lock.
+func (a *SlidingWindowAutoscaler) applyLastPodStickiness(desiredPodCount int32, stableValue, burstValue float64, readyPodCount int32, now time.Time) int32 {
+	if stableValue != 0 || burstValue != 0 {
+		a.zeroSince = time.Time{}
+	} else if a.zeroSince.IsZero() {
+		a.zeroSince = now
+	}
+
+	if !a.config.LastPodStickiness || desiredPodCount > 0 || readyPodCount == 0 {
+		return desiredPodCount
+	}
+	if a.zeroSince.IsZero() || now.Sub(a.zeroSince) < a.config.ScaleToZeroGracePeriod {
+		return 1
+	}
+	return desiredPodCount
+}
+
+// applyScaleDownSoak holds the previous pod count until ScaleDownSoakTicks
+// consecutive evaluations agree on a lower one. It then scales down to the
+// highest pod count observed during those evaluations.
+// The caller must hold the lock.
+func (a *SlidingWindowAutoscaler) applyScaleDownSoak(desiredPodCount int32) int32 {
+	if a.config.ScaleDownSoakTicks > 1 && a.hasLastDesired && desiredPodCount < a.lastDesiredPodCount {
+		a.lowReadings++
+		if a.lowReadings == 1 || desiredPodCount > a.lowReadingsMax {
+			a.lowReadingsMax = desiredPodCount
+		}
+		if a.lowReadings < a.config.ScaleDownSoakTicks {
+			desiredPodCount = a.lastDesiredPodCount
+		} else {
+			desiredPodCount = a.lowReadingsMax
+			a.lowReadings = 0
+		}
+	} else {
+		a.lowReadings = 0
+	}
+
+	a.lastDesiredPodCount = desiredPodCount
+	a.hasLastDesired = true
+	return desiredPodCount
+}
+
+// SizeBytes returns the approximate memory used by the autoscaler in bytes.
+func (a *SlidingWindowAutoscaler) SizeBytes() int64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	size := int64(unsafe.Sizeof(*a))
+	for _, w := range []*maxtimewindow.TimeWindow{a.maxTimeWindow, a.tickState.maxTimeWindow} {
+		if w != nil {
+			size += w.SizeBytes()
+		}
+	}
+	return size
 }
 
 // Update reconfigures the autoscaler with a new spec.
@@ -193,6 +725,7 @@ func (a *SlidingWindowAutoscaler) Update(config api.AutoscalerConfig) error {
 	}
 
 	a.config = config
+	a.hasTick = false
 
 	// Update delay window if needed
 	if config.ScaleDownDelay > 0 {
@@ -208,3 +741,9 @@ func (a *SlidingWindowAutoscaler) GetConfig() api.AutoscalerConfig {
 	defer a.mu.RUnlock()
 	return a.config
 }
+
+// EffectiveConfig returns the current configuration as the autoscaler
+// interprets it.
+func (a *SlidingWindowAutoscaler) EffectiveConfig() libkpaconfig.EffectiveConfig {
+	return libkpaconfig.Effective(a.GetConfig())
+}
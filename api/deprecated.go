@@ -0,0 +1,48 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+// The accessors below keep code written against the Knative names of the
+// burst mode settings compiling. Knative calls burst mode "panic mode".
+
+// PanicWindowPercentage returns BurstWindowPercentage.
+//
+// Deprecated: Use BurstWindowPercentage.
+func (c *AutoscalerConfig) PanicWindowPercentage() float64 {
+	return c.BurstWindowPercentage
+}
+
+// SetPanicWindowPercentage sets BurstWindowPercentage.
+//
+// Deprecated: Set BurstWindowPercentage.
+func (c *AutoscalerConfig) SetPanicWindowPercentage(percentage float64) {
+	c.BurstWindowPercentage = percentage
+}
+
+// PanicThreshold returns BurstThreshold.
+//
+// Deprecated: Use BurstThreshold.
+func (c *AutoscalerConfig) PanicThreshold() float64 {
+	return c.BurstThreshold
+}
+
+// SetPanicThreshold sets BurstThreshold.
+//
+// Deprecated: Set BurstThreshold.
+func (c *AutoscalerConfig) SetPanicThreshold(threshold float64) {
+	c.BurstThreshold = threshold
+}
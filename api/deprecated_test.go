@@ -0,0 +1,38 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import "testing"
+
+func TestPanicAliases(t *testing.T) {
+	cfg := &AutoscalerConfig{}
+
+	cfg.SetPanicWindowPercentage(15)
+	if cfg.BurstWindowPercentage != 15 || cfg.PanicWindowPercentage() != 15 {
+		t.Errorf("BurstWindowPercentage = %v, PanicWindowPercentage() = %v, want 15", cfg.BurstWindowPercentage, cfg.PanicWindowPercentage())
+	}
+
+	cfg.SetPanicThreshold(3)
+	if cfg.BurstThreshold != 3 || cfg.PanicThreshold() != 3 {
+		t.Errorf("BurstThreshold = %v, PanicThreshold() = %v, want 3", cfg.BurstThreshold, cfg.PanicThreshold())
+	}
+
+	cfg.BurstWindowPercentage = 20
+	if got := cfg.PanicWindowPercentage(); got != 20 {
+		t.Errorf("PanicWindowPercentage() = %v, want 20", got)
+	}
+}
@@ -61,15 +61,59 @@ type PodCounter interface {
 	ReadyCount() (int, error)
 }
 
-// MetricCollector collects metrics from pods.
-type MetricCollector interface {
+// Recommender makes scale recommendations from metric snapshots. It is
+// implemented by algorithm.SlidingWindowAutoscaler and audit.Autoscaler, so
+// controllers depending on it can be tested with a fake recommender.
+type Recommender interface {
+	// Scale returns the recommendation for the snapshot at the given time.
+	Scale(snapshot MetricSnapshot, now time.Time) ScaleRecommendation
+
+	// GetConfig returns the current configuration.
+	GetConfig() AutoscalerConfig
+}
+
+// Recorder ingests the values of a scaling metric. It is implemented by
+// manager.Scaler and shadow.Comparator.
+type Recorder interface {
+	// Record adds a metric value at the given time.
+	Record(value float64, t time.Time)
+}
+
+// ApplyOutcome describes what an Applier did with a recommendation, see
+// the Outcome constants of the applier package.
+type ApplyOutcome string
+
+// Applier applies scale recommendations to a scale target. It is
+// implemented by the appliers of the applier package.
+type Applier interface {
+	// Apply scales the target according to the recommendation.
+	Apply(ctx context.Context, rec ScaleRecommendation) (ApplyOutcome, error)
+}
+
+// Collector collects metrics from pods.
+type Collector interface {
 	// CollectMetrics collects metrics from all pods.
 	CollectMetrics(ctx context.Context) ([]Metrics, error)
+}
+
+// MetricCollector is a Collector that also creates snapshots from the
+// collected metrics.
+type MetricCollector interface {
+	Collector
 
 	// CreateSnapshot creates a metric snapshot from collected pod metrics.
 	CreateSnapshot(metrics []Metrics, now time.Time) MetricSnapshot
 }
 
+// WeightedRecorder is implemented by aggregators that can record a value
+// standing for several samples, e.g. a sample of one pod representing
+// several pods.
+type WeightedRecorder interface {
+	// RecordWeighted adds a value with the given sample weight at the given
+	// time.
+	RecordWeighted(time time.Time, value, weight float64)
+}
+
 // MetricAggregator aggregates metrics over time windows.
 type MetricAggregator interface {
 	// Record adds a metric value at the given time.
@@ -85,6 +129,25 @@ type MetricAggregator interface {
 	ResizeWindow(w time.Duration)
 }
 
+// Forecaster predicts the value of the scaling metric ahead of time.
+// Implementations may range from simple trend extrapolation to a client of
+// an external forecasting service.
+type Forecaster interface {
+	// Predict returns the expected value of the scaling metric after the
+	// given horizon, along with the confidence of the prediction in [0, 1].
+	// A confidence of 0 means the prediction must not be used.
+	Predict(horizon time.Duration) (value float64, confidence float64)
+}
+
+// ForecasterFunc is an adapter to allow the use of ordinary functions as
+// a Forecaster.
+type ForecasterFunc func(horizon time.Duration) (float64, float64)
+
+// Predict calls f(horizon).
+func (f ForecasterFunc) Predict(horizon time.Duration) (float64, float64) {
+	return f(horizon)
+}
+
 // Reporter reports autoscaler metrics for monitoring.
 type Reporter interface {
 	// ReportMetrics reports the current state of the autoscaler.
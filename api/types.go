@@ -21,8 +21,53 @@ import (
 	"time"
 )
 
+// ScalingMetricType describes the semantics of the metric the autoscaler scales on.
+type ScalingMetricType string
+
+const (
+	// ScalingMetricConcurrency scales on the number of in-flight requests.
+	// The recorded value is the total concurrency across all pods and
+	// TargetValue is the desired concurrency per pod.
+	ScalingMetricConcurrency ScalingMetricType = "concurrency"
+
+	// ScalingMetricRPS scales on requests per second.
+	// The recorded value is the total request rate across all pods and
+	// TargetValue is the desired request rate per pod.
+	ScalingMetricRPS ScalingMetricType = "rps"
+
+	// ScalingMetricUtilization scales on resource utilization, like the
+	// Kubernetes HPA does for resource metrics. The recorded value is the
+	// average utilization across pods as a percentage of the per-pod resource
+	// request, and TargetValue is the desired utilization percentage.
+	ScalingMetricUtilization ScalingMetricType = "utilization"
+
+	// ScalingMetricValue scales on an arbitrary metric value. Its meaning is
+	// defined by the caller, and either TargetValue or TotalTargetValue can be used.
+	ScalingMetricValue ScalingMetricType = "value"
+)
+
 // AutoscalerConfig defines the parameters for autoscaling behavior.
 type AutoscalerConfig struct {
+	// Version is the schema version of the configuration map the
+	// configuration was loaded from. Maps of older versions are migrated
+	// when loaded, so it only tells whether a stored map should be
+	// rewritten. 0 means the configuration was not loaded from a map.
+	Version int
+
+	// ScalingMetricType is the kind of metric the autoscaler scales on.
+	// Concurrency and RPS are request based metrics that are always recorded
+	// as a total across all pods and only support TargetValue. Utilization is
+	// recorded as an average percentage and also only supports TargetValue.
+	// Default is "value".
+	ScalingMetricType ScalingMetricType
+
+	// Unit is the unit of the recorded metric values, TargetValue,
+	// TotalTargetValue and BurstAbsoluteThreshold. Values recorded in
+	// another unit of the same dimension are converted, values in a unit of
+	// another dimension are rejected. Default is "" (unspecified, no
+	// conversion).
+	Unit Unit
+
 	// MaxScaleUpRate is the maximum rate at which the autoscaler will scale up pods.
 	// It must be greater than 1.0. For example, a value of 2.0 allows scaling up
 	// by at most doubling the pod count. Default is 1000.0.
@@ -33,6 +78,14 @@ type AutoscalerConfig struct {
 	// by at most halving the pod count. Default is 2.0.
 	MaxScaleDownRate float64
 
+	// MaxScaleDownFraction is the maximum fraction of the ready pods that may
+	// be removed by a single evaluation, like the maxUnavailable of a
+	// PodDisruptionBudget. The number of pods is rounded up, so at least one
+	// pod can always be removed. It applies in addition to MaxScaleDownRate
+	// and after the scale-down delay and soak, and ScaleRecommendation.LimitedBy
+	// tells when it binds. Must be in [0, 1]. Default is 0 (disabled).
+	MaxScaleDownFraction float64
+
 	// TargetValue is the desired value of the scaling metric per pod that we aim to maintain.
 	// Default is 100.0.
 	TargetValue float64
@@ -42,23 +95,86 @@ type AutoscalerConfig struct {
 	TotalTargetValue float64
 
 	// BurstThreshold is the threshold for entering burst mode, expressed as a
-	// percentage of desired pod count. If the observed load over the burst window
-	// exceeds this percentage of the current pod count capacity, burst mode is triggered.
-	// Default is 200 (200%).
+	// ratio of the desired to the current pod count. If the observed load over
+	// the burst window needs this many times the current pods, burst mode is
+	// triggered. The loaders read it as a percentage and convert it. Default
+	// is 2.0 (200%).
 	BurstThreshold float64
 
+	// BurstAbsoluteThreshold is an additional trigger for burst mode, expressed
+	// in units of the scaling metric. If the burst window average exceeds the
+	// stable window average by at least this amount, burst mode is triggered
+	// regardless of the pod ratio. This is useful for very large deployments
+	// where a ratio based threshold is rarely reached. Must be >= 0.
+	// Default is 0 (disabled).
+	BurstAbsoluteThreshold float64
+
 	// BurstWindowPercentage is the percentage of the stable window used for
 	// burst mode calculations. Must be in range [1.0, 100.0]. Default is 10.0.
 	BurstWindowPercentage float64
 
+	// BurstEntryTicks is the number of consecutive evaluations the burst
+	// condition must hold before burst mode is entered. It keeps a single
+	// spike from holding the pod count for a whole stable window. Must be
+	// >= 0. Default is 0 (disabled), 1 is equivalent to disabled.
+	BurstEntryTicks int32
+
+	// BurstEntryDelay is the minimum time the burst condition must hold
+	// before burst mode is entered. With BurstEntryTicks both must be met.
+	// Must be >= 0. Default is 0s (enter immediately).
+	BurstEntryDelay time.Duration
+
+	// BurstBlending replaces the choice between the stable and the burst pod
+	// counts with a weighted combination, w*burst + (1-w)*stable, where w
+	// grows from 0 when the burst window asks for the ready pods to 1 when
+	// the burst ratio reaches BurstThreshold. It makes transitions into and
+	// out of bursts smoother. Default is false.
+	BurstBlending bool
+
 	// StableWindow is the time window over which metrics are averaged for
 	// scaling decisions. Must be between 5s and 600s. Default is 60s.
 	StableWindow time.Duration
 
+	// MinWindowFillFraction is the fraction, in range [0, 1], of the stable
+	// window's buckets that must carry data before recommendations are
+	// valid. It avoids scaling on an average of a few samples right after
+	// a scaler is created. Default is 0, where any data suffices.
+	MinWindowFillFraction float64
+
+	// WindowGranularity is the duration of a bucket of the stable and burst
+	// windows. Long windows can use coarser buckets to save memory, fast
+	// metrics sub-second buckets. It must not exceed the burst window and
+	// StableWindow must be a multiple of it. Default is 0, which means 1s.
+	WindowGranularity time.Duration
+
 	// ScaleDownDelay is the minimum time that must pass at reduced load
 	// before scaling down. Default is 0s (immediate scale down).
 	ScaleDownDelay time.Duration
 
+	// ScaleDownSoakTicks is the number of consecutive evaluations that must
+	// agree on a lower pod count before a scale-down is recommended. Unlike
+	// ScaleDownDelay it counts evaluations rather than time, which protects
+	// against single-sample dips when windows are short. Must be >= 0.
+	// Default is 0 (disabled), 1 is equivalent to disabled.
+	ScaleDownSoakTicks int32
+
+	// ScaleInvariant makes recommendations proportional to the load: k
+	// times the load at k times the ready pods is recommended k times the
+	// pods, give or take less than k pods of rounding. Rounding ignores
+	// floating point errors, burst mode is decided on unrounded pod counts
+	// and ActivationScale only applies when scaling from zero. It cannot be
+	// combined with BurstAbsoluteThreshold, which is not proportional.
+	// MinScale and MaxScale still apply. Default is false.
+	ScaleInvariant bool
+
+	// ProvisioningRate is the number of pods per minute the cluster can
+	// provision, e.g. as limited by how fast the cluster autoscaler adds
+	// nodes. Scale-ups above the previous recommendation are paced to it,
+	// and the rest is reported as ScaleRecommendation.PendingPodCount, so
+	// controllers don't create pods that can't be scheduled yet. MinScale and
+	// MaxScale still apply. Must be >= 0. Default is 0 (unlimited).
+	ProvisioningRate float64
+
 	// MinScale is the minimum number of pods to maintain. Must be >= 0.
 	// Default is 0 (can scale to zero).
 	MinScale int32
@@ -67,17 +183,51 @@ type AutoscalerConfig struct {
 	// Default is 0.
 	MaxScale int32
 
+	// Zones is the number of topology zones the pods are spread across.
+	// When set, recommendations are rounded up to a multiple of Zones, so
+	// the pods can be spread evenly, and ScaleRecommendation.ZonePodCounts
+	// returns the per-zone breakdown. MaxScale, if set, must allow for
+	// MinPodsPerZone in every zone and a recommendation above it is rounded
+	// down to a multiple of Zones. Must be >= 0. Default is 0 (disabled).
+	Zones int32
+
+	// MinPodsPerZone is the minimum number of pods in every zone. It
+	// requires Zones and takes precedence over scaling to zero. Must be >= 0.
+	// Default is 0.
+	MinPodsPerZone int32
+
 	// ActivationScale is the minimum scale to use when scaling from zero.
 	// Must be >= 1. Default is 1.
 	ActivationScale int32
 
+	// IgnoreActivationScaleWithMinScale disables ActivationScale when
+	// MinScale > 0. Such deployments never scale to zero, so without it a tiny
+	// load can raise the pod count from MinScale straight to ActivationScale.
+	// Default is false (ActivationScale always applies).
+	IgnoreActivationScaleWithMinScale bool
+
 	// ScaleToZeroGracePeriod is the time to wait before scaling to zero
 	// after the service becomes idle. Default is 30s.
 	ScaleToZeroGracePeriod time.Duration
+
+	// LastPodStickiness keeps the last pod until both the stable and burst
+	// values have been exactly zero for ScaleToZeroGracePeriod, so a
+	// trickle of load never scales to zero. Default is false.
+	LastPodStickiness bool
+
+	// ActivationThreshold is the burst value that must be exceeded to scale
+	// from zero. Together with LastPodStickiness it forms a dead band that
+	// prevents flapping between zero and one pod. Must be >= 0. Default is 0
+	// (any load scales from zero).
+	ActivationThreshold float64
 }
 
 // Metrics represents collected metrics.
 type Metrics struct {
+	// PodName identifies the pod the metrics were collected from.
+	// It is optional and only used for per-pod tracking.
+	PodName string
+
 	// Timestamp is when these metrics were collected.
 	Timestamp time.Time
 
@@ -96,4 +246,123 @@ type ScaleRecommendation struct {
 
 	// InBurstMode indicates whether the autoscaler is in burst mode.
 	InBurstMode bool
+
+	// PreviousDesiredPodCount is the DesiredPodCount of the previous valid
+	// recommendation of the autoscaler. For the first recommendation it is
+	// the ready pod count the autoscaler was called with.
+	PreviousDesiredPodCount int32
+
+	// Direction tells whether DesiredPodCount is above, below or equal to
+	// PreviousDesiredPodCount.
+	Direction ScaleDirection
+
+	// Zones is the number of zones DesiredPodCount is spread across, see
+	// AutoscalerConfig.Zones. It is 0 if zones are not configured.
+	Zones int32
+
+	// PendingPodCount is the number of pods wanted on top of
+	// DesiredPodCount that were held back to pace the scale-up to
+	// AutoscalerConfig.ProvisioningRate. They are recommended in later
+	// evaluations as the cluster provisions nodes.
+	PendingPodCount int32
+
+	// LimitedBy names the constraint that held DesiredPodCount back from
+	// the pod count wanted by the metrics, if any.
+	LimitedBy ScaleLimit
+
+	// ValidUntil is when the recommendation becomes stale: the data it is
+	// based on was recorded one burst window earlier, and for scalers
+	// evaluated at an interval, the next evaluation is due. Consumers
+	// applying recommendations asynchronously should discard expired ones.
+	// It is zero for invalid recommendations.
+	ValidUntil time.Time
+}
+
+// Expired reports whether the recommendation is stale at now, see
+// ValidUntil. Recommendations without a ValidUntil never expire.
+func (r ScaleRecommendation) Expired(now time.Time) bool {
+	return !r.ValidUntil.IsZero() && now.After(r.ValidUntil)
+}
+
+// ScaleLimit identifies a constraint that limited a recommendation.
+type ScaleLimit string
+
+const (
+	// LimitNone means no constraint limited the recommendation.
+	LimitNone ScaleLimit = ""
+
+	// LimitProvisioningRate means a scale-up was paced to
+	// AutoscalerConfig.ProvisioningRate.
+	LimitProvisioningRate ScaleLimit = "provisioning-rate"
+
+	// LimitScaleDownFraction means a scale-down was limited by
+	// AutoscalerConfig.MaxScaleDownFraction.
+	LimitScaleDownFraction ScaleLimit = "max-scale-down-fraction"
+
+	// LimitCapacity means the recommendation was reduced to fit the global
+	// capacity of a multitenant manager.
+	LimitCapacity ScaleLimit = "capacity"
+)
+
+// ZonePodCounts returns the number of pods per zone of DesiredPodCount, or
+// nil if zones are not configured.
+func (r ScaleRecommendation) ZonePodCounts() []int32 {
+	return SpreadAcrossZones(r.DesiredPodCount, r.Zones)
+}
+
+// ScaleDirection is the direction of a scaling recommendation relative to the
+// previous one.
+type ScaleDirection int
+
+const (
+	// ScaleNone means the desired pod count didn't change.
+	ScaleNone ScaleDirection = iota
+
+	// ScaleUp means the desired pod count increased.
+	ScaleUp
+
+	// ScaleDown means the desired pod count decreased.
+	ScaleDown
+)
+
+// SpreadAcrossZones splits pods across zones as evenly as possible. The
+// first zones get one pod more if pods is not a multiple of zones. It returns
+// nil if zones is not positive.
+func SpreadAcrossZones(pods, zones int32) []int32 {
+	if zones <= 0 {
+		return nil
+	}
+	counts := make([]int32, zones)
+	for i := range counts {
+		counts[i] = pods / zones
+		if int32(i) < pods%zones {
+			counts[i]++
+		}
+	}
+	return counts
+}
+
+// DirectionOf returns the direction of a change from previous to desired
+// pods.
+func DirectionOf(previous, desired int32) ScaleDirection {
+	switch {
+	case desired > previous:
+		return ScaleUp
+	case desired < previous:
+		return ScaleDown
+	default:
+		return ScaleNone
+	}
+}
+
+// String returns "none", "up" or "down".
+func (d ScaleDirection) String() string {
+	switch d {
+	case ScaleUp:
+		return "up"
+	case ScaleDown:
+		return "down"
+	default:
+		return "none"
+	}
 }
@@ -0,0 +1,80 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDirectionOf(t *testing.T) {
+	tests := []struct {
+		previous, desired int32
+		want              ScaleDirection
+		wantString        string
+	}{
+		{previous: 1, desired: 3, want: ScaleUp, wantString: "up"},
+		{previous: 3, desired: 1, want: ScaleDown, wantString: "down"},
+		{previous: 2, desired: 2, want: ScaleNone, wantString: "none"},
+	}
+
+	for _, tt := range tests {
+		got := DirectionOf(tt.previous, tt.desired)
+		if got != tt.want || got.String() != tt.wantString {
+			t.Errorf("DirectionOf(%d, %d) = %v, want %v", tt.previous, tt.desired, got, tt.wantString)
+		}
+	}
+}
+
+func TestSpreadAcrossZones(t *testing.T) {
+	tests := []struct {
+		pods, zones int32
+		want        []int32
+	}{
+		{pods: 9, zones: 3, want: []int32{3, 3, 3}},
+		{pods: 7, zones: 3, want: []int32{3, 2, 2}},
+		{pods: 1, zones: 2, want: []int32{1, 0}},
+		{pods: 5, zones: 0, want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := SpreadAcrossZones(tt.pods, tt.zones); !slices.Equal(got, tt.want) {
+			t.Errorf("SpreadAcrossZones(%d, %d) = %v, want %v", tt.pods, tt.zones, got, tt.want)
+		}
+	}
+
+	rec := ScaleRecommendation{DesiredPodCount: 6, Zones: 3}
+	if got := rec.ZonePodCounts(); !slices.Equal(got, []int32{2, 2, 2}) {
+		t.Errorf("ZonePodCounts() = %v, want [2 2 2]", got)
+	}
+}
+
+func TestScaleRecommendationExpired(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rec := ScaleRecommendation{DesiredPodCount: 3, ScaleValid: true, ValidUntil: now}
+
+	if rec.Expired(now) {
+		t.Error("Expired() at ValidUntil = true, want false")
+	}
+	if !rec.Expired(now.Add(time.Nanosecond)) {
+		t.Error("Expired() after ValidUntil = false, want true")
+	}
+	if (ScaleRecommendation{}).Expired(now) {
+		t.Error("Expired() without ValidUntil = true, want false")
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Unit is the unit of a metric value.
+type Unit string
+
+const (
+	// UnitNone means the unit is not specified. Values without a unit are
+	// never converted.
+	UnitNone Unit = ""
+
+	// UnitRequests counts in-flight requests, i.e. concurrency.
+	UnitRequests Unit = "requests"
+
+	// UnitRequestsPerSecond and UnitRequestsPerMinute are request rates.
+	UnitRequestsPerSecond Unit = "requests-per-second"
+	UnitRequestsPerMinute Unit = "requests-per-minute"
+
+	// UnitPercent is a utilization percentage.
+	UnitPercent Unit = "percent"
+
+	// UnitMillicores and UnitCores measure CPU.
+	UnitMillicores Unit = "millicores"
+	UnitCores      Unit = "cores"
+
+	// UnitBytes, UnitKiB, UnitMiB and UnitGiB measure memory.
+	UnitBytes Unit = "bytes"
+	UnitKiB   Unit = "KiB"
+	UnitMiB   Unit = "MiB"
+	UnitGiB   Unit = "GiB"
+)
+
+// unitInfo describes a unit by its dimension and its size in the base unit
+// of the dimension.
+type unitInfo struct {
+	dimension string
+	factor    float64
+}
+
+var units = map[Unit]unitInfo{
+	UnitRequests:          {dimension: "concurrency", factor: 1},
+	UnitRequestsPerSecond: {dimension: "request rate", factor: 1},
+	UnitRequestsPerMinute: {dimension: "request rate", factor: 1.0 / 60},
+	UnitPercent:           {dimension: "utilization", factor: 1},
+	UnitMillicores:        {dimension: "cpu", factor: 1e-3},
+	UnitCores:             {dimension: "cpu", factor: 1},
+	UnitBytes:             {dimension: "memory", factor: 1},
+	UnitKiB:               {dimension: "memory", factor: 1 << 10},
+	UnitMiB:               {dimension: "memory", factor: 1 << 20},
+	UnitGiB:               {dimension: "memory", factor: 1 << 30},
+}
+
+// Validate ensures the unit is known.
+func (u Unit) Validate() error {
+	if u == UnitNone {
+		return nil
+	}
+	if _, ok := units[u]; !ok {
+		return fmt.Errorf("unknown unit %q", u)
+	}
+	return nil
+}
+
+// Units returns the known units sorted by name.
+func Units() []Unit {
+	known := make([]Unit, 0, len(units))
+	for u := range units {
+		known = append(known, u)
+	}
+	slices.Sort(known)
+	return known
+}
+
+// Dimension returns what the unit measures, e.g. "cpu" or "memory". It is
+// empty for UnitNone and unknown units.
+func (u Unit) Dimension() string {
+	return units[u].dimension
+}
+
+// Convert converts a value in unit u to the unit to. Values can only be
+// converted between units of the same dimension, e.g. from millicores to
+// cores, but not from bytes to cores. A value without a unit can only be
+// converted to no unit.
+func (u Unit) Convert(value float64, to Unit) (float64, error) {
+	if u == to {
+		return value, nil
+	}
+	from, ok := units[u]
+	if !ok {
+		return 0, fmt.Errorf("cannot convert from unit %q", u)
+	}
+	target, ok := units[to]
+	if !ok {
+		return 0, fmt.Errorf("cannot convert to unit %q", to)
+	}
+	if from.dimension != target.dimension {
+		return 0, fmt.Errorf("cannot convert %s in %s to %s in %s", from.dimension, u, target.dimension, to)
+	}
+	return value * from.factor / target.factor, nil
+}
+
+// MillicoresToCores converts millicores to cores.
+func MillicoresToCores(millicores float64) float64 {
+	return millicores / 1000
+}
+
+// CoresToMillicores converts cores to millicores.
+func CoresToMillicores(cores float64) float64 {
+	return cores * 1000
+}
+
+// BytesToMiB converts bytes to mebibytes.
+func BytesToMiB(bytes float64) float64 {
+	return bytes / (1 << 20)
+}
+
+// MiBToBytes converts mebibytes to bytes.
+func MiBToBytes(mib float64) float64 {
+	return mib * (1 << 20)
+}
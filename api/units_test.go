@@ -0,0 +1,82 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+)
+
+func TestUnitConvert(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   float64
+		from    Unit
+		to      Unit
+		want    float64
+		wantErr bool
+	}{
+		{name: "same unit", value: 5, from: UnitMiB, to: UnitMiB, want: 5},
+		{name: "no unit", value: 5, from: UnitNone, to: UnitNone, want: 5},
+		{name: "millicores to cores", value: 1500, from: UnitMillicores, to: UnitCores, want: 1.5},
+		{name: "cores to millicores", value: 0.25, from: UnitCores, to: UnitMillicores, want: 250},
+		{name: "bytes to MiB", value: 3 << 20, from: UnitBytes, to: UnitMiB, want: 3},
+		{name: "GiB to MiB", value: 2, from: UnitGiB, to: UnitMiB, want: 2048},
+		{name: "requests per minute to second", value: 120, from: UnitRequestsPerMinute, to: UnitRequestsPerSecond, want: 2},
+		{name: "other dimension", value: 1, from: UnitBytes, to: UnitCores, wantErr: true},
+		{name: "from no unit", value: 1, from: UnitNone, to: UnitCores, wantErr: true},
+		{name: "to no unit", value: 1, from: UnitCores, to: UnitNone, wantErr: true},
+		{name: "unknown unit", value: 1, from: Unit("furlongs"), to: UnitCores, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.from.Convert(tt.value, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Convert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Convert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitHelpers(t *testing.T) {
+	if got := MillicoresToCores(250); got != 0.25 {
+		t.Errorf("MillicoresToCores(250) = %v, want 0.25", got)
+	}
+	if got := CoresToMillicores(2); got != 2000 {
+		t.Errorf("CoresToMillicores(2) = %v, want 2000", got)
+	}
+	if got := BytesToMiB(1 << 21); got != 2 {
+		t.Errorf("BytesToMiB(2MiB) = %v, want 2", got)
+	}
+	if got := MiBToBytes(1); got != 1<<20 {
+		t.Errorf("MiBToBytes(1) = %v, want %v", got, 1<<20)
+	}
+	if err := Unit("furlongs").Validate(); err == nil {
+		t.Error("expected error for an unknown unit")
+	}
+	if got := UnitMillicores.Dimension(); got != UnitCores.Dimension() {
+		t.Errorf("Dimension() of millicores = %q, want %q", got, UnitCores.Dimension())
+	}
+	for _, u := range Units() {
+		if err := u.Validate(); err != nil || u == UnitNone {
+			t.Errorf("Units() contains %q", u)
+		}
+	}
+}
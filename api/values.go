@@ -0,0 +1,51 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+// PodCount is a number of pods.
+type PodCount int32
+
+// PerPodValue is a metric value measured for a single pod, or averaged per
+// pod, e.g. the CPU usage of one pod.
+type PerPodValue float64
+
+// TotalValue is a metric value summed across all pods of a workload, e.g. the
+// total number of in-flight requests.
+type TotalValue float64
+
+// Total converts a per-pod value into the total across the given number of pods.
+func (v PerPodValue) Total(pods PodCount) TotalValue {
+	return TotalValue(float64(v) * float64(pods))
+}
+
+// PerPod converts a total into the average value per pod. It returns 0 if
+// there are no pods, since there is nothing to average over.
+func (v TotalValue) PerPod(pods PodCount) PerPodValue {
+	if pods <= 0 {
+		return 0
+	}
+	return PerPodValue(float64(v) / float64(pods))
+}
+
+// SumPerPod sums the values of individual pods into a total.
+func SumPerPod(values ...PerPodValue) TotalValue {
+	total := 0.
+	for _, v := range values {
+		total += float64(v)
+	}
+	return TotalValue(total)
+}
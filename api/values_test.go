@@ -0,0 +1,44 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+)
+
+func TestValueConversions(t *testing.T) {
+	if got := PerPodValue(50).Total(4); got != 200 {
+		t.Errorf("PerPodValue(50).Total(4) = %v, want 200", got)
+	}
+	if got := TotalValue(200).PerPod(4); got != 50 {
+		t.Errorf("TotalValue(200).PerPod(4) = %v, want 50", got)
+	}
+	if got := TotalValue(200).PerPod(0); got != 0 {
+		t.Errorf("TotalValue(200).PerPod(0) = %v, want 0", got)
+	}
+	if got := SumPerPod(10, 20, 30); got != 60 {
+		t.Errorf("SumPerPod(10, 20, 30) = %v, want 60", got)
+	}
+	if got := SumPerPod(); got != 0 {
+		t.Errorf("SumPerPod() = %v, want 0", got)
+	}
+
+	// Round trip.
+	if got := PerPodValue(12.5).Total(8).PerPod(8); got != 12.5 {
+		t.Errorf("round trip = %v, want 12.5", got)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package applier applies scale recommendations to the platforms that run
+// the scaled workloads, so libkpa can be used outside of Kubernetes.
+package applier
+
+import (
+	"context"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// Outcome describes what an Applier did with a recommendation.
+type Outcome = api.ApplyOutcome
+
+const (
+	// OutcomeApplied means the target was scaled to a new size.
+	OutcomeApplied Outcome = "applied"
+
+	// OutcomeUnchanged means the target already had the recommended size.
+	OutcomeUnchanged Outcome = "unchanged"
+
+	// OutcomeInvalid means the recommendation was not valid and was ignored.
+	OutcomeInvalid Outcome = "invalid"
+
+	// OutcomeCooldown means the change was postponed because the target is
+	// in a cooldown period after a previous change.
+	OutcomeCooldown Outcome = "cooldown"
+
+	// OutcomeInProgress means the target is being scaled up gradually by a
+	// StrategyApplier and hasn't reached the recommended size yet.
+	OutcomeInProgress Outcome = "in_progress"
+)
+
+// Applier applies scale recommendations to a scale target.
+type Applier = api.Applier
+
+// Func adapts a function to the Applier interface.
+type Func func(ctx context.Context, rec api.ScaleRecommendation) (Outcome, error)
+
+// Apply calls f(ctx, rec).
+func (f Func) Apply(ctx context.Context, rec api.ScaleRecommendation) (Outcome, error) {
+	return f(ctx, rec)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// ASGGroup is the state of an AWS Auto Scaling Group.
+type ASGGroup struct {
+	DesiredCapacity int32
+	MinSize         int32
+	MaxSize         int32
+}
+
+// ASGClient is the subset of the AWS Auto Scaling API used by ASGApplier.
+// It is usually implemented with a thin wrapper around the
+// DescribeAutoScalingGroups and SetDesiredCapacity calls of the AWS SDK, which
+// keeps this library free of the SDK dependency.
+type ASGClient interface {
+	// DescribeGroup returns the state of the named group.
+	DescribeGroup(ctx context.Context, name string) (ASGGroup, error)
+
+	// SetDesiredCapacity sets the desired capacity of the named group.
+	SetDesiredCapacity(ctx context.Context, name string, capacity int32, honorCooldown bool) error
+}
+
+// ASGApplier maps scale recommendations to the DesiredCapacity of an AWS Auto
+// Scaling Group. Recommendations are clamped to the size bounds of the group,
+// and changes within the cooldown period after a previous change are
+// postponed. AWS is also asked to honor the group's own cooldown.
+type ASGApplier struct {
+	client   ASGClient
+	group    string
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu         sync.Mutex
+	lastChange time.Time
+}
+
+var _ Applier = (*ASGApplier)(nil)
+
+// NewASGApplier creates an applier for the named group. A cooldown of 0
+// disables the local cooldown tracking.
+func NewASGApplier(client ASGClient, group string, cooldown time.Duration) (*ASGApplier, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+	if group == "" {
+		return nil, fmt.Errorf("group name cannot be empty")
+	}
+	if cooldown < 0 {
+		return nil, fmt.Errorf("cooldown = %v, must be at least 0", cooldown)
+	}
+	return &ASGApplier{
+		client:   client,
+		group:    group,
+		cooldown: cooldown,
+		now:      time.Now,
+	}, nil
+}
+
+// Apply sets the desired capacity of the group to the recommended pod count.
+func (a *ASGApplier) Apply(ctx context.Context, rec api.ScaleRecommendation) (Outcome, error) {
+	if !rec.ScaleValid {
+		return OutcomeInvalid, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	g, err := a.client.DescribeGroup(ctx, a.group)
+	if err != nil {
+		return "", fmt.Errorf("failed to describe ASG %q: %w", a.group, err)
+	}
+
+	// AWS always reports MaxSize, so a MaxSize of 0 pins the group at 0.
+	desired := min(g.MaxSize, max(g.MinSize, rec.DesiredPodCount))
+	if desired == g.DesiredCapacity {
+		return OutcomeUnchanged, nil
+	}
+
+	now := a.now()
+	if a.cooldown > 0 && !a.lastChange.IsZero() && now.Sub(a.lastChange) < a.cooldown {
+		return OutcomeCooldown, nil
+	}
+
+	if err := a.client.SetDesiredCapacity(ctx, a.group, desired, true); err != nil {
+		return "", fmt.Errorf("failed to set desired capacity of ASG %q to %d: %w", a.group, desired, err)
+	}
+	a.lastChange = now
+	return OutcomeApplied, nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// fakeASG is an in-memory ASGClient.
+type fakeASG struct {
+	group       ASGGroup
+	calls       int
+	honored     bool
+	setErr      error
+	describeErr error
+}
+
+func (f *fakeASG) DescribeGroup(ctx context.Context, name string) (ASGGroup, error) {
+	return f.group, f.describeErr
+}
+
+func (f *fakeASG) SetDesiredCapacity(ctx context.Context, name string, capacity int32, honorCooldown bool) error {
+	if f.setErr != nil {
+		return f.setErr
+	}
+	f.calls++
+	f.honored = honorCooldown
+	f.group.DesiredCapacity = capacity
+	return nil
+}
+
+func valid(pods int32) api.ScaleRecommendation {
+	return api.ScaleRecommendation{DesiredPodCount: pods, ScaleValid: true}
+}
+
+func TestNewASGApplier(t *testing.T) {
+	if _, err := NewASGApplier(nil, "g", 0); err == nil {
+		t.Error("expected error for nil client")
+	}
+	if _, err := NewASGApplier(&fakeASG{}, "", 0); err == nil {
+		t.Error("expected error for empty group name")
+	}
+	if _, err := NewASGApplier(&fakeASG{}, "g", -time.Second); err == nil {
+		t.Error("expected error for negative cooldown")
+	}
+}
+
+func TestASGApplier(t *testing.T) {
+	client := &fakeASG{group: ASGGroup{DesiredCapacity: 2, MinSize: 1, MaxSize: 10}}
+	a, err := NewASGApplier(client, "workers", time.Minute)
+	if err != nil {
+		t.Fatalf("NewASGApplier failed: %v", err)
+	}
+	now := time.Now()
+	a.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	steps := []struct {
+		name     string
+		rec      api.ScaleRecommendation
+		advance  time.Duration
+		want     Outcome
+		capacity int32
+	}{
+		{name: "invalid", rec: api.ScaleRecommendation{}, want: OutcomeInvalid, capacity: 2},
+		{name: "unchanged", rec: valid(2), want: OutcomeUnchanged, capacity: 2},
+		{name: "scale up", rec: valid(5), want: OutcomeApplied, capacity: 5},
+		{name: "cooldown", rec: valid(6), advance: 30 * time.Second, want: OutcomeCooldown, capacity: 5},
+		{name: "clamped to max", rec: valid(20), advance: 31 * time.Second, want: OutcomeApplied, capacity: 10},
+		{name: "clamped to min", rec: valid(0), advance: time.Minute, want: OutcomeApplied, capacity: 1},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		got, err := a.Apply(ctx, step.rec)
+		if err != nil {
+			t.Fatalf("%s: Apply failed: %v", step.name, err)
+		}
+		if got != step.want {
+			t.Errorf("%s: Apply() = %q, want %q", step.name, got, step.want)
+		}
+		if client.group.DesiredCapacity != step.capacity {
+			t.Errorf("%s: DesiredCapacity = %d, want %d", step.name, client.group.DesiredCapacity, step.capacity)
+		}
+	}
+	if !client.honored {
+		t.Error("SetDesiredCapacity was not asked to honor the cooldown")
+	}
+}
+
+func TestASGApplierZeroMaxSize(t *testing.T) {
+	client := &fakeASG{group: ASGGroup{DesiredCapacity: 2}}
+	a, err := NewASGApplier(client, "workers", 0)
+	if err != nil {
+		t.Fatalf("NewASGApplier failed: %v", err)
+	}
+	got, err := a.Apply(context.Background(), valid(5))
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got != OutcomeApplied || client.group.DesiredCapacity != 0 {
+		t.Errorf("Apply() = %q with DesiredCapacity = %d, want applied with 0", got, client.group.DesiredCapacity)
+	}
+}
+
+func TestASGApplierErrors(t *testing.T) {
+	ctx := context.Background()
+
+	client := &fakeASG{describeErr: errors.New("throttled")}
+	a, _ := NewASGApplier(client, "workers", 0)
+	if _, err := a.Apply(ctx, valid(3)); err == nil {
+		t.Error("expected error when describing the group fails")
+	}
+
+	client = &fakeASG{group: ASGGroup{DesiredCapacity: 1, MaxSize: 10}, setErr: errors.New("denied")}
+	a, _ = NewASGApplier(client, "workers", time.Minute)
+	if _, err := a.Apply(ctx, valid(3)); err == nil {
+		t.Error("expected error when setting the capacity fails")
+	}
+
+	// A failed change doesn't start the cooldown.
+	client.setErr = nil
+	if got, err := a.Apply(ctx, valid(3)); err != nil || got != OutcomeApplied {
+		t.Errorf("Apply() = %q, %v, want applied", got, err)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// NomadConfig configures the connection to the Nomad HTTP API.
+type NomadConfig struct {
+	// Address is the address of the Nomad agent, e.g. http://127.0.0.1:4646.
+	Address string
+
+	// Namespace is the namespace of the job. Default is the agent's default.
+	Namespace string
+
+	// Token is the ACL token sent with every request, if set.
+	Token string
+
+	// Client is the HTTP client. Default is http.DefaultClient.
+	Client *http.Client
+}
+
+// NomadApplier adjusts the count of a task group of a HashiCorp Nomad job
+// through the job scale API.
+type NomadApplier struct {
+	cfg   NomadConfig
+	job   string
+	group string
+}
+
+var _ Applier = (*NomadApplier)(nil)
+
+// NewNomadApplier creates an applier for the task group of the job.
+func NewNomadApplier(cfg NomadConfig, job, group string) (*NomadApplier, error) {
+	u, err := url.Parse(cfg.Address)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid Nomad address %q", cfg.Address)
+	}
+	if job == "" || group == "" {
+		return nil, fmt.Errorf("job and task group cannot be empty")
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	cfg.Address = strings.TrimSuffix(cfg.Address, "/")
+	return &NomadApplier{cfg: cfg, job: job, group: group}, nil
+}
+
+// nomadScaleStatus is the subset of the job scale status response used by
+// NomadApplier.
+type nomadScaleStatus struct {
+	TaskGroups map[string]struct {
+		Desired int32
+	}
+}
+
+// nomadScaleRequest is the body of a job scale request.
+type nomadScaleRequest struct {
+	Count   int64
+	Target  map[string]string
+	Message string
+}
+
+// Apply sets the count of the task group to the recommended pod count.
+func (a *NomadApplier) Apply(ctx context.Context, rec api.ScaleRecommendation) (Outcome, error) {
+	if !rec.ScaleValid {
+		return OutcomeInvalid, nil
+	}
+
+	var status nomadScaleStatus
+	if err := a.do(ctx, http.MethodGet, nil, &status); err != nil {
+		return "", err
+	}
+	group, ok := status.TaskGroups[a.group]
+	if !ok {
+		return "", fmt.Errorf("task group %q not found in Nomad job %q", a.group, a.job)
+	}
+	if group.Desired == rec.DesiredPodCount {
+		return OutcomeUnchanged, nil
+	}
+
+	body := nomadScaleRequest{
+		Count:   int64(rec.DesiredPodCount),
+		Target:  map[string]string{"Group": a.group},
+		Message: fmt.Sprintf("libkpa: scaling from %d to %d", group.Desired, rec.DesiredPodCount),
+	}
+	if err := a.do(ctx, http.MethodPost, body, nil); err != nil {
+		return "", err
+	}
+	return OutcomeApplied, nil
+}
+
+// do sends a request to the job scale endpoint and decodes the response into
+// out, if set.
+func (a *NomadApplier) do(ctx context.Context, method string, in, out any) error {
+	endpoint := a.cfg.Address + "/v1/job/" + url.PathEscape(a.job) + "/scale"
+	if a.cfg.Namespace != "" {
+		endpoint += "?namespace=" + url.QueryEscape(a.cfg.Namespace)
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode Nomad request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create Nomad request: %w", err)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if a.cfg.Token != "" {
+		req.Header.Set("X-Nomad-Token", a.cfg.Token)
+	}
+
+	resp, err := a.cfg.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to Nomad failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("request %s %s to Nomad failed with status %s: %s", method, req.URL.Path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode Nomad response: %w", err)
+	}
+	return nil
+}
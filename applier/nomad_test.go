@@ -0,0 +1,136 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// fakeNomad serves the job scale API of a single job.
+type fakeNomad struct {
+	counts   map[string]int32
+	requests []nomadScaleRequest
+	token    string
+	ns       string
+}
+
+func (f *fakeNomad) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/job/api/scale" {
+		http.NotFound(w, r)
+		return
+	}
+	f.token = r.Header.Get("X-Nomad-Token")
+	f.ns = r.URL.Query().Get("namespace")
+
+	switch r.Method {
+	case http.MethodGet:
+		groups := map[string]map[string]int32{}
+		for name, count := range f.counts {
+			groups[name] = map[string]int32{"Desired": count, "Running": count}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"JobID": "api", "TaskGroups": groups})
+	case http.MethodPost:
+		var req nomadScaleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.requests = append(f.requests, req)
+		f.counts[req.Target["Group"]] = int32(req.Count)
+		fmt.Fprint(w, `{"EvalID":"1"}`)
+	}
+}
+
+func TestNewNomadApplier(t *testing.T) {
+	tests := []struct {
+		name  string
+		addr  string
+		job   string
+		group string
+	}{
+		{name: "invalid address", addr: "127.0.0.1:4646", job: "api", group: "web"},
+		{name: "empty job", addr: "http://127.0.0.1:4646", group: "web"},
+		{name: "empty group", addr: "http://127.0.0.1:4646", job: "api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewNomadApplier(NomadConfig{Address: tt.addr}, tt.job, tt.group); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestNomadApplier(t *testing.T) {
+	nomad := &fakeNomad{counts: map[string]int32{"web": 2, "worker": 1}}
+	server := httptest.NewServer(nomad)
+	defer server.Close()
+
+	a, err := NewNomadApplier(NomadConfig{
+		Address:   server.URL + "/",
+		Namespace: "prod",
+		Token:     "secret",
+		Client:    server.Client(),
+	}, "api", "web")
+	if err != nil {
+		t.Fatalf("NewNomadApplier failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if got, err := a.Apply(ctx, api.ScaleRecommendation{}); err != nil || got != OutcomeInvalid {
+		t.Errorf("Apply(invalid) = %q, %v, want invalid", got, err)
+	}
+	if got, err := a.Apply(ctx, valid(2)); err != nil || got != OutcomeUnchanged {
+		t.Errorf("Apply(2) = %q, %v, want unchanged", got, err)
+	}
+	if got, err := a.Apply(ctx, valid(5)); err != nil || got != OutcomeApplied {
+		t.Errorf("Apply(5) = %q, %v, want applied", got, err)
+	}
+
+	if nomad.counts["web"] != 5 || nomad.counts["worker"] != 1 {
+		t.Errorf("counts = %v, want web=5 worker=1", nomad.counts)
+	}
+	if len(nomad.requests) != 1 || nomad.requests[0].Message == "" {
+		t.Errorf("scale requests = %+v", nomad.requests)
+	}
+	if nomad.token != "secret" || nomad.ns != "prod" {
+		t.Errorf("token = %q, namespace = %q", nomad.token, nomad.ns)
+	}
+}
+
+func TestNomadApplierErrors(t *testing.T) {
+	server := httptest.NewServer(&fakeNomad{counts: map[string]int32{"web": 1}})
+	defer server.Close()
+	ctx := context.Background()
+
+	a, _ := NewNomadApplier(NomadConfig{Address: server.URL, Client: server.Client()}, "api", "missing")
+	if _, err := a.Apply(ctx, valid(3)); err == nil {
+		t.Error("expected error for a missing task group")
+	}
+
+	a, _ = NewNomadApplier(NomadConfig{Address: server.URL, Client: server.Client()}, "other", "web")
+	if _, err := a.Apply(ctx, valid(3)); err == nil {
+		t.Error("expected error for a missing job")
+	}
+}
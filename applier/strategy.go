@@ -0,0 +1,307 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// ErrRolloutFailed is returned by StrategyApplier.Apply while the recommended
+// size is the target of a rollout that failed.
+var ErrRolloutFailed = errors.New("rollout failed")
+
+// TargetStatus is the observed state of a scale target.
+type TargetStatus struct {
+	// Replicas is the current size of the target.
+	Replicas int32
+	// ReadyReplicas is the number of replicas ready to serve.
+	ReadyReplicas int32
+}
+
+// StatusFunc returns the current status of the scale target, e.g. from the
+// status of a Deployment or from a readiness.Tracker.
+type StatusFunc func(ctx context.Context) (TargetStatus, error)
+
+// Rollout is a scale up in progress.
+type Rollout struct {
+	// From is the size of the target when the rollout started.
+	From int32 `json:"from"`
+	// To is the size the rollout scales the target to.
+	To int32 `json:"to"`
+	// StartedAt is when the rollout started.
+	StartedAt time.Time `json:"startedAt"`
+	// Steps is the number of steps applied so far.
+	Steps int32 `json:"steps"`
+	// LastStepAt is when the latest step was applied. It is zero before the
+	// first step.
+	LastStepAt time.Time `json:"lastStepAt"`
+}
+
+// Strategy decides how a StrategyApplier moves a scale target towards the
+// size of a rollout.
+type Strategy interface {
+	// Name identifies the strategy in RolloutStatus.
+	Name() string
+
+	// Next returns the size to scale the target to next. Returning the
+	// current size waits until the next call. An error fails the rollout.
+	Next(r Rollout, status TargetStatus, now time.Time) (int32, error)
+}
+
+// Immediate returns the strategy scaling the target to the recommended size
+// at once.
+func Immediate() Strategy {
+	return immediateStrategy{}
+}
+
+type immediateStrategy struct{}
+
+func (immediateStrategy) Name() string { return "immediate" }
+
+func (immediateStrategy) Next(r Rollout, status TargetStatus, now time.Time) (int32, error) {
+	return r.To, nil
+}
+
+// SteppedStrategy scales the target up in equal steps, one per interval.
+type SteppedStrategy struct {
+	steps    int32
+	interval time.Duration
+}
+
+// NewSteppedStrategy creates a strategy splitting a scale up into the given
+// number of steps, applied at least interval apart.
+func NewSteppedStrategy(steps int32, interval time.Duration) (*SteppedStrategy, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps = %d, must be at least 1", steps)
+	}
+	if interval < 0 {
+		return nil, fmt.Errorf("interval = %v, must be at least 0", interval)
+	}
+	return &SteppedStrategy{steps: steps, interval: interval}, nil
+}
+
+// Name returns "stepped".
+func (s *SteppedStrategy) Name() string { return "stepped" }
+
+// Next returns the size after the next step once the interval since the
+// previous step has passed.
+func (s *SteppedStrategy) Next(r Rollout, status TargetStatus, now time.Time) (int32, error) {
+	if r.Steps > 0 && now.Sub(r.LastStepAt) < s.interval {
+		return status.Replicas, nil
+	}
+	step := (r.To - r.From + s.steps - 1) / s.steps
+	return min(r.To, r.From+step*(r.Steps+1)), nil
+}
+
+// CanaryStrategy adds a single replica first, and scales the target to the
+// recommended size only once that replica is ready.
+type CanaryStrategy struct {
+	timeout time.Duration
+}
+
+// NewCanaryStrategy creates a canary strategy failing the rollout if the
+// canary isn't ready within the timeout. A timeout of 0 waits forever.
+func NewCanaryStrategy(timeout time.Duration) (*CanaryStrategy, error) {
+	if timeout < 0 {
+		return nil, fmt.Errorf("timeout = %v, must be at least 0", timeout)
+	}
+	return &CanaryStrategy{timeout: timeout}, nil
+}
+
+// Name returns "canary".
+func (s *CanaryStrategy) Name() string { return "canary" }
+
+// Next returns one replica more than the initial size first, and the size of
+// the rollout once all replicas including the canary are ready.
+func (s *CanaryStrategy) Next(r Rollout, status TargetStatus, now time.Time) (int32, error) {
+	if r.Steps == 0 {
+		return r.From + 1, nil
+	}
+	if status.ReadyReplicas >= status.Replicas && status.Replicas > r.From {
+		return r.To, nil
+	}
+	if s.timeout > 0 && now.Sub(r.LastStepAt) >= s.timeout {
+		return 0, fmt.Errorf("canary not ready after %v: %d of %d replicas ready", s.timeout, status.ReadyReplicas, status.Replicas)
+	}
+	return status.Replicas, nil
+}
+
+// RolloutPhase is the phase of a StrategyApplier.
+type RolloutPhase string
+
+const (
+	// RolloutIdle means no rollout is in progress.
+	RolloutIdle RolloutPhase = "idle"
+
+	// RolloutInProgress means a rollout is scaling the target up.
+	RolloutInProgress RolloutPhase = "in_progress"
+
+	// RolloutFailed means the latest rollout failed. The phase is kept until
+	// a different size is recommended.
+	RolloutFailed RolloutPhase = "failed"
+)
+
+// RolloutStatus reports the state of a StrategyApplier.
+type RolloutStatus struct {
+	// Strategy is the name of the strategy.
+	Strategy string `json:"strategy"`
+	// Phase is the phase of the latest rollout.
+	Phase RolloutPhase `json:"phase"`
+	// Rollout is the latest rollout. It is zero if there was none.
+	Rollout Rollout `json:"rollout"`
+	// Replicas is the size of the target observed by the latest Apply.
+	Replicas int32 `json:"replicas"`
+	// Error describes why the latest rollout failed.
+	Error string `json:"error,omitempty"`
+}
+
+// StrategyConfig configures a StrategyApplier.
+type StrategyConfig struct {
+	// Strategy applies the scale ups. Defaults to Immediate.
+	Strategy Strategy
+
+	// MinScaleUp is the smallest scale up, in replicas, applied with the
+	// strategy. Smaller scale ups are applied immediately. 0 applies every
+	// scale up with the strategy.
+	MinScaleUp int32
+}
+
+// StrategyApplier wraps an Applier and applies large scale ups gradually
+// according to a Strategy, e.g. in steps or after a canary replica became
+// ready. Scale downs and recommendations that are not valid are passed
+// through. A scale up in progress follows later recommendations: a larger
+// one extends the rollout, and one at or below the current size ends it.
+type StrategyApplier struct {
+	next   Applier
+	status StatusFunc
+	config StrategyConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	phase    RolloutPhase
+	rollout  Rollout
+	replicas int32
+	err      error
+}
+
+var _ Applier = (*StrategyApplier)(nil)
+
+// NewStrategyApplier creates an applier scaling the target through next,
+// observing it with status.
+func NewStrategyApplier(next Applier, status StatusFunc, config StrategyConfig) (*StrategyApplier, error) {
+	if next == nil {
+		return nil, fmt.Errorf("applier cannot be nil")
+	}
+	if status == nil {
+		return nil, fmt.Errorf("status func cannot be nil")
+	}
+	if config.MinScaleUp < 0 {
+		return nil, fmt.Errorf("min scale up = %d, must be at least 0", config.MinScaleUp)
+	}
+	if config.Strategy == nil {
+		config.Strategy = Immediate()
+	}
+	return &StrategyApplier{
+		next:   next,
+		status: status,
+		config: config,
+		now:    time.Now,
+		phase:  RolloutIdle,
+	}, nil
+}
+
+// Apply moves the target towards the recommended size. It returns
+// OutcomeInProgress while a rollout hasn't reached the recommended size.
+func (a *StrategyApplier) Apply(ctx context.Context, rec api.ScaleRecommendation) (Outcome, error) {
+	if !rec.ScaleValid {
+		return a.next.Apply(ctx, rec)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	status, err := a.status(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the target status: %w", err)
+	}
+	a.replicas = status.Replicas
+	now := a.now()
+	desired := rec.DesiredPodCount
+
+	switch {
+	case a.phase == RolloutFailed && desired == a.rollout.To:
+		return "", fmt.Errorf("%w: %v", ErrRolloutFailed, a.err)
+	case desired <= status.Replicas:
+		a.phase, a.err = RolloutIdle, nil
+		return a.next.Apply(ctx, rec)
+	case a.phase == RolloutInProgress:
+		a.rollout.To = desired
+	case desired-status.Replicas < a.config.MinScaleUp:
+		a.phase, a.err = RolloutIdle, nil
+		return a.next.Apply(ctx, rec)
+	default:
+		a.phase, a.err = RolloutInProgress, nil
+		a.rollout = Rollout{From: status.Replicas, To: desired, StartedAt: now}
+	}
+
+	size, err := a.config.Strategy.Next(a.rollout, status, now)
+	if err != nil {
+		a.phase, a.err = RolloutFailed, err
+		return "", fmt.Errorf("%w: %v", ErrRolloutFailed, err)
+	}
+	size = min(size, a.rollout.To)
+	if size <= status.Replicas {
+		return OutcomeInProgress, nil
+	}
+
+	step := rec
+	step.DesiredPodCount = size
+	outcome, err := a.next.Apply(ctx, step)
+	if err != nil || outcome != OutcomeApplied {
+		return outcome, err
+	}
+	a.rollout.Steps++
+	a.rollout.LastStepAt = now
+	if size < a.rollout.To {
+		return OutcomeInProgress, nil
+	}
+	a.phase = RolloutIdle
+	return outcome, nil
+}
+
+// Status returns the state of the latest rollout.
+func (a *StrategyApplier) Status() RolloutStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s := RolloutStatus{
+		Strategy: a.config.Strategy.Name(),
+		Phase:    a.phase,
+		Rollout:  a.rollout,
+		Replicas: a.replicas,
+	}
+	if a.err != nil {
+		s.Error = a.err.Error()
+	}
+	return s
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// fakeTarget is a scale target applying every recommendation, whose replicas
+// become ready when told so.
+type fakeTarget struct {
+	replicas int32
+	ready    int32
+	applied  []int32
+}
+
+func (f *fakeTarget) Apply(ctx context.Context, rec api.ScaleRecommendation) (Outcome, error) {
+	if !rec.ScaleValid {
+		return OutcomeInvalid, nil
+	}
+	if rec.DesiredPodCount == f.replicas {
+		return OutcomeUnchanged, nil
+	}
+	f.replicas = rec.DesiredPodCount
+	f.ready = min(f.ready, f.replicas)
+	f.applied = append(f.applied, rec.DesiredPodCount)
+	return OutcomeApplied, nil
+}
+
+func (f *fakeTarget) status(ctx context.Context) (TargetStatus, error) {
+	return TargetStatus{Replicas: f.replicas, ReadyReplicas: f.ready}, nil
+}
+
+func TestNewStrategyApplier(t *testing.T) {
+	target := &fakeTarget{}
+	if _, err := NewStrategyApplier(nil, target.status, StrategyConfig{}); err == nil {
+		t.Error("expected error for nil applier")
+	}
+	if _, err := NewStrategyApplier(target, nil, StrategyConfig{}); err == nil {
+		t.Error("expected error for nil status func")
+	}
+	if _, err := NewStrategyApplier(target, target.status, StrategyConfig{MinScaleUp: -1}); err == nil {
+		t.Error("expected error for negative min scale up")
+	}
+	if _, err := NewSteppedStrategy(0, time.Minute); err == nil {
+		t.Error("expected error for zero steps")
+	}
+	if _, err := NewSteppedStrategy(2, -time.Minute); err == nil {
+		t.Error("expected error for negative interval")
+	}
+	if _, err := NewCanaryStrategy(-time.Minute); err == nil {
+		t.Error("expected error for negative canary timeout")
+	}
+
+	a, err := NewStrategyApplier(target, target.status, StrategyConfig{})
+	if err != nil {
+		t.Fatalf("NewStrategyApplier failed: %v", err)
+	}
+	if got := a.Status(); got.Strategy != "immediate" || got.Phase != RolloutIdle {
+		t.Errorf("Status() = %+v, want an idle immediate strategy", got)
+	}
+}
+
+func TestStrategyApplierImmediate(t *testing.T) {
+	target := &fakeTarget{replicas: 2, ready: 2}
+	a, err := NewStrategyApplier(target, target.status, StrategyConfig{Strategy: Immediate()})
+	if err != nil {
+		t.Fatalf("NewStrategyApplier failed: %v", err)
+	}
+
+	outcome, err := a.Apply(context.Background(), valid(20))
+	if err != nil || outcome != OutcomeApplied {
+		t.Fatalf("Apply() = %q, %v, want %q", outcome, err, OutcomeApplied)
+	}
+	if target.replicas != 20 {
+		t.Errorf("replicas = %d, want 20", target.replicas)
+	}
+}
+
+func TestStrategyApplierStepped(t *testing.T) {
+	target := &fakeTarget{replicas: 2, ready: 2}
+	stepped, err := NewSteppedStrategy(3, time.Minute)
+	if err != nil {
+		t.Fatalf("NewSteppedStrategy failed: %v", err)
+	}
+	a, err := NewStrategyApplier(target, target.status, StrategyConfig{Strategy: stepped, MinScaleUp: 3})
+	if err != nil {
+		t.Fatalf("NewStrategyApplier failed: %v", err)
+	}
+	start := time.Unix(1700000000, 0)
+	now := start
+	a.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	steps := []struct {
+		name     string
+		at       time.Duration
+		desired  int32
+		outcome  Outcome
+		replicas int32
+		phase    RolloutPhase
+	}{
+		{name: "small scale up applied immediately", desired: 4, outcome: OutcomeApplied, replicas: 4, phase: RolloutIdle},
+		{name: "first step", at: time.Minute, desired: 10, outcome: OutcomeInProgress, replicas: 6, phase: RolloutInProgress},
+		{name: "waits for the interval", at: time.Minute + 30*time.Second, desired: 10, outcome: OutcomeInProgress, replicas: 6, phase: RolloutInProgress},
+		// A larger recommendation extends the rollout, keeping the number of steps.
+		{name: "second step", at: 2 * time.Minute, desired: 16, outcome: OutcomeInProgress, replicas: 12, phase: RolloutInProgress},
+		{name: "last step", at: 3 * time.Minute, desired: 16, outcome: OutcomeApplied, replicas: 16, phase: RolloutIdle},
+		{name: "scale down applied immediately", at: 5 * time.Minute, desired: 3, outcome: OutcomeApplied, replicas: 3, phase: RolloutIdle},
+	}
+
+	for _, s := range steps {
+		now = start.Add(s.at)
+		outcome, err := a.Apply(ctx, valid(s.desired))
+		if err != nil {
+			t.Fatalf("%s: Apply failed: %v", s.name, err)
+		}
+		if outcome != s.outcome {
+			t.Errorf("%s: outcome = %q, want %q", s.name, outcome, s.outcome)
+		}
+		if target.replicas != s.replicas {
+			t.Errorf("%s: replicas = %d, want %d", s.name, target.replicas, s.replicas)
+		}
+		if got := a.Status(); got.Phase != s.phase {
+			t.Errorf("%s: Status() = %+v, want phase %q", s.name, got, s.phase)
+		}
+	}
+}
+
+func TestStrategyApplierCanary(t *testing.T) {
+	target := &fakeTarget{replicas: 2, ready: 2}
+	canary, err := NewCanaryStrategy(5 * time.Minute)
+	if err != nil {
+		t.Fatalf("NewCanaryStrategy failed: %v", err)
+	}
+	a, err := NewStrategyApplier(target, target.status, StrategyConfig{Strategy: canary})
+	if err != nil {
+		t.Fatalf("NewStrategyApplier failed: %v", err)
+	}
+	start := time.Unix(1700000000, 0)
+	now := start
+	a.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if outcome, err := a.Apply(ctx, valid(10)); err != nil || outcome != OutcomeInProgress {
+		t.Fatalf("Apply() = %q, %v, want %q", outcome, err, OutcomeInProgress)
+	}
+	if target.replicas != 3 {
+		t.Fatalf("replicas = %d, want the canary added", target.replicas)
+	}
+
+	// The rollout waits for the canary to become ready.
+	now = start.Add(time.Minute)
+	if outcome, err := a.Apply(ctx, valid(10)); err != nil || outcome != OutcomeInProgress {
+		t.Fatalf("Apply() = %q, %v, want %q", outcome, err, OutcomeInProgress)
+	}
+	if target.replicas != 3 {
+		t.Fatalf("replicas = %d, want 3 until the canary is ready", target.replicas)
+	}
+	want := RolloutStatus{
+		Strategy: "canary",
+		Phase:    RolloutInProgress,
+		Rollout:  Rollout{From: 2, To: 10, StartedAt: start, Steps: 1, LastStepAt: start},
+		Replicas: 3,
+	}
+	if got := a.Status(); got != want {
+		t.Errorf("Status() = %+v, want %+v", got, want)
+	}
+
+	target.ready = 3
+	now = start.Add(2 * time.Minute)
+	if outcome, err := a.Apply(ctx, valid(10)); err != nil || outcome != OutcomeApplied {
+		t.Fatalf("Apply() = %q, %v, want %q", outcome, err, OutcomeApplied)
+	}
+	if target.replicas != 10 {
+		t.Errorf("replicas = %d, want 10", target.replicas)
+	}
+	if got := a.Status().Phase; got != RolloutIdle {
+		t.Errorf("phase = %q, want %q", got, RolloutIdle)
+	}
+}
+
+func TestStrategyApplierCanaryTimeout(t *testing.T) {
+	target := &fakeTarget{replicas: 2, ready: 2}
+	canary, err := NewCanaryStrategy(5 * time.Minute)
+	if err != nil {
+		t.Fatalf("NewCanaryStrategy failed: %v", err)
+	}
+	a, err := NewStrategyApplier(target, target.status, StrategyConfig{Strategy: canary})
+	if err != nil {
+		t.Fatalf("NewStrategyApplier failed: %v", err)
+	}
+	start := time.Unix(1700000000, 0)
+	now := start
+	a.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := a.Apply(ctx, valid(10)); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	now = start.Add(5 * time.Minute)
+	if _, err := a.Apply(ctx, valid(10)); !errors.Is(err, ErrRolloutFailed) {
+		t.Fatalf("Apply() error = %v, want %v", err, ErrRolloutFailed)
+	}
+	if got := a.Status(); got.Phase != RolloutFailed || got.Error == "" {
+		t.Errorf("Status() = %+v, want a failed rollout with an error", got)
+	}
+
+	// The failed rollout isn't retried for the same recommendation.
+	now = start.Add(6 * time.Minute)
+	if _, err := a.Apply(ctx, valid(10)); !errors.Is(err, ErrRolloutFailed) {
+		t.Errorf("Apply() error = %v, want %v", err, ErrRolloutFailed)
+	}
+	if target.replicas != 3 {
+		t.Errorf("replicas = %d, want 3", target.replicas)
+	}
+
+	// A different recommendation starts a new rollout.
+	target.ready = 3
+	if outcome, err := a.Apply(ctx, valid(12)); err != nil || outcome != OutcomeInProgress {
+		t.Errorf("Apply() = %q, %v, want %q", outcome, err, OutcomeInProgress)
+	}
+	if target.replicas != 4 {
+		t.Errorf("replicas = %d, want a new canary", target.replicas)
+	}
+}
+
+func TestStrategyApplierPassesThrough(t *testing.T) {
+	target := &fakeTarget{replicas: 2, ready: 2}
+	canary, err := NewCanaryStrategy(0)
+	if err != nil {
+		t.Fatalf("NewCanaryStrategy failed: %v", err)
+	}
+	a, err := NewStrategyApplier(target, target.status, StrategyConfig{Strategy: canary})
+	if err != nil {
+		t.Fatalf("NewStrategyApplier failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if outcome, err := a.Apply(ctx, api.ScaleRecommendation{DesiredPodCount: 10}); err != nil || outcome != OutcomeInvalid {
+		t.Errorf("Apply() = %q, %v, want %q", outcome, err, OutcomeInvalid)
+	}
+	if outcome, err := a.Apply(ctx, valid(2)); err != nil || outcome != OutcomeUnchanged {
+		t.Errorf("Apply() = %q, %v, want %q", outcome, err, OutcomeUnchanged)
+	}
+
+	failing := func(ctx context.Context) (TargetStatus, error) { return TargetStatus{}, errors.New("boom") }
+	a, err = NewStrategyApplier(target, failing, StrategyConfig{Strategy: canary})
+	if err != nil {
+		t.Fatalf("NewStrategyApplier failed: %v", err)
+	}
+	if _, err := a.Apply(ctx, valid(10)); err == nil {
+		t.Error("expected error for a failing status func")
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit records every scaling decision with its inputs, the
+// configuration it was made with, its output and the reason for it.
+// Records are written to a pluggable Sink, e.g. a JSON Lines file.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// Reasons for scaling decisions.
+const (
+	ReasonInsufficientData = "insufficient-data"
+	ReasonBurstMode        = "burst-mode"
+	ReasonScaleUp          = "scale-up"
+	ReasonScaleDown        = "scale-down"
+	ReasonNoChange         = "no-change"
+)
+
+// Record is a complete record of a single scaling decision.
+type Record struct {
+	// Time is when the decision was made.
+	Time time.Time `json:"time"`
+
+	// Name identifies the autoscaler that made the decision.
+	Name string `json:"name,omitempty"`
+
+	// StableValue, BurstValue and ReadyPodCount are the inputs of the decision.
+	StableValue   float64 `json:"stableValue"`
+	BurstValue    float64 `json:"burstValue"`
+	ReadyPodCount int32   `json:"readyPodCount"`
+
+	// ConfigHash identifies the configuration the decision was made with.
+	ConfigHash string `json:"configHash"`
+
+	// DesiredPodCount, ScaleValid and InBurstMode are the output of the decision.
+	DesiredPodCount int32 `json:"desiredPodCount"`
+	ScaleValid      bool  `json:"scaleValid"`
+	InBurstMode     bool  `json:"inBurstMode"`
+
+	// Reason summarizes why the decision was made.
+	Reason string `json:"reason"`
+
+	// LimitedBy names the constraint that held the decision back, if any.
+	LimitedBy api.ScaleLimit `json:"limitedBy,omitempty"`
+
+	// Latency is how long the decision took, encoded in nanoseconds.
+	Latency time.Duration `json:"latency,omitempty"`
+}
+
+// Sink receives audit records.
+type Sink interface {
+	// Write stores a record.
+	Write(r Record) error
+}
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(r Record) error
+
+// Write calls f(r).
+func (f SinkFunc) Write(r Record) error {
+	return f(r)
+}
+
+// JSONLSink writes records as JSON Lines, one JSON object per line.
+type JSONLSink struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	closer io.Closer
+}
+
+// NewJSONLSink creates a sink that writes records to w.
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	return &JSONLSink{enc: json.NewEncoder(w)}
+}
+
+// NewStdoutSink creates a sink that writes records to the standard output.
+func NewStdoutSink() *JSONLSink {
+	return NewJSONLSink(os.Stdout)
+}
+
+// NewFileSink creates a sink that appends records to the file at path.
+// The file is created if it doesn't exist and is closed by Close.
+func NewFileSink(path string) (*JSONLSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	s := NewJSONLSink(f)
+	s.closer = f
+	return s, nil
+}
+
+// Write writes the record as a single line.
+func (s *JSONLSink) Write(r Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(r)
+}
+
+// Close closes the underlying file of sinks created with NewFileSink.
+func (s *JSONLSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// ConfigHash returns a short stable hash of the configuration, so records
+// can be correlated with configuration changes without storing the full
+// configuration in every record.
+func ConfigHash(cfg api.AutoscalerConfig) string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		// AutoscalerConfig only contains plain fields.
+		data = fmt.Appendf(nil, "%+v", cfg)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Reason returns the reason for a decision made with the given ready pod count.
+func Reason(readyPods int32, rec api.ScaleRecommendation) string {
+	switch {
+	case !rec.ScaleValid:
+		return ReasonInsufficientData
+	case rec.InBurstMode:
+		return ReasonBurstMode
+	case rec.DesiredPodCount > readyPods:
+		return ReasonScaleUp
+	case rec.DesiredPodCount < readyPods:
+		return ReasonScaleDown
+	default:
+		return ReasonNoChange
+	}
+}
+
+// Scaler is the autoscaler interface audited by Autoscaler. It is implemented
+// by algorithm.SlidingWindowAutoscaler.
+type Scaler = api.Recommender
+
+// Autoscaler wraps an autoscaler and writes an audit record for every decision.
+type Autoscaler struct {
+	name   string
+	scaler Scaler
+	sink   Sink
+
+	failed atomic.Uint64
+}
+
+var _ api.Recommender = (*Autoscaler)(nil)
+
+// NewAutoscaler wraps the autoscaler, writing records with the given name
+// to the sink.
+func NewAutoscaler(name string, scaler Scaler, sink Sink) (*Autoscaler, error) {
+	if scaler == nil {
+		return nil, fmt.Errorf("autoscaler cannot be nil")
+	}
+	if sink == nil {
+		return nil, fmt.Errorf("audit sink cannot be nil")
+	}
+	return &Autoscaler{name: name, scaler: scaler, sink: sink}, nil
+}
+
+// Scale delegates to the wrapped autoscaler and records the decision.
+// Failing to write a record doesn't affect the decision; such failures are
+// counted by FailedWrites.
+func (a *Autoscaler) Scale(snapshot api.MetricSnapshot, now time.Time) api.ScaleRecommendation {
+	start := time.Now()
+	rec := a.scaler.Scale(snapshot, now)
+	latency := time.Since(start)
+
+	err := a.sink.Write(Record{
+		Time:            now,
+		Name:            a.name,
+		StableValue:     snapshot.StableValue(),
+		BurstValue:      snapshot.BurstValue(),
+		ReadyPodCount:   snapshot.ReadyPodCount(),
+		ConfigHash:      ConfigHash(a.scaler.GetConfig()),
+		DesiredPodCount: rec.DesiredPodCount,
+		ScaleValid:      rec.ScaleValid,
+		InBurstMode:     rec.InBurstMode,
+		Reason:          Reason(snapshot.ReadyPodCount(), rec),
+		LimitedBy:       rec.LimitedBy,
+		Latency:         latency,
+	})
+	if err != nil {
+		a.failed.Add(1)
+	}
+	return rec
+}
+
+// GetConfig returns the configuration of the wrapped autoscaler.
+func (a *Autoscaler) GetConfig() api.AutoscalerConfig {
+	return a.scaler.GetConfig()
+}
+
+// FailedWrites returns the number of records that could not be written.
+func (a *Autoscaler) FailedWrites() uint64 {
+	return a.failed.Load()
+}
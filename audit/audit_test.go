@@ -0,0 +1,188 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/algorithm"
+	"github.com/Fedosin/libkpa/api"
+	libkpaconfig "github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/metrics"
+)
+
+func TestReason(t *testing.T) {
+	tests := []struct {
+		name      string
+		readyPods int32
+		rec       api.ScaleRecommendation
+		want      string
+	}{
+		{name: "invalid", readyPods: 1, rec: api.ScaleRecommendation{}, want: ReasonInsufficientData},
+		{name: "burst", readyPods: 1, rec: api.ScaleRecommendation{DesiredPodCount: 5, ScaleValid: true, InBurstMode: true}, want: ReasonBurstMode},
+		{name: "up", readyPods: 1, rec: api.ScaleRecommendation{DesiredPodCount: 5, ScaleValid: true}, want: ReasonScaleUp},
+		{name: "down", readyPods: 5, rec: api.ScaleRecommendation{DesiredPodCount: 1, ScaleValid: true}, want: ReasonScaleDown},
+		{name: "no change", readyPods: 3, rec: api.ScaleRecommendation{DesiredPodCount: 3, ScaleValid: true}, want: ReasonNoChange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Reason(tt.readyPods, tt.rec); got != tt.want {
+				t.Errorf("Reason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigHash(t *testing.T) {
+	a := *libkpaconfig.NewDefaultAutoscalerConfig()
+	b := a
+
+	if ConfigHash(a) != ConfigHash(b) {
+		t.Error("equal configs have different hashes")
+	}
+	b.TargetValue++
+	if ConfigHash(a) == ConfigHash(b) {
+		t.Error("different configs have equal hashes")
+	}
+	if got := len(ConfigHash(a)); got != 16 {
+		t.Errorf("len(ConfigHash()) = %d, want 16", got)
+	}
+}
+
+func TestAutoscaler(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	scaler, err := algorithm.NewSlidingWindowAutoscaler(config)
+	if err != nil {
+		t.Fatalf("NewSlidingWindowAutoscaler failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	a, err := NewAutoscaler("web", scaler, NewJSONLSink(&buf))
+	if err != nil {
+		t.Fatalf("NewAutoscaler failed: %v", err)
+	}
+
+	now := time.Now()
+	rec := a.Scale(metrics.NewMetricSnapshot(500, 500, 2, now), now)
+
+	var got Record
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode record %q: %v", buf.String(), err)
+	}
+	want := Record{
+		Time:            now,
+		Name:            "web",
+		StableValue:     500,
+		BurstValue:      500,
+		ReadyPodCount:   2,
+		ConfigHash:      ConfigHash(config),
+		DesiredPodCount: rec.DesiredPodCount,
+		ScaleValid:      rec.ScaleValid,
+		InBurstMode:     rec.InBurstMode,
+		Reason:          Reason(2, rec),
+		LimitedBy:       rec.LimitedBy,
+	}
+	if !got.Time.Equal(want.Time) {
+		t.Errorf("Time = %v, want %v", got.Time, want.Time)
+	}
+	got.Time = want.Time
+	if got.Latency < 0 {
+		t.Errorf("Latency = %v, want at least 0", got.Latency)
+	}
+	want.Latency = got.Latency
+	if got != want {
+		t.Errorf("record = %+v, want %+v", got, want)
+	}
+	if a.GetConfig() != config {
+		t.Error("GetConfig() doesn't return the wrapped config")
+	}
+}
+
+func TestAutoscalerFailedWrites(t *testing.T) {
+	scaler, err := algorithm.NewSlidingWindowAutoscaler(*libkpaconfig.NewDefaultAutoscalerConfig())
+	if err != nil {
+		t.Fatalf("NewSlidingWindowAutoscaler failed: %v", err)
+	}
+	sink := SinkFunc(func(Record) error { return errors.New("disk full") })
+
+	a, err := NewAutoscaler("web", scaler, sink)
+	if err != nil {
+		t.Fatalf("NewAutoscaler failed: %v", err)
+	}
+	now := time.Now()
+	a.Scale(metrics.NewMetricSnapshot(100, 100, 1, now), now)
+	a.Scale(metrics.NewMetricSnapshot(100, 100, 1, now), now)
+
+	if got := a.FailedWrites(); got != 2 {
+		t.Errorf("FailedWrites() = %d, want 2", got)
+	}
+
+	if _, err := NewAutoscaler("web", nil, sink); err == nil {
+		t.Error("expected error for nil autoscaler")
+	}
+	if _, err := NewAutoscaler("web", scaler, nil); err == nil {
+		t.Error("expected error for nil sink")
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	for range 2 {
+		sink, err := NewFileSink(path)
+		if err != nil {
+			t.Fatalf("NewFileSink failed: %v", err)
+		}
+		if err := sink.Write(Record{Name: "web", Reason: ReasonNoChange}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	defer f.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Errorf("line %d is not a record: %v", lines, err)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("got %d lines, want 2 (records must be appended)", lines)
+	}
+
+	if _, err := NewFileSink(filepath.Join(t.TempDir(), "missing", "audit.jsonl")); err == nil {
+		t.Error("expected error for a missing directory")
+	}
+}
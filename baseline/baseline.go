@@ -0,0 +1,266 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package baseline learns the seasonal shape of a metric over days or weeks,
+// so that the expected load at a given time of day can be looked up ahead of
+// time and blended with live metric windows.
+package baseline
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// Daily is the period of a baseline following the time of day.
+	Daily = 24 * time.Hour
+
+	// Weekly is the period of a baseline following the time of week.
+	Weekly = 7 * Daily
+
+	// DefaultBucketSize is the default resolution of a baseline.
+	DefaultBucketSize = 15 * time.Minute
+
+	// DefaultMaxCycles is the default number of periods the learned average
+	// is taken over. Older periods decay exponentially beyond that.
+	DefaultMaxCycles = 4
+)
+
+// slot holds the learned average of one bucket of the period, along with the
+// samples of the period in progress. float32 keeps a weekly baseline with
+// 15 minute buckets below 20KiB.
+type slot struct {
+	// mean is the average over the completed periods.
+	mean float32
+	// cycles is the number of completed periods folded into mean.
+	cycles uint16
+
+	// sum and n accumulate the samples of the current period.
+	sum   float32
+	n     uint32
+	cycle int64
+}
+
+// Baseline records per time-of-period averages of a metric. It is safe for
+// concurrent use.
+type Baseline struct {
+	mu         sync.RWMutex
+	period     time.Duration
+	bucketSize time.Duration
+	maxCycles  int
+	slots      []slot
+}
+
+// New creates a Baseline with the given period, e.g. Daily or Weekly, and
+// bucket size. The period must be a multiple of the bucket size. The learned
+// average of every bucket is taken over the last maxCycles periods.
+func New(period, bucketSize time.Duration, maxCycles int) (*Baseline, error) {
+	if bucketSize <= 0 {
+		return nil, fmt.Errorf("bucket size = %v, must be positive", bucketSize)
+	}
+	if period < bucketSize || period%bucketSize != 0 {
+		return nil, fmt.Errorf("period = %v, must be a multiple of the bucket size %v", period, bucketSize)
+	}
+	if maxCycles < 1 || maxCycles > math.MaxUint16 {
+		return nil, fmt.Errorf("max cycles = %d, must be in [1, %d]", maxCycles, math.MaxUint16)
+	}
+
+	return &Baseline{
+		period:     period,
+		bucketSize: bucketSize,
+		maxCycles:  maxCycles,
+		slots:      make([]slot, period/bucketSize),
+	}, nil
+}
+
+// NewDaily creates a daily Baseline with the default settings.
+func NewDaily() *Baseline {
+	b, _ := New(Daily, DefaultBucketSize, DefaultMaxCycles)
+	return b
+}
+
+// NewWeekly creates a weekly Baseline with the default settings.
+func NewWeekly() *Baseline {
+	b, _ := New(Weekly, DefaultBucketSize, DefaultMaxCycles)
+	return b
+}
+
+// Period returns the period of the baseline.
+func (b *Baseline) Period() time.Duration {
+	return b.period
+}
+
+// BucketSize returns the resolution of the baseline.
+func (b *Baseline) BucketSize() time.Duration {
+	return b.bucketSize
+}
+
+// locate returns the slot index and the period number of the given time.
+// Periods are aligned to the Unix epoch in UTC.
+func (b *Baseline) locate(t time.Time) (int, int64) {
+	ns := t.UnixNano()
+	cycle := ns / int64(b.period)
+	offset := ns % int64(b.period)
+	if offset < 0 {
+		cycle--
+		offset += int64(b.period)
+	}
+	return int(offset / int64(b.bucketSize)), cycle
+}
+
+// Record adds a sample at the given time. Samples of the same bucket within
+// one period are averaged before they are folded into the learned average.
+func (b *Baseline) Record(t time.Time, value float64) {
+	idx, cycle := b.locate(t)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := &b.slots[idx]
+	switch {
+	case s.n == 0 || s.cycle == cycle:
+	case cycle > s.cycle:
+		b.fold(s)
+	default:
+		// Samples of an already folded period are ignored.
+		return
+	}
+	s.cycle = cycle
+	s.sum += float32(value)
+	s.n++
+}
+
+// fold merges the samples of the period in progress into the learned average.
+func (b *Baseline) fold(s *slot) {
+	avg := s.sum / float32(s.n)
+	if int(s.cycles) < b.maxCycles {
+		s.cycles++
+	}
+	s.mean += (avg - s.mean) / float32(s.cycles)
+	s.sum, s.n = 0, 0
+}
+
+// Expected returns the expected value of the metric at the given time of
+// the period. It returns false if no completed period carries data for the
+// bucket yet. Samples of a period that has already ended, but were not
+// followed by newer samples in the same bucket, are taken into account too.
+func (b *Baseline) Expected(t time.Time) (float64, bool) {
+	idx, cycle := b.locate(t)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	s := b.slots[idx]
+	if s.n > 0 && s.cycle < cycle {
+		b.fold(&s)
+	}
+	if s.cycles == 0 {
+		return 0, false
+	}
+	return float64(s.mean), true
+}
+
+// ExpectedAt returns the expected value of the metric at now + horizon.
+func (b *Baseline) ExpectedAt(now time.Time, horizon time.Duration) (float64, bool) {
+	return b.Expected(now.Add(horizon))
+}
+
+// Blend mixes a live value with the expected value at now + horizon. weight
+// is the share of the baseline in [0, 1]. The live value is returned as is
+// if the baseline has no data for that time.
+func (b *Baseline) Blend(live float64, now time.Time, horizon time.Duration, weight float64) float64 {
+	expected, ok := b.ExpectedAt(now, horizon)
+	if !ok {
+		return live
+	}
+	weight = math.Max(0, math.Min(1, weight))
+	return weight*expected + (1-weight)*live
+}
+
+// Reset drops everything learned so far.
+func (b *Baseline) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	clear(b.slots)
+}
+
+const (
+	encodingVersion = 1
+	headerSize      = 1 + 8 + 8 + 2
+	slotSize        = 4 + 2 + 4 + 4 + 8
+)
+
+// MarshalBinary encodes the baseline, so that it survives restarts.
+func (b *Baseline) MarshalBinary() ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	buf := make([]byte, 0, headerSize+len(b.slots)*slotSize)
+	buf = append(buf, encodingVersion)
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(b.period))
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(b.bucketSize))
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(b.maxCycles))
+	for _, s := range b.slots {
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(s.mean))
+		buf = binary.LittleEndian.AppendUint16(buf, s.cycles)
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(s.sum))
+		buf = binary.LittleEndian.AppendUint32(buf, s.n)
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(s.cycle))
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary restores a baseline encoded by MarshalBinary. The receiver
+// takes over the period, bucket size and max cycles of the encoded baseline.
+func (b *Baseline) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize {
+		return errors.New("baseline data is too short")
+	}
+	if data[0] != encodingVersion {
+		return fmt.Errorf("unsupported baseline encoding version %d", data[0])
+	}
+	period := time.Duration(binary.LittleEndian.Uint64(data[1:]))
+	bucketSize := time.Duration(binary.LittleEndian.Uint64(data[9:]))
+	maxCycles := int(binary.LittleEndian.Uint16(data[17:]))
+
+	decoded, err := New(period, bucketSize, maxCycles)
+	if err != nil {
+		return fmt.Errorf("invalid baseline data: %w", err)
+	}
+	data = data[headerSize:]
+	if len(data) != len(decoded.slots)*slotSize {
+		return fmt.Errorf("baseline data has %d bytes of buckets, want %d", len(data), len(decoded.slots)*slotSize)
+	}
+	for i := range decoded.slots {
+		d := data[i*slotSize:]
+		decoded.slots[i] = slot{
+			mean:   math.Float32frombits(binary.LittleEndian.Uint32(d)),
+			cycles: binary.LittleEndian.Uint16(d[4:]),
+			sum:    math.Float32frombits(binary.LittleEndian.Uint32(d[6:])),
+			n:      binary.LittleEndian.Uint32(d[10:]),
+			cycle:  int64(binary.LittleEndian.Uint64(d[14:])),
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.period, b.bucketSize, b.maxCycles, b.slots = decoded.period, decoded.bucketSize, decoded.maxCycles, decoded.slots
+	return nil
+}
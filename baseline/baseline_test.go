@@ -0,0 +1,185 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baseline
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var day0 = time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		period     time.Duration
+		bucketSize time.Duration
+		maxCycles  int
+		wantErr    bool
+	}{
+		{"daily", Daily, DefaultBucketSize, DefaultMaxCycles, false},
+		{"weekly", Weekly, time.Hour, 1, false},
+		{"zero bucket size", Daily, 0, 4, true},
+		{"period not a multiple", Daily, 7 * time.Minute, 4, true},
+		{"period below bucket size", time.Minute, time.Hour, 4, true},
+		{"zero max cycles", Daily, time.Hour, 0, true},
+		{"too many max cycles", Daily, time.Hour, math.MaxUint16 + 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.period, tt.bucketSize, tt.maxCycles)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBaselineLearnsDailyShape(t *testing.T) {
+	b, err := New(Daily, time.Hour, 4)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	nineAM := day0.Add(9 * time.Hour)
+	b.Record(nineAM, 100)
+	b.Record(nineAM.Add(30*time.Minute), 200)
+
+	if _, ok := b.Expected(nineAM.Add(10 * time.Minute)); ok {
+		t.Error("Expected() ok = true before the period completed")
+	}
+
+	// Next day the previous day's average is known.
+	if got, ok := b.Expected(nineAM.Add(Daily)); !ok || got != 150 {
+		t.Errorf("Expected() = %v, %v, want 150, true", got, ok)
+	}
+	if _, ok := b.Expected(nineAM.Add(Daily + time.Hour)); ok {
+		t.Error("Expected() ok = true for a bucket without data")
+	}
+
+	// A second day is averaged with the first one.
+	b.Record(nineAM.Add(Daily), 50)
+	if got, ok := b.ExpectedAt(nineAM.Add(Daily-time.Hour), 2*Daily+time.Hour); !ok || got != 100 {
+		t.Errorf("ExpectedAt() = %v, %v, want 100, true", got, ok)
+	}
+}
+
+func TestBaselineDecaysOldCycles(t *testing.T) {
+	b, err := New(Daily, time.Hour, 2)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	at := day0.Add(12 * time.Hour)
+	for i, v := range []float64{100, 100, 300} {
+		b.Record(at.Add(time.Duration(i)*Daily), v)
+	}
+
+	// After two cycles, each new cycle moves the average half-way.
+	if got, ok := b.Expected(at.Add(3 * Daily)); !ok || got != 200 {
+		t.Errorf("Expected() = %v, %v, want 200, true", got, ok)
+	}
+
+	// Samples of a folded cycle are ignored.
+	b.Record(at, 1000)
+	if got, _ := b.Expected(at.Add(3 * Daily)); got != 200 {
+		t.Errorf("Expected() = %v after a stale sample, want 200", got)
+	}
+}
+
+func TestBaselineBlend(t *testing.T) {
+	b := NewDaily()
+	now := day0.Add(8 * time.Hour)
+
+	if got := b.Blend(40, now, time.Hour, 0.5); got != 40 {
+		t.Errorf("Blend() = %v without data, want 40", got)
+	}
+
+	b.Record(now.Add(time.Hour), 100)
+	tomorrow := now.Add(Daily)
+	tests := []struct {
+		name   string
+		weight float64
+		want   float64
+	}{
+		{"live only", 0, 40},
+		{"half", 0.5, 70},
+		{"baseline only", 1, 100},
+		{"clamped", 2, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.Blend(40, tomorrow, time.Hour, tt.weight); got != tt.want {
+				t.Errorf("Blend() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaselineBeforeEpoch(t *testing.T) {
+	b, err := New(Daily, time.Hour, 4)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	at := time.Date(1969, 12, 30, 5, 0, 0, 0, time.UTC)
+	b.Record(at, 10)
+	if got, ok := b.Expected(at.Add(Daily)); !ok || got != 10 {
+		t.Errorf("Expected() = %v, %v, want 10, true", got, ok)
+	}
+}
+
+func TestBaselineMarshalBinary(t *testing.T) {
+	b := NewWeekly()
+	at := day0.Add(30 * time.Hour)
+	b.Record(at, 10)
+	b.Record(at.Add(Weekly), 20)
+
+	data, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	restored := NewDaily()
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if restored.Period() != Weekly || restored.BucketSize() != DefaultBucketSize {
+		t.Errorf("restored period, bucket size = %v, %v, want %v, %v",
+			restored.Period(), restored.BucketSize(), Weekly, DefaultBucketSize)
+	}
+	if got, ok := restored.Expected(at.Add(2 * Weekly)); !ok || got != 15 {
+		t.Errorf("Expected() = %v, %v, want 15, true", got, ok)
+	}
+
+	for _, bad := range [][]byte{nil, data[:headerSize], append([]byte{2}, data[1:]...)} {
+		if err := restored.UnmarshalBinary(bad); err == nil {
+			t.Errorf("UnmarshalBinary(%d bytes) succeeded, want error", len(bad))
+		}
+	}
+}
+
+func TestBaselineReset(t *testing.T) {
+	b := NewDaily()
+	b.Record(day0, 10)
+	b.Reset()
+	if _, ok := b.Expected(day0.Add(Daily)); ok {
+		t.Error("Expected() ok = true after Reset")
+	}
+}
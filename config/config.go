@@ -20,6 +20,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -34,17 +35,47 @@ const (
 	// Default values
 	defaultMaxScaleUpRate           = 1000.0
 	defaultMaxScaleDownRate         = 2.0
+	defaultMaxScaleDownFraction     = 0.0
 	defaultBurstWindowPercentage    = 10.0
 	defaultBurstThresholdPercentage = 200.0
+	defaultBurstAbsoluteThreshold   = 0.0
+	defaultBurstEntryTicks          = int32(0)
+	defaultBurstEntryDelay          = 0 * time.Second
+	defaultBurstBlending            = false
 	defaultStableWindow             = 60 * time.Second
+	defaultMinWindowFillFraction    = 0.0
+	defaultWindowGranularity        = 0 * time.Second
 	defaultScaleToZeroGracePeriod   = 30 * time.Second
 	defaultScaleDownDelay           = 0 * time.Second
+	defaultScaleDownSoakTicks       = int32(0)
 	defaultInitialScale             = int32(1)
 	defaultMinScale                 = int32(0)
 	defaultMaxScale                 = int32(0)
+	defaultZones                    = int32(0)
+	defaultMinPodsPerZone           = int32(0)
 	defaultActivationScale          = int32(1)
+	defaultIgnoreActivationScale    = false
+	defaultLastPodStickiness        = false
+	defaultActivationThreshold      = 0.0
+	defaultScaleInvariant           = false
+	defaultProvisioningRate         = 0.0
 	defaultTargetValue              = 100.0
 	defaultTotalTargetValue         = 0.0
+	defaultScalingMetricType        = api.ScalingMetricValue
+
+	// Defaults for request rate based scaling. Rates are derived from counter
+	// deltas and are noisier than gauges, so the burst window is wider.
+	defaultRPSBurstWindowPercentage = 20.0
+
+	// Preset for accelerator (GPU or other device) workloads. Such pods are
+	// expensive and slow to start, so load is averaged over longer windows
+	// and capacity is released slowly.
+	deviceTargetUtilization      = 80.0
+	deviceStableWindow           = 300 * time.Second
+	deviceScaleDownDelay         = 300 * time.Second
+	deviceMaxScaleDownRate       = 1.5
+	deviceScaleDownSoakTicks     = int32(3)
+	deviceScaleToZeroGracePeriod = 600 * time.Second
 
 	// Validation constraints
 	minStableWindow = 5 * time.Second
@@ -63,6 +94,14 @@ func (ce *configErrors) add(err error) {
 	}
 }
 
+// addFor adds an error caused by the value of the given configuration map
+// key.
+func (ce *configErrors) addFor(key string, err error) {
+	if err != nil {
+		ce.errors = append(ce.errors, &keyError{key: key, err: err})
+	}
+}
+
 func (ce *configErrors) hasErrors() bool {
 	return len(ce.errors) > 0
 }
@@ -82,8 +121,22 @@ func (ce *configErrors) Error() string {
 
 // Load creates a Config from environment variables and validates it.
 func Load() (*api.AutoscalerConfig, error) {
+	return load(false)
+}
+
+// LoadStrict is Load, but refuses ambiguous values instead of guessing what
+// they mean, see LoadFromMapStrict.
+func LoadStrict() (*api.AutoscalerConfig, error) {
+	return load(true)
+}
+
+func load(strict bool) (*api.AutoscalerConfig, error) {
 	errs := &configErrors{}
 
+	scalingMetricType := api.ScalingMetricType(getEnvString("SCALING_METRIC_TYPE", string(defaultScalingMetricType)))
+	unit := api.Unit(getEnvString("UNIT", string(api.UnitNone)))
+	burstWindowPercentageDefault := defaultBurstWindowPercentageFor(scalingMetricType)
+
 	scaleToZeroGracePeriod, err := getEnvDuration("SCALE_TO_ZERO_GRACE_PERIOD", defaultScaleToZeroGracePeriod)
 	errs.add(err)
 
@@ -93,55 +146,117 @@ func Load() (*api.AutoscalerConfig, error) {
 	maxScaleDownRate, err := getEnvFloat("MAX_SCALE_DOWN_RATE", defaultMaxScaleDownRate)
 	errs.add(err)
 
-	targetValue, err := getEnvFloat("TARGET_VALUE", defaultTargetValue)
+	maxScaleDownFraction, err := getEnvFloat("MAX_SCALE_DOWN_FRACTION", defaultMaxScaleDownFraction)
+	errs.add(err)
+
+	targetValue, err := getEnvQuantity("TARGET_VALUE", unit, defaultTargetValue)
 	errs.add(err)
 
-	totalTargetValue, err := getEnvFloat("TOTAL_TARGET_VALUE", defaultTotalTargetValue)
+	totalTargetValue, err := getEnvQuantity("TOTAL_TARGET_VALUE", unit, defaultTotalTargetValue)
 	errs.add(err)
 
 	burstThreshold, err := getEnvFloat("BURST_THRESHOLD_PERCENTAGE", defaultBurstThresholdPercentage)
 	errs.add(err)
+	if err == nil {
+		burstThreshold, err = burstThresholdRatio(burstThreshold, strict)
+		errs.add(err)
+	}
+
+	burstAbsoluteThreshold, err := getEnvFloat("BURST_ABSOLUTE_THRESHOLD", defaultBurstAbsoluteThreshold)
+	errs.add(err)
+
+	burstWindowPercentage, err := getEnvFloat("BURST_WINDOW_PERCENTAGE", burstWindowPercentageDefault)
+	errs.add(err)
 
-	burstWindowPercentage, err := getEnvFloat("BURST_WINDOW_PERCENTAGE", defaultBurstWindowPercentage)
+	burstEntryTicks, err := getEnvInt32("BURST_ENTRY_TICKS", defaultBurstEntryTicks)
+	errs.add(err)
+
+	burstEntryDelay, err := getEnvDuration("BURST_ENTRY_DELAY", defaultBurstEntryDelay)
+	errs.add(err)
+
+	burstBlending, err := getEnvBool("BURST_BLENDING", defaultBurstBlending)
 	errs.add(err)
 
 	stableWindow, err := getEnvDuration("STABLE_WINDOW", defaultStableWindow)
 	errs.add(err)
 
+	minWindowFillFraction, err := getEnvFloat("MIN_WINDOW_FILL_FRACTION", defaultMinWindowFillFraction)
+	errs.add(err)
+
+	windowGranularity, err := getEnvDuration("WINDOW_GRANULARITY", defaultWindowGranularity)
+	errs.add(err)
+
 	scaleDownDelay, err := getEnvDuration("SCALE_DOWN_DELAY", defaultScaleDownDelay)
 	errs.add(err)
 
+	scaleDownSoakTicks, err := getEnvInt32("SCALE_DOWN_SOAK_TICKS", defaultScaleDownSoakTicks)
+	errs.add(err)
+
 	minScale, err := getEnvInt32("MIN_SCALE", defaultMinScale)
 	errs.add(err)
 
 	maxScale, err := getEnvInt32("MAX_SCALE", defaultMaxScale)
 	errs.add(err)
 
+	zones, err := getEnvInt32("ZONES", defaultZones)
+	errs.add(err)
+
+	minPodsPerZone, err := getEnvInt32("MIN_PODS_PER_ZONE", defaultMinPodsPerZone)
+	errs.add(err)
+
 	activationScale, err := getEnvInt32("ACTIVATION_SCALE", defaultActivationScale)
 	errs.add(err)
 
+	ignoreActivationScale, err := getEnvBool("IGNORE_ACTIVATION_SCALE_WITH_MIN_SCALE", defaultIgnoreActivationScale)
+	errs.add(err)
+
+	lastPodStickiness, err := getEnvBool("LAST_POD_STICKINESS", defaultLastPodStickiness)
+	errs.add(err)
+
+	activationThreshold, err := getEnvFloat("ACTIVATION_THRESHOLD", defaultActivationThreshold)
+	errs.add(err)
+
+	scaleInvariant, err := getEnvBool("SCALE_INVARIANT", defaultScaleInvariant)
+	errs.add(err)
+
+	provisioningRate, err := getEnvFloat("PROVISIONING_RATE", defaultProvisioningRate)
+	errs.add(err)
+
 	if errs.hasErrors() {
 		return nil, errs
 	}
 
 	cfg := &api.AutoscalerConfig{
+		ScalingMetricType:      scalingMetricType,
+		Unit:                   unit,
 		ScaleToZeroGracePeriod: scaleToZeroGracePeriod,
 		MaxScaleUpRate:         maxScaleUpRate,
 		MaxScaleDownRate:       maxScaleDownRate,
+		MaxScaleDownFraction:   maxScaleDownFraction,
 		TargetValue:            targetValue,
 		TotalTargetValue:       totalTargetValue,
 		BurstThreshold:         burstThreshold,
+		BurstAbsoluteThreshold: burstAbsoluteThreshold,
 		BurstWindowPercentage:  burstWindowPercentage,
+		BurstEntryTicks:        burstEntryTicks,
+		BurstEntryDelay:        burstEntryDelay,
+		BurstBlending:          burstBlending,
 		StableWindow:           stableWindow,
+		MinWindowFillFraction:  minWindowFillFraction,
+		WindowGranularity:      windowGranularity,
 		ScaleDownDelay:         scaleDownDelay,
+		ScaleDownSoakTicks:     scaleDownSoakTicks,
+		ScaleInvariant:         scaleInvariant,
+		ProvisioningRate:       provisioningRate,
 		MinScale:               minScale,
 		MaxScale:               maxScale,
+		Zones:                  zones,
+		MinPodsPerZone:         minPodsPerZone,
 		ActivationScale:        activationScale,
-	}
+		LastPodStickiness:      lastPodStickiness,
+		ActivationThreshold:    activationThreshold,
 
-	// Adjust percentage to fraction if needed
-	if cfg.BurstThreshold > 10.0 {
-		cfg.BurstThreshold /= 100.0
+		IgnoreActivationScaleWithMinScale: ignoreActivationScale,
 	}
 
 	// Validate the configuration
@@ -155,18 +270,35 @@ func Load() (*api.AutoscalerConfig, error) {
 // NewDefaultAutoscalerConfig creates an AutoscalerConfig with all default values.
 func NewDefaultAutoscalerConfig() *api.AutoscalerConfig {
 	cfg := &api.AutoscalerConfig{
+		ScalingMetricType:      defaultScalingMetricType,
 		ScaleToZeroGracePeriod: defaultScaleToZeroGracePeriod,
 		MaxScaleUpRate:         defaultMaxScaleUpRate,
 		MaxScaleDownRate:       defaultMaxScaleDownRate,
+		MaxScaleDownFraction:   defaultMaxScaleDownFraction,
 		TargetValue:            defaultTargetValue,
 		TotalTargetValue:       defaultTotalTargetValue,
 		BurstThreshold:         defaultBurstThresholdPercentage,
+		BurstAbsoluteThreshold: defaultBurstAbsoluteThreshold,
 		BurstWindowPercentage:  defaultBurstWindowPercentage,
+		BurstEntryTicks:        defaultBurstEntryTicks,
+		BurstEntryDelay:        defaultBurstEntryDelay,
+		BurstBlending:          defaultBurstBlending,
 		StableWindow:           defaultStableWindow,
+		MinWindowFillFraction:  defaultMinWindowFillFraction,
+		WindowGranularity:      defaultWindowGranularity,
 		ScaleDownDelay:         defaultScaleDownDelay,
+		ScaleDownSoakTicks:     defaultScaleDownSoakTicks,
+		ScaleInvariant:         defaultScaleInvariant,
+		ProvisioningRate:       defaultProvisioningRate,
 		MinScale:               defaultMinScale,
 		MaxScale:               defaultMaxScale,
+		Zones:                  defaultZones,
+		MinPodsPerZone:         defaultMinPodsPerZone,
 		ActivationScale:        defaultActivationScale,
+		LastPodStickiness:      defaultLastPodStickiness,
+		ActivationThreshold:    defaultActivationThreshold,
+
+		IgnoreActivationScaleWithMinScale: defaultIgnoreActivationScale,
 	}
 
 	// Adjust percentage to fraction if needed
@@ -177,68 +309,209 @@ func NewDefaultAutoscalerConfig() *api.AutoscalerConfig {
 	return cfg
 }
 
+// NewDefaultAutoscalerConfigForMetric creates an AutoscalerConfig with default
+// values adjusted for the given scaling metric type. Only the burst window
+// percentage differs between the types, see defaultBurstWindowPercentageFor.
+func NewDefaultAutoscalerConfigForMetric(metricType api.ScalingMetricType) *api.AutoscalerConfig {
+	cfg := NewDefaultAutoscalerConfig()
+	cfg.ScalingMetricType = metricType
+	cfg.BurstWindowPercentage = defaultBurstWindowPercentageFor(metricType)
+	return cfg
+}
+
+// NewDeviceAutoscalerConfig creates an AutoscalerConfig preset for workloads
+// scaled on accelerator utilization, e.g. GPU utilization reported by DCGM.
+// Accelerator pods take long to start and are expensive to keep idle as well
+// as to churn, so the preset averages over a 5 minute window, delays and soaks
+// scale-downs and releases at most a third of the pods per step. The target is
+// 80% utilization.
+func NewDeviceAutoscalerConfig() *api.AutoscalerConfig {
+	cfg := NewDefaultAutoscalerConfigForMetric(api.ScalingMetricUtilization)
+	cfg.TargetValue = deviceTargetUtilization
+	cfg.StableWindow = deviceStableWindow
+	cfg.ScaleDownDelay = deviceScaleDownDelay
+	cfg.MaxScaleDownRate = deviceMaxScaleDownRate
+	cfg.ScaleDownSoakTicks = deviceScaleDownSoakTicks
+	cfg.ScaleToZeroGracePeriod = deviceScaleToZeroGracePeriod
+	return cfg
+}
+
+// defaultBurstWindowPercentageFor returns the default burst window
+// percentage for the given scaling metric type. It is the only default that
+// depends on the metric type: all types share the default stable window.
+func defaultBurstWindowPercentageFor(metricType api.ScalingMetricType) float64 {
+	if metricType == api.ScalingMetricRPS {
+		return defaultRPSBurstWindowPercentage
+	}
+	return defaultBurstWindowPercentage
+}
+
 // LoadFromMap creates a Config from a map of string values.
+// Maps of an older schema version are migrated first, see Migrate.
 func LoadFromMap(data map[string]string) (*api.AutoscalerConfig, error) {
+	return loadFromMap(data, false)
+}
+
+// LoadFromMapStrict is LoadFromMap, but refuses ambiguous values instead of
+// guessing what they mean. burst-threshold-percentage is always a percentage
+// and must be greater than 100, while LoadFromMap takes values up to 10 as
+// ratios, so 8 means 800% rather than 8%. Unknown keys, which are most likely
+// misspelled, are rejected as well.
+func LoadFromMapStrict(data map[string]string) (*api.AutoscalerConfig, error) {
+	return loadFromMap(data, true)
+}
+
+func loadFromMap(data map[string]string, strict bool) (*api.AutoscalerConfig, error) {
 	errs := &configErrors{}
 
+	version, err := mapVersion(data)
+	if err == nil && version < CurrentVersion {
+		data, err = Migrate(version, data)
+	}
+	if err != nil {
+		errs.addFor(VersionKey, err)
+		return nil, errs
+	}
+
+	if strict {
+		// Keys of older versions are gone after the migration.
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			if !isMapKey(k) {
+				errs.addFor(k, fmt.Errorf("unknown configuration key %q", k))
+			}
+		}
+	}
+
+	scalingMetricType := api.ScalingMetricType(strings.TrimSpace(parseString(data["scaling-metric-type"], string(defaultScalingMetricType))))
+	unit := api.Unit(strings.TrimSpace(parseString(data["unit"], string(api.UnitNone))))
+	burstWindowPercentageDefault := defaultBurstWindowPercentageFor(scalingMetricType)
+
 	scaleToZeroGracePeriod, err := parseDuration(data["scale-to-zero-grace-period"], defaultScaleToZeroGracePeriod)
-	errs.add(err)
+	errs.addFor("scale-to-zero-grace-period", err)
 
 	maxScaleUpRate, err := parseFloat(data["max-scale-up-rate"], defaultMaxScaleUpRate)
-	errs.add(err)
+	errs.addFor("max-scale-up-rate", err)
 
 	maxScaleDownRate, err := parseFloat(data["max-scale-down-rate"], defaultMaxScaleDownRate)
-	errs.add(err)
+	errs.addFor("max-scale-down-rate", err)
 
-	targetValue, err := parseFloat(data["target-value"], defaultTargetValue)
-	errs.add(err)
+	maxScaleDownFraction, err := parseFloat(data["max-scale-down-fraction"], defaultMaxScaleDownFraction)
+	errs.addFor("max-scale-down-fraction", err)
 
-	totalTargetValue, err := parseFloat(data["total-target-value"], defaultTotalTargetValue)
-	errs.add(err)
+	targetValue, err := parseQuantity(data["target-value"], unit, defaultTargetValue)
+	errs.addFor("target-value", err)
+
+	totalTargetValue, err := parseQuantity(data["total-target-value"], unit, defaultTotalTargetValue)
+	errs.addFor("total-target-value", err)
 
 	burstThreshold, err := parseFloat(data["burst-threshold-percentage"], defaultBurstThresholdPercentage)
-	errs.add(err)
+	errs.addFor("burst-threshold-percentage", err)
+	if err == nil {
+		burstThreshold, err = burstThresholdRatio(burstThreshold, strict)
+		errs.addFor("burst-threshold-percentage", err)
+	}
 
-	burstWindowPercentage, err := parseFloat(data["burst-window-percentage"], defaultBurstWindowPercentage)
-	errs.add(err)
+	burstAbsoluteThreshold, err := parseFloat(data["burst-absolute-threshold"], defaultBurstAbsoluteThreshold)
+	errs.addFor("burst-absolute-threshold", err)
+
+	burstWindowPercentage, err := parseFloat(data["burst-window-percentage"], burstWindowPercentageDefault)
+	errs.addFor("burst-window-percentage", err)
+
+	burstEntryTicks, err := parseInt32(data["burst-entry-ticks"], defaultBurstEntryTicks)
+	errs.addFor("burst-entry-ticks", err)
+
+	burstEntryDelay, err := parseDuration(data["burst-entry-delay"], defaultBurstEntryDelay)
+	errs.addFor("burst-entry-delay", err)
+
+	burstBlending, err := parseBool(data["burst-blending"], defaultBurstBlending)
+	errs.addFor("burst-blending", err)
 
 	stableWindow, err := parseDuration(data["stable-window"], defaultStableWindow)
-	errs.add(err)
+	errs.addFor("stable-window", err)
+
+	minWindowFillFraction, err := parseFloat(data["min-window-fill-fraction"], defaultMinWindowFillFraction)
+	errs.addFor("min-window-fill-fraction", err)
+
+	windowGranularity, err := parseDuration(data["window-granularity"], defaultWindowGranularity)
+	errs.addFor("window-granularity", err)
 
 	scaleDownDelay, err := parseDuration(data["scale-down-delay"], defaultScaleDownDelay)
-	errs.add(err)
+	errs.addFor("scale-down-delay", err)
+
+	scaleDownSoakTicks, err := parseInt32(data["scale-down-soak-ticks"], defaultScaleDownSoakTicks)
+	errs.addFor("scale-down-soak-ticks", err)
 
 	minScale, err := parseInt32(data["min-scale"], defaultMinScale)
-	errs.add(err)
+	errs.addFor("min-scale", err)
 
 	maxScale, err := parseInt32(data["max-scale"], defaultMaxScale)
-	errs.add(err)
+	errs.addFor("max-scale", err)
+
+	zones, err := parseInt32(data["zones"], defaultZones)
+	errs.addFor("zones", err)
+
+	minPodsPerZone, err := parseInt32(data["min-pods-per-zone"], defaultMinPodsPerZone)
+	errs.addFor("min-pods-per-zone", err)
 
 	activationScale, err := parseInt32(data["activation-scale"], defaultActivationScale)
-	errs.add(err)
+	errs.addFor("activation-scale", err)
+
+	ignoreActivationScale, err := parseBool(data["ignore-activation-scale-with-min-scale"], defaultIgnoreActivationScale)
+	errs.addFor("ignore-activation-scale-with-min-scale", err)
+
+	lastPodStickiness, err := parseBool(data["last-pod-stickiness"], defaultLastPodStickiness)
+	errs.addFor("last-pod-stickiness", err)
+
+	activationThreshold, err := parseFloat(data["activation-threshold"], defaultActivationThreshold)
+	errs.addFor("activation-threshold", err)
+
+	scaleInvariant, err := parseBool(data["scale-invariant"], defaultScaleInvariant)
+	errs.addFor("scale-invariant", err)
+
+	provisioningRate, err := parseFloat(data["provisioning-rate"], defaultProvisioningRate)
+	errs.addFor("provisioning-rate", err)
 
 	if errs.hasErrors() {
 		return nil, errs
 	}
 
 	cfg := &api.AutoscalerConfig{
+		Version:                version,
+		ScalingMetricType:      scalingMetricType,
+		Unit:                   unit,
 		ScaleToZeroGracePeriod: scaleToZeroGracePeriod,
 		MaxScaleUpRate:         maxScaleUpRate,
 		MaxScaleDownRate:       maxScaleDownRate,
+		MaxScaleDownFraction:   maxScaleDownFraction,
 		TargetValue:            targetValue,
 		TotalTargetValue:       totalTargetValue,
 		BurstThreshold:         burstThreshold,
+		BurstAbsoluteThreshold: burstAbsoluteThreshold,
 		BurstWindowPercentage:  burstWindowPercentage,
+		BurstEntryTicks:        burstEntryTicks,
+		BurstEntryDelay:        burstEntryDelay,
+		BurstBlending:          burstBlending,
 		StableWindow:           stableWindow,
+		MinWindowFillFraction:  minWindowFillFraction,
+		WindowGranularity:      windowGranularity,
 		ScaleDownDelay:         scaleDownDelay,
+		ScaleDownSoakTicks:     scaleDownSoakTicks,
+		ScaleInvariant:         scaleInvariant,
+		ProvisioningRate:       provisioningRate,
 		MinScale:               minScale,
 		MaxScale:               maxScale,
+		Zones:                  zones,
+		MinPodsPerZone:         minPodsPerZone,
 		ActivationScale:        activationScale,
-	}
+		LastPodStickiness:      lastPodStickiness,
+		ActivationThreshold:    activationThreshold,
 
-	// Adjust percentage to fraction if needed
-	if cfg.BurstThreshold > 10.0 {
-		cfg.BurstThreshold /= 100.0
+		IgnoreActivationScaleWithMinScale: ignoreActivationScale,
 	}
 
 	// Validate the configuration
@@ -249,64 +522,218 @@ func LoadFromMap(data map[string]string) (*api.AutoscalerConfig, error) {
 	return cfg, nil
 }
 
+// Target is a scaling target in either the per-pod or the total mode.
+// Exactly one of TargetValue and TotalTargetValue is set.
+type Target struct {
+	MetricType       api.ScalingMetricType
+	TargetValue      float64
+	TotalTargetValue float64
+}
+
+// TargetFor creates a Target for the given metric type. If perPod is true,
+// value is used as the per-pod TargetValue, otherwise as the TotalTargetValue.
+// Request based and utilization metric types only support per-pod targets.
+func TargetFor(metricType api.ScalingMetricType, value float64, perPod bool) (Target, error) {
+	if value < minTargetValue {
+		return Target{}, fmt.Errorf("target value = %v, must be at least %v", value, minTargetValue)
+	}
+	if metricType == "" {
+		metricType = defaultScalingMetricType
+	}
+
+	if perPod {
+		return Target{MetricType: metricType, TargetValue: value}, nil
+	}
+
+	switch metricType {
+	case api.ScalingMetricConcurrency, api.ScalingMetricRPS, api.ScalingMetricUtilization:
+		return Target{}, fmt.Errorf("scaling-metric-type %q only supports per-pod targets", metricType)
+	}
+	return Target{MetricType: metricType, TotalTargetValue: value}, nil
+}
+
+// ApplyTo sets the metric type and target values of the configuration,
+// clearing the target value of the other mode.
+func (t Target) ApplyTo(cfg *api.AutoscalerConfig) {
+	cfg.ScalingMetricType = t.MetricType
+	cfg.TargetValue = t.TargetValue
+	cfg.TotalTargetValue = t.TotalTargetValue
+}
+
+// QueueTarget describes scaling workers on the depth of a queue.
+type QueueTarget struct {
+	// ProcessingRate is the number of items a single worker processes per
+	// second.
+	ProcessingRate float64
+
+	// TargetLatency is the maximum time an item should wait in the queue.
+	// Default is 1s.
+	TargetLatency time.Duration
+}
+
+// Target returns the target for scaling on the total queue depth. Each worker
+// can drain ProcessingRate*TargetLatency items within the target latency, so
+// the desired number of workers is ceil(depth / (rate * latency)).
+func (q QueueTarget) Target() (Target, error) {
+	if q.ProcessingRate <= 0 {
+		return Target{}, fmt.Errorf("queue processing rate = %v, must be positive", q.ProcessingRate)
+	}
+	if q.TargetLatency < 0 {
+		return Target{}, fmt.Errorf("queue target latency = %v, must be at least 0", q.TargetLatency)
+	}
+
+	latency := q.TargetLatency
+	if latency == 0 {
+		latency = time.Second
+	}
+	return TargetFor(api.ScalingMetricValue, q.ProcessingRate*latency.Seconds(), true)
+}
+
+// metricTypeDimensions are the unit dimensions of the scaling metric types
+// with predefined semantics.
+var metricTypeDimensions = map[api.ScalingMetricType]string{
+	api.ScalingMetricConcurrency: api.UnitRequests.Dimension(),
+	api.ScalingMetricRPS:         api.UnitRequestsPerSecond.Dimension(),
+	api.ScalingMetricUtilization: api.UnitPercent.Dimension(),
+}
+
 // Validate ensures all configuration values are valid.
 func Validate(cfg *api.AutoscalerConfig) error {
 	errs := &configErrors{}
 
 	// Validate scale-to-zero grace period
 	if cfg.ScaleToZeroGracePeriod <= 0 {
-		errs.add(fmt.Errorf("scale-to-zero-grace-period must be positive, was: %v", cfg.ScaleToZeroGracePeriod))
+		errs.addFor("scale-to-zero-grace-period", fmt.Errorf("scale-to-zero-grace-period must be positive, was: %v", cfg.ScaleToZeroGracePeriod))
 	}
 
 	// Validate scale-down delay
 	if cfg.ScaleDownDelay < 0 {
-		errs.add(fmt.Errorf("scale-down-delay cannot be negative, was: %v", cfg.ScaleDownDelay))
+		errs.addFor("scale-down-delay", fmt.Errorf("scale-down-delay cannot be negative, was: %v", cfg.ScaleDownDelay))
 	}
 	if cfg.ScaleDownDelay.Round(time.Second) != cfg.ScaleDownDelay {
-		errs.add(fmt.Errorf("scale-down-delay = %v, must be specified with at most second precision", cfg.ScaleDownDelay))
+		errs.addFor("scale-down-delay", fmt.Errorf("scale-down-delay = %v, must be specified with at most second precision", cfg.ScaleDownDelay))
+	}
+
+	// Validate scaling metric type
+	switch cfg.ScalingMetricType {
+	case "", api.ScalingMetricValue:
+	case api.ScalingMetricConcurrency, api.ScalingMetricRPS, api.ScalingMetricUtilization:
+		if cfg.TotalTargetValue > 0 {
+			errs.addFor("total-target-value", fmt.Errorf("total-target-value cannot be used with scaling-metric-type %q, use target-value instead", cfg.ScalingMetricType))
+		}
+	default:
+		errs.addFor("scaling-metric-type", fmt.Errorf("scaling-metric-type = %q, must be one of %q, %q, %q or %q",
+			cfg.ScalingMetricType, api.ScalingMetricConcurrency, api.ScalingMetricRPS, api.ScalingMetricUtilization, api.ScalingMetricValue))
+	}
+
+	// Validate unit
+	if err := cfg.Unit.Validate(); err != nil {
+		errs.addFor("unit", fmt.Errorf("unit: %w", err))
+	} else if dimension, ok := metricTypeDimensions[cfg.ScalingMetricType]; ok && cfg.Unit != api.UnitNone && cfg.Unit.Dimension() != dimension {
+		errs.addFor("unit", fmt.Errorf("unit %q cannot be used with scaling-metric-type %q", cfg.Unit, cfg.ScalingMetricType))
+	}
+
+	// Validate scale-down soak ticks
+	if cfg.ScaleDownSoakTicks < 0 {
+		errs.addFor("scale-down-soak-ticks", fmt.Errorf("scale-down-soak-ticks = %v, must be at least 0", cfg.ScaleDownSoakTicks))
 	}
 
 	// Validate target values
 	if cfg.TargetValue <= 0 && cfg.TotalTargetValue <= 0 {
-		errs.add(fmt.Errorf("either target-value or total-target-value must be positive"))
+		errs.addFor("target-value", fmt.Errorf("either target-value or total-target-value must be positive"))
 	}
 	if cfg.TargetValue > 0 && cfg.TotalTargetValue > 0 {
-		errs.add(fmt.Errorf("cannot specify both target-value (%v) and total-target-value (%v)", cfg.TargetValue, cfg.TotalTargetValue))
+		errs.addFor("total-target-value", fmt.Errorf("cannot specify both target-value (%v) and total-target-value (%v)", cfg.TargetValue, cfg.TotalTargetValue))
 	}
 
 	// Validate scale rates
 	if cfg.MaxScaleUpRate <= 1.0 {
-		errs.add(fmt.Errorf("max-scale-up-rate = %v, must be greater than 1.0", cfg.MaxScaleUpRate))
+		errs.addFor("max-scale-up-rate", fmt.Errorf("max-scale-up-rate = %v, must be greater than 1.0", cfg.MaxScaleUpRate))
 	}
 	if cfg.MaxScaleDownRate <= 1.0 {
-		errs.add(fmt.Errorf("max-scale-down-rate = %v, must be greater than 1.0", cfg.MaxScaleDownRate))
+		errs.addFor("max-scale-down-rate", fmt.Errorf("max-scale-down-rate = %v, must be greater than 1.0", cfg.MaxScaleDownRate))
+	}
+	if cfg.MaxScaleDownFraction < 0 || cfg.MaxScaleDownFraction > 1 {
+		errs.addFor("max-scale-down-fraction", fmt.Errorf("max-scale-down-fraction = %v, must be in [0, 1] interval", cfg.MaxScaleDownFraction))
 	}
 
 	// Validate stable window
 	if cfg.StableWindow < minStableWindow || cfg.StableWindow > maxStableWindow {
-		errs.add(fmt.Errorf("stable-window = %v, must be in [%v; %v] range", cfg.StableWindow, minStableWindow, maxStableWindow))
+		errs.addFor("stable-window", fmt.Errorf("stable-window = %v, must be in [%v; %v] range", cfg.StableWindow, minStableWindow, maxStableWindow))
 	}
 	if cfg.StableWindow.Round(time.Second) != cfg.StableWindow {
-		errs.add(fmt.Errorf("stable-window = %v, must be specified with at most second precision", cfg.StableWindow))
+		errs.addFor("stable-window", fmt.Errorf("stable-window = %v, must be specified with at most second precision", cfg.StableWindow))
+	}
+
+	// Validate minimum window fill
+	if cfg.MinWindowFillFraction < 0 || cfg.MinWindowFillFraction > 1 {
+		errs.addFor("min-window-fill-fraction", fmt.Errorf("min-window-fill-fraction = %v, must be in [0, 1] interval", cfg.MinWindowFillFraction))
+	}
+
+	// Validate window granularity
+	if cfg.WindowGranularity < 0 {
+		errs.addFor("window-granularity", fmt.Errorf("window-granularity cannot be negative, was: %v", cfg.WindowGranularity))
+	} else if effective := Effective(*cfg); effective.WindowGranularity > effective.BurstWindow {
+		errs.addFor("window-granularity", fmt.Errorf("window-granularity = %v, must not exceed the burst window of %v", cfg.WindowGranularity, effective.BurstWindow))
+	} else if cfg.StableWindow%effective.WindowGranularity != 0 {
+		errs.addFor("window-granularity", fmt.Errorf("window-granularity = %v, must divide stable-window = %v", cfg.WindowGranularity, cfg.StableWindow))
 	}
 
 	// Validate burst window percentage
 	if cfg.BurstWindowPercentage < 1.0 || cfg.BurstWindowPercentage > 100.0 {
-		errs.add(fmt.Errorf("burst-window-percentage = %v, must be in [1.0, 100.0] interval", cfg.BurstWindowPercentage))
+		errs.addFor("burst-window-percentage", fmt.Errorf("burst-window-percentage = %v, must be in [1.0, 100.0] interval", cfg.BurstWindowPercentage))
+	}
+
+	// Validate burst absolute threshold
+	if cfg.BurstAbsoluteThreshold < 0 {
+		errs.addFor("burst-absolute-threshold", fmt.Errorf("burst-absolute-threshold = %v, must be at least 0", cfg.BurstAbsoluteThreshold))
+	}
+	if cfg.BurstAbsoluteThreshold > 0 && cfg.ScaleInvariant {
+		errs.addFor("burst-absolute-threshold", fmt.Errorf("burst-absolute-threshold cannot be used with scale-invariant"))
+	}
+
+	// Validate burst entry confirmation
+	if cfg.BurstEntryTicks < 0 {
+		errs.addFor("burst-entry-ticks", fmt.Errorf("burst-entry-ticks = %v, must be at least 0", cfg.BurstEntryTicks))
+	}
+	if cfg.BurstEntryDelay < 0 {
+		errs.addFor("burst-entry-delay", fmt.Errorf("burst-entry-delay cannot be negative, was: %v", cfg.BurstEntryDelay))
+	}
+
+	// Validate provisioning rate
+	if cfg.ProvisioningRate < 0 {
+		errs.addFor("provisioning-rate", fmt.Errorf("provisioning-rate = %v, must be at least 0", cfg.ProvisioningRate))
 	}
 
 	// Validate scale bounds
 	if cfg.MinScale < 0 {
-		errs.add(fmt.Errorf("min-scale = %v, must be at least 0", cfg.MinScale))
+		errs.addFor("min-scale", fmt.Errorf("min-scale = %v, must be at least 0", cfg.MinScale))
 	}
 	if cfg.MaxScale < 0 {
-		errs.add(fmt.Errorf("max-scale = %v, must be at least 0", cfg.MaxScale))
+		errs.addFor("max-scale", fmt.Errorf("max-scale = %v, must be at least 0", cfg.MaxScale))
 	}
 	if cfg.MinScale > cfg.MaxScale && cfg.MaxScale > 0 {
-		errs.add(fmt.Errorf("min-scale (%d) must be less than or equal to max-scale (%d)", cfg.MinScale, cfg.MaxScale))
+		errs.addFor("min-scale", fmt.Errorf("min-scale (%d) must be less than or equal to max-scale (%d)", cfg.MinScale, cfg.MaxScale))
 	}
 	if cfg.ActivationScale < 1 {
-		errs.add(fmt.Errorf("activation-scale = %v, must be at least 1", cfg.ActivationScale))
+		errs.addFor("activation-scale", fmt.Errorf("activation-scale = %v, must be at least 1", cfg.ActivationScale))
+	}
+	if cfg.ActivationThreshold < 0 {
+		errs.addFor("activation-threshold", fmt.Errorf("activation-threshold = %v, must be at least 0", cfg.ActivationThreshold))
+	}
+
+	// Validate zones
+	if cfg.Zones < 0 {
+		errs.addFor("zones", fmt.Errorf("zones = %v, must be at least 0", cfg.Zones))
+	}
+	if cfg.MinPodsPerZone < 0 {
+		errs.addFor("min-pods-per-zone", fmt.Errorf("min-pods-per-zone = %v, must be at least 0", cfg.MinPodsPerZone))
+	}
+	if cfg.MinPodsPerZone > 0 && cfg.Zones == 0 {
+		errs.addFor("min-pods-per-zone", fmt.Errorf("min-pods-per-zone requires zones"))
+	}
+	if cfg.Zones > 0 && cfg.MaxScale > 0 && int64(cfg.MaxScale) < int64(cfg.Zones)*int64(max(cfg.MinPodsPerZone, 1)) {
+		errs.addFor("max-scale", fmt.Errorf("max-scale (%d) must allow for at least %d pods in each of the %d zones", cfg.MaxScale, max(cfg.MinPodsPerZone, 1), cfg.Zones))
 	}
 
 	if errs.hasErrors() {
@@ -316,6 +743,22 @@ func Validate(cfg *api.AutoscalerConfig) error {
 	return nil
 }
 
+// burstThresholdRatio converts the burst threshold percentage to the ratio
+// used by the autoscaler. Values up to 10 are taken as ratios already, unless
+// strict is set.
+func burstThresholdRatio(percentage float64, strict bool) (float64, error) {
+	if strict {
+		if percentage <= 100 {
+			return 0, fmt.Errorf("burst-threshold-percentage = %v, must be greater than 100, e.g. 800 for 8x the ready pods", percentage)
+		}
+		return percentage / 100, nil
+	}
+	if percentage > 10.0 {
+		return percentage / 100, nil
+	}
+	return percentage, nil
+}
+
 // Helper functions for environment variable parsing
 func getEnvString(key, defaultValue string) string {
 	if value := os.Getenv(EnvPrefix + key); value != "" {
@@ -348,6 +791,18 @@ func getEnvInt32(key string, defaultValue int32) (int32, error) {
 	return int32(i), nil
 }
 
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(EnvPrefix + key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue, fmt.Errorf("invalid bool value for %s%s: %q", EnvPrefix, key, value)
+	}
+	return b, nil
+}
+
 func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
 	value := os.Getenv(EnvPrefix + key)
 	if value == "" {
@@ -390,6 +845,17 @@ func parseInt32(value string, defaultValue int32) (int32, error) {
 	return int32(i), nil
 }
 
+func parseBool(value string, defaultValue bool) (bool, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return defaultValue, fmt.Errorf("invalid bool value: %q", value)
+	}
+	return b, nil
+}
+
 func parseDuration(value string, defaultValue time.Duration) (time.Duration, error) {
 	if value == "" {
 		return defaultValue, nil
@@ -101,6 +101,7 @@ func TestLoad(t *testing.T) {
 			name:    "default values when no env vars set",
 			envVars: map[string]string{},
 			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
 				ScaleToZeroGracePeriod: 30 * time.Second,
 				MaxScaleUpRate:         1000.0,
 				MaxScaleDownRate:       2.0,
@@ -129,8 +130,11 @@ func TestLoad(t *testing.T) {
 				"AUTOSCALER_MIN_SCALE":                  "1",
 				"AUTOSCALER_MAX_SCALE":                  "10",
 				"AUTOSCALER_ACTIVATION_SCALE":           "2",
+
+				"AUTOSCALER_IGNORE_ACTIVATION_SCALE_WITH_MIN_SCALE": "true",
 			},
 			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
 				ScaleToZeroGracePeriod: 45 * time.Second,
 				MaxScaleUpRate:         500.5,
 				MaxScaleDownRate:       3.5,
@@ -143,6 +147,8 @@ func TestLoad(t *testing.T) {
 				MinScale:               1,
 				MaxScale:               10,
 				ActivationScale:        2,
+
+				IgnoreActivationScaleWithMinScale: true,
 			},
 		},
 		{
@@ -151,6 +157,7 @@ func TestLoad(t *testing.T) {
 				"AUTOSCALER_BURST_THRESHOLD_PERCENTAGE": "2.5",
 			},
 			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
 				ScaleToZeroGracePeriod: 30 * time.Second,
 				MaxScaleUpRate:         1000.0,
 				MaxScaleDownRate:       2.0,
@@ -172,6 +179,7 @@ func TestLoad(t *testing.T) {
 				"AUTOSCALER_TOTAL_TARGET_VALUE": "2000.0",
 			},
 			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
 				ScaleToZeroGracePeriod: 30 * time.Second,
 				MaxScaleUpRate:         1000.0,
 				MaxScaleDownRate:       2.0,
@@ -186,6 +194,23 @@ func TestLoad(t *testing.T) {
 				ActivationScale:        1,
 			},
 		},
+		{
+			name: "target value as quantity",
+			envVars: map[string]string{
+				"AUTOSCALER_TARGET_VALUE": "1.5k",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            1500.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  10.0,
+				StableWindow:           60 * time.Second,
+				ActivationScale:        1,
+			},
+		},
 		{
 			name: "invalid float value",
 			envVars: map[string]string{
@@ -210,6 +235,14 @@ func TestLoad(t *testing.T) {
 			wantErr: true,
 			errMsg:  "invalid int32 value",
 		},
+		{
+			name: "invalid bool value",
+			envVars: map[string]string{
+				"AUTOSCALER_IGNORE_ACTIVATION_SCALE_WITH_MIN_SCALE": "sometimes",
+			},
+			wantErr: true,
+			errMsg:  "invalid bool value",
+		},
 		{
 			name: "multiple errors",
 			envVars: map[string]string{
@@ -266,6 +299,7 @@ func TestLoadFromMap(t *testing.T) {
 			name: "default values with empty map",
 			data: map[string]string{},
 			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
 				ScaleToZeroGracePeriod: 30 * time.Second,
 				MaxScaleUpRate:         1000.0,
 				MaxScaleDownRate:       2.0,
@@ -294,8 +328,11 @@ func TestLoadFromMap(t *testing.T) {
 				"min-scale":                  "1",
 				"max-scale":                  "10",
 				"activation-scale":           "2",
+
+				"ignore-activation-scale-with-min-scale": "true",
 			},
 			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
 				ScaleToZeroGracePeriod: 45 * time.Second,
 				MaxScaleUpRate:         500.5,
 				MaxScaleDownRate:       3.5,
@@ -308,6 +345,8 @@ func TestLoadFromMap(t *testing.T) {
 				MinScale:               1,
 				MaxScale:               10,
 				ActivationScale:        2,
+
+				IgnoreActivationScaleWithMinScale: true,
 			},
 		},
 		{
@@ -318,6 +357,7 @@ func TestLoadFromMap(t *testing.T) {
 				"stable-window":     " 30s ",
 			},
 			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
 				ScaleToZeroGracePeriod: 30 * time.Second,
 				MaxScaleUpRate:         500.5,
 				MaxScaleDownRate:       2.0,
@@ -339,6 +379,7 @@ func TestLoadFromMap(t *testing.T) {
 				"total-target-value": "1500.0",
 			},
 			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
 				ScaleToZeroGracePeriod: 30 * time.Second,
 				MaxScaleUpRate:         1000.0,
 				MaxScaleDownRate:       2.0,
@@ -354,12 +395,514 @@ func TestLoadFromMap(t *testing.T) {
 			},
 		},
 		{
-			name: "invalid float",
+			name: "burst absolute threshold from map",
+			data: map[string]string{
+				"burst-absolute-threshold": "500",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            100.0,
+				TotalTargetValue:       0.0,
+				BurstThreshold:         2.0,
+				BurstAbsoluteThreshold: 500.0,
+				BurstWindowPercentage:  10.0,
+				StableWindow:           60 * time.Second,
+				ScaleDownDelay:         0 * time.Second,
+				MinScale:               0,
+				MaxScale:               0,
+				ActivationScale:        1,
+			},
+		},
+		{
+			name: "scale-down soak ticks from map",
+			data: map[string]string{
+				"scale-down-soak-ticks": "3",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            100.0,
+				TotalTargetValue:       0.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  10.0,
+				StableWindow:           60 * time.Second,
+				ScaleDownDelay:         0 * time.Second,
+				ScaleDownSoakTicks:     3,
+				MinScale:               0,
+				MaxScale:               0,
+				ActivationScale:        1,
+			},
+		},
+		{
+			name: "minimum window fill fraction from map",
+			data: map[string]string{
+				"min-window-fill-fraction": "0.5",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            100.0,
+				TotalTargetValue:       0.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  10.0,
+				StableWindow:           60 * time.Second,
+				MinWindowFillFraction:  0.5,
+				ScaleDownDelay:         0 * time.Second,
+				MinScale:               0,
+				MaxScale:               0,
+				ActivationScale:        1,
+			},
+		},
+		{
+			name: "minimum window fill fraction above 1",
+			data: map[string]string{
+				"min-window-fill-fraction": "1.5",
+			},
+			wantErr: true,
+			errMsg:  "min-window-fill-fraction = 1.5, must be in [0, 1] interval",
+		},
+		{
+			name: "window granularity from map",
+			data: map[string]string{
+				"window-granularity": "5s",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            100.0,
+				TotalTargetValue:       0.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  10.0,
+				StableWindow:           60 * time.Second,
+				WindowGranularity:      5 * time.Second,
+				ScaleDownDelay:         0 * time.Second,
+				MinScale:               0,
+				MaxScale:               0,
+				ActivationScale:        1,
+			},
+		},
+		{
+			name: "window granularity exceeding the burst window",
+			data: map[string]string{
+				"window-granularity": "10s",
+			},
+			wantErr: true,
+			errMsg:  "window-granularity = 10s, must not exceed the burst window of 6s",
+		},
+		{
+			name: "window granularity not dividing the stable window",
+			data: map[string]string{
+				"window-granularity":      "7s",
+				"burst-window-percentage": "50",
+			},
+			wantErr: true,
+			errMsg:  "window-granularity = 7s, must divide stable-window = 1m0s",
+		},
+		{
+			name: "burst entry confirmation from map",
+			data: map[string]string{
+				"burst-entry-ticks": "3",
+				"burst-entry-delay": "5s",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            100.0,
+				TotalTargetValue:       0.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  10.0,
+				BurstEntryTicks:        3,
+				BurstEntryDelay:        5 * time.Second,
+				StableWindow:           60 * time.Second,
+				ScaleDownDelay:         0 * time.Second,
+				MinScale:               0,
+				MaxScale:               0,
+				ActivationScale:        1,
+			},
+		},
+		{
+			name: "burst blending from map",
+			data: map[string]string{
+				"burst-blending": "true",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            100.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  10.0,
+				BurstBlending:          true,
+				StableWindow:           60 * time.Second,
+				ActivationScale:        1,
+			},
+		},
+		{
+			name: "scale-to-zero dead band from map",
+			data: map[string]string{
+				"last-pod-stickiness":  "true",
+				"activation-threshold": "5",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            100.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  10.0,
+				StableWindow:           60 * time.Second,
+				ActivationScale:        1,
+				LastPodStickiness:      true,
+				ActivationThreshold:    5,
+			},
+		},
+		{
+			name: "negative activation threshold",
+			data: map[string]string{
+				"activation-threshold": "-1",
+			},
+			wantErr: true,
+			errMsg:  "activation-threshold = -1, must be at least 0",
+		},
+		{
+			name: "negative burst entry ticks",
+			data: map[string]string{
+				"burst-entry-ticks": "-1",
+			},
+			wantErr: true,
+			errMsg:  "burst-entry-ticks = -1, must be at least 0",
+		},
+		{
+			name: "negative burst entry delay",
+			data: map[string]string{
+				"burst-entry-delay": "-1s",
+			},
+			wantErr: true,
+			errMsg:  "burst-entry-delay cannot be negative, was: -1s",
+		},
+		{
+			name: "scale invariant from map",
+			data: map[string]string{
+				"scale-invariant": "true",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            100.0,
+				TotalTargetValue:       0.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  10.0,
+				StableWindow:           60 * time.Second,
+				ScaleDownDelay:         0 * time.Second,
+				ScaleInvariant:         true,
+				MinScale:               0,
+				MaxScale:               0,
+				ActivationScale:        1,
+			},
+		},
+		{
+			name: "scale invariant with burst absolute threshold",
+			data: map[string]string{
+				"scale-invariant":          "true",
+				"burst-absolute-threshold": "500",
+			},
+			wantErr: true,
+			errMsg:  "burst-absolute-threshold cannot be used with scale-invariant",
+		},
+		{
+			name: "max scale down fraction from map",
+			data: map[string]string{
+				"max-scale-down-fraction": "0.25",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				MaxScaleDownFraction:   0.25,
+				TargetValue:            100.0,
+				TotalTargetValue:       0.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  10.0,
+				StableWindow:           60 * time.Second,
+				ScaleDownDelay:         0 * time.Second,
+				MinScale:               0,
+				MaxScale:               0,
+				ActivationScale:        1,
+			},
+		},
+		{
+			name: "max scale down fraction above 1",
+			data: map[string]string{
+				"max-scale-down-fraction": "1.5",
+			},
+			wantErr: true,
+			errMsg:  "max-scale-down-fraction = 1.5, must be in [0, 1] interval",
+		},
+		{
+			name: "provisioning rate from map",
+			data: map[string]string{
+				"provisioning-rate": "30",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            100.0,
+				TotalTargetValue:       0.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  10.0,
+				StableWindow:           60 * time.Second,
+				ScaleDownDelay:         0 * time.Second,
+				ProvisioningRate:       30,
+				MinScale:               0,
+				MaxScale:               0,
+				ActivationScale:        1,
+			},
+		},
+		{
+			name: "negative provisioning rate",
+			data: map[string]string{
+				"provisioning-rate": "-1",
+			},
+			wantErr: true,
+			errMsg:  "provisioning-rate = -1, must be at least 0",
+		},
+		{
+			name: "zones from map",
+			data: map[string]string{
+				"zones":             "3",
+				"min-pods-per-zone": "2",
+				"max-scale":         "30",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            100.0,
+				TotalTargetValue:       0.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  10.0,
+				StableWindow:           60 * time.Second,
+				ScaleDownDelay:         0 * time.Second,
+				MinScale:               0,
+				MaxScale:               30,
+				Zones:                  3,
+				MinPodsPerZone:         2,
+				ActivationScale:        1,
+			},
+		},
+		{
+			name: "negative zones",
+			data: map[string]string{
+				"zones": "-1",
+			},
+			wantErr: true,
+			errMsg:  "zones = -1, must be at least 0",
+		},
+		{
+			name: "min pods per zone without zones",
+			data: map[string]string{
+				"min-pods-per-zone": "2",
+			},
+			wantErr: true,
+			errMsg:  "min-pods-per-zone requires zones",
+		},
+		{
+			name: "max scale below the zone minimums",
+			data: map[string]string{
+				"zones":             "3",
+				"min-pods-per-zone": "2",
+				"max-scale":         "5",
+			},
+			wantErr: true,
+			errMsg:  "max-scale (5) must allow for at least 2 pods in each of the 3 zones",
+		},
+		{
+			name: "negative scale-down soak ticks",
+			data: map[string]string{
+				"scale-down-soak-ticks": "-1",
+			},
+			wantErr: true,
+			errMsg:  "scale-down-soak-ticks = -1, must be at least 0",
+		},
+		{
+			name: "rps scaling metric type adjusts default windows",
+			data: map[string]string{
+				"scaling-metric-type": "rps",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricRPS,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            100.0,
+				TotalTargetValue:       0.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  20.0,
+				StableWindow:           60 * time.Second,
+				ScaleDownDelay:         0 * time.Second,
+				MinScale:               0,
+				MaxScale:               0,
+				ActivationScale:        1,
+			},
+		},
+		{
+			name: "explicit burst window overrides scaling metric type default",
+			data: map[string]string{
+				"scaling-metric-type":     "rps",
+				"burst-window-percentage": "5",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricRPS,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            100.0,
+				TotalTargetValue:       0.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  5.0,
+				StableWindow:           60 * time.Second,
+				ScaleDownDelay:         0 * time.Second,
+				MinScale:               0,
+				MaxScale:               0,
+				ActivationScale:        1,
+			},
+		},
+		{
+			name: "concurrency scaling metric type rejects total target value",
+			data: map[string]string{
+				"scaling-metric-type": "concurrency",
+				"target-value":        "0",
+				"total-target-value":  "1000",
+			},
+			wantErr: true,
+			errMsg:  `total-target-value cannot be used with scaling-metric-type "concurrency"`,
+		},
+		{
+			name: "utilization scaling metric type rejects total target value",
+			data: map[string]string{
+				"scaling-metric-type": "utilization",
+				"target-value":        "0",
+				"total-target-value":  "70",
+			},
+			wantErr: true,
+			errMsg:  `total-target-value cannot be used with scaling-metric-type "utilization"`,
+		},
+		{
+			name: "invalid bool value",
+			data: map[string]string{
+				"ignore-activation-scale-with-min-scale": "sometimes",
+			},
+			wantErr: true,
+			errMsg:  "invalid bool value",
+		},
+		{
+			name: "unknown scaling metric type",
+			data: map[string]string{
+				"scaling-metric-type": "latency",
+			},
+			wantErr: true,
+			errMsg:  `scaling-metric-type = "latency"`,
+		},
+		{
+			name: "unit",
+			data: map[string]string{
+				"unit": "millicores",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				Unit:                   api.UnitMillicores,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            100.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  10.0,
+				StableWindow:           60 * time.Second,
+				ActivationScale:        1,
+			},
+		},
+		{
+			name: "unknown unit",
+			data: map[string]string{
+				"unit": "furlongs",
+			},
+			wantErr: true,
+			errMsg:  `unit: unknown unit "furlongs"`,
+		},
+		{
+			name: "unit of another dimension than the scaling metric type",
+			data: map[string]string{
+				"scaling-metric-type": "rps",
+				"unit":                "bytes",
+			},
+			wantErr: true,
+			errMsg:  `unit "bytes" cannot be used with scaling-metric-type "rps"`,
+		},
+		{
+			name: "invalid quantity",
 			data: map[string]string{
 				"target-value": "abc",
 			},
 			wantErr: true,
-			errMsg:  "invalid float value",
+			errMsg:  "invalid quantity value",
+		},
+		{
+			name: "target value as quantity",
+			data: map[string]string{
+				"unit":         "millicores",
+				"target-value": "500m",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				Unit:                   api.UnitMillicores,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            500.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  10.0,
+				StableWindow:           60 * time.Second,
+				ActivationScale:        1,
+			},
+		},
+		{
+			name: "total target value as quantity",
+			data: map[string]string{
+				"unit":               "MiB",
+				"target-value":       "0",
+				"total-target-value": "1.5Gi",
+			},
+			want: &api.AutoscalerConfig{
+				ScalingMetricType:      api.ScalingMetricValue,
+				Unit:                   api.UnitMiB,
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         1000.0,
+				MaxScaleDownRate:       2.0,
+				TotalTargetValue:       1536.0,
+				BurstThreshold:         2.0,
+				BurstWindowPercentage:  10.0,
+				StableWindow:           60 * time.Second,
+				ActivationScale:        1,
+			},
 		},
 		{
 			name: "invalid duration",
@@ -663,6 +1206,21 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: false, // This should be valid as max scale 0 means unlimited
 		},
+		{
+			name: "negative burst absolute threshold",
+			config: &api.AutoscalerConfig{
+				ScaleToZeroGracePeriod: 30 * time.Second,
+				MaxScaleUpRate:         2.0,
+				MaxScaleDownRate:       2.0,
+				TargetValue:            1.0,
+				StableWindow:           60 * time.Second,
+				BurstWindowPercentage:  10.0,
+				BurstAbsoluteThreshold: -1.0,
+				ActivationScale:        1,
+			},
+			wantErr: true,
+			errMsg:  "burst-absolute-threshold = -1, must be at least 0",
+		},
 		{
 			name: "activation scale less than 1",
 			config: &api.AutoscalerConfig{
@@ -730,6 +1288,39 @@ func TestHelperFunctions(t *testing.T) {
 		}
 	})
 
+	// Test ParseQuantity
+	t.Run("ParseQuantity", func(t *testing.T) {
+		tests := []struct {
+			in      string
+			want    float64
+			wantErr bool
+		}{
+			{in: "100", want: 100},
+			{in: " 2.5 ", want: 2.5},
+			{in: "1e3", want: 1000},
+			{in: "500m", want: 0.5},
+			{in: "2k", want: 2000},
+			{in: "1E", want: 1e18},
+			{in: "1Ki", want: 1024},
+			{in: "1.5Gi", want: 1.5 * (1 << 30)},
+			{in: "", wantErr: true},
+			{in: "m", wantErr: true},
+			{in: "1.5Gb", wantErr: true},
+			{in: "Infk", wantErr: true},
+		}
+
+		for _, tt := range tests {
+			got, err := ParseQuantity(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseQuantity(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+				continue
+			}
+			if got != tt.want {
+				t.Errorf("ParseQuantity(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		}
+	})
+
 	// Test parseString
 	t.Run("parseString", func(t *testing.T) {
 		if got := parseString("test", "default"); got != "test" {
@@ -760,16 +1351,262 @@ func configsEqual(a, b *api.AutoscalerConfig) bool {
 		return a == b
 	}
 
-	return a.ScaleToZeroGracePeriod == b.ScaleToZeroGracePeriod &&
+	return a.ScalingMetricType == b.ScalingMetricType &&
+		a.Unit == b.Unit &&
+		a.ScaleToZeroGracePeriod == b.ScaleToZeroGracePeriod &&
 		a.MaxScaleUpRate == b.MaxScaleUpRate &&
 		a.MaxScaleDownRate == b.MaxScaleDownRate &&
+		a.MaxScaleDownFraction == b.MaxScaleDownFraction &&
 		a.TargetValue == b.TargetValue &&
 		a.TotalTargetValue == b.TotalTargetValue &&
 		a.BurstThreshold == b.BurstThreshold &&
+		a.BurstAbsoluteThreshold == b.BurstAbsoluteThreshold &&
 		a.BurstWindowPercentage == b.BurstWindowPercentage &&
+		a.BurstEntryTicks == b.BurstEntryTicks &&
+		a.BurstEntryDelay == b.BurstEntryDelay &&
+		a.BurstBlending == b.BurstBlending &&
 		a.StableWindow == b.StableWindow &&
+		a.MinWindowFillFraction == b.MinWindowFillFraction &&
+		a.WindowGranularity == b.WindowGranularity &&
 		a.ScaleDownDelay == b.ScaleDownDelay &&
+		a.ScaleDownSoakTicks == b.ScaleDownSoakTicks &&
+		a.ScaleInvariant == b.ScaleInvariant &&
+		a.ProvisioningRate == b.ProvisioningRate &&
 		a.MinScale == b.MinScale &&
 		a.MaxScale == b.MaxScale &&
-		a.ActivationScale == b.ActivationScale
+		a.Zones == b.Zones &&
+		a.MinPodsPerZone == b.MinPodsPerZone &&
+		a.ActivationScale == b.ActivationScale &&
+		a.IgnoreActivationScaleWithMinScale == b.IgnoreActivationScaleWithMinScale &&
+		a.LastPodStickiness == b.LastPodStickiness &&
+		a.ActivationThreshold == b.ActivationThreshold
+}
+
+func TestTargetFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		metricType api.ScalingMetricType
+		value      float64
+		perPod     bool
+		want       Target
+		wantErr    bool
+	}{
+		{
+			name:       "per-pod value",
+			metricType: api.ScalingMetricValue,
+			value:      100,
+			perPod:     true,
+			want:       Target{MetricType: api.ScalingMetricValue, TargetValue: 100},
+		},
+		{
+			name:       "total value",
+			metricType: api.ScalingMetricValue,
+			value:      1000,
+			perPod:     false,
+			want:       Target{MetricType: api.ScalingMetricValue, TotalTargetValue: 1000},
+		},
+		{
+			name:   "empty metric type defaults to value",
+			value:  1000,
+			perPod: false,
+			want:   Target{MetricType: api.ScalingMetricValue, TotalTargetValue: 1000},
+		},
+		{
+			name:       "per-pod concurrency",
+			metricType: api.ScalingMetricConcurrency,
+			value:      10,
+			perPod:     true,
+			want:       Target{MetricType: api.ScalingMetricConcurrency, TargetValue: 10},
+		},
+		{
+			name:       "total concurrency is rejected",
+			metricType: api.ScalingMetricConcurrency,
+			value:      10,
+			perPod:     false,
+			wantErr:    true,
+		},
+		{
+			name:       "non-positive value is rejected",
+			metricType: api.ScalingMetricValue,
+			value:      0,
+			perPod:     true,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TargetFor(tt.metricType, tt.value, tt.perPod)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TargetFor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("TargetFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetApplyTo(t *testing.T) {
+	cfg := NewDefaultAutoscalerConfig()
+
+	target, err := TargetFor(api.ScalingMetricValue, 500, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	target.ApplyTo(cfg)
+
+	if cfg.TargetValue != 0 || cfg.TotalTargetValue != 500 {
+		t.Errorf("expected TargetValue 0 and TotalTargetValue 500, got %v and %v", cfg.TargetValue, cfg.TotalTargetValue)
+	}
+	if err := Validate(cfg); err != nil {
+		t.Errorf("unexpected validation error: %v", err)
+	}
+}
+
+func TestLoadFromMapStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    float64
+		wantErr string
+	}{
+		{name: "percentage", data: map[string]string{"burst-threshold-percentage": "800"}, want: 8},
+		{name: "default", data: map[string]string{}, want: 2},
+		{name: "ambiguous threshold", data: map[string]string{"burst-threshold-percentage": "8"}, wantErr: "must be greater than 100"},
+		{name: "unknown key", data: map[string]string{"stable-windw": "30s"}, wantErr: `unknown configuration key "stable-windw"`},
+		{name: "migrated keys", data: map[string]string{"version": "1", "panic-threshold-percentage": "300"}, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadFromMapStrict(tt.data)
+			if tt.wantErr != "" {
+				if err == nil || !containsString(err.Error(), tt.wantErr) {
+					t.Errorf("LoadFromMapStrict() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFromMapStrict() unexpected error = %v", err)
+			}
+			if got.BurstThreshold != tt.want {
+				t.Errorf("BurstThreshold = %v, want %v", got.BurstThreshold, tt.want)
+			}
+		})
+	}
+
+	// LoadFromMap guesses that small values are ratios.
+	cfg, err := LoadFromMap(map[string]string{"burst-threshold-percentage": "8"})
+	if err != nil || cfg.BurstThreshold != 8 {
+		t.Errorf("LoadFromMap() = %v, %v, want a burst threshold of 8", cfg, err)
+	}
+}
+
+func TestLoadStrict(t *testing.T) {
+	t.Setenv("AUTOSCALER_BURST_THRESHOLD_PERCENTAGE", "8")
+	if _, err := LoadStrict(); err == nil {
+		t.Error("LoadStrict() accepted an ambiguous burst threshold")
+	}
+
+	t.Setenv("AUTOSCALER_BURST_THRESHOLD_PERCENTAGE", "800")
+	cfg, err := LoadStrict()
+	if err != nil {
+		t.Fatalf("LoadStrict() unexpected error = %v", err)
+	}
+	if cfg.BurstThreshold != 8 {
+		t.Errorf("BurstThreshold = %v, want 8", cfg.BurstThreshold)
+	}
+}
+
+func TestEffective(t *testing.T) {
+	cfg := *NewDefaultAutoscalerConfig()
+	cfg.ScalingMetricType = ""
+	cfg.StableWindow = 5 * time.Second
+
+	got := Effective(cfg)
+	if got.ScalingMetricType != api.ScalingMetricValue {
+		t.Errorf("ScalingMetricType = %q, want %q", got.ScalingMetricType, api.ScalingMetricValue)
+	}
+	if got.BurstThresholdPercentage != 200 {
+		t.Errorf("BurstThresholdPercentage = %v, want 200", got.BurstThresholdPercentage)
+	}
+	// 10% of 5s is below the minimum burst window.
+	if got.BurstWindow != time.Second {
+		t.Errorf("BurstWindow = %v, want 1s", got.BurstWindow)
+	}
+	if got.WindowGranularity != time.Second {
+		t.Errorf("WindowGranularity = %v, want 1s", got.WindowGranularity)
+	}
+}
+
+func TestQueueTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		queue   QueueTarget
+		want    Target
+		wantErr bool
+	}{
+		{
+			name:  "default latency",
+			queue: QueueTarget{ProcessingRate: 50},
+			want:  Target{MetricType: api.ScalingMetricValue, TargetValue: 50},
+		},
+		{
+			name:  "with latency",
+			queue: QueueTarget{ProcessingRate: 20, TargetLatency: 30 * time.Second},
+			want:  Target{MetricType: api.ScalingMetricValue, TargetValue: 600},
+		},
+		{
+			name:    "zero rate",
+			queue:   QueueTarget{},
+			wantErr: true,
+		},
+		{
+			name:    "negative latency",
+			queue:   QueueTarget{ProcessingRate: 1, TargetLatency: -time.Second},
+			wantErr: true,
+		},
+		{
+			name:    "target below minimum",
+			queue:   QueueTarget{ProcessingRate: 0.001, TargetLatency: time.Second},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.queue.Target()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Target() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Target() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDeviceAutoscalerConfig(t *testing.T) {
+	cfg := NewDeviceAutoscalerConfig()
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("device preset is invalid: %v", err)
+	}
+
+	defaults := NewDefaultAutoscalerConfig()
+	if cfg.ScalingMetricType != api.ScalingMetricUtilization {
+		t.Errorf("ScalingMetricType = %q, want %q", cfg.ScalingMetricType, api.ScalingMetricUtilization)
+	}
+	if cfg.StableWindow <= defaults.StableWindow {
+		t.Errorf("StableWindow = %v, want longer than the default %v", cfg.StableWindow, defaults.StableWindow)
+	}
+	if cfg.ScaleDownDelay <= defaults.ScaleDownDelay {
+		t.Errorf("ScaleDownDelay = %v, want longer than the default %v", cfg.ScaleDownDelay, defaults.ScaleDownDelay)
+	}
+	if cfg.MaxScaleDownRate >= defaults.MaxScaleDownRate {
+		t.Errorf("MaxScaleDownRate = %v, want slower than the default %v", cfg.MaxScaleDownRate, defaults.MaxScaleDownRate)
+	}
+	if cfg.ScaleDownSoakTicks == 0 {
+		t.Error("ScaleDownSoakTicks = 0, want scale-down soak enabled")
+	}
 }
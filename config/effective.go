@@ -0,0 +1,55 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// EffectiveConfig is a configuration as the autoscaler interprets it.
+type EffectiveConfig struct {
+	// AutoscalerConfig is the configuration with defaults applied.
+	// BurstThreshold is the ratio of desired to ready pods that enters burst
+	// mode, e.g. 2.0 for 200%.
+	api.AutoscalerConfig
+
+	// BurstThresholdPercentage is BurstThreshold as a percentage.
+	BurstThresholdPercentage float64
+
+	// BurstWindow is the duration of the burst window, a percentage of the
+	// stable window but at least a second.
+	BurstWindow time.Duration
+}
+
+// Effective returns how the autoscaler interprets cfg. Unlike the loaders
+// the autoscaler doesn't guess whether BurstThreshold is a percentage, so
+// Effective shows what a configuration built in code actually means.
+func Effective(cfg api.AutoscalerConfig) EffectiveConfig {
+	if cfg.ScalingMetricType == "" {
+		cfg.ScalingMetricType = defaultScalingMetricType
+	}
+	if cfg.WindowGranularity == 0 {
+		cfg.WindowGranularity = time.Second
+	}
+	return EffectiveConfig{
+		AutoscalerConfig:         cfg,
+		BurstThresholdPercentage: cfg.BurstThreshold * 100,
+		BurstWindow:              max(time.Second, time.Duration(float64(cfg.StableWindow)*cfg.BurstWindowPercentage/100)),
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// keyError is an error caused by the value of a configuration map key.
+type keyError struct {
+	key string
+	err error
+}
+
+func (e *keyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *keyError) Unwrap() error {
+	return e.err
+}
+
+// ErrorType is the kind of a FieldError. The values match the field error
+// types of Kubernetes, so they can be returned as status causes of admission
+// responses unchanged.
+type ErrorType string
+
+const (
+	// ErrorTypeInvalid means the value of a field is invalid.
+	ErrorTypeInvalid ErrorType = "FieldValueInvalid"
+
+	// ErrorTypeNotSupported means the field is not supported.
+	ErrorTypeNotSupported ErrorType = "FieldValueNotSupported"
+)
+
+// FieldError is a validation error of a single field.
+type FieldError struct {
+	// Type is the kind of the error.
+	Type ErrorType
+
+	// Field is the path of the field, e.g. "stable-window" or
+	// "metadata.annotations[stable-window]".
+	Field string
+
+	// BadValue is the value of the field.
+	BadValue string
+
+	// Detail describes the error.
+	Detail string
+}
+
+// Error implements the error interface in the format of Kubernetes field
+// errors.
+func (e *FieldError) Error() string {
+	switch e.Type {
+	case ErrorTypeNotSupported:
+		return fmt.Sprintf("%s: Unsupported value: %q: %s", e.Field, e.BadValue, e.Detail)
+	default:
+		return fmt.Sprintf("%s: Invalid value: %q: %s", e.Field, e.BadValue, e.Detail)
+	}
+}
+
+// ErrorList is a list of field errors.
+type ErrorList []*FieldError
+
+// Prefix returns a copy of the list with the fields nested under path, e.g.
+// "metadata.annotations" turns "stable-window" into
+// "metadata.annotations[stable-window]".
+func (l ErrorList) Prefix(path string) ErrorList {
+	if path == "" {
+		return l
+	}
+	prefixed := make(ErrorList, len(l))
+	for i, e := range l {
+		c := *e
+		c.Field = fmt.Sprintf("%s[%s]", path, e.Field)
+		prefixed[i] = &c
+	}
+	return prefixed
+}
+
+// ToAggregate returns the errors as a single error, or nil if the list is
+// empty.
+func (l ErrorList) ToAggregate() error {
+	if len(l) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return errors.New("[" + strings.Join(msgs, ", ") + "]")
+}
+
+// ValidateMap validates a configuration map the way LoadFromMap does and
+// reports every problem as a FieldError with the key as the field, e.g. for
+// Kubernetes admission webhooks validating annotations. Unlike LoadFromMap it
+// also rejects unknown keys, which are most likely misspelled.
+func ValidateMap(data map[string]string) ErrorList {
+	var list ErrorList
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	version, _ := mapVersion(data)
+	for _, k := range keys {
+		if !isMapKey(k) && !(version < CurrentVersion && slices.Contains(v1Keys, k)) {
+			list = append(list, &FieldError{
+				Type:     ErrorTypeNotSupported,
+				Field:    k,
+				BadValue: data[k],
+				Detail:   "unknown configuration key",
+			})
+		}
+	}
+
+	_, err := LoadFromMap(data)
+	if err == nil {
+		return list
+	}
+	var errs *configErrors
+	if !errors.As(err, &errs) {
+		return append(list, &FieldError{Type: ErrorTypeInvalid, Detail: err.Error()})
+	}
+	for _, err := range errs.errors {
+		fe := &FieldError{Type: ErrorTypeInvalid, Detail: err.Error()}
+		var ke *keyError
+		if errors.As(err, &ke) {
+			fe.Field = ke.key
+			fe.BadValue = data[ke.key]
+		}
+		list = append(list, fe)
+	}
+	return list
+}
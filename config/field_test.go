@@ -0,0 +1,92 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateMap(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]string
+		want []FieldError
+	}{
+		{
+			name: "valid",
+			data: map[string]string{"target-value": "50", "stable-window": "30s"},
+		},
+		{
+			name: "parse error",
+			data: map[string]string{"max-scale": "ten"},
+			want: []FieldError{
+				{Type: ErrorTypeInvalid, Field: "max-scale", BadValue: "ten", Detail: `invalid int32 value: "ten"`},
+			},
+		},
+		{
+			name: "validation errors",
+			data: map[string]string{"stable-window": "1s", "min-scale": "5", "max-scale": "2"},
+			want: []FieldError{
+				{Type: ErrorTypeInvalid, Field: "stable-window", BadValue: "1s", Detail: "stable-window = 1s, must be in [5s; 10m0s] range"},
+				{Type: ErrorTypeInvalid, Field: "min-scale", BadValue: "5", Detail: "min-scale (5) must be less than or equal to max-scale (2)"},
+			},
+		},
+		{
+			name: "unknown key",
+			data: map[string]string{"target-valu": "50"},
+			want: []FieldError{
+				{Type: ErrorTypeNotSupported, Field: "target-valu", BadValue: "50", Detail: "unknown configuration key"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateMap(tt.data)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ValidateMap() = %v, want %d errors", got.ToAggregate(), len(tt.want))
+			}
+			for i := range got {
+				if *got[i] != tt.want[i] {
+					t.Errorf("error %d = %+v, want %+v", i, *got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestErrorList(t *testing.T) {
+	var empty ErrorList
+	if err := empty.ToAggregate(); err != nil {
+		t.Errorf("ToAggregate() of empty list = %v, want nil", err)
+	}
+
+	list := ValidateMap(map[string]string{"stable-window": "1s", "foo": "bar"}).Prefix("metadata.annotations")
+	err := list.ToAggregate()
+	if err == nil {
+		t.Fatal("ToAggregate() = nil, want error")
+	}
+	for _, want := range []string{
+		`metadata.annotations[foo]: Unsupported value: "bar": unknown configuration key`,
+		`metadata.annotations[stable-window]: Invalid value: "1s": stable-window = 1s`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ToAggregate() = %v, want it to contain %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CurrentVersion is the version of the configuration map schema
+	// understood by LoadFromMap. Maps without a "version" key are assumed to
+	// be of the current version.
+	CurrentVersion = 2
+
+	// VersionKey is the configuration map key holding the schema version.
+	VersionKey = "version"
+)
+
+// migrations upgrade configuration maps by one version. migrations[v-1]
+// upgrades from version v to v+1.
+var migrations = []func(data map[string]string) error{
+	migrateV1,
+}
+
+// Migrate upgrades a configuration map stored with oldVersion of the schema
+// to CurrentVersion, renaming keys and converting values whose meaning
+// changed. The input map is not modified. The returned map has VersionKey
+// set to CurrentVersion.
+func Migrate(oldVersion int, data map[string]string) (map[string]string, error) {
+	if oldVersion < 1 || oldVersion > CurrentVersion {
+		return nil, fmt.Errorf("version = %d, must be in [1; %d] range", oldVersion, CurrentVersion)
+	}
+
+	migrated := maps.Clone(data)
+	if migrated == nil {
+		migrated = map[string]string{}
+	}
+	for v := oldVersion; v < CurrentVersion; v++ {
+		if err := migrations[v-1](migrated); err != nil {
+			return nil, fmt.Errorf("migrating from version %d: %w", v, err)
+		}
+	}
+	migrated[VersionKey] = strconv.Itoa(CurrentVersion)
+	return migrated, nil
+}
+
+// v1Keys are the keys of version 1 that were renamed or replaced.
+var v1Keys = []string{"panic-window-percentage", "panic-threshold-percentage", "panic-window"}
+
+// migrateV1 upgrades from version 1, which used the Knative names of the
+// burst mode settings. Version 1 also allowed the panic window to be set as
+// a duration, which is now a percentage of the stable window.
+func migrateV1(data map[string]string) error {
+	renames := []struct{ from, to string }{
+		{from: "panic-window-percentage", to: "burst-window-percentage"},
+		{from: "panic-threshold-percentage", to: "burst-threshold-percentage"},
+	}
+	for _, r := range renames {
+		if err := renameKey(data, r.from, r.to); err != nil {
+			return err
+		}
+	}
+
+	panicWindow, ok := data["panic-window"]
+	if !ok {
+		return nil
+	}
+	delete(data, "panic-window")
+	if _, ok := data["burst-window-percentage"]; ok {
+		return fmt.Errorf("cannot specify both panic-window and burst-window-percentage")
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(panicWindow))
+	if err != nil {
+		return fmt.Errorf("invalid duration value for panic-window: %q", panicWindow)
+	}
+	stableWindow, err := parseDuration(data["stable-window"], defaultStableWindow)
+	if err != nil {
+		return fmt.Errorf("stable-window: %w", err)
+	}
+	data["burst-window-percentage"] = strconv.FormatFloat(100*d.Seconds()/stableWindow.Seconds(), 'f', -1, 64)
+	return nil
+}
+
+// renameKey moves the value of the key from to the key to.
+func renameKey(data map[string]string, from, to string) error {
+	value, ok := data[from]
+	if !ok {
+		return nil
+	}
+	if _, ok := data[to]; ok {
+		return fmt.Errorf("cannot specify both %s and %s", from, to)
+	}
+	delete(data, from)
+	data[to] = value
+	return nil
+}
+
+// mapVersion returns the schema version of a configuration map.
+func mapVersion(data map[string]string) (int, error) {
+	value := strings.TrimSpace(data[VersionKey])
+	if value == "" {
+		return CurrentVersion, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid int value: %q", data[VersionKey])
+	}
+	if v < 1 || v > CurrentVersion {
+		return 0, fmt.Errorf("version = %d, must be in [1; %d] range", v, CurrentVersion)
+	}
+	return v, nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name       string
+		oldVersion int
+		data       map[string]string
+		want       map[string]string
+		wantErr    bool
+	}{
+		{
+			name:       "renamed keys",
+			oldVersion: 1,
+			data: map[string]string{
+				"panic-window-percentage":    "20",
+				"panic-threshold-percentage": "300",
+				"target-value":               "50",
+			},
+			want: map[string]string{
+				"version":                    "2",
+				"burst-window-percentage":    "20",
+				"burst-threshold-percentage": "300",
+				"target-value":               "50",
+			},
+		},
+		{
+			name:       "panic window duration",
+			oldVersion: 1,
+			data: map[string]string{
+				"stable-window": "120s",
+				"panic-window":  "6s",
+			},
+			want: map[string]string{
+				"version":                 "2",
+				"stable-window":           "120s",
+				"burst-window-percentage": "5",
+			},
+		},
+		{
+			name:       "current version",
+			oldVersion: CurrentVersion,
+			data:       map[string]string{"target-value": "50"},
+			want:       map[string]string{"version": "2", "target-value": "50"},
+		},
+		{
+			name:       "both old and new key",
+			oldVersion: 1,
+			data: map[string]string{
+				"panic-window-percentage": "20",
+				"burst-window-percentage": "10",
+			},
+			wantErr: true,
+		},
+		{
+			name:       "invalid panic window",
+			oldVersion: 1,
+			data:       map[string]string{"panic-window": "soon"},
+			wantErr:    true,
+		},
+		{
+			name:       "unknown version",
+			oldVersion: CurrentVersion + 1,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := maps.Clone(tt.data)
+			got, err := Migrate(tt.oldVersion, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Migrate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !maps.Equal(tt.data, original) {
+				t.Errorf("Migrate() modified its input: %v", tt.data)
+			}
+			if !tt.wantErr && !maps.Equal(got, tt.want) {
+				t.Errorf("Migrate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFromMapVersions(t *testing.T) {
+	cfg, err := LoadFromMap(map[string]string{
+		"version":                 "1",
+		"panic-window-percentage": "20",
+	})
+	if err != nil {
+		t.Fatalf("LoadFromMap() error = %v", err)
+	}
+	if cfg.Version != 1 || cfg.BurstWindowPercentage != 20 {
+		t.Errorf("Version = %d, BurstWindowPercentage = %v, want 1 and 20", cfg.Version, cfg.BurstWindowPercentage)
+	}
+
+	cfg, err = LoadFromMap(map[string]string{})
+	if err != nil {
+		t.Fatalf("LoadFromMap() error = %v", err)
+	}
+	if cfg.Version != CurrentVersion {
+		t.Errorf("Version = %d, want %d", cfg.Version, CurrentVersion)
+	}
+
+	if _, err := LoadFromMap(map[string]string{"version": "3"}); err == nil {
+		t.Error("LoadFromMap() of a newer version succeeded")
+	}
+
+	if errs := ValidateMap(map[string]string{"version": "1", "panic-window": "6s"}); len(errs) != 0 {
+		t.Errorf("ValidateMap() = %v, want no errors", errs.ToAggregate())
+	}
+	if errs := ValidateMap(map[string]string{"panic-window": "6s"}); len(errs) != 1 {
+		t.Errorf("ValidateMap() = %v, want an unknown key", errs.ToAggregate())
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// quantitySuffixes are the suffixes of Kubernetes quantities and their
+// multipliers.
+var quantitySuffixes = map[string]float64{
+	"n":  1e-9,
+	"u":  1e-6,
+	"m":  1e-3,
+	"k":  1e3,
+	"M":  1e6,
+	"G":  1e9,
+	"T":  1e12,
+	"P":  1e15,
+	"E":  1e18,
+	"Ki": 1 << 10,
+	"Mi": 1 << 20,
+	"Gi": 1 << 30,
+	"Ti": 1 << 40,
+	"Pi": 1 << 50,
+	"Ei": 1 << 60,
+}
+
+// ParseQuantity parses a Kubernetes-style quantity, e.g. "500m", "1.5Gi" or
+// "2k", into a float64. Plain numbers, including ones with an exponent like
+// "1e3", are accepted as well.
+func ParseQuantity(s string) (float64, error) {
+	value, _, err := parseQuantityValue(s)
+	return value, err
+}
+
+// parseQuantityValue parses a quantity and reports whether it had a suffix.
+func parseQuantityValue(s string) (float64, bool, error) {
+	s = strings.TrimSpace(s)
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, false, nil
+	}
+
+	// Binary suffixes are two characters long, so try them first.
+	for _, n := range []int{2, 1} {
+		if len(s) <= n {
+			continue
+		}
+		multiplier, ok := quantitySuffixes[s[len(s)-n:]]
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(s[:len(s)-n], 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			break
+		}
+		return f * multiplier, true, nil
+	}
+	return 0, false, fmt.Errorf("invalid quantity %q", s)
+}
+
+// quantityIn parses a target value in unit. Plain numbers are in unit
+// already. Quantities with a suffix are in the Kubernetes base unit of the
+// dimension of unit, i.e. cores for CPU and bytes for memory, and are
+// converted to unit, so "500m" is 500 millicores and "1Gi" is 1024 MiB.
+func quantityIn(s string, unit api.Unit) (float64, error) {
+	value, suffixed, err := parseQuantityValue(s)
+	if err != nil || !suffixed {
+		return value, err
+	}
+
+	var base api.Unit
+	switch unit.Dimension() {
+	case api.UnitCores.Dimension():
+		base = api.UnitCores
+	case api.UnitBytes.Dimension():
+		base = api.UnitBytes
+	default:
+		return value, nil
+	}
+	return base.Convert(value, unit)
+}
+
+func getEnvQuantity(key string, unit api.Unit, defaultValue float64) (float64, error) {
+	value := os.Getenv(EnvPrefix + key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	q, err := quantityIn(value, unit)
+	if err != nil {
+		return defaultValue, fmt.Errorf("invalid quantity value for %s%s: %q", EnvPrefix, key, value)
+	}
+	return q, nil
+}
+
+func parseQuantity(value string, unit api.Unit, defaultValue float64) (float64, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	q, err := quantityIn(value, unit)
+	if err != nil {
+		return defaultValue, fmt.Errorf("invalid quantity value: %q", value)
+	}
+	return q, nil
+}
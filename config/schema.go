@@ -0,0 +1,142 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// JSONSchemaDialect is the JSON Schema version of JSONSchema.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Patterns of the string values of the configuration map.
+const (
+	floatPattern    = `^\s*[-+]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][-+]?[0-9]+)?\s*$`
+	quantityPattern = `^\s*[-+]?([0-9]+(\.[0-9]*)?|\.[0-9]+)(([eE][-+]?[0-9]+)|[numkMGTPE]|[KMGTPE]i)?\s*$`
+	int32Pattern    = `^\s*[-+]?[0-9]+\s*$`
+	boolPattern     = `^\s*(1|t|T|TRUE|true|True|0|f|F|FALSE|false|False)\s*$`
+	durationPattern = `^\s*[-+]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))+)\s*$`
+)
+
+// mapField describes a key of the configuration map.
+type mapField struct {
+	key         string
+	description string
+	pattern     string
+	enum        []string
+	def         string
+}
+
+// mapFields are the keys supported by LoadFromMap.
+var mapFields = []mapField{
+	{key: VersionKey, description: "Schema version of the configuration map.", pattern: int32Pattern, def: strconv.Itoa(CurrentVersion)},
+	{key: "scaling-metric-type", description: "Semantics of the scaling metric.", enum: []string{
+		string(api.ScalingMetricConcurrency), string(api.ScalingMetricRPS), string(api.ScalingMetricUtilization), string(api.ScalingMetricValue),
+	}, def: string(defaultScalingMetricType)},
+	{key: "unit", description: "Unit of the recorded values and the targets.", enum: unitNames(), def: string(api.UnitNone)},
+	{key: "scale-to-zero-grace-period", description: "Grace period before scaling to zero.", pattern: durationPattern, def: defaultScaleToZeroGracePeriod.String()},
+	{key: "max-scale-up-rate", description: "Maximum rate to scale up pods, greater than 1.0.", pattern: floatPattern, def: formatFloat(defaultMaxScaleUpRate)},
+	{key: "max-scale-down-rate", description: "Maximum rate to scale down pods, greater than 1.0.", pattern: floatPattern, def: formatFloat(defaultMaxScaleDownRate)},
+	{key: "max-scale-down-fraction", description: "Maximum fraction of the ready pods removed per evaluation, in [0, 1], 0 disables it.", pattern: floatPattern, def: formatFloat(defaultMaxScaleDownFraction)},
+	{key: "target-value", description: "Target metric value per pod, a number or a Kubernetes quantity.", pattern: quantityPattern, def: formatFloat(defaultTargetValue)},
+	{key: "total-target-value", description: "Total target metric value across all pods, a number or a Kubernetes quantity.", pattern: quantityPattern, def: formatFloat(defaultTotalTargetValue)},
+	{key: "burst-threshold-percentage", description: "Percentage threshold to enter burst mode.", pattern: floatPattern, def: formatFloat(defaultBurstThresholdPercentage)},
+	{key: "burst-absolute-threshold", description: "Burst-over-stable delta to enter burst mode, 0 disables it.", pattern: floatPattern, def: formatFloat(defaultBurstAbsoluteThreshold)},
+	{key: "burst-window-percentage", description: "Burst window as percentage of the stable window, in [1.0, 100.0].", pattern: floatPattern, def: formatFloat(defaultBurstWindowPercentage)},
+	{key: "burst-entry-ticks", description: "Consecutive evaluations the burst condition must hold before entering burst mode, 0 disables it.", pattern: int32Pattern, def: strconv.Itoa(int(defaultBurstEntryTicks))},
+	{key: "burst-entry-delay", description: "Time the burst condition must hold before entering burst mode.", pattern: durationPattern, def: defaultBurstEntryDelay.String()},
+	{key: "burst-blending", description: "Blends the stable and burst pod counts by how far the burst ratio is towards the burst threshold.", pattern: boolPattern, def: strconv.FormatBool(defaultBurstBlending)},
+	{key: "stable-window", description: "Time window for stable metric averaging, in [5s, 600s].", pattern: durationPattern, def: defaultStableWindow.String()},
+	{key: "min-window-fill-fraction", description: "Fraction of the stable window that must carry data before recommendations are valid, in [0, 1].", pattern: floatPattern, def: formatFloat(defaultMinWindowFillFraction)},
+	{key: "window-granularity", description: "Bucket duration of the stable and burst windows, 0 means 1s.", pattern: durationPattern, def: defaultWindowGranularity.String()},
+	{key: "scale-down-delay", description: "Delay before applying scale-down decisions.", pattern: durationPattern, def: defaultScaleDownDelay.String()},
+	{key: "scale-down-soak-ticks", description: "Consecutive evaluations that must agree before scaling down, 0 disables it.", pattern: int32Pattern, def: strconv.Itoa(int(defaultScaleDownSoakTicks))},
+	{key: "scale-invariant", description: "Makes recommendations proportional to the load.", pattern: boolPattern, def: strconv.FormatBool(defaultScaleInvariant)},
+	{key: "provisioning-rate", description: "Pods per minute the cluster can provision to pace scale-ups to, 0 disables it.", pattern: floatPattern, def: formatFloat(defaultProvisioningRate)},
+	{key: "min-scale", description: "Minimum number of pods.", pattern: int32Pattern, def: strconv.Itoa(int(defaultMinScale))},
+	{key: "max-scale", description: "Maximum number of pods, 0 means unlimited.", pattern: int32Pattern, def: strconv.Itoa(int(defaultMaxScale))},
+	{key: "zones", description: "Number of zones to spread the pods evenly across, 0 disables it.", pattern: int32Pattern, def: strconv.Itoa(int(defaultZones))},
+	{key: "min-pods-per-zone", description: "Minimum number of pods in every zone.", pattern: int32Pattern, def: strconv.Itoa(int(defaultMinPodsPerZone))},
+	{key: "activation-scale", description: "Minimum number of pods when scaling from zero.", pattern: int32Pattern, def: strconv.Itoa(int(defaultActivationScale))},
+	{key: "ignore-activation-scale-with-min-scale", description: "Disables activation-scale when min-scale is greater than 0.", pattern: boolPattern, def: strconv.FormatBool(defaultIgnoreActivationScale)},
+	{key: "last-pod-stickiness", description: "Keeps the last pod until the load was zero for scale-to-zero-grace-period.", pattern: boolPattern, def: strconv.FormatBool(defaultLastPodStickiness)},
+	{key: "activation-threshold", description: "Burst value that must be exceeded to scale from zero, at least 0.", pattern: floatPattern, def: formatFloat(defaultActivationThreshold)},
+}
+
+// Schema returns the schema of the configuration map as an object
+// compatible with both JSON Schema and the OpenAPI v3 schemas of Kubernetes
+// CustomResourceDefinitions. All values are strings, as in ConfigMaps and
+// annotations, so their format is described by patterns.
+func Schema() map[string]any {
+	properties := make(map[string]any, len(mapFields))
+	for _, f := range mapFields {
+		p := map[string]any{
+			"type":        "string",
+			"description": f.description,
+			"default":     f.def,
+		}
+		if f.pattern != "" {
+			p["pattern"] = f.pattern
+		}
+		if f.enum != nil {
+			p["enum"] = f.enum
+		}
+		properties[f.key] = p
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"description":          "Autoscaler configuration as loaded by config.LoadFromMap.",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// JSONSchema returns Schema as an indented JSON Schema document, e.g. for
+// validating configuration files in editors.
+func JSONSchema() ([]byte, error) {
+	schema := Schema()
+	schema["$schema"] = JSONSchemaDialect
+	schema["title"] = "libkpa autoscaler configuration"
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// isMapKey reports whether LoadFromMap supports the key.
+func isMapKey(key string) bool {
+	for _, f := range mapFields {
+		if f.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// unitNames returns the names of the known units, including the empty one.
+func unitNames() []string {
+	names := []string{string(api.UnitNone)}
+	for _, u := range api.Units() {
+		names = append(names, string(u))
+	}
+	return names
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
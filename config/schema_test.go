@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestSchemaDefaults(t *testing.T) {
+	properties := Schema()["properties"].(map[string]any)
+
+	defaults := map[string]string{}
+	for key, p := range properties {
+		p := p.(map[string]any)
+		def := p["default"].(string)
+		if pattern, ok := p["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(def) {
+			t.Errorf("default %q of %s doesn't match %s", def, key, pattern)
+		}
+		defaults[key] = def
+	}
+
+	// The schema defaults are the defaults of LoadFromMap.
+	got, err := LoadFromMap(defaults)
+	if err != nil {
+		t.Fatalf("LoadFromMap() of the schema defaults failed: %v", err)
+	}
+	if want := NewDefaultAutoscalerConfig(); !configsEqual(got, want) {
+		t.Errorf("LoadFromMap() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSchemaPatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		valid   []string
+		invalid []string
+	}{
+		{
+			pattern: floatPattern,
+			valid:   []string{"1", "-2.5", " 1.5 ", ".5", "1e3"},
+			invalid: []string{"", "abc", "1.5.5", "500m"},
+		},
+		{
+			pattern: quantityPattern,
+			valid:   []string{"100", "500m", "1.5Gi", "2k", "1e3", "1E"},
+			invalid: []string{"", "1.5Gb", "m", "1 k"},
+		},
+		{
+			pattern: int32Pattern,
+			valid:   []string{"0", "-1", "10"},
+			invalid: []string{"", "1.5", "ten"},
+		},
+		{
+			pattern: durationPattern,
+			valid:   []string{"0", "60s", "1m30s", "1.5h", "500ms"},
+			invalid: []string{"", "60", "1d", "s"},
+		},
+	}
+
+	for _, tt := range tests {
+		re := regexp.MustCompile(tt.pattern)
+		for _, v := range tt.valid {
+			if !re.MatchString(v) {
+				t.Errorf("%q doesn't match %s", v, tt.pattern)
+			}
+		}
+		for _, v := range tt.invalid {
+			if re.MatchString(v) {
+				t.Errorf("%q matches %s", v, tt.pattern)
+			}
+		}
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema() error = %v", err)
+	}
+
+	var schema struct {
+		Schema               string                    `json:"$schema"`
+		AdditionalProperties bool                      `json:"additionalProperties"`
+		Properties           map[string]map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("JSONSchema() is not valid JSON: %v", err)
+	}
+	if schema.Schema != JSONSchemaDialect {
+		t.Errorf("$schema = %q, want %q", schema.Schema, JSONSchemaDialect)
+	}
+	if schema.AdditionalProperties {
+		t.Error("additionalProperties = true, want false")
+	}
+	if got := schema.Properties["scaling-metric-type"]["enum"]; len(got.([]any)) != 4 {
+		t.Errorf("scaling-metric-type enum = %v, want 4 values", got)
+	}
+	if _, ok := Schema()["$schema"]; ok {
+		t.Error("Schema() contains $schema, which CRD schemas don't allow")
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// An example of scaling GPU workloads on accelerator utilization reported in
+// the format of the NVIDIA DCGM exporter.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/loadgen"
+	"github.com/Fedosin/libkpa/manager"
+	"github.com/Fedosin/libkpa/metrics"
+)
+
+const (
+	// utilizationMetric is the DCGM field with the GPU utilization in percent.
+	utilizationMetric = "DCGM_FI_DEV_GPU_UTIL"
+)
+
+// DCGMCollector reads per-pod GPU utilization from DCGM exporter output.
+// In a real deployment the input is the body of an HTTP request to the
+// exporter's /metrics endpoint.
+type DCGMCollector struct{}
+
+// Collect returns the average GPU utilization of every pod. Pods with several
+// GPUs report the average over their GPUs.
+func (c *DCGMCollector) Collect(r io.Reader) (map[string]float64, error) {
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, utilizationMetric+"{") {
+			continue
+		}
+
+		labelsEnd := strings.LastIndex(line, "}")
+		if labelsEnd < 0 {
+			return nil, fmt.Errorf("malformed sample %q", line)
+		}
+		pod := labelValue(line[len(utilizationMetric)+1:labelsEnd], "pod")
+		if pod == "" {
+			// GPUs not assigned to a pod.
+			continue
+		}
+
+		fields := strings.Fields(line[labelsEnd+1:])
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("malformed sample %q", line)
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed value in %q: %w", line, err)
+		}
+
+		sums[pod] += value
+		counts[pod]++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]float64, len(sums))
+	for pod, sum := range sums {
+		result[pod] = sum / float64(counts[pod])
+	}
+	return result, nil
+}
+
+// labelValue returns the value of the label in a Prometheus label set.
+func labelValue(labels, name string) string {
+	for _, pair := range strings.Split(labels, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && k == name {
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
+
+// fakeExporter renders DCGM exporter output for the given pods, each with
+// two GPUs. The utilization of every GPU varies by up to 5% around load.
+func fakeExporter(pods int, load float64, seed int64) (string, error) {
+	gpus, err := loadgen.NewStream(loadgen.Constant(load), time.Time{}, time.Second, 5, seed)
+	if err != nil {
+		return "", fmt.Errorf("failed to create load stream: %w", err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# HELP %s GPU utilization (in %%).\n", utilizationMetric)
+	fmt.Fprintf(&sb, "# TYPE %s gauge\n", utilizationMetric)
+	for p := range pods {
+		for gpu := range 2 {
+			_, util := gpus.Next()
+			util = min(100, util)
+			fmt.Fprintf(&sb, "%s{gpu=\"%d\",modelName=\"NVIDIA A100\",namespace=\"ml\",pod=\"inference-%d\"} %.0f\n",
+				utilizationMetric, gpu, p, util)
+		}
+	}
+	return sb.String(), nil
+}
+
+func main() {
+	if err := run(os.Stdout, time.Now()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run simulates a load spike followed by a quiet period, scaling every 10
+// seconds after start. The time is simulated, so the run is instant.
+func run(out io.Writer, start time.Time) error {
+	// Start from the device preset: utilization based, long windows and
+	// slow scale-down.
+	cfg := config.NewDeviceAutoscalerConfig()
+	cfg.StableWindow = 60 * time.Second // shorter for the demo
+	cfg.ScaleDownDelay = 30 * time.Second
+	cfg.MaxScale = 16
+
+	scaler, err := manager.NewScaler("gpu-utilization", *cfg, "linear")
+	if err != nil {
+		return fmt.Errorf("failed to create scaler: %w", err)
+	}
+
+	collector := &DCGMCollector{}
+	currentPods := int32(2)
+	now := start
+
+	// Simulate a load spike followed by a quiet period.
+	loads := []float64{70, 95, 100, 100, 100, 90, 60, 40, 30, 30, 30, 30}
+	for i, load := range loads {
+		now = now.Add(10 * time.Second)
+
+		// Load is spread over the pods, so utilization drops as pods are added.
+		perPodLoad := load * 2 / float64(currentPods)
+		exported, err := fakeExporter(int(currentPods), perPodLoad, int64(i))
+		if err != nil {
+			return err
+		}
+		perPod, err := collector.Collect(strings.NewReader(exported))
+		if err != nil {
+			return fmt.Errorf("failed to collect metrics: %w", err)
+		}
+
+		usages := make([]float64, 0, len(perPod))
+		for _, u := range perPod {
+			usages = append(usages, u)
+		}
+		// DCGM reports percentages already, so the request is 100%.
+		avg, err := metrics.AverageUtilization(usages, 100)
+		if err != nil {
+			return fmt.Errorf("failed to compute utilization: %w", err)
+		}
+		scaler.Record(avg, now)
+
+		rec := scaler.Scale(currentPods, now)
+		fmt.Fprintf(out, "[%02d] pods=%d utilization=%.1f%% desired=%d burst=%v\n",
+			i, currentPods, avg, rec.DesiredPodCount, rec.InBurstMode)
+		if rec.ScaleValid {
+			currentPods = max(1, rec.DesiredPodCount)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDCGMCollector(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{
+			name: "averages the GPUs of a pod",
+			input: `# TYPE DCGM_FI_DEV_GPU_UTIL gauge
+DCGM_FI_DEV_GPU_UTIL{gpu="0",pod="a"} 40
+DCGM_FI_DEV_GPU_UTIL{gpu="1",pod="a"} 60
+DCGM_FI_DEV_GPU_UTIL{gpu="0",pod="b"} 90
+DCGM_FI_DEV_GPU_UTIL{gpu="1",pod=""} 100
+DCGM_FI_DEV_MEM_COPY_UTIL{gpu="0",pod="a"} 10
+`,
+			want: map[string]float64{"a": 50, "b": 90},
+		},
+		{
+			name:    "malformed value",
+			input:   `DCGM_FI_DEV_GPU_UTIL{gpu="0",pod="a"} high`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&DCGMCollector{}).Collect(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Collect() = %v, want %v", got, tt.want)
+			}
+			for pod, want := range tt.want {
+				if got[pod] != want {
+					t.Errorf("Collect()[%s] = %v, want %v", pod, got[pod], want)
+				}
+			}
+		})
+	}
+}
+
+func Example() {
+	if err := run(os.Stdout, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		panic(err)
+	}
+	// Output:
+	// [00] pods=2 utilization=69.8% desired=2 burst=true
+	// [01] pods=2 utilization=96.5% desired=3 burst=true
+	// [02] pods=3 utilization=65.2% desired=3 burst=true
+	// [03] pods=3 utilization=68.7% desired=3 burst=true
+	// [04] pods=3 utilization=65.7% desired=3 burst=true
+	// [05] pods=3 utilization=61.8% desired=3 burst=true
+	// [06] pods=3 utilization=41.3% desired=3 burst=true
+	// [07] pods=3 utilization=27.0% desired=3 burst=true
+	// [08] pods=3 utilization=20.7% desired=3 burst=true
+	// [09] pods=3 utilization=18.5% desired=3 burst=true
+	// [10] pods=3 utilization=21.2% desired=3 burst=true
+	// [11] pods=3 utilization=20.2% desired=3 burst=true
+}
@@ -20,41 +20,52 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
-	"math/rand"
+	"os"
 	"time"
 
 	"github.com/Fedosin/libkpa/algorithm"
 	"github.com/Fedosin/libkpa/api"
+	"github.com/Fedosin/libkpa/audit"
 	"github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/loadgen"
 	"github.com/Fedosin/libkpa/metrics"
+	"github.com/Fedosin/libkpa/report"
 	"github.com/Fedosin/libkpa/transmitter"
 )
 
 const (
 	scalingMetric = "concurrency"
+
+	// interval is the time between two scaling decisions.
+	interval = 2 * time.Second
 )
 
 // MockMetricCollector simulates collecting metrics from pods
 type MockMetricCollector struct {
-	baseLoad float64
+	pods []*loadgen.Stream
+}
+
+// NewMockMetricCollector simulates pods following the load pattern, each
+// with up to 20 of additional concurrency.
+func NewMockMetricCollector(load loadgen.Pattern, pods int, interval time.Duration) (*MockMetricCollector, error) {
+	m := &MockMetricCollector{}
+	for i := range pods {
+		stream, err := loadgen.NewStream(loadgen.Sum(load, loadgen.Constant(10)), time.Now(), interval, 10, int64(i))
+		if err != nil {
+			return nil, err
+		}
+		m.pods = append(m.pods, stream)
+	}
+	return m, nil
 }
 
 func (m *MockMetricCollector) CollectMetrics() []api.Metrics {
-	// Simulate 3 pods with varying load
-	pods := []api.Metrics{
-		{
-			Timestamp: time.Now(),
-			Value:     m.baseLoad + rand.Float64()*20,
-		},
-		{
-			Timestamp: time.Now(),
-			Value:     m.baseLoad + rand.Float64()*20,
-		},
-		{
-			Timestamp: time.Now(),
-			Value:     m.baseLoad + rand.Float64()*20,
-		},
+	pods := make([]api.Metrics, len(m.pods))
+	for i, stream := range m.pods {
+		t, v := stream.Next()
+		pods[i] = api.Metrics{Timestamp: t, Value: v}
 	}
 	return pods
 }
@@ -74,27 +85,48 @@ func main() {
 	cfg.MaxScale = 10
 	cfg.ScaleDownDelay = 5 * time.Second
 
-	fmt.Println("=== Knative Pod Autoscaler Library Demo ===")
-	fmt.Printf("Configuration:\n")
-	fmt.Printf("  Scaling Metric: %s\n", scalingMetric)
-	fmt.Printf("  Target Value: %.0f\n", cfg.TargetValue)
-	fmt.Printf("  Stable Window: %s\n", cfg.StableWindow)
-	fmt.Printf("  Min/Max Scale: %d/%d\n", cfg.MinScale, cfg.MaxScale)
-	fmt.Println()
+	// Create a metric transmitter for logging
+	metricTransmitter := transmitter.NewLogTransmitter(nil, transmitter.KubernetesLabels("default", "example-app"))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	fmt.Println("Press Ctrl+C to stop")
+	if err := run(os.Stdout, *cfg, metricTransmitter, time.Now(), ticker.C); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run simulates the load phases with 3 pods, scaling once per tick until all
+// phases passed or ticks is closed. The ticks are the current time, so tests
+// can drive the simulation with a fake clock.
+func run(out io.Writer, cfg api.AutoscalerConfig, metricTransmitter transmitter.MetricTransmitter, start time.Time, ticks <-chan time.Time) error {
+	fmt.Fprintln(out, "=== Knative Pod Autoscaler Library Demo ===")
+	fmt.Fprintf(out, "Configuration:\n")
+	fmt.Fprintf(out, "  Scaling Metric: %s\n", scalingMetric)
+	fmt.Fprintf(out, "  Target Value: %.0f\n", cfg.TargetValue)
+	fmt.Fprintf(out, "  Stable Window: %s\n", cfg.StableWindow)
+	fmt.Fprintf(out, "  Min/Max Scale: %d/%d\n", cfg.MinScale, cfg.MaxScale)
+	fmt.Fprintln(out)
 
 	// Create the autoscaler
-	autoscaler, err := algorithm.NewSlidingWindowAutoscaler(*cfg)
+	autoscaler, err := algorithm.NewSlidingWindowAutoscalerAt(cfg, start)
 	if err != nil {
-		log.Fatalf("Failed to create autoscaler: %v", err)
+		return fmt.Errorf("failed to create autoscaler: %w", err)
 	}
+	autoscaler.SetLatencyTransmitter(metricTransmitter)
 
-	// Create a metric transmitter for logging
-	metricTransmitter := transmitter.NewLogTransmitter(nil)
+	// Keep the decisions for the chart at the end of the simulation
+	history := &report.History{}
+	audited, err := audit.NewAutoscaler("example-app", autoscaler, history)
+	if err != nil {
+		return fmt.Errorf("failed to create audited autoscaler: %w", err)
+	}
 
 	// Create metric windows for stable and burst averages
 	stableWindow, err := metrics.NewTimeWindow(cfg.StableWindow, time.Second)
 	if err != nil {
-		log.Fatalf("Failed to create new stable time window: %v", err)
+		return fmt.Errorf("failed to create new stable time window: %w", err)
 	}
 
 	burstWindow, err := metrics.NewTimeWindow(
@@ -102,134 +134,118 @@ func main() {
 		time.Second,
 	)
 	if err != nil {
-		log.Fatalf("Failed to create new burst time window: %v", err)
+		return fmt.Errorf("failed to create new burst time window: %w", err)
 	}
 
-	// Create a mock metric collector
-	collector := &MockMetricCollector{baseLoad: 80}
+	// Simulate different load patterns
+	loadPhases := []loadgen.Phase{
+		{Name: "Normal Load", Duration: 20 * time.Second, Pattern: loadgen.Constant(80)},
+		{Name: "High Load", Duration: 20 * time.Second, Pattern: loadgen.Constant(250)},
+		{Name: "Spike Load", Duration: 10 * time.Second, Pattern: loadgen.Constant(500)},
+		{Name: "Decreasing Load", Duration: 20 * time.Second, Pattern: loadgen.Constant(50)},
+		{Name: "Idle", Duration: 15 * time.Second, Pattern: loadgen.Constant(0)},
+	}
+	var simulationDuration time.Duration
+	for _, phase := range loadPhases {
+		simulationDuration += phase.Duration
+	}
+
+	// Create a mock metric collector simulating 3 pods
+	collector, err := NewMockMetricCollector(loadgen.Phases(loadPhases...), 3, interval)
+	if err != nil {
+		return fmt.Errorf("failed to create metric collector: %w", err)
+	}
 
-	// Simulation parameters
 	ctx := context.Background()
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
 
-	fmt.Println("Starting autoscaler simulation...")
-	fmt.Println("Press Ctrl+C to stop")
-	fmt.Println()
+	fmt.Fprintln(out, "Starting autoscaler simulation...")
+	fmt.Fprintln(out)
 
 	// Track current pod count
 	currentPods := int32(3)
 
-	// Simulate different load patterns
-	loadPhases := []struct {
-		name     string
-		duration time.Duration
-		load     float64
-	}{
-		{"Normal Load", 20 * time.Second, 80},
-		{"High Load", 20 * time.Second, 250},
-		{"Spike Load", 10 * time.Second, 500},
-		{"Decreasing Load", 20 * time.Second, 50},
-		{"Idle", 15 * time.Second, 0},
-	}
+	phase := ""
 
-	phaseIndex := 0
-	phaseStart := time.Now()
-
-	for {
-		select {
-		case <-ticker.C:
-			now := time.Now()
-
-			// Update load based on current phase
-			if phaseIndex < len(loadPhases) {
-				phase := loadPhases[phaseIndex]
-				if now.Sub(phaseStart) > phase.duration {
-					phaseIndex++
-					phaseStart = now
-					if phaseIndex < len(loadPhases) {
-						fmt.Printf("\n=== Phase: %s ===\n", loadPhases[phaseIndex].name)
-					}
-				}
-				if phaseIndex < len(loadPhases) {
-					collector.baseLoad = loadPhases[phaseIndex].load
-				}
-			}
+	for now := range ticks {
+		// Announce the current phase
+		elapsed := now.Sub(start)
+		if name := loadgen.PhaseAt(loadPhases, elapsed); name != phase {
+			phase = name
+			fmt.Fprintf(out, "\n=== Phase: %s ===\n", phase)
+		}
 
-			// Collect metrics
-			podMetrics := collector.CollectMetrics()
+		// Collect metrics
+		podMetrics := collector.CollectMetrics()
 
-			// Calculate total load
-			totalConcurrency := 0.0
-			for _, pm := range podMetrics {
-				totalConcurrency += pm.Value
-			}
+		// Calculate total load
+		totalConcurrency := 0.0
+		for _, pm := range podMetrics {
+			totalConcurrency += pm.Value
+		}
 
-			// Record in windows
-			stableWindow.Record(now, totalConcurrency)
-			burstWindow.Record(now, totalConcurrency)
-
-			// Get window averages
-			stableAvg := stableWindow.WindowAverage(now)
-			burstAvg := burstWindow.WindowAverage(now)
-
-			// Create metric snapshot
-			snapshot := metrics.NewMetricSnapshot(
-				stableAvg,
-				burstAvg,
-				currentPods,
-				now,
-			)
-
-			// Get scaling recommendation
-			recommendation := autoscaler.Scale(snapshot, now)
-
-			// Log current state
-			fmt.Printf("[%s] Metrics: stable=%.1f, burst=%.1f, current=%d pods\n",
-				now.Format("15:04:05"),
-				stableAvg,
-				burstAvg,
-				currentPods,
-			)
-
-			if recommendation.ScaleValid {
-				// Log recommendation
-				action := "maintain"
-				if recommendation.DesiredPodCount > currentPods {
-					action = "scale up"
-				} else if recommendation.DesiredPodCount < currentPods {
-					action = "scale down"
-				}
-
-				fmt.Printf("  → Recommendation: %s to %d pods", action, recommendation.DesiredPodCount)
-				if recommendation.InBurstMode {
-					fmt.Print(" [BURST MODE]")
-				}
-				fmt.Println()
-
-				// Record metrics
-				metricTransmitter.RecordDesiredPods(ctx, "default", "example-app", recommendation.DesiredPodCount)
-				metricTransmitter.RecordStableValue(ctx, "default", "example-app", scalingMetric, stableAvg)
-				metricTransmitter.RecordBurstValue(ctx, "default", "example-app", scalingMetric, burstAvg)
-				metricTransmitter.RecordBurstMode(ctx, "default", "example-app", recommendation.InBurstMode)
-
-				// Simulate applying the recommendation
-				if recommendation.DesiredPodCount != currentPods {
-					fmt.Printf("  → Scaling from %d to %d pods...\n", currentPods, recommendation.DesiredPodCount)
-					currentPods = recommendation.DesiredPodCount
-				}
-			} else {
-				fmt.Println("  → No valid recommendation (insufficient data)")
+		// Record in windows
+		stableWindow.Record(now, totalConcurrency)
+		burstWindow.Record(now, totalConcurrency)
+
+		// Get window averages
+		stableAvg := stableWindow.WindowAverage(now)
+		burstAvg := burstWindow.WindowAverage(now)
+
+		// Create metric snapshot
+		snapshot := metrics.NewMetricSnapshot(
+			stableAvg,
+			burstAvg,
+			currentPods,
+			now,
+		)
+
+		// Get scaling recommendation
+		recommendation := audited.Scale(snapshot, now)
+
+		// Log current state
+		fmt.Fprintf(out, "[%s] Metrics: stable=%.1f, burst=%.1f, current=%d pods\n",
+			now.Format("15:04:05"),
+			stableAvg,
+			burstAvg,
+			currentPods,
+		)
+
+		if recommendation.ScaleValid {
+			// Log recommendation
+			action := "maintain"
+			if recommendation.DesiredPodCount > currentPods {
+				action = "scale up"
+			} else if recommendation.DesiredPodCount < currentPods {
+				action = "scale down"
 			}
 
-			// Exit after all phases
-			if phaseIndex >= len(loadPhases) {
-				fmt.Println("\nSimulation complete!")
-				return
+			fmt.Fprintf(out, "  → Recommendation: %s to %d pods", action, recommendation.DesiredPodCount)
+			if recommendation.InBurstMode {
+				fmt.Fprint(out, " [BURST MODE]")
 			}
+			fmt.Fprintln(out)
+
+			// Record metrics
+			metricTransmitter.RecordDesiredPods(ctx, recommendation.DesiredPodCount)
+			metricTransmitter.RecordStableValue(ctx, scalingMetric, stableAvg)
+			metricTransmitter.RecordBurstValue(ctx, scalingMetric, burstAvg)
+			metricTransmitter.RecordBurstMode(ctx, recommendation.InBurstMode)
+
+			// Simulate applying the recommendation
+			if recommendation.DesiredPodCount != currentPods {
+				fmt.Fprintf(out, "  → Scaling from %d to %d pods...\n", currentPods, recommendation.DesiredPodCount)
+				currentPods = recommendation.DesiredPodCount
+			}
+		} else {
+			fmt.Fprintln(out, "  → No valid recommendation (insufficient data)")
+		}
 
-		case <-ctx.Done():
-			return
+		// Exit after all phases
+		if elapsed >= simulationDuration {
+			fmt.Fprintln(out, "\nSimulation complete!")
+			fmt.Fprintln(out)
+			return report.WriteChart(out, history.Records(), report.ChartOptions{})
 		}
 	}
+	return nil
 }
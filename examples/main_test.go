@@ -0,0 +1,72 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/transmitter"
+)
+
+// fakeTicks returns n ticks interval apart after start, as a ticker would
+// deliver them.
+func fakeTicks(start time.Time, interval time.Duration, n int) <-chan time.Time {
+	ticks := make(chan time.Time, n)
+	for i := 1; i <= n; i++ {
+		ticks <- start.Add(time.Duration(i) * interval)
+	}
+	close(ticks)
+	return ticks
+}
+
+func TestRun(t *testing.T) {
+	cfg := config.NewDefaultAutoscalerConfig()
+	cfg.StableWindow = 30 * time.Second
+	cfg.MinScale = 1
+	cfg.MaxScale = 10
+	cfg.ScaleDownDelay = 5 * time.Second
+
+	var out bytes.Buffer
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := run(&out, *cfg, transmitter.NewNoOpTransmitter(), start, fakeTicks(start, interval, 100)); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"=== Phase: Spike Load ===",
+		"Recommendation: scale up",
+		"[BURST MODE]",
+		"current=10 pods",
+		"=== Phase: Idle ===",
+		"Recommendation: scale down",
+		"Simulation complete!",
+		"# desired pods  o ready pods  . load",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, got)
+		}
+	}
+	// MaxScale caps scale-ups.
+	if strings.Contains(got, "current=11") {
+		t.Errorf("pod count exceeded 10:\n%s", got)
+	}
+}
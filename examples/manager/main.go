@@ -19,15 +19,44 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"log"
-	"math/rand"
+	"os"
 	"time"
 
+	"github.com/Fedosin/libkpa/api"
 	libkpaconfig "github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/loadgen"
 	"github.com/Fedosin/libkpa/manager"
 )
 
+// workload returns a per-second stream of a metric with normal load, a high
+// load spike, gradually decreasing load and normal load again.
+func workload(normal, high, decreasing, noise float64, seed int64) (*loadgen.Stream, error) {
+	pattern := loadgen.Phases(
+		loadgen.Phase{Name: "normal", Duration: 5 * time.Second, Pattern: loadgen.Constant(normal)},
+		loadgen.Phase{Name: "high load spike", Duration: 6 * time.Second, Pattern: loadgen.Constant(high)},
+		loadgen.Phase{Name: "decreasing", Duration: 6 * time.Second, Pattern: loadgen.Constant(decreasing)},
+		loadgen.Phase{Name: "back to normal", Pattern: loadgen.Constant(normal)},
+	)
+	return loadgen.NewStream(pattern, time.Now(), time.Second, noise, seed)
+}
+
 func main() {
+	// Simulate metric collection and scaling loop
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	fmt.Println("Press Ctrl+C to stop")
+	if err := run(os.Stdout, ticker.C); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run records the simulated workload and scales once per tick for 21 ticks
+// or until ticks is closed. The ticks are the current time, so tests can
+// drive the simulation with a fake clock.
+func run(out io.Writer, ticks <-chan time.Time) error {
 	// Configure autoscaler settings
 	config := libkpaconfig.NewDefaultAutoscalerConfig()
 	config.StableWindow = 6 * time.Second
@@ -40,7 +69,7 @@ func main() {
 	// Create scalers for different metrics
 	cpuScaler, err := manager.NewScaler("cpu", *config, "linear")
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	// Memory scaler with weighted algorithm for faster response
@@ -48,7 +77,7 @@ func main() {
 	memConfig.TargetValue = 270.0 // Target 270 Mb memory per pod
 	memoryScaler, err := manager.NewScaler("memory", *memConfig, "weighted")
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	// Request rate scaler
@@ -56,97 +85,88 @@ func main() {
 	reqConfig.TargetValue = 1000.0 // Target 1000 requests/sec per pod
 	requestScaler, err := manager.NewScaler("requests", *reqConfig, "weighted")
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	// Create manager with initial scalers
 	mgr := manager.NewManager(2, 20, cpuScaler, memoryScaler, requestScaler)
+	defer mgr.Close()
 
-	// Simulate metric collection and scaling loop
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-
-	fmt.Println("Starting autoscaler simulation...")
-	fmt.Println("Press Ctrl+C to stop")
+	fmt.Fprintln(out, "Starting autoscaler simulation...")
 
 	currentPods := int32(5) // In real usage, get from Kubernetes
 
 	// Simulate some workload patterns
+	cpuLoad, err := workload(50, 187.5, 70, 10, 1) // mCPU
+	if err != nil {
+		return err
+	}
+	memLoad, err := workload(55, 180, 62.5, 5, 2) // Mb
+	if err != nil {
+		return err
+	}
+	requestLoad, err := workload(600, 25250, 900, 100, 3) // req/s
+	if err != nil {
+		return err
+	}
 	iteration := 0
-	for range ticker.C {
+	for now := range ticks {
 		iteration++
-		now := time.Now()
 
 		// Simulate varying workload
-		var cpuUsage, memUsage, reqRate float64
-
-		switch {
-		case iteration < 6:
-			// Normal load
-			cpuUsage = 40.0 + rand.Float64()*20  // 40-60 mCPU
-			memUsage = 50.0 + rand.Float64()*10  // 50-60 Mb
-			reqRate = 500.0 + rand.Float64()*200 // 500-700 req/s
-
-		case iteration < 12:
-			// High load spike
-			cpuUsage = 180.0 + rand.Float64()*15   // 180-195 mCPU
-			memUsage = 170.0 + rand.Float64()*20   // 170-190 Mb
-			reqRate = 25000.0 + rand.Float64()*500 // 25000-25500 req/s
-
-		case iteration < 18:
-			// Gradually decreasing
-			cpuUsage = 60.0 + rand.Float64()*20  // 60-80 mCPU
-			memUsage = 55.0 + rand.Float64()*15  // 55-70 Mb
-			reqRate = 800.0 + rand.Float64()*200 // 800-1000 req/s
-
-		default:
-			// Back to normal
-			cpuUsage = 40.0 + rand.Float64()*20  // 40-60 mCPU
-			memUsage = 50.0 + rand.Float64()*10  // 50-60 Mb
-			reqRate = 500.0 + rand.Float64()*200 // 500-700 req/s
-		}
+		_, cpuUsage := cpuLoad.Next()
+		_, memUsage := memLoad.Next()
+		_, reqRate := requestLoad.Next()
 
-		// Record metrics (multiply by current pod count to get total)
-		err = mgr.Record("cpu", cpuUsage*float64(currentPods), now)
+		// Record metrics (the usage is measured per pod, the scalers expect totals)
+		totalCPU := api.PerPodValue(cpuUsage).Total(api.PodCount(currentPods))
+		totalMem := api.PerPodValue(memUsage).Total(api.PodCount(currentPods))
+
+		err = mgr.Record("cpu", float64(totalCPU), now)
 		if err != nil {
-			log.Printf("Record error: %v", err)
+			fmt.Fprintf(out, "Record error: %v\n", err)
 		}
-		err = mgr.Record("memory", memUsage*float64(currentPods), now)
+		err = mgr.Record("memory", float64(totalMem), now)
 		if err != nil {
-			log.Printf("Record error: %v", err)
+			fmt.Fprintf(out, "Record error: %v\n", err)
 		}
 
 		// Record total servicerequests per second
 		err = mgr.Record("requests", reqRate, now)
 		if err != nil {
-			log.Printf("Record error: %v", err)
+			fmt.Fprintf(out, "Record error: %v\n", err)
 		}
 
 		// Calculate desired scale
-		desiredPods := mgr.Scale(currentPods, now)
+		desiredPods, err := mgr.Scale(currentPods, now)
+		if err != nil {
+			fmt.Fprintf(out, "Scale error: %v\n", err)
+			continue
+		}
 
 		// Print status
-		fmt.Printf("\n[%s] Iteration %d:\n", now.Format("15:04:05"), iteration)
-		fmt.Printf("  Metrics: Total CPU=%.0f mCPU, Total Memory=%.0f Mb, Total Requests=%.0f/s\n",
-			cpuUsage*float64(currentPods), memUsage*float64(currentPods), reqRate)
-		fmt.Printf("  Current pods: %d → Desired pods: %d\n", currentPods, desiredPods)
+		fmt.Fprintf(out, "\n[%s] Iteration %d:\n", now.Format("15:04:05"), iteration)
+		fmt.Fprintf(out, "  Metrics: Total CPU=%.0f mCPU, Total Memory=%.0f Mb, Total Requests=%.0f/s\n",
+			totalCPU, totalMem, reqRate)
+		fmt.Fprintf(out, "  Current pods: %d → Desired pods: %d\n", currentPods, desiredPods)
 
 		// Update current pods to the desired pods
 		currentPods = desiredPods
 
 		if iteration == 5 {
-			fmt.Println("\n  >>> Adding more load")
+			fmt.Fprintln(out, "\n  >>> Adding more load")
 		}
 
 		if iteration == 6 {
-			fmt.Println("\n  >>> Adjusting scale bounds for off-peak")
+			fmt.Fprintln(out, "\n  >>> Adjusting scale bounds for off-peak")
 			mgr.SetMinScale(1)
 			mgr.SetMaxScale(30)
 		}
 
 		if iteration > 20 {
-			fmt.Println("\nSimulation complete!")
+			fmt.Fprintln(out, "\nSimulation complete!")
 			break
 		}
 	}
+	return nil
 }
@@ -0,0 +1,59 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	ticks := make(chan time.Time, 30)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= cap(ticks); i++ {
+		ticks <- start.Add(time.Duration(i) * time.Second)
+	}
+	close(ticks)
+
+	var out bytes.Buffer
+	if err := run(&out, ticks); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"Iteration 21:",
+		// The spike is capped at the initial max scale of 20 ...
+		"Current pods: 2 → Desired pods: 20",
+		">>> Adjusting scale bounds for off-peak",
+		// ... and exceeds it once the bounds are raised.
+		"Current pods: 20 → Desired pods: 26",
+		"Simulation complete!",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "error") {
+		t.Errorf("output contains errors:\n%s", got)
+	}
+	if strings.Contains(got, "Iteration 22:") {
+		t.Errorf("simulation didn't stop after 21 iterations:\n%s", got)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake provides fake implementations of the main libkpa components
+// with programmable responses that record how they were called, for unit
+// tests of controllers built on the library. All fakes are safe for
+// concurrent use.
+package fake
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// ScaleCall is a call of Autoscaler.Scale.
+type ScaleCall struct {
+	Snapshot api.MetricSnapshot
+	Now      time.Time
+}
+
+// Autoscaler is a fake api.Autoscaler and api.Recommender. Scale returns the
+// programmed recommendations in order and keeps returning the last one once
+// all were returned. Without programmed recommendations it returns a valid
+// recommendation of the ready pods of the snapshot, i.e. keeps the scale.
+type Autoscaler struct {
+	mu              sync.Mutex
+	config          api.AutoscalerConfig
+	recommendations []api.ScaleRecommendation
+	scaleFunc       func(api.MetricSnapshot, time.Time) api.ScaleRecommendation
+	updateErr       error
+
+	scaleCalls []ScaleCall
+	updates    []api.AutoscalerConfig
+}
+
+var (
+	_ api.Autoscaler  = (*Autoscaler)(nil)
+	_ api.Recommender = (*Autoscaler)(nil)
+)
+
+// NewAutoscaler creates a fake autoscaler with the given configuration
+// returning the given recommendations.
+func NewAutoscaler(config api.AutoscalerConfig, recommendations ...api.ScaleRecommendation) *Autoscaler {
+	return &Autoscaler{config: config, recommendations: recommendations}
+}
+
+// SetRecommendations replaces the recommendations returned by Scale.
+func (a *Autoscaler) SetRecommendations(recommendations ...api.ScaleRecommendation) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.recommendations = recommendations
+}
+
+// SetScaleFunc makes Scale return the result of f, taking precedence over
+// the programmed recommendations. Nil restores them.
+func (a *Autoscaler) SetScaleFunc(f func(snapshot api.MetricSnapshot, now time.Time) api.ScaleRecommendation) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.scaleFunc = f
+}
+
+// SetUpdateError makes Update return err without changing the
+// configuration. Nil makes updates succeed again.
+func (a *Autoscaler) SetUpdateError(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.updateErr = err
+}
+
+// Scale records the call and returns the next programmed recommendation.
+func (a *Autoscaler) Scale(snapshot api.MetricSnapshot, now time.Time) api.ScaleRecommendation {
+	a.mu.Lock()
+	a.scaleCalls = append(a.scaleCalls, ScaleCall{Snapshot: snapshot, Now: now})
+	scaleFunc := a.scaleFunc
+	if scaleFunc == nil {
+		defer a.mu.Unlock()
+		switch len(a.recommendations) {
+		case 0:
+			return api.ScaleRecommendation{DesiredPodCount: snapshot.ReadyPodCount(), ScaleValid: true}
+		case 1:
+			return a.recommendations[0]
+		}
+		rec := a.recommendations[0]
+		a.recommendations = a.recommendations[1:]
+		return rec
+	}
+	a.mu.Unlock()
+
+	// Call f without holding the lock, so it can use the fake.
+	return scaleFunc(snapshot, now)
+}
+
+// Update records the configuration and applies it unless an update error
+// is set.
+func (a *Autoscaler) Update(config api.AutoscalerConfig) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.updates = append(a.updates, config)
+	if a.updateErr != nil {
+		return a.updateErr
+	}
+	a.config = config
+	return nil
+}
+
+// GetConfig returns the current configuration.
+func (a *Autoscaler) GetConfig() api.AutoscalerConfig {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.config
+}
+
+// GetSpec returns the current configuration.
+func (a *Autoscaler) GetSpec() api.AutoscalerConfig {
+	return a.GetConfig()
+}
+
+// ScaleCalls returns the calls of Scale in order.
+func (a *Autoscaler) ScaleCalls() []ScaleCall {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]ScaleCall(nil), a.scaleCalls...)
+}
+
+// Updates returns the configurations passed to Update in order, including
+// rejected ones.
+func (a *Autoscaler) Updates() []api.AutoscalerConfig {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]api.AutoscalerConfig(nil), a.updates...)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+	"github.com/Fedosin/libkpa/metrics"
+)
+
+// Collector is a fake api.MetricCollector returning programmed pod metrics.
+// CreateSnapshot sums the values of the pods into both the stable and the
+// burst value, with a ready pod per sample.
+type Collector struct {
+	mu      sync.Mutex
+	metrics []api.Metrics
+	err     error
+
+	collectCalls int
+	snapshots    []api.MetricSnapshot
+}
+
+var _ api.MetricCollector = (*Collector)(nil)
+
+// NewCollector creates a fake collector returning the given pod metrics.
+func NewCollector(metrics ...api.Metrics) *Collector {
+	return &Collector{metrics: metrics}
+}
+
+// SetMetrics replaces the pod metrics returned by CollectMetrics.
+func (c *Collector) SetMetrics(metrics ...api.Metrics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics = metrics
+}
+
+// SetError makes CollectMetrics return err instead of the metrics. Nil
+// makes it succeed again.
+func (c *Collector) SetError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+// CollectMetrics returns a copy of the programmed metrics or error.
+func (c *Collector) CollectMetrics(ctx context.Context) ([]api.Metrics, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.collectCalls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return slices.Clone(c.metrics), nil
+}
+
+// CreateSnapshot returns a snapshot of the summed values of the pods.
+func (c *Collector) CreateSnapshot(pods []api.Metrics, now time.Time) api.MetricSnapshot {
+	var total float64
+	for _, pod := range pods {
+		total += pod.Value
+	}
+	snapshot := metrics.NewMetricSnapshot(total, total, int32(len(pods)), now)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snapshots = append(c.snapshots, snapshot)
+	return snapshot
+}
+
+// CollectCalls returns the number of calls of CollectMetrics.
+func (c *Collector) CollectCalls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.collectCalls
+}
+
+// Snapshots returns the snapshots created by CreateSnapshot in order.
+func (c *Collector) Snapshots() []api.MetricSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.snapshots)
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+	"github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/manager"
+	"github.com/Fedosin/libkpa/metrics"
+)
+
+// scaleManager is the subset of the manager a controller might depend on.
+type scaleManager interface {
+	Record(name string, value float64, t time.Time) error
+	Scale(readyPods int32, now time.Time) (int32, error)
+	GetMaxScale() int32
+}
+
+var (
+	_ scaleManager = (*manager.Manager)(nil)
+	_ scaleManager = (*Manager)(nil)
+)
+
+func TestAutoscaler(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := *config.NewDefaultAutoscalerConfig()
+	a := NewAutoscaler(cfg)
+	snapshot := metrics.NewMetricSnapshot(10, 10, 3, now)
+
+	if got := a.Scale(snapshot, now); got != (api.ScaleRecommendation{DesiredPodCount: 3, ScaleValid: true}) {
+		t.Errorf("Scale without recommendations = %+v, want to keep 3 pods", got)
+	}
+
+	a.SetRecommendations(api.ScaleRecommendation{DesiredPodCount: 5, ScaleValid: true}, api.ScaleRecommendation{DesiredPodCount: 7, ScaleValid: true})
+	var got []int32
+	for range 3 {
+		got = append(got, a.Scale(snapshot, now).DesiredPodCount)
+	}
+	if want := []int32{5, 7, 7}; !slices.Equal(got, want) {
+		t.Errorf("programmed recommendations = %v, want %v", got, want)
+	}
+
+	a.SetScaleFunc(func(s api.MetricSnapshot, _ time.Time) api.ScaleRecommendation {
+		return api.ScaleRecommendation{DesiredPodCount: s.ReadyPodCount() * 2}
+	})
+	if got := a.Scale(snapshot, now).DesiredPodCount; got != 6 {
+		t.Errorf("Scale with a scale func = %d, want 6", got)
+	}
+	if got := len(a.ScaleCalls()); got != 5 {
+		t.Errorf("len(ScaleCalls()) = %d, want 5", got)
+	}
+
+	updated := cfg
+	updated.MaxScale = 10
+	if err := a.Update(updated); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if got := a.GetSpec().MaxScale; got != 10 {
+		t.Errorf("MaxScale after Update = %d, want 10", got)
+	}
+	errUpdate := errors.New("invalid")
+	a.SetUpdateError(errUpdate)
+	if err := a.Update(cfg); !errors.Is(err, errUpdate) {
+		t.Errorf("Update error = %v, want %v", err, errUpdate)
+	}
+	if got := a.GetConfig().MaxScale; got != 10 {
+		t.Errorf("MaxScale after a failed Update = %d, want 10", got)
+	}
+	if got := len(a.Updates()); got != 2 {
+		t.Errorf("len(Updates()) = %d, want 2", got)
+	}
+}
+
+func TestManager(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewManager(1, 10)
+
+	if err := m.Record("rps", 42, now); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if got, want := m.Records(), []RecordCall{{Name: "rps", Value: 42, Time: now}}; !slices.Equal(got, want) {
+		t.Errorf("Records() = %v, want %v", got, want)
+	}
+
+	// Without programmed counts the ready pods are kept within the bounds.
+	for readyPods, want := range map[int32]int32{0: 1, 5: 5, 20: 10} {
+		if got, _ := m.Scale(readyPods, now); got != want {
+			t.Errorf("Scale(%d) = %d, want %d", readyPods, got, want)
+		}
+	}
+
+	m.SetDesired(3, 4)
+	if got, _ := m.PeekScale(1, now); got != 3 {
+		t.Errorf("PeekScale = %d, want 3", got)
+	}
+	var got []int32
+	for range 3 {
+		desired, _ := m.Scale(1, now)
+		got = append(got, desired)
+	}
+	if want := []int32{3, 4, 4}; !slices.Equal(got, want) {
+		t.Errorf("programmed counts = %v, want %v", got, want)
+	}
+	if got := len(m.ScaleCalls()); got != 6 {
+		t.Errorf("len(ScaleCalls()) = %d, want 6", got)
+	}
+
+	errScale := errors.New("no metrics")
+	m.SetScaleFunc(func(int32, time.Time) (int32, error) { return 0, errScale })
+	if _, err := m.Scale(1, now); !errors.Is(err, errScale) {
+		t.Errorf("Scale error = %v, want %v", err, errScale)
+	}
+
+	_ = m.Close()
+	if err := m.Record("rps", 1, now); !errors.Is(err, manager.ErrClosed) {
+		t.Errorf("Record after Close error = %v, want ErrClosed", err)
+	}
+	if _, err := m.Scale(1, now); !errors.Is(err, manager.ErrClosed) {
+		t.Errorf("Scale after Close error = %v, want ErrClosed", err)
+	}
+}
+
+func TestTransmitter(t *testing.T) {
+	ctx := context.Background()
+	tr := NewTransmitter()
+	tr.RecordDesiredPods(ctx, 3)
+	tr.RecordStableValue(ctx, "concurrency", 10)
+	tr.RecordBurstMode(ctx, true)
+	tr.RecordDesiredPods(ctx, 5)
+	tr.RecordHistogram(ctx, "scale_latency_seconds", 0.001)
+
+	if got, want := tr.Values("desired_pods"), []float64{3, 5}; !slices.Equal(got, want) {
+		t.Errorf("Values(desired_pods) = %v, want %v", got, want)
+	}
+	if got, ok := tr.Last("stable_concurrency"); !ok || got != 10 {
+		t.Errorf("Last(stable_concurrency) = %v, %v, want 10, true", got, ok)
+	}
+	if got, ok := tr.Last("burst_mode"); !ok || got != 1 {
+		t.Errorf("Last(burst_mode) = %v, %v, want 1, true", got, ok)
+	}
+	if got := tr.Observations(); len(got) != 5 || !got[4].Histogram {
+		t.Errorf("Observations() = %v, want 5 ending with a histogram observation", got)
+	}
+
+	tr.Reset()
+	if _, ok := tr.Last("desired_pods"); ok {
+		t.Error("Last after Reset found a value")
+	}
+}
+
+func TestCollector(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewCollector(api.Metrics{Timestamp: now, Value: 10}, api.Metrics{Timestamp: now, Value: 20})
+
+	pods, err := c.CollectMetrics(ctx)
+	if err != nil {
+		t.Fatalf("CollectMetrics failed: %v", err)
+	}
+	snapshot := c.CreateSnapshot(pods, now)
+	if snapshot.StableValue() != 30 || snapshot.BurstValue() != 30 || snapshot.ReadyPodCount() != 2 {
+		t.Errorf("snapshot = %v/%v/%d, want 30/30/2", snapshot.StableValue(), snapshot.BurstValue(), snapshot.ReadyPodCount())
+	}
+
+	errCollect := errors.New("unreachable")
+	c.SetError(errCollect)
+	if _, err := c.CollectMetrics(ctx); !errors.Is(err, errCollect) {
+		t.Errorf("CollectMetrics error = %v, want %v", err, errCollect)
+	}
+	if got := c.CollectCalls(); got != 2 {
+		t.Errorf("CollectCalls() = %d, want 2", got)
+	}
+	if got := len(c.Snapshots()); got != 1 {
+		t.Errorf("len(Snapshots()) = %d, want 1", got)
+	}
+}
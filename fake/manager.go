@@ -0,0 +1,192 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/manager"
+)
+
+// RecordCall is a call of Manager.Record.
+type RecordCall struct {
+	Name  string
+	Value float64
+	Time  time.Time
+}
+
+// ManagerScaleCall is a call of Manager.Scale.
+type ManagerScaleCall struct {
+	ReadyPods int32
+	Now       time.Time
+}
+
+// Manager is a fake of the scaling methods of manager.Manager, so it
+// satisfies interfaces a controller declares for the subset of the manager
+// it uses. Scale returns the programmed replica counts in order and keeps
+// returning the last one once all were returned. Without programmed counts
+// it returns the ready pods within the replica bounds. After Close, Record,
+// Scale and PeekScale return manager.ErrClosed, like the real manager.
+type Manager struct {
+	mu          sync.Mutex
+	minReplicas int32
+	maxReplicas int32
+	desired     []int32
+	scaleFunc   func(readyPods int32, now time.Time) (int32, error)
+	recordErr   error
+	closed      bool
+
+	records    []RecordCall
+	scaleCalls []ManagerScaleCall
+}
+
+// NewManager creates a fake manager with the given replica bounds returning
+// the given replica counts.
+func NewManager(minReplicas, maxReplicas int32, desired ...int32) *Manager {
+	return &Manager{minReplicas: minReplicas, maxReplicas: maxReplicas, desired: desired}
+}
+
+// SetDesired replaces the replica counts returned by Scale.
+func (m *Manager) SetDesired(desired ...int32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.desired = desired
+}
+
+// SetScaleFunc makes Scale and PeekScale return the result of f, taking
+// precedence over the programmed replica counts, e.g. to return errors. Nil
+// restores them.
+func (m *Manager) SetScaleFunc(f func(readyPods int32, now time.Time) (int32, error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scaleFunc = f
+}
+
+// SetRecordError makes Record return err. The values are recorded as calls
+// regardless. Nil makes Record succeed again.
+func (m *Manager) SetRecordError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordErr = err
+}
+
+// Record records the call.
+func (m *Manager) Record(name string, value float64, t time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return manager.ErrClosed
+	}
+	m.records = append(m.records, RecordCall{Name: name, Value: value, Time: t})
+	return m.recordErr
+}
+
+// Scale records the call and returns the next programmed replica count.
+func (m *Manager) Scale(readyPods int32, now time.Time) (int32, error) {
+	return m.scale(readyPods, now, true)
+}
+
+// PeekScale returns the replica count the next Scale call would return
+// without recording the call or consuming a programmed count.
+func (m *Manager) PeekScale(readyPods int32, now time.Time) (int32, error) {
+	return m.scale(readyPods, now, false)
+}
+
+func (m *Manager) scale(readyPods int32, now time.Time, consume bool) (int32, error) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return 0, manager.ErrClosed
+	}
+	if consume {
+		m.scaleCalls = append(m.scaleCalls, ManagerScaleCall{ReadyPods: readyPods, Now: now})
+	}
+	scaleFunc := m.scaleFunc
+	if scaleFunc == nil {
+		defer m.mu.Unlock()
+		switch len(m.desired) {
+		case 0:
+			desired := max(readyPods, m.minReplicas)
+			if m.maxReplicas > 0 {
+				desired = min(desired, m.maxReplicas)
+			}
+			return desired, nil
+		case 1:
+			return m.desired[0], nil
+		}
+		desired := m.desired[0]
+		if consume {
+			m.desired = m.desired[1:]
+		}
+		return desired, nil
+	}
+	m.mu.Unlock()
+
+	// Call f without holding the lock, so it can use the fake.
+	return scaleFunc(readyPods, now)
+}
+
+// GetMinScale returns the minimum replica count.
+func (m *Manager) GetMinScale() int32 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.minReplicas
+}
+
+// GetMaxScale returns the maximum replica count.
+func (m *Manager) GetMaxScale() int32 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.maxReplicas
+}
+
+// SetMinScale sets the minimum replica count.
+func (m *Manager) SetMinScale(minValue int32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.minReplicas = minValue
+}
+
+// SetMaxScale sets the maximum replica count.
+func (m *Manager) SetMaxScale(maxValue int32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxReplicas = maxValue
+}
+
+// Close makes later calls return manager.ErrClosed.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+// Records returns the calls of Record in order.
+func (m *Manager) Records() []RecordCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]RecordCall(nil), m.records...)
+}
+
+// ScaleCalls returns the calls of Scale in order.
+func (m *Manager) ScaleCalls() []ManagerScaleCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ManagerScaleCall(nil), m.scaleCalls...)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Fedosin/libkpa/transmitter"
+)
+
+// Observation is a value recorded by a Transmitter.
+type Observation struct {
+	Name  string
+	Value float64
+
+	// Histogram is true for observations recorded with RecordHistogram.
+	Histogram bool
+}
+
+// Transmitter is a fake transmitter.MetricTransmitter and
+// transmitter.HistogramRecorder recording all observations. The typed
+// methods record gauges named like the ones of transmitter.LogTransmitter,
+// e.g. desired_pods and stable_<metric>, with burst_mode being 0 or 1.
+type Transmitter struct {
+	mu           sync.Mutex
+	observations []Observation
+}
+
+var (
+	_ transmitter.MetricTransmitter = (*Transmitter)(nil)
+	_ transmitter.HistogramRecorder = (*Transmitter)(nil)
+)
+
+// NewTransmitter creates a fake transmitter.
+func NewTransmitter() *Transmitter {
+	return &Transmitter{}
+}
+
+// RecordDesiredPods records the desired_pods gauge.
+func (t *Transmitter) RecordDesiredPods(ctx context.Context, value int32) {
+	t.RecordGauge(ctx, "desired_pods", float64(value))
+}
+
+// RecordStableValue records the stable_<metric> gauge.
+func (t *Transmitter) RecordStableValue(ctx context.Context, metric string, value float64) {
+	t.RecordGauge(ctx, "stable_"+metric, value)
+}
+
+// RecordBurstValue records the burst_<metric> gauge.
+func (t *Transmitter) RecordBurstValue(ctx context.Context, metric string, value float64) {
+	t.RecordGauge(ctx, "burst_"+metric, value)
+}
+
+// RecordTargetValue records the target_<metric> gauge.
+func (t *Transmitter) RecordTargetValue(ctx context.Context, metric string, value float64) {
+	t.RecordGauge(ctx, "target_"+metric, value)
+}
+
+// RecordBurstMode records the burst_mode gauge.
+func (t *Transmitter) RecordBurstMode(ctx context.Context, inBurst bool) {
+	value := 0.0
+	if inBurst {
+		value = 1
+	}
+	t.RecordGauge(ctx, "burst_mode", value)
+}
+
+// RecordGauge records a gauge observation.
+func (t *Transmitter) RecordGauge(ctx context.Context, name string, value float64) {
+	t.record(Observation{Name: name, Value: value})
+}
+
+// RecordHistogram records a histogram observation.
+func (t *Transmitter) RecordHistogram(ctx context.Context, name string, value float64) {
+	t.record(Observation{Name: name, Value: value, Histogram: true})
+}
+
+func (t *Transmitter) record(o Observation) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.observations = append(t.observations, o)
+}
+
+// Observations returns all observations in order.
+func (t *Transmitter) Observations() []Observation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Observation(nil), t.observations...)
+}
+
+// Values returns the values observed for the metric name in order.
+func (t *Transmitter) Values(name string) []float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var values []float64
+	for _, o := range t.observations {
+		if o.Name == name {
+			values = append(values, o.Value)
+		}
+	}
+	return values
+}
+
+// Last returns the latest value observed for the metric name, and whether
+// there was one.
+func (t *Transmitter) Last(name string) (float64, bool) {
+	values := t.Values(name)
+	if len(values) == 0 {
+		return 0, false
+	}
+	return values[len(values)-1], true
+}
+
+// Reset forgets all observations.
+func (t *Transmitter) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.observations = nil
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package faultinject degrades metric telemetry on purpose, so controllers
+// built on the library can be tested against dropped, duplicated and delayed
+// samples and clock jumps. The wrappers sit between the source of the samples
+// and a metric window or collector. Faults are drawn from a seeded source, so
+// runs with the same seed inject the same faults.
+package faultinject
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// Faults describes the faults to inject into a stream of samples.
+type Faults struct {
+	// DropRate is the probability in [0, 1] that a sample is lost.
+	DropRate float64
+
+	// DuplicateRate is the probability in [0, 1] that a sample is delivered
+	// twice.
+	DuplicateRate float64
+
+	// DelayRate is the probability in [0, 1] that a sample arrives late. A
+	// delayed sample keeps its timestamp, but is delivered only once a
+	// sample or query at least MaxDelay later is seen.
+	DelayRate float64
+	MaxDelay  time.Duration
+
+	// ClockJumps shift the timestamps of the samples, as if the clock of the
+	// source jumped.
+	ClockJumps []ClockJump
+}
+
+// ClockJump moves the clock of the sample source by Offset at At. Samples
+// taken at or after At are stamped Offset later, or earlier if Offset is
+// negative. Jumps add up.
+type ClockJump struct {
+	At     time.Time
+	Offset time.Duration
+}
+
+// Stats counts the injected faults.
+type Stats struct {
+	Dropped    uint64
+	Duplicated uint64
+	Delayed    uint64
+	Shifted    uint64
+}
+
+func (f Faults) validate() error {
+	rates := []struct {
+		name string
+		rate float64
+	}{{"drop", f.DropRate}, {"duplicate", f.DuplicateRate}, {"delay", f.DelayRate}}
+	for _, r := range rates {
+		if !(r.rate >= 0 && r.rate <= 1) {
+			return fmt.Errorf("%s rate = %v, must be in [0, 1] interval", r.name, r.rate)
+		}
+	}
+	if f.MaxDelay < 0 {
+		return fmt.Errorf("max delay = %v, must be at least 0", f.MaxDelay)
+	}
+	return nil
+}
+
+// injector applies faults to samples. The caller must serialize access.
+type injector struct {
+	faults  Faults
+	rand    *rand.Rand
+	pending []delayedSample
+	stats   Stats
+}
+
+type delayedSample struct {
+	due    time.Time
+	sample api.Metrics
+}
+
+func newInjector(faults Faults, seed int64) (*injector, error) {
+	if err := faults.validate(); err != nil {
+		return nil, err
+	}
+	faults.ClockJumps = slices.Clone(faults.ClockJumps)
+	return &injector{faults: faults, rand: rand.New(rand.NewSource(seed))}, nil
+}
+
+// inject returns the samples to deliver when sample arrives: the delayed
+// samples that are due, followed by sample unless it is dropped or delayed.
+func (in *injector) inject(sample api.Metrics) []api.Metrics {
+	due := in.release(sample.Timestamp)
+
+	sample.Timestamp = in.shift(sample.Timestamp)
+	switch {
+	case in.rand.Float64() < in.faults.DropRate:
+		in.stats.Dropped++
+	case in.rand.Float64() < in.faults.DelayRate && in.faults.MaxDelay > 0:
+		in.stats.Delayed++
+		delay := time.Duration(in.rand.Int63n(int64(in.faults.MaxDelay))) + 1
+		in.pending = append(in.pending, delayedSample{due: sample.Timestamp.Add(delay), sample: sample})
+	case in.rand.Float64() < in.faults.DuplicateRate:
+		in.stats.Duplicated++
+		due = append(due, sample, sample)
+	default:
+		due = append(due, sample)
+	}
+	return due
+}
+
+// release returns the delayed samples due at now in the order they were
+// taken.
+func (in *injector) release(now time.Time) []api.Metrics {
+	var due []api.Metrics
+	pending := in.pending[:0]
+	for _, d := range in.pending {
+		if d.due.After(now) {
+			pending = append(pending, d)
+		} else {
+			due = append(due, d.sample)
+		}
+	}
+	in.pending = pending
+	return due
+}
+
+// shift applies the clock jumps to a timestamp.
+func (in *injector) shift(t time.Time) time.Time {
+	shifted := t
+	for _, j := range in.faults.ClockJumps {
+		if !t.Before(j.At) {
+			shifted = shifted.Add(j.Offset)
+		}
+	}
+	if !shifted.Equal(t) {
+		in.stats.Shifted++
+	}
+	return shifted
+}
+
+// Aggregator is an api.MetricAggregator injecting faults into the values
+// recorded into another aggregator. It is safe for concurrent use if the
+// wrapped aggregator is.
+type Aggregator struct {
+	mu       sync.Mutex
+	next     api.MetricAggregator
+	injector *injector
+}
+
+var _ api.MetricAggregator = (*Aggregator)(nil)
+
+// NewAggregator wraps next, injecting faults drawn from a source seeded with
+// seed into recorded values.
+func NewAggregator(next api.MetricAggregator, faults Faults, seed int64) (*Aggregator, error) {
+	if next == nil {
+		return nil, fmt.Errorf("aggregator cannot be nil")
+	}
+	in, err := newInjector(faults, seed)
+	if err != nil {
+		return nil, err
+	}
+	return &Aggregator{next: next, injector: in}, nil
+}
+
+// Record records the value, subject to the faults, and any delayed values
+// that are due.
+func (a *Aggregator) Record(t time.Time, value float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, s := range a.injector.inject(api.Metrics{Timestamp: t, Value: value}) {
+		a.next.Record(s.Timestamp, s.Value)
+	}
+}
+
+// WindowAverage records the delayed values due at now and returns the
+// average of the wrapped aggregator.
+func (a *Aggregator) WindowAverage(now time.Time) float64 {
+	a.flush(now)
+	return a.next.WindowAverage(now)
+}
+
+// IsEmpty records the delayed values due at now and reports whether the
+// wrapped aggregator is empty.
+func (a *Aggregator) IsEmpty(now time.Time) bool {
+	a.flush(now)
+	return a.next.IsEmpty(now)
+}
+
+// ResizeWindow resizes the wrapped aggregator.
+func (a *Aggregator) ResizeWindow(w time.Duration) {
+	a.next.ResizeWindow(w)
+}
+
+// Stats returns the number of injected faults.
+func (a *Aggregator) Stats() Stats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.injector.stats
+}
+
+func (a *Aggregator) flush(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, s := range a.injector.release(now) {
+		a.next.Record(s.Timestamp, s.Value)
+	}
+}
+
+// Collector is an api.MetricCollector injecting faults into the per-pod
+// metrics collected by another collector. Delayed metrics are returned by
+// the first collection with a metric taken at least their delay later. It is
+// safe for concurrent use if the wrapped collector is.
+type Collector struct {
+	mu       sync.Mutex
+	next     api.MetricCollector
+	injector *injector
+}
+
+var _ api.MetricCollector = (*Collector)(nil)
+
+// NewCollector wraps next, injecting faults drawn from a source seeded with
+// seed into collected metrics.
+func NewCollector(next api.MetricCollector, faults Faults, seed int64) (*Collector, error) {
+	if next == nil {
+		return nil, fmt.Errorf("collector cannot be nil")
+	}
+	in, err := newInjector(faults, seed)
+	if err != nil {
+		return nil, err
+	}
+	return &Collector{next: next, injector: in}, nil
+}
+
+// CollectMetrics collects the metrics of the wrapped collector, subject to
+// the faults, including any delayed metrics that are due.
+func (c *Collector) CollectMetrics(ctx context.Context) ([]api.Metrics, error) {
+	collected, err := c.next.CollectMetrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make([]api.Metrics, 0, len(collected))
+	for _, m := range collected {
+		result = append(result, c.injector.inject(m)...)
+	}
+	return result, nil
+}
+
+// CreateSnapshot creates a snapshot with the wrapped collector.
+func (c *Collector) CreateSnapshot(metrics []api.Metrics, now time.Time) api.MetricSnapshot {
+	return c.next.CreateSnapshot(metrics, now)
+}
+
+// Stats returns the number of injected faults.
+func (c *Collector) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.injector.stats
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinject
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+	"github.com/Fedosin/libkpa/metrics"
+)
+
+// recorder is an aggregator remembering the recorded samples.
+type recorder struct {
+	samples []api.Metrics
+}
+
+func (r *recorder) Record(t time.Time, value float64) {
+	r.samples = append(r.samples, api.Metrics{Timestamp: t, Value: value})
+}
+func (r *recorder) WindowAverage(time.Time) float64 { return 0 }
+func (r *recorder) IsEmpty(time.Time) bool          { return len(r.samples) == 0 }
+func (r *recorder) ResizeWindow(time.Duration)      {}
+
+func (r *recorder) values() []float64 {
+	var values []float64
+	for _, s := range r.samples {
+		values = append(values, s.Value)
+	}
+	return values
+}
+
+func TestAggregator(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		faults Faults
+		want   []float64
+		stats  Stats
+	}{{
+		name: "no faults",
+		want: []float64{1, 2, 3, 4},
+	}, {
+		name:   "drop all",
+		faults: Faults{DropRate: 1},
+		stats:  Stats{Dropped: 4},
+	}, {
+		name:   "duplicate all",
+		faults: Faults{DuplicateRate: 1},
+		want:   []float64{1, 1, 2, 2, 3, 3, 4, 4},
+		stats:  Stats{Duplicated: 4},
+	}, {
+		name:   "delay all by a second",
+		faults: Faults{DelayRate: 1, MaxDelay: 1},
+		want:   []float64{1, 2, 3},
+		stats:  Stats{Delayed: 4},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			a, err := NewAggregator(r, tt.faults, 1)
+			if err != nil {
+				t.Fatalf("NewAggregator() error = %v", err)
+			}
+			for i := range 4 {
+				a.Record(start.Add(time.Duration(i)*time.Second), float64(i+1))
+			}
+			if got := r.values(); !slices.Equal(got, tt.want) {
+				t.Errorf("recorded %v, want %v", got, tt.want)
+			}
+			if got := a.Stats(); got != tt.stats {
+				t.Errorf("Stats() = %+v, want %+v", got, tt.stats)
+			}
+		})
+	}
+}
+
+func TestAggregatorDelay(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	window, err := metrics.NewTimeWindow(time.Minute, time.Second)
+	if err != nil {
+		t.Fatalf("NewTimeWindow() error = %v", err)
+	}
+	a, err := NewAggregator(window, Faults{DelayRate: 1, MaxDelay: 10 * time.Second}, 1)
+	if err != nil {
+		t.Fatalf("NewAggregator() error = %v", err)
+	}
+
+	a.Record(start, 10)
+	if !a.IsEmpty(start) {
+		t.Error("delayed value was recorded right away")
+	}
+	// The value arrives late, but keeps its timestamp.
+	later := start.Add(10 * time.Second)
+	if a.IsEmpty(later) {
+		t.Error("delayed value was not recorded after the maximum delay")
+	}
+	if got := a.WindowAverage(later); got <= 0 {
+		t.Errorf("WindowAverage() = %v, want the delayed value", got)
+	}
+}
+
+func TestAggregatorClockJump(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := &recorder{}
+	a, err := NewAggregator(r, Faults{ClockJumps: []ClockJump{
+		{At: start.Add(2 * time.Second), Offset: -time.Hour},
+	}}, 1)
+	if err != nil {
+		t.Fatalf("NewAggregator() error = %v", err)
+	}
+
+	for i := range 4 {
+		a.Record(start.Add(time.Duration(i)*time.Second), float64(i))
+	}
+	want := []time.Time{start, start.Add(time.Second), start.Add(2*time.Second - time.Hour), start.Add(3*time.Second - time.Hour)}
+	for i, s := range r.samples {
+		if !s.Timestamp.Equal(want[i]) {
+			t.Errorf("sample %d at %v, want %v", i, s.Timestamp, want[i])
+		}
+	}
+	if got := a.Stats().Shifted; got != 2 {
+		t.Errorf("Stats().Shifted = %d, want 2", got)
+	}
+}
+
+func TestAggregatorReproducible(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	faults := Faults{DropRate: 0.2, DuplicateRate: 0.2, DelayRate: 0.2, MaxDelay: 3 * time.Second}
+
+	run := func(seed int64) []api.Metrics {
+		r := &recorder{}
+		a, err := NewAggregator(r, faults, seed)
+		if err != nil {
+			t.Fatalf("NewAggregator() error = %v", err)
+		}
+		for i := range 100 {
+			a.Record(start.Add(time.Duration(i)*time.Second), float64(i))
+		}
+		return r.samples
+	}
+
+	first, second := run(7), run(7)
+	if !slices.Equal(first, second) {
+		t.Error("runs with the same seed differ")
+	}
+	if len(first) == 100 {
+		t.Error("no faults were injected")
+	}
+}
+
+func TestNewAggregatorErrors(t *testing.T) {
+	for _, faults := range []Faults{
+		{DropRate: -0.1},
+		{DuplicateRate: 1.5},
+		{DelayRate: 0.5, MaxDelay: -time.Second},
+	} {
+		if _, err := NewAggregator(&recorder{}, faults, 1); err == nil {
+			t.Errorf("NewAggregator(%+v) succeeded, want an error", faults)
+		}
+	}
+	if _, err := NewAggregator(nil, Faults{}, 1); err == nil {
+		t.Error("NewAggregator(nil) succeeded, want an error")
+	}
+}
+
+// staticCollector collects one metric per pod at the time of the collection.
+type staticCollector struct {
+	now  time.Time
+	pods int
+}
+
+func (c *staticCollector) CollectMetrics(context.Context) ([]api.Metrics, error) {
+	samples := make([]api.Metrics, c.pods)
+	for i := range samples {
+		samples[i] = api.Metrics{PodName: string(rune('a' + i)), Timestamp: c.now, Value: 1}
+	}
+	return samples, nil
+}
+
+func (c *staticCollector) CreateSnapshot(samples []api.Metrics, now time.Time) api.MetricSnapshot {
+	var total float64
+	for _, m := range samples {
+		total += m.Value
+	}
+	return metrics.NewMetricSnapshot(total, total, int32(c.pods), now)
+}
+
+func TestCollector(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	next := &staticCollector{now: start, pods: 3}
+	c, err := NewCollector(next, Faults{DelayRate: 1, MaxDelay: time.Nanosecond}, 1)
+	if err != nil {
+		t.Fatalf("NewCollector() error = %v", err)
+	}
+
+	// The first collection is delayed entirely and arrives with the second.
+	got, err := c.CollectMetrics(context.Background())
+	if err != nil || len(got) != 0 {
+		t.Fatalf("CollectMetrics() = %v, %v, want no metrics", got, err)
+	}
+	next.now = start.Add(time.Second)
+	got, err = c.CollectMetrics(context.Background())
+	if err != nil || len(got) != 3 {
+		t.Fatalf("CollectMetrics() = %v, %v, want the 3 delayed metrics", got, err)
+	}
+	for _, m := range got {
+		if !m.Timestamp.Equal(start) {
+			t.Errorf("delayed metric at %v, want %v", m.Timestamp, start)
+		}
+	}
+	if snapshot := c.CreateSnapshot(got, next.now); snapshot.StableValue() != 3 {
+		t.Errorf("CreateSnapshot().StableValue() = %v, want 3", snapshot.StableValue())
+	}
+	if got := c.Stats().Delayed; got != 6 {
+		t.Errorf("Stats().Delayed = %d, want 6", got)
+	}
+}
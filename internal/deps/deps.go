@@ -0,0 +1,98 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package deps checks that the core module of the library only depends on
+// the standard library. Integrations with heavy dependencies, like client-go
+// or the Prometheus client, belong in nested modules under contrib/, which
+// are skipped.
+package deps
+
+import (
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ExternalImports returns the imports of the Go files below root that are
+// neither in the standard library nor in the module, mapped to the sorted
+// files importing them. Directories with their own go.mod, i.e. nested
+// modules, and testdata directories are skipped.
+func ExternalImports(root, module string) (map[string][]string, error) {
+	imports := make(map[string][]string)
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == root {
+				return nil
+			}
+			if d.Name() == "testdata" || strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		f, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		for _, spec := range f.Imports {
+			imp, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return err
+			}
+			if isStandard(imp) || imp == module || strings.HasPrefix(imp, module+"/") {
+				continue
+			}
+			if !slices.Contains(imports[imp], rel) {
+				imports[imp] = append(imports[imp], rel)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, files := range imports {
+		slices.Sort(files)
+	}
+	return imports, nil
+}
+
+// isStandard reports whether an import path belongs to the standard
+// library, whose first path element, unlike the one of a module path, has
+// no dot.
+func isStandard(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const module = "github.com/Fedosin/libkpa"
+
+// TestCoreModuleHasNoDependencies keeps the core module free of third-party
+// dependencies, so minimal users don't pull in a large dependency tree.
+func TestCoreModuleHasNoDependencies(t *testing.T) {
+	imports, err := ExternalImports(filepath.Join("..", ".."), module)
+	if err != nil {
+		t.Fatalf("ExternalImports failed: %v", err)
+	}
+	for imp, files := range imports {
+		t.Errorf("%s is imported by %v, move the integration to a nested module under contrib/", imp, files)
+	}
+}
+
+func TestExternalImports(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("core/core.go", `package core
+
+import (
+	"context"
+
+	"github.com/Fedosin/libkpa/api"
+	"k8s.io/client-go/kubernetes"
+)
+`)
+	write("core/core_test.go", `package core
+
+import "k8s.io/client-go/kubernetes"
+`)
+	write("contrib/k8s/go.mod", "module github.com/Fedosin/libkpa/contrib/k8s\n")
+	write("contrib/k8s/k8s.go", `package k8s
+
+import "github.com/prometheus/client_golang/prometheus"
+`)
+	write("core/testdata/x.go", `package x
+
+import "example.com/ignored"
+`)
+
+	imports, err := ExternalImports(root, module)
+	if err != nil {
+		t.Fatalf("ExternalImports failed: %v", err)
+	}
+	if len(imports) != 1 {
+		t.Fatalf("ExternalImports = %v, want only client-go", imports)
+	}
+	files := imports["k8s.io/client-go/kubernetes"]
+	if want := []string{filepath.Join("core", "core.go"), filepath.Join("core", "core_test.go")}; !slices.Equal(files, want) {
+		t.Errorf("client-go importers = %v, want %v", files, want)
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package protowire provides the protobuf wire format encoding shared by the
+// hand-written encoders of the library, so it doesn't depend on the
+// protobuf module.
+package protowire
+
+import "encoding/binary"
+
+// Protobuf wire types.
+const (
+	Varint  = 0
+	Fixed64 = 1
+	Bytes   = 2
+	Fixed32 = 5
+)
+
+// AppendTag appends a protobuf field tag.
+func AppendTag(b []byte, field, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wireType))
+}
+
+// AppendBytesField appends a length-delimited protobuf field.
+func AppendBytesField(b []byte, field int, data []byte) []byte {
+	b = binary.AppendUvarint(AppendTag(b, field, Bytes), uint64(len(data)))
+	return append(b, data...)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package syncutil provides the synchronization primitives shared by the
+// packages of the library.
+package syncutil
+
+import "sync"
+
+// RWMutex is a sync.RWMutex that can be disabled for values only ever used
+// by a single goroutine. A disabled mutex doesn't lock at all, which saves
+// the cost of locking in tight loops, e.g. of simulations.
+type RWMutex struct {
+	mu       sync.RWMutex
+	disabled bool
+}
+
+// Disable turns all further calls into no-ops. It must be called before the
+// mutex is used, typically by the constructor of the value it guards.
+func (m *RWMutex) Disable() {
+	m.disabled = true
+}
+
+// Disabled reports whether the mutex was disabled.
+func (m *RWMutex) Disabled() bool {
+	return m.disabled
+}
+
+// Lock locks m for writing unless it is disabled.
+func (m *RWMutex) Lock() {
+	if !m.disabled {
+		m.mu.Lock()
+	}
+}
+
+// Unlock unlocks m for writing unless it is disabled.
+func (m *RWMutex) Unlock() {
+	if !m.disabled {
+		m.mu.Unlock()
+	}
+}
+
+// RLock locks m for reading unless it is disabled.
+func (m *RWMutex) RLock() {
+	if !m.disabled {
+		m.mu.RLock()
+	}
+}
+
+// RUnlock undoes a single RLock call unless m is disabled.
+func (m *RWMutex) RUnlock() {
+	if !m.disabled {
+		m.mu.RUnlock()
+	}
+}
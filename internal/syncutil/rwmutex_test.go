@@ -0,0 +1,48 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncutil
+
+import "testing"
+
+func TestRWMutex(t *testing.T) {
+	var m RWMutex
+	if m.Disabled() {
+		t.Fatal("zero RWMutex is disabled")
+	}
+	m.Lock()
+	if m.mu.TryRLock() {
+		t.Error("RLock succeeded while locked")
+	}
+	m.Unlock()
+	m.RLock()
+	if m.mu.TryLock() {
+		t.Error("Lock succeeded while read locked")
+	}
+	m.RUnlock()
+
+	m.Disable()
+	if !m.Disabled() {
+		t.Fatal("Disabled() = false after Disable")
+	}
+	// A disabled mutex never blocks, even on nested locks.
+	m.Lock()
+	m.Lock()
+	m.RLock()
+	if !m.mu.TryLock() {
+		t.Error("disabled RWMutex locked the underlying mutex")
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loadgen produces synthetic metric streams for benchmarks,
+// simulations and examples. A Pattern describes the shape of the load over
+// time and a Stream samples it at a fixed interval, adding uniform noise from
+// a seeded source, so that runs with the same seed are reproducible.
+package loadgen
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Pattern returns the load at the given time since the start of a stream.
+type Pattern func(elapsed time.Duration) float64
+
+// Constant returns a pattern with a constant load.
+func Constant(value float64) Pattern {
+	return func(time.Duration) float64 {
+		return value
+	}
+}
+
+// Sine returns a pattern oscillating around base by amplitude with the given
+// period, e.g. to model a daily cycle.
+func Sine(base, amplitude float64, period time.Duration) Pattern {
+	return func(elapsed time.Duration) float64 {
+		return base + amplitude*math.Sin(2*math.Pi*elapsed.Seconds()/period.Seconds())
+	}
+}
+
+// Ramp returns a pattern changing linearly from from to to within d and
+// staying at to afterwards.
+func Ramp(from, to float64, d time.Duration) Pattern {
+	return func(elapsed time.Duration) float64 {
+		if elapsed >= d {
+			return to
+		}
+		return from + (to-from)*elapsed.Seconds()/d.Seconds()
+	}
+}
+
+// Spike returns a pattern at base, except between at and at+d, where the load
+// is peak.
+func Spike(base, peak float64, at, d time.Duration) Pattern {
+	return func(elapsed time.Duration) float64 {
+		if elapsed >= at && elapsed < at+d {
+			return peak
+		}
+		return base
+	}
+}
+
+// Phase is a pattern applied for a limited duration.
+type Phase struct {
+	// Name describes the phase, e.g. "High Load".
+	Name string
+
+	// Duration is how long the phase lasts.
+	Duration time.Duration
+
+	// Pattern is the load during the phase. Its elapsed time is measured
+	// from the start of the phase.
+	Pattern Pattern
+}
+
+// Phases returns a pattern running the phases one after another. The last
+// phase continues indefinitely.
+func Phases(phases ...Phase) Pattern {
+	return func(elapsed time.Duration) float64 {
+		p, start := phaseAt(phases, elapsed)
+		if p < 0 {
+			return 0
+		}
+		return phases[p].Pattern(elapsed - start)
+	}
+}
+
+// PhaseAt returns the name of the phase active at elapsed.
+func PhaseAt(phases []Phase, elapsed time.Duration) string {
+	p, _ := phaseAt(phases, elapsed)
+	if p < 0 {
+		return ""
+	}
+	return phases[p].Name
+}
+
+// phaseAt returns the index and the start of the phase active at elapsed,
+// or -1 if there are no phases.
+func phaseAt(phases []Phase, elapsed time.Duration) (int, time.Duration) {
+	var start time.Duration
+	for i, p := range phases {
+		if elapsed < start+p.Duration || i == len(phases)-1 {
+			return i, start
+		}
+		start += p.Duration
+	}
+	return -1, 0
+}
+
+// Sum returns a pattern adding up the given patterns.
+func Sum(patterns ...Pattern) Pattern {
+	return func(elapsed time.Duration) float64 {
+		var sum float64
+		for _, p := range patterns {
+			sum += p(elapsed)
+		}
+		return sum
+	}
+}
+
+// Stream samples a pattern at a fixed interval. It is not safe for
+// concurrent use.
+type Stream struct {
+	pattern  Pattern
+	start    time.Time
+	interval time.Duration
+	noise    float64
+	seed     int64
+
+	rand *rand.Rand
+	n    int
+}
+
+// NewStream creates a stream sampling pattern every interval from start.
+// Every value gets uniform noise within ±noise added and is clamped at 0, as
+// metrics like concurrency or utilization can't be negative. Streams created
+// with the same arguments produce the same values.
+func NewStream(pattern Pattern, start time.Time, interval time.Duration, noise float64, seed int64) (*Stream, error) {
+	if pattern == nil {
+		return nil, fmt.Errorf("pattern cannot be nil")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive, got %v", interval)
+	}
+	if noise < 0 {
+		return nil, fmt.Errorf("noise must be non-negative, got %v", noise)
+	}
+
+	return &Stream{
+		pattern:  pattern,
+		start:    start,
+		interval: interval,
+		noise:    noise,
+		seed:     seed,
+		rand:     rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// Next returns the time and the value of the next sample.
+func (s *Stream) Next() (time.Time, float64) {
+	elapsed := time.Duration(s.n) * s.interval
+	s.n++
+
+	value := s.pattern(elapsed)
+	if s.noise > 0 {
+		value += (2*s.rand.Float64() - 1) * s.noise
+	}
+	return s.start.Add(elapsed), max(0, value)
+}
+
+// Elapsed returns the time between the start of the stream and the next
+// sample.
+func (s *Stream) Elapsed() time.Duration {
+	return time.Duration(s.n) * s.interval
+}
+
+// Reset rewinds the stream to its start, so it produces the same values
+// again.
+func (s *Stream) Reset() {
+	s.n = 0
+	s.rand = rand.New(rand.NewSource(s.seed))
+}
+
+// Values returns the values of the next n samples.
+func (s *Stream) Values(n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		_, values[i] = s.Next()
+	}
+	return values
+}
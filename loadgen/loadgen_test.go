@@ -0,0 +1,127 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadgen
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPatterns(t *testing.T) {
+	phases := []Phase{
+		{Name: "normal", Duration: 10 * time.Second, Pattern: Constant(10)},
+		{Name: "ramp", Duration: 10 * time.Second, Pattern: Ramp(10, 30, 10*time.Second)},
+		{Name: "idle", Duration: time.Second, Pattern: Constant(0)},
+	}
+
+	tests := []struct {
+		name    string
+		pattern Pattern
+		elapsed time.Duration
+		want    float64
+	}{
+		{name: "constant", pattern: Constant(5), elapsed: time.Hour, want: 5},
+		{name: "sine at start", pattern: Sine(10, 5, time.Minute), elapsed: 0, want: 10},
+		{name: "sine at quarter period", pattern: Sine(10, 5, time.Minute), elapsed: 15 * time.Second, want: 15},
+		{name: "ramp halfway", pattern: Ramp(0, 100, 10*time.Second), elapsed: 5 * time.Second, want: 50},
+		{name: "ramp done", pattern: Ramp(0, 100, 10*time.Second), elapsed: time.Minute, want: 100},
+		{name: "before spike", pattern: Spike(1, 9, 10*time.Second, 5*time.Second), elapsed: 9 * time.Second, want: 1},
+		{name: "in spike", pattern: Spike(1, 9, 10*time.Second, 5*time.Second), elapsed: 10 * time.Second, want: 9},
+		{name: "after spike", pattern: Spike(1, 9, 10*time.Second, 5*time.Second), elapsed: 15 * time.Second, want: 1},
+		{name: "sum", pattern: Sum(Constant(1), Constant(2)), elapsed: 0, want: 3},
+		{name: "first phase", pattern: Phases(phases...), elapsed: 9 * time.Second, want: 10},
+		{name: "second phase", pattern: Phases(phases...), elapsed: 15 * time.Second, want: 20},
+		{name: "last phase continues", pattern: Phases(phases...), elapsed: time.Hour, want: 0},
+		{name: "no phases", pattern: Phases(), elapsed: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pattern(tt.elapsed); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("pattern(%v) = %v, want %v", tt.elapsed, got, tt.want)
+			}
+		})
+	}
+
+	if got := PhaseAt(phases, 15*time.Second); got != "ramp" {
+		t.Errorf("PhaseAt() = %q, want ramp", got)
+	}
+}
+
+func TestStream(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	s, err := NewStream(Constant(10), start, 2*time.Second, 5, 42)
+	if err != nil {
+		t.Fatalf("NewStream failed: %v", err)
+	}
+
+	tm, v := s.Next()
+	if !tm.Equal(start) {
+		t.Errorf("first sample at %v, want %v", tm, start)
+	}
+	if v < 5 || v > 15 {
+		t.Errorf("value %v outside of 10±5", v)
+	}
+	if tm, _ := s.Next(); !tm.Equal(start.Add(2 * time.Second)) {
+		t.Errorf("second sample at %v, want %v", tm, start.Add(2*time.Second))
+	}
+	if got := s.Elapsed(); got != 4*time.Second {
+		t.Errorf("Elapsed() = %v, want 4s", got)
+	}
+
+	// The same seed produces the same values.
+	s.Reset()
+	first := s.Values(100)
+	other, _ := NewStream(Constant(10), start, 2*time.Second, 5, 42)
+	if second := other.Values(100); !slices.Equal(first, second) {
+		t.Error("streams with the same seed produced different values")
+	}
+	if first[0] != v {
+		t.Errorf("first value after Reset = %v, want %v", first[0], v)
+	}
+
+	// Values are clamped at 0.
+	s, _ = NewStream(Constant(0), start, time.Second, 1, 1)
+	for _, v := range s.Values(100) {
+		if v < 0 {
+			t.Fatalf("negative value %v", v)
+		}
+	}
+}
+
+func TestNewStreamErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  Pattern
+		interval time.Duration
+		noise    float64
+	}{
+		{name: "nil pattern", pattern: nil, interval: time.Second},
+		{name: "zero interval", pattern: Constant(1), interval: 0},
+		{name: "negative noise", pattern: Constant(1), interval: time.Second, noise: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewStream(tt.pattern, time.Now(), tt.interval, tt.noise, 0); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
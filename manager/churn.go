@@ -0,0 +1,106 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"slices"
+	"time"
+)
+
+// churnWindow is the period over which recommendation changes are counted.
+const churnWindow = time.Minute
+
+// churnGuard suppresses flip-flopping between adjacent replica counts.
+type churnGuard struct {
+	maxChangesPerMinute int
+
+	hasLast    bool
+	last       int32
+	changes    []time.Time
+	suppressed uint64
+}
+
+// apply returns the replica count to recommend for the desired count.
+// Once the limit of changes within the last minute is reached, a change to an
+// adjacent replica count is replaced by the higher of the two counts, so the
+// recommendation stops oscillating between them. Larger changes always pass.
+func (g *churnGuard) apply(desired int32, now time.Time) int32 {
+	if !g.hasLast {
+		g.hasLast = true
+		g.last = desired
+		return desired
+	}
+	if desired == g.last {
+		return desired
+	}
+
+	// Drop changes that are no longer within the window.
+	cutoff := now.Add(-churnWindow)
+	i := 0
+	for i < len(g.changes) && !g.changes[i].After(cutoff) {
+		i++
+	}
+	g.changes = g.changes[i:]
+
+	diff := desired - g.last
+	if g.maxChangesPerMinute > 0 && len(g.changes) >= g.maxChangesPerMinute && (diff == 1 || diff == -1) {
+		higher := max(desired, g.last)
+		if higher == g.last {
+			g.suppressed++
+			return g.last
+		}
+		desired = higher
+	}
+
+	g.changes = append(g.changes, now)
+	g.last = desired
+	return desired
+}
+
+// SetMaxRecommendationChangesPerMinute limits how often the recommendation of
+// Scale may change within a minute. Once the limit is reached, changes between
+// adjacent replica counts are suppressed in favor of the higher count.
+// A value of 0 disables the limit.
+func (m *Manager) SetMaxRecommendationChangesPerMinute(n int) {
+	m.churnMu.Lock()
+	defer m.churnMu.Unlock()
+	m.churn.maxChangesPerMinute = max(0, n)
+}
+
+// SuppressedRecommendationChanges returns how many recommendation changes
+// were suppressed by the limit set with SetMaxRecommendationChangesPerMinute.
+func (m *Manager) SuppressedRecommendationChanges() uint64 {
+	m.churnMu.Lock()
+	defer m.churnMu.Unlock()
+	return m.churn.suppressed
+}
+
+// applyChurnGuard passes the desired count through the churn guard.
+func (m *Manager) applyChurnGuard(desired int32, now time.Time) int32 {
+	m.churnMu.Lock()
+	defer m.churnMu.Unlock()
+	return m.churn.apply(desired, now)
+}
+
+// peekChurnGuard is applyChurnGuard without changing the state.
+func (m *Manager) peekChurnGuard(desired int32, now time.Time) int32 {
+	m.churnMu.Lock()
+	defer m.churnMu.Unlock()
+	g := m.churn
+	g.changes = slices.Clone(g.changes)
+	return g.apply(desired, now)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// ScalerDescription describes what a scaler scales on and how, e.g. for
+// debug endpoints, metric exporters or the status of custom resources.
+// Durations are encoded in JSON as nanoseconds.
+type ScalerDescription struct {
+	// Name is the name of the scaler, usually the name of its metric.
+	Name string `json:"name"`
+
+	// MetricType and Unit describe the semantics and the unit of the
+	// recorded values and the targets.
+	MetricType api.ScalingMetricType `json:"metricType"`
+	Unit       api.Unit              `json:"unit,omitempty"`
+
+	// TargetValue is the target per pod and TotalTargetValue the target
+	// across all pods. Only one of them is positive.
+	TargetValue      float64 `json:"targetValue"`
+	TotalTargetValue float64 `json:"totalTargetValue"`
+
+	// StableWindow, BurstWindow and WindowGranularity are the lengths of the
+	// aggregation windows and of their buckets.
+	StableWindow      time.Duration `json:"stableWindow"`
+	BurstWindow       time.Duration `json:"burstWindow"`
+	WindowGranularity time.Duration `json:"windowGranularity"`
+
+	// StableAggregator and BurstAggregator are the aggregation algorithms of
+	// the windows, "linear" or "weighted".
+	StableAggregator string `json:"stableAggregator"`
+	BurstAggregator  string `json:"burstAggregator"`
+}
+
+// Describe returns the metric, the target and the aggregation windows of the
+// scaler.
+func (s *Scaler) Describe() ScalerDescription {
+	cfg := s.EffectiveConfig()
+	stableAlgoType, burstAlgoType := s.AggregationAlgorithms()
+	return ScalerDescription{
+		Name:              s.name,
+		MetricType:        cfg.ScalingMetricType,
+		Unit:              cfg.Unit,
+		TargetValue:       cfg.TargetValue,
+		TotalTargetValue:  cfg.TotalTargetValue,
+		StableWindow:      cfg.StableWindow,
+		BurstWindow:       cfg.BurstWindow,
+		WindowGranularity: cfg.WindowGranularity,
+		StableAggregator:  stableAlgoType,
+		BurstAggregator:   burstAlgoType,
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+	libkpaconfig "github.com/Fedosin/libkpa/config"
+)
+
+func TestScalerDescribe(t *testing.T) {
+	cfg := *libkpaconfig.NewDefaultAutoscalerConfigForMetric(api.ScalingMetricUtilization)
+	cfg.Unit = api.UnitPercent
+	cfg.TargetValue = 80
+	cfg.StableWindow = 120 * time.Second
+	cfg.BurstWindowPercentage = 10
+
+	scaler, err := NewScalerWithAggregationAlgorithms("cpu", cfg, "linear", "weighted")
+	if err != nil {
+		t.Fatalf("NewScalerWithAggregationAlgorithms failed: %v", err)
+	}
+
+	want := ScalerDescription{
+		Name:              "cpu",
+		MetricType:        api.ScalingMetricUtilization,
+		Unit:              api.UnitPercent,
+		TargetValue:       80,
+		StableWindow:      120 * time.Second,
+		BurstWindow:       12 * time.Second,
+		WindowGranularity: time.Second,
+		StableAggregator:  "linear",
+		BurstAggregator:   "weighted",
+	}
+	got := scaler.Describe()
+	if got != want {
+		t.Errorf("Describe() = %+v, want %+v", got, want)
+	}
+
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded ScalerDescription
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != want {
+		t.Errorf("JSON round trip = %+v, %v, want %+v", decoded, err, want)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"expvar"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// expvarMu makes checking and publishing an expvar name atomic, as
+// expvar.Publish panics on names that are already published.
+var expvarMu sync.Mutex
+
+// ScalerStatus is a snapshot of the state of a scaler.
+type ScalerStatus struct {
+	// StableAverage and BurstAverage are the window averages, or -1 if the
+	// window is empty.
+	StableAverage float64 `json:"stableAverage"`
+	BurstAverage  float64 `json:"burstAverage"`
+
+	// DesiredPodCount, ScaleValid and InBurstMode describe the latest
+	// recommendation of the scaler.
+	DesiredPodCount int32 `json:"desiredPodCount"`
+	ScaleValid      bool  `json:"scaleValid"`
+	InBurstMode     bool  `json:"inBurstMode"`
+
+	// LastScaleTime is when the latest recommendation was made. It is zero
+	// if Scale hasn't been called yet.
+	LastScaleTime time.Time `json:"lastScaleTime"`
+}
+
+// ManagerStatus is a snapshot of the state of a manager.
+type ManagerStatus struct {
+	MinScale int32                   `json:"minScale"`
+	MaxScale int32                   `json:"maxScale"`
+	Scalers  map[string]ScalerStatus `json:"scalers"`
+
+	// InBurstMode is true if the latest valid recommendation of any scaler
+	// is in burst mode. InvalidScalers are the sorted names of the scalers
+	// whose latest recommendation is invalid, including scalers that haven't
+	// scaled yet.
+	InBurstMode    bool     `json:"inBurstMode"`
+	InvalidScalers []string `json:"invalidScalers"`
+
+	// PeekDesiredPodCount is the replica count Scale would return at the
+	// time of the status for the inputs of the latest Scale call, see
+	// PeekScale.
+	PeekDesiredPodCount int32 `json:"peekDesiredPodCount"`
+
+	// Frozen is true if the replica count is pinned by Freeze, and
+	// FreezeReason is the reason passed to Freeze.
+	Frozen       bool   `json:"frozen"`
+	FreezeReason string `json:"freezeReason,omitempty"`
+}
+
+// windowAverages returns the averages of the stable and the burst windows at
+// now, or -1 for an empty window.
+func (s *Scaler) windowAverages(now time.Time) (stable, burst float64) {
+	s.aggMu.RLock()
+	defer s.aggMu.RUnlock()
+	stable, burst = -1, -1
+	if !s.stableAggregator.IsEmpty(now) {
+		stable = s.stableAggregator.WindowAverage(now)
+	}
+	if !s.burstAggregator.IsEmpty(now) {
+		burst = s.burstAggregator.WindowAverage(now)
+	}
+	return stable, burst
+}
+
+// Status returns the window averages at now and the latest recommendation.
+func (s *Scaler) Status(now time.Time) ScalerStatus {
+	status := ScalerStatus{}
+	status.StableAverage, status.BurstAverage = s.windowAverages(now)
+
+	s.lastMu.Lock()
+	defer s.lastMu.Unlock()
+	status.DesiredPodCount = s.lastRecommendation.DesiredPodCount
+	status.ScaleValid = s.lastRecommendation.ScaleValid
+	status.InBurstMode = s.lastRecommendation.InBurstMode
+	status.LastScaleTime = s.lastScaleTime
+	return status
+}
+
+// Status returns the replica bounds and the status of all registered scalers.
+func (m *Manager) Status(now time.Time) ManagerStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status := ManagerStatus{
+		MinScale: m.minReplicas,
+		MaxScale: m.maxReplicas,
+		Scalers:  make(map[string]ScalerStatus, len(m.scalers)),
+	}
+	for name, s := range m.scalers {
+		st := s.Status(now)
+		status.Scalers[name] = st
+		if !st.ScaleValid {
+			status.InvalidScalers = append(status.InvalidScalers, name)
+		} else if st.InBurstMode {
+			status.InBurstMode = true
+		}
+	}
+	slices.Sort(status.InvalidScalers)
+	if !m.closed {
+		status.PeekDesiredPodCount = m.peekFreeze(m.desired(m.latestInputs(), now, (*Scaler).PeekScale).DesiredPodCount)
+	}
+	status.FreezeReason, status.Frozen = m.Frozen()
+	return status
+}
+
+// PublishExpvar exposes the manager status as the expvar variable name, so
+// it is served as JSON under /debug/vars by the default HTTP mux. This is
+// meant for environments without a Prometheus or OpenTelemetry pipeline.
+// The status is computed on every read. Names must be unique in the process:
+// publishing a name that is already published returns an error.
+func (m *Manager) PublishExpvar(name string) error {
+	if name == "" {
+		return fmt.Errorf("expvar name cannot be empty")
+	}
+
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("expvar %q is already published", name)
+	}
+	expvar.Publish(name, expvar.Func(func() any {
+		return m.Status(time.Now())
+	}))
+	return nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+
+	"github.com/Fedosin/libkpa/transmitter"
+)
+
+// FrozenMetric is the name of the gauge reporting whether a manager is
+// frozen, 1 or 0.
+const FrozenMetric = "frozen"
+
+// freezeState pins the replica count of a frozen manager.
+type freezeState struct {
+	frozen bool
+	reason string
+
+	// pinned is the replica count Scale returns while frozen, valid if
+	// hasPinned is set.
+	pinned    int32
+	hasPinned bool
+
+	// last is the latest replica count returned by Scale, valid if hasLast
+	// is set.
+	last    int32
+	hasLast bool
+
+	transmitter transmitter.MetricTransmitter
+}
+
+// Freeze pins the replica count returned by Scale at the latest one, e.g.
+// during maintenance windows or incident response. Scalers keep recording
+// metrics and making recommendations, which are reported by ScaleDetails,
+// but the returned replica count doesn't change until Unfreeze. If Scale
+// hasn't been called yet, the first replica count is pinned. Freezing a
+// frozen manager only updates the reason.
+func (m *Manager) Freeze(reason string) {
+	m.freezeMu.Lock()
+	defer m.freezeMu.Unlock()
+
+	wasFrozen := m.freeze.frozen
+	m.freeze.frozen = true
+	m.freeze.reason = reason
+	if wasFrozen {
+		return
+	}
+	m.freeze.pinned, m.freeze.hasPinned = m.freeze.last, m.freeze.hasLast
+	m.reportFreeze()
+}
+
+// Unfreeze makes Scale follow the recommendations again.
+func (m *Manager) Unfreeze() {
+	m.freezeMu.Lock()
+	defer m.freezeMu.Unlock()
+
+	if !m.freeze.frozen {
+		return
+	}
+	m.freeze = freezeState{
+		last:        m.freeze.last,
+		hasLast:     m.freeze.hasLast,
+		transmitter: m.freeze.transmitter,
+	}
+	m.reportFreeze()
+}
+
+// Frozen reports whether the manager is frozen and the reason passed to
+// Freeze.
+func (m *Manager) Frozen() (reason string, frozen bool) {
+	m.freezeMu.Lock()
+	defer m.freezeMu.Unlock()
+	return m.freeze.reason, m.freeze.frozen
+}
+
+// SetFreezeTransmitter sets the transmitter FrozenMetric is reported to
+// whenever the manager is frozen or unfrozen. Nil disables reporting.
+func (m *Manager) SetFreezeTransmitter(t transmitter.MetricTransmitter) {
+	m.freezeMu.Lock()
+	defer m.freezeMu.Unlock()
+	m.freeze.transmitter = t
+	m.reportFreeze()
+}
+
+// reportFreeze reports FrozenMetric. The caller must hold freezeMu.
+func (m *Manager) reportFreeze() {
+	if m.freeze.transmitter == nil {
+		return
+	}
+	value := 0.0
+	if m.freeze.frozen {
+		value = 1
+	}
+	m.freeze.transmitter.RecordGauge(context.Background(), FrozenMetric, value)
+}
+
+// applyFreeze returns the replica count Scale returns for the desired one,
+// i.e. the pinned count if the manager is frozen, and the freeze reason.
+func (m *Manager) applyFreeze(desired int32) (int32, string, bool) {
+	m.freezeMu.Lock()
+	defer m.freezeMu.Unlock()
+
+	if m.freeze.frozen {
+		if !m.freeze.hasPinned {
+			m.freeze.pinned, m.freeze.hasPinned = desired, true
+		}
+		desired = m.freeze.pinned
+	}
+	m.freeze.last, m.freeze.hasLast = desired, true
+	return desired, m.freeze.reason, m.freeze.frozen
+}
+
+// peekFreeze is applyFreeze without changing the state, for PeekScale.
+func (m *Manager) peekFreeze(desired int32) int32 {
+	m.freezeMu.Lock()
+	defer m.freezeMu.Unlock()
+
+	if m.freeze.frozen && m.freeze.hasPinned {
+		return m.freeze.pinned
+	}
+	return desired
+}
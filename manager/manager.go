@@ -20,17 +20,51 @@ limitations under the License.
 package manager
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/Fedosin/libkpa/api"
+	"github.com/Fedosin/libkpa/schedule"
 )
 
+// ErrClosed is returned by Manager methods called after Close.
+var ErrClosed = errors.New("manager is closed")
+
 // Manager manages multiple autoscalers and coordinates their scaling decisions.
 type Manager struct {
 	mu          sync.RWMutex
 	minReplicas int32
 	maxReplicas int32
 	scalers     map[string]*Scaler
+	closed      bool
+
+	// schedule is set by SetMinScaleSchedule.
+	schedule *schedule.Schedule
+
+	// lastInputs are the inputs of the latest Scale call, used to evaluate
+	// decisions on Record for subscribers.
+	lastInputs atomic.Pointer[ScaleInputs]
+
+	// recoverPanics is set by SetRecoverPanics.
+	recoverPanics atomic.Bool
+
+	churnMu sync.Mutex
+	churn   churnGuard
+
+	freezeMu sync.Mutex
+	freeze   freezeState
+
+	rampMu sync.Mutex
+	ramp   rampDown
+
+	subMu         sync.Mutex
+	subscriptions []*subscription
+	subsClosed    bool
 }
 
 // NewManager creates a new Manager instance with the specified replica bounds.
@@ -63,6 +97,7 @@ func NewManager(
 
 // Register adds a scaler to the manager.
 // If a scaler with the same name already exists, it will be replaced.
+// Scalers registered after Close are ignored.
 func (m *Manager) Register(s *Scaler) {
 	if s == nil {
 		return
@@ -70,6 +105,9 @@ func (m *Manager) Register(s *Scaler) {
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.closed {
+		return
+	}
 	m.scalers[s.Name()] = s
 }
 
@@ -136,29 +174,248 @@ func (m *Manager) ChangeAggregationAlgorithm(name, algoType string) error {
 	return scaler.ChangeAggregationAlgorithm(algoType)
 }
 
-// Record records a metric value for a specific scaler.
+// ChangeAggregationAlgorithms changes the aggregation algorithms of the
+// stable and the burst windows for a specific scaler.
+func (m *Manager) ChangeAggregationAlgorithms(name, stableAlgoType, burstAlgoType string) error {
+	m.mu.RLock()
+	scaler, exists := m.scalers[name]
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("scaler %q not found", name)
+	}
+
+	return scaler.ChangeAggregationAlgorithms(stableAlgoType, burstAlgoType)
+}
+
+// Record records a metric value for a specific scaler. It returns an error
+// wrapping ErrRejected if a validator of the scaler rejected the value.
 func (m *Manager) Record(name string, value float64, t time.Time) error {
+	return m.record(name, t, func(s *Scaler) error {
+		return s.TryRecord(value, t)
+	})
+}
+
+// RecordIn records a metric value in the given unit for a specific scaler.
+// The value is converted to the unit of the scaler, see Scaler.RecordIn.
+func (m *Manager) RecordIn(name string, value float64, unit api.Unit, t time.Time) error {
+	return m.record(name, t, func(s *Scaler) error {
+		return s.RecordIn(value, unit, t)
+	})
+}
+
+// RecordWeighted records a metric value standing for weight samples for a
+// specific scaler, see Scaler.RecordWeighted.
+func (m *Manager) RecordWeighted(name string, value, weight float64, t time.Time) error {
+	return m.record(name, t, func(s *Scaler) error {
+		return s.RecordWeighted(value, weight, t)
+	})
+}
+
+// record passes a metric value at time t to the named scaler and, while there
+// are subscribers, evaluates a new decision. The decision is evaluated
+// without changing any state, so subscribing doesn't change the decisions of
+// Scale.
+func (m *Manager) record(name string, t time.Time, record func(*Scaler) error) (err error) {
+	if m.recoverPanics.Load() {
+		defer recoverPanic(&err)
+	}
+
 	m.mu.RLock()
 	scaler, exists := m.scalers[name]
+	closed := m.closed
 	m.mu.RUnlock()
 
+	if closed {
+		return ErrClosed
+	}
 	if !exists {
 		return fmt.Errorf("scaler %q not found", name)
 	}
 
-	scaler.Record(value, t)
+	if err := record(scaler); err != nil {
+		return fmt.Errorf("scaler %q: %w", name, err)
+	}
+
+	if m.hasSubscribers() {
+		inputs := m.latestInputs()
+		if details, err := m.evaluate(inputs, t); err == nil {
+			m.publish(details.DesiredPodCount, inputs.ReadyPods, details.ValidUntil, t)
+		}
+	}
 	return nil
 }
 
 // Scale computes the desired replica count by taking the maximum of all scalers' recommendations.
-// The context parameter is provided for future extensibility but is not currently used.
-func (m *Manager) Scale(readyPods int32, now time.Time) int32 {
+// It returns ErrClosed if the manager is closed.
+func (m *Manager) Scale(readyPods int32, now time.Time) (int32, error) {
+	details, err := m.ScaleWithDetails(readyPods, now)
+	return details.DesiredPodCount, err
+}
+
+// ScaleDetails is the outcome of a scaling decision of a manager, including
+// the recommendations it was made from.
+type ScaleDetails struct {
+	// DesiredPodCount is the desired replica count returned by Scale.
+	DesiredPodCount int32
+
+	// Recommendations are the recommendations of the scalers by name.
+	Recommendations map[string]api.ScaleRecommendation
+
+	// InBurstMode is true if any scaler with a valid recommendation is in
+	// burst mode, and BurstScalers are the sorted names of those scalers.
+	InBurstMode  bool
+	BurstScalers []string
+
+	// InvalidScalers are the sorted names of the scalers without a valid
+	// recommendation, e.g. because they have no metrics yet.
+	InvalidScalers []string
+
+	// Frozen is true if DesiredPodCount was pinned by Freeze rather than
+	// derived from the recommendations, and FreezeReason is the reason
+	// passed to Freeze.
+	Frozen       bool
+	FreezeReason string
+
+	// RampingDown is true if DesiredPodCount is above the recommendations
+	// because it ramps down after burst mode, a freeze or a higher minimum
+	// scale ended, see SetRampDownIntervals.
+	RampingDown bool
+
+	// raisedToMin is set if the minimum scale raised DesiredPodCount.
+	raisedToMin bool
+
+	// ValidUntil is the earliest ValidUntil of the valid recommendations,
+	// i.e. when DesiredPodCount becomes stale. It is zero if no scaler has a
+	// valid recommendation.
+	ValidUntil time.Time
+}
+
+// add includes the recommendation of the named scaler.
+func (d *ScaleDetails) add(name string, rec api.ScaleRecommendation) {
+	d.Recommendations[name] = rec
+	switch {
+	case !rec.ScaleValid:
+		d.InvalidScalers = append(d.InvalidScalers, name)
+		return
+	case rec.InBurstMode:
+		d.InBurstMode = true
+		d.BurstScalers = append(d.BurstScalers, name)
+	}
+	if !rec.ValidUntil.IsZero() && (d.ValidUntil.IsZero() || rec.ValidUntil.Before(d.ValidUntil)) {
+		d.ValidUntil = rec.ValidUntil
+	}
+}
+
+// ScaleWithDetails is like Scale, but also returns the recommendations of
+// the scalers and which of them are in burst mode or invalid, e.g. for
+// reporting the status of a controller.
+func (m *Manager) ScaleWithDetails(readyPods int32, now time.Time) (ScaleDetails, error) {
+	return m.ScaleWithInputs(ScaleInputs{ReadyPods: readyPods}, now)
+}
+
+// ScaleInputs are the inputs of a scaling decision of a manager.
+type ScaleInputs struct {
+	// ReadyPods is the number of ready pods of the scaled workload.
+	ReadyPods int32
+
+	// ScalerPods overrides ReadyPods for the named scalers. Use it for
+	// metrics measured against another deployment than the scaled one, e.g.
+	// the utilization of a worker pool that grows with a frontend.
+	ScalerPods map[string]int32
+}
+
+// readyPodsFor returns the ready pod count for the named scaler.
+func (in ScaleInputs) readyPodsFor(name string) int32 {
+	if pods, ok := in.ScalerPods[name]; ok {
+		return pods
+	}
+	return in.ReadyPods
+}
+
+// ScaleWithInputs is like ScaleWithDetails, but passes each scaler the ready
+// pod count of inputs for its name.
+func (m *Manager) ScaleWithInputs(inputs ScaleInputs, now time.Time) (_ ScaleDetails, err error) {
+	if m.recoverPanics.Load() {
+		defer recoverPanic(&err)
+	}
+
+	inputs.ScalerPods = maps.Clone(inputs.ScalerPods)
+
+	details, err := m.scale(inputs, now)
+	if err != nil {
+		return ScaleDetails{}, err
+	}
+	details.DesiredPodCount = m.applyChurnGuard(details.DesiredPodCount, now)
+	details.DesiredPodCount, details.FreezeReason, details.Frozen = m.applyFreeze(details.DesiredPodCount)
+	elevated := details.InBurstMode || details.Frozen || details.raisedToMin
+	details.DesiredPodCount, details.RampingDown = m.applyRampDown(details.DesiredPodCount, elevated)
+
+	m.lastInputs.Store(&inputs)
+	m.publish(details.DesiredPodCount, inputs.ReadyPods, details.ValidUntil, now)
+	return details, nil
+}
+
+// evaluate returns the decision ScaleWithInputs would make, without changing
+// the state of the manager or its scalers.
+func (m *Manager) evaluate(inputs ScaleInputs, now time.Time) (ScaleDetails, error) {
+	m.mu.RLock()
+	if m.closed {
+		m.mu.RUnlock()
+		return ScaleDetails{}, ErrClosed
+	}
+	details := m.desired(inputs, now, (*Scaler).PeekScale)
+	m.mu.RUnlock()
+
+	details.DesiredPodCount = m.peekChurnGuard(details.DesiredPodCount, now)
+	details.DesiredPodCount = m.peekFreeze(details.DesiredPodCount)
+	details.FreezeReason, details.Frozen = m.Frozen()
+	elevated := details.InBurstMode || details.Frozen || details.raisedToMin
+	details.DesiredPodCount, details.RampingDown = m.peekRampDown(details.DesiredPodCount, elevated)
+	return details, nil
+}
+
+// latestInputs returns the inputs of the latest Scale call.
+func (m *Manager) latestInputs() ScaleInputs {
+	if inputs := m.lastInputs.Load(); inputs != nil {
+		return *inputs
+	}
+	return ScaleInputs{}
+}
+
+// PeekScale returns the replica count Scale would return, without changing
+// the state of the scalers, e.g. for dry runs. The churn guard is not
+// applied, and subscribers are not notified. The replica count pinned by
+// Freeze is. It returns ErrClosed if the manager is closed.
+func (m *Manager) PeekScale(readyPods int32, now time.Time) (int32, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return 0, ErrClosed
+	}
+	return m.peekFreeze(m.desired(ScaleInputs{ReadyPods: readyPods}, now, (*Scaler).PeekScale).DesiredPodCount), nil
+}
+
+// scale computes the desired replica count without notifying subscribers.
+func (m *Manager) scale(inputs ScaleInputs, now time.Time) (ScaleDetails, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	if m.closed {
+		return ScaleDetails{}, ErrClosed
+	}
+	return m.desired(inputs, now, (*Scaler).Scale), nil
+}
+
+// desired computes the desired replica count from the recommendations of
+// all scalers. The caller must hold the read lock.
+func (m *Manager) desired(inputs ScaleInputs, now time.Time, recommend func(*Scaler, int32, time.Time) api.ScaleRecommendation) ScaleDetails {
+	details := ScaleDetails{Recommendations: make(map[string]api.ScaleRecommendation, len(m.scalers))}
 	if len(m.scalers) == 0 {
 		// No scalers registered, return minimum replicas
-		return m.minReplicas
+		details.DesiredPodCount = m.minScaleAt(now)
+		return details
 	}
 
 	// Start with the minimum possible value
@@ -167,7 +424,8 @@ func (m *Manager) Scale(readyPods int32, now time.Time) int32 {
 
 	// Iterate through all scalers and get their recommendations
 	for name, scaler := range m.scalers {
-		recommendation := scaler.Scale(readyPods, now)
+		recommendation := recommend(scaler, inputs.readyPodsFor(name), now)
+		details.add(name, recommendation)
 
 		// Only consider valid recommendations
 		if recommendation.ScaleValid {
@@ -175,24 +433,43 @@ func (m *Manager) Scale(readyPods int32, now time.Time) int32 {
 			if recommendation.DesiredPodCount > maxDesired {
 				maxDesired = recommendation.DesiredPodCount
 			}
-		} else {
-			// Log or handle invalid recommendations if needed
-			_ = name // avoid unused variable warning
 		}
 	}
+	slices.Sort(details.BurstScalers)
+	slices.Sort(details.InvalidScalers)
 
 	// If no valid scalers, return current scale
 	if validScalers == 0 {
-		return readyPods
+		details.DesiredPodCount = inputs.ReadyPods
+		return details
 	}
 
 	// Apply min/max bounds
-	if maxDesired < m.minReplicas {
-		maxDesired = m.minReplicas
+	if minScale := m.minScaleAt(now); maxDesired < minScale {
+		maxDesired = minScale
+		details.raisedToMin = true
 	}
 	if m.maxReplicas > 0 && maxDesired > m.maxReplicas {
 		maxDesired = m.maxReplicas
 	}
 
-	return maxDesired
+	details.DesiredPodCount = maxDesired
+	return details
+}
+
+// Close stops the manager, releases its scalers, see Scaler.Release, and
+// closes all subscription channels. After Close, Record and Scale return
+// ErrClosed. Close is idempotent.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	m.closed = true
+	scalers := m.scalers
+	m.scalers = make(map[string]*Scaler)
+	m.mu.Unlock()
+
+	for _, s := range scalers {
+		s.Release()
+	}
+	m.closeSubscriptions()
+	return nil
 }
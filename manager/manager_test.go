@@ -17,10 +17,25 @@ limitations under the License.
 package manager
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/Fedosin/libkpa/api"
 	libkpaconfig "github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/metrics"
+	"github.com/Fedosin/libkpa/schedule"
+	"github.com/Fedosin/libkpa/transmitter"
 )
 
 func TestNewScaler(t *testing.T) {
@@ -117,6 +132,103 @@ func TestScalerChangeAggregationAlgorithm(t *testing.T) {
 	if err == nil {
 		t.Errorf("expected error for invalid algorithm type")
 	}
+	if stable, burst := scaler.AggregationAlgorithms(); stable != "linear" || burst != "linear" {
+		t.Errorf("AggregationAlgorithms() = %q, %q after a failed change, want linear, linear", stable, burst)
+	}
+
+	// Test different algorithms per window
+	if err := scaler.ChangeAggregationAlgorithms("linear", "weighted"); err != nil {
+		t.Errorf("failed to change to linear and weighted: %v", err)
+	}
+	if _, ok := scaler.stableAggregator.(*metrics.TimeWindow); !ok {
+		t.Errorf("stable aggregator = %T, want *metrics.TimeWindow", scaler.stableAggregator)
+	}
+	if _, ok := scaler.burstAggregator.(*metrics.WeightedTimeWindow); !ok {
+		t.Errorf("burst aggregator = %T, want *metrics.WeightedTimeWindow", scaler.burstAggregator)
+	}
+	if err := scaler.ChangeAggregationAlgorithms("linear", "invalid"); err == nil {
+		t.Errorf("expected error for invalid burst algorithm type")
+	}
+}
+
+func TestNewScalerWithAggregationAlgorithms(t *testing.T) {
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+
+	scaler, err := NewScalerWithAggregationAlgorithms("test-scaler", *config, "weighted", "linear")
+	if err != nil {
+		t.Fatalf("failed to create scaler: %v", err)
+	}
+	if stable, burst := scaler.AggregationAlgorithms(); stable != "weighted" || burst != "linear" {
+		t.Errorf("AggregationAlgorithms() = %q, %q, want weighted, linear", stable, burst)
+	}
+
+	if _, err := NewScalerWithAggregationAlgorithms("test-scaler", *config, "invalid", "linear"); err == nil {
+		t.Error("expected error for invalid stable algorithm type")
+	}
+
+	manager := NewManager(0, 0, scaler)
+	if err := manager.ChangeAggregationAlgorithms("test-scaler", "linear", "weighted"); err != nil {
+		t.Errorf("ChangeAggregationAlgorithms failed: %v", err)
+	}
+	if stable, burst := scaler.AggregationAlgorithms(); stable != "linear" || burst != "weighted" {
+		t.Errorf("AggregationAlgorithms() = %q, %q, want linear, weighted", stable, burst)
+	}
+	if err := manager.ChangeAggregationAlgorithms("missing", "linear", "linear"); err == nil {
+		t.Error("expected error for a missing scaler")
+	}
+}
+
+func TestScalerSetTarget(t *testing.T) {
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 100.0
+
+	scaler, err := NewScaler("test-scaler", *config, "linear")
+	if err != nil {
+		t.Fatalf("failed to create scaler: %v", err)
+	}
+
+	// Switch to the total target mode
+	if err := scaler.SetTarget(1000, false); err != nil {
+		t.Fatalf("failed to switch to total target: %v", err)
+	}
+	if cfg := scaler.Config(); cfg.TargetValue != 0 || cfg.TotalTargetValue != 1000 {
+		t.Errorf("expected TargetValue 0 and TotalTargetValue 1000, got %v and %v", cfg.TargetValue, cfg.TotalTargetValue)
+	}
+
+	// And back to the per-pod mode
+	if err := scaler.SetTarget(50, true); err != nil {
+		t.Fatalf("failed to switch to per-pod target: %v", err)
+	}
+	if cfg := scaler.Config(); cfg.TargetValue != 50 || cfg.TotalTargetValue != 0 {
+		t.Errorf("expected TargetValue 50 and TotalTargetValue 0, got %v and %v", cfg.TargetValue, cfg.TotalTargetValue)
+	}
+
+	// Invalid targets leave the configuration untouched
+	if err := scaler.SetTarget(-1, true); err == nil {
+		t.Error("expected error for negative target")
+	}
+	if cfg := scaler.Config(); cfg.TargetValue != 50 {
+		t.Errorf("expected TargetValue to remain 50, got %v", cfg.TargetValue)
+	}
+}
+
+func TestScalerEffectiveConfig(t *testing.T) {
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.StableWindow = 60 * time.Second
+	config.BurstWindowPercentage = 20
+
+	scaler, err := NewScaler("test-scaler", *config, "linear")
+	if err != nil {
+		t.Fatalf("failed to create scaler: %v", err)
+	}
+
+	effective := scaler.EffectiveConfig()
+	if effective.BurstWindow != 12*time.Second {
+		t.Errorf("expected burst window 12s, got %v", effective.BurstWindow)
+	}
+	if effective.BurstThresholdPercentage != 200 {
+		t.Errorf("expected burst threshold of 200%%, got %v", effective.BurstThresholdPercentage)
+	}
 }
 
 func TestScalerRecordAndScale(t *testing.T) {
@@ -158,6 +270,161 @@ func TestScalerRecordAndScale(t *testing.T) {
 	}
 }
 
+func TestScalerStalenessThreshold(t *testing.T) {
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.StableWindow = 60 * time.Second
+	config.BurstWindowPercentage = 50.0
+
+	for _, algoType := range []string{"linear", "weighted"} {
+		t.Run(algoType, func(t *testing.T) {
+			scaler, err := NewScaler("test-scaler", *config, algoType)
+			if err != nil {
+				t.Fatalf("failed to create scaler: %v", err)
+			}
+
+			now := time.Now().Truncate(time.Second)
+			if got := scaler.TimeSinceLastRecord(now); got != math.MaxInt64 {
+				t.Errorf("expected max duration before any record, got %v", got)
+			}
+
+			scaler.SetStalenessThreshold(5 * time.Second)
+			scaler.Record(100, now)
+			if got := scaler.TimeSinceLastRecord(now.Add(3 * time.Second)); got != 3*time.Second {
+				t.Errorf("expected 3s since the last record, got %v", got)
+			}
+
+			if rec := scaler.Scale(1, now.Add(3*time.Second)); !rec.ScaleValid {
+				t.Error("expected valid scale before the staleness threshold")
+			}
+			if rec := scaler.Scale(1, now.Add(7*time.Second)); rec.ScaleValid {
+				t.Error("expected invalid scale after the staleness threshold")
+			}
+
+			// The threshold survives swapping the aggregators.
+			if err := scaler.ChangeAggregationAlgorithm(algoType); err != nil {
+				t.Fatalf("failed to change aggregation algorithm: %v", err)
+			}
+			scaler.Record(100, now)
+			if rec := scaler.Scale(1, now.Add(7*time.Second)); rec.ScaleValid {
+				t.Error("expected invalid scale after changing the aggregation algorithm")
+			}
+
+			scaler.SetStalenessThreshold(0)
+			if rec := scaler.Scale(1, now.Add(7*time.Second)); !rec.ScaleValid {
+				t.Error("expected valid scale with the threshold disabled")
+			}
+		})
+	}
+}
+
+func TestScalerMinWindowFillFraction(t *testing.T) {
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.StableWindow = 10 * time.Second
+	config.MinWindowFillFraction = 0.5
+
+	scaler, err := NewScaler("test-scaler", *config, "linear")
+	if err != nil {
+		t.Fatalf("failed to create scaler: %v", err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	for i := range 4 {
+		scaler.Record(100, now.Add(time.Duration(i)*time.Second))
+	}
+	if rec := scaler.Scale(1, now.Add(3*time.Second)); rec.ScaleValid {
+		t.Error("expected invalid scale with 40% of the stable window filled")
+	}
+
+	scaler.Record(100, now.Add(4*time.Second))
+	if rec := scaler.Scale(1, now.Add(4*time.Second)); !rec.ScaleValid {
+		t.Error("expected valid scale with 50% of the stable window filled")
+	}
+
+	config.MinWindowFillFraction = 1
+	if err := scaler.Update(*config); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if rec := scaler.Scale(1, now.Add(4*time.Second)); rec.ScaleValid {
+		t.Error("expected invalid scale after raising the minimum fill")
+	}
+}
+
+func TestScalerRecordWeighted(t *testing.T) {
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 10
+
+	scaler, err := NewScaler("test-scaler", *config, "linear")
+	if err != nil {
+		t.Fatalf("failed to create scaler: %v", err)
+	}
+	scaler.AddValidator(func(value float64, _ time.Time) error {
+		if value > 100 {
+			return errors.New("too large")
+		}
+		return nil
+	})
+	manager := NewManager(0, 0, scaler)
+
+	// The validators check the value before it is weighted.
+	now := time.Now()
+	if err := manager.RecordWeighted("test-scaler", 50, 4, now); err != nil {
+		t.Fatalf("RecordWeighted failed: %v", err)
+	}
+	if err := scaler.RecordWeighted(200, 1, now); !errors.Is(err, ErrRejected) {
+		t.Errorf("RecordWeighted() error = %v, want ErrRejected", err)
+	}
+	if err := scaler.RecordWeighted(50, -1, now); err == nil {
+		t.Error("expected error for a negative weight")
+	}
+	if err := scaler.RecordWeighted(90, 0, now); err != nil {
+		t.Errorf("RecordWeighted with weight 0 failed: %v", err)
+	}
+	if got := scaler.Recorded(); got != 1 {
+		t.Errorf("Recorded() = %d, want 1 without the record with weight 0", got)
+	}
+
+	if got := scaler.Status(now).StableAverage; got != 200 {
+		t.Errorf("StableAverage = %v, want 200", got)
+	}
+}
+
+func TestScalerWindowGranularity(t *testing.T) {
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.StableWindow = 60 * time.Second
+	config.BurstWindowPercentage = 50
+	config.WindowGranularity = 10 * time.Second
+
+	scaler, err := NewScaler("test-scaler", *config, "linear")
+	if err != nil {
+		t.Fatalf("failed to create scaler: %v", err)
+	}
+
+	// One record fills one of the six 10s buckets of the stable window.
+	now := time.Now().Truncate(10 * time.Second)
+	scaler.Record(100, now)
+	if got := scaler.stableAggregator.(fillAware).FillFraction(now); got != 1.0/6 {
+		t.Errorf("FillFraction() = %v, want 1/6", got)
+	}
+
+	// Changing the granularity recreates the windows.
+	config.WindowGranularity = 0
+	if err := scaler.Update(*config); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if !scaler.stableAggregator.IsEmpty(now) {
+		t.Error("expected empty stable window after changing the granularity")
+	}
+	scaler.Record(100, now)
+	if got := scaler.stableAggregator.(fillAware).FillFraction(now); got != 1.0/60 {
+		t.Errorf("FillFraction() = %v, want 1/60", got)
+	}
+
+	config.WindowGranularity = 45 * time.Second
+	if err := scaler.Update(*config); err == nil {
+		t.Error("expected error for a granularity exceeding the burst window")
+	}
+}
+
 func TestNewManager(t *testing.T) {
 	// Test basic creation
 	manager := NewManager(1, 10)
@@ -320,7 +587,7 @@ func TestManagerScale(t *testing.T) {
 
 	// Test with no scalers
 	manager := NewManager(2, 10)
-	result := manager.Scale(2, now)
+	result, _ := manager.Scale(2, now)
 	if result != 2 {
 		t.Errorf("expected min replicas (2) with no scalers, got %d", result)
 	}
@@ -353,14 +620,14 @@ func TestManagerScale(t *testing.T) {
 	}
 
 	// Scale should return the maximum (5)
-	result = manager.Scale(3, now.Add(10*time.Second))
+	result, _ = manager.Scale(3, now.Add(10*time.Second))
 	if result != 5 {
 		t.Errorf("expected 5 pods (max of 3 and 5), got %d", result)
 	}
 
 	// Test with max replicas constraint
 	manager.SetMaxScale(4)
-	result = manager.Scale(3, now.Add(10*time.Second))
+	result, _ = manager.Scale(3, now.Add(10*time.Second))
 	if result != 4 {
 		t.Errorf("expected 4 pods (clamped by max), got %d", result)
 	}
@@ -372,12 +639,55 @@ func TestManagerScale(t *testing.T) {
 	manager2.Register(emptyScaler1)
 	manager2.Register(emptyScaler2)
 
-	result = manager2.Scale(1, now)
+	result, _ = manager2.Scale(1, now)
 	if result != 1 {
 		t.Errorf("expected current scale (1) with all invalid scalers, got %d", result)
 	}
 }
 
+func TestManagerClose(t *testing.T) {
+	now := time.Now()
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+
+	scaler, err := NewScaler("cpu", *config, "linear")
+	if err != nil {
+		t.Fatalf("failed to create scaler: %v", err)
+	}
+	pool := metrics.NewBucketPool(0)
+	pooled, err := NewScalerFromPool("rps", *config, "linear", pool)
+	if err != nil {
+		t.Fatalf("NewScalerFromPool failed: %v", err)
+	}
+	manager := NewManager(1, 10, scaler, pooled)
+
+	if err := manager.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := manager.Close(); err != nil {
+		t.Errorf("second Close failed: %v", err)
+	}
+	if stats := pool.Stats(); stats.InUse != 0 {
+		t.Errorf("%d pooled buckets in use after Close, want 0", stats.InUse)
+	}
+
+	if _, err := manager.Scale(1, now); !errors.Is(err, ErrClosed) {
+		t.Errorf("Scale after Close error = %v, want ErrClosed", err)
+	}
+	if err := manager.Record("cpu", 100, now); !errors.Is(err, ErrClosed) {
+		t.Errorf("Record after Close error = %v, want ErrClosed", err)
+	}
+	revisions := []Revision{{Name: "r1", TrafficPercent: 100}}
+	if _, err := manager.ScaleRevisions(revisions, 1, now); !errors.Is(err, ErrClosed) {
+		t.Errorf("ScaleRevisions after Close error = %v, want ErrClosed", err)
+	}
+
+	// Registering after Close is ignored.
+	manager.Register(scaler)
+	if _, err := manager.Scale(1, now); !errors.Is(err, ErrClosed) {
+		t.Errorf("Scale after Register error = %v, want ErrClosed", err)
+	}
+}
+
 func TestManagerScaleMultipleScenarios(t *testing.T) {
 	now := time.Now()
 
@@ -402,7 +712,7 @@ func TestManagerScaleMultipleScenarios(t *testing.T) {
 		memoryScaler.Record(200.0, now.Add(time.Duration(i)*time.Second)) // Would want 2 pods
 	}
 
-	result := manager.Scale(5, now.Add(10*time.Second))
+	result, _ := manager.Scale(5, now.Add(10*time.Second))
 	if result != 8 {
 		t.Errorf("expected 8 pods (max of CPU), got %d", result)
 	}
@@ -420,7 +730,7 @@ func TestManagerScaleMultipleScenarios(t *testing.T) {
 		memoryScaler2.Record(0.0, now.Add(time.Duration(i)*time.Second))
 	}
 
-	result = manager2.Scale(1, now.Add(10*time.Second))
+	result, _ = manager2.Scale(1, now.Add(10*time.Second))
 	if result != 0 {
 		t.Errorf("expected 0 pods (scale to zero), got %d", result)
 	}
@@ -459,7 +769,7 @@ func TestConcurrentAccess(t *testing.T) {
 
 	go func() {
 		for range 100 {
-			manager.Scale(80, time.Now())
+			_, _ = manager.Scale(80, time.Now())
 		}
 		done <- true
 	}()
@@ -478,3 +788,1334 @@ func TestConcurrentAccess(t *testing.T) {
 		<-done
 	}
 }
+
+func TestConcurrentChangeAggregationAlgorithms(t *testing.T) {
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.StableWindow = 10 * time.Second
+	config.TargetValue = 10
+
+	// Pooled windows are reused once released, so a scaler still using the
+	// old windows would corrupt the new ones.
+	scaler, err := NewScalerFromPool("rps", *config, "linear", metrics.NewBucketPool(64))
+	if err != nil {
+		t.Fatalf("NewScalerFromPool failed: %v", err)
+	}
+	defer scaler.Release()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for i := range 200 {
+			scaler.Record(float64(i), start.Add(time.Duration(i)*10*time.Millisecond))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := range 200 {
+			_ = scaler.Scale(2, start.Add(time.Duration(i)*10*time.Millisecond))
+			_ = scaler.Status(start)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := range 50 {
+			algoType := []string{"linear", "weighted"}[i%2]
+			if err := scaler.ChangeAggregationAlgorithms(algoType, "linear"); err != nil {
+				t.Errorf("ChangeAggregationAlgorithms failed: %v", err)
+			}
+			_, _ = scaler.AggregationAlgorithms()
+		}
+	}()
+	wg.Wait()
+
+	if stable, burst := scaler.AggregationAlgorithms(); stable != "weighted" || burst != "linear" {
+		t.Errorf("AggregationAlgorithms() = %s, %s, want weighted, linear", stable, burst)
+	}
+}
+
+func TestValidateRevisions(t *testing.T) {
+	tests := []struct {
+		name      string
+		revisions []Revision
+		wantErr   bool
+	}{
+		{
+			name:      "valid split",
+			revisions: []Revision{{Name: "blue", TrafficPercent: 90}, {Name: "green", TrafficPercent: 10}},
+		},
+		{
+			name:      "single revision",
+			revisions: []Revision{{Name: "stable", TrafficPercent: 100}},
+		},
+		{
+			name:      "no revisions",
+			revisions: nil,
+			wantErr:   true,
+		},
+		{
+			name:      "empty name",
+			revisions: []Revision{{Name: "", TrafficPercent: 100}},
+			wantErr:   true,
+		},
+		{
+			name:      "duplicate names",
+			revisions: []Revision{{Name: "blue", TrafficPercent: 50}, {Name: "blue", TrafficPercent: 50}},
+			wantErr:   true,
+		},
+		{
+			name:      "negative percent",
+			revisions: []Revision{{Name: "blue", TrafficPercent: 110}, {Name: "green", TrafficPercent: -10}},
+			wantErr:   true,
+		},
+		{
+			name:      "percents do not add up to 100",
+			revisions: []Revision{{Name: "blue", TrafficPercent: 50}, {Name: "green", TrafficPercent: 40}},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRevisions(tt.revisions)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRevisions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestManagerScaleRevisions(t *testing.T) {
+	now := time.Now()
+
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.StableWindow = 10 * time.Second
+	config.TargetValue = 100.0
+
+	scaler, err := NewScaler("requests", *config, "linear")
+	if err != nil {
+		t.Fatalf("failed to create scaler: %v", err)
+	}
+	manager := NewManager(0, 0, scaler)
+
+	for i := range 10 {
+		scaler.Record(1000.0, now.Add(time.Duration(i)*time.Second)) // Would want 10 pods
+	}
+
+	tests := []struct {
+		name      string
+		revisions []Revision
+		want      map[string]int32
+	}{
+		{
+			name:      "canary split",
+			revisions: []Revision{{Name: "stable", TrafficPercent: 75}, {Name: "canary", TrafficPercent: 25}},
+			want:      map[string]int32{"stable": 8, "canary": 3},
+		},
+		{
+			name:      "tiny canary gets at least one pod",
+			revisions: []Revision{{Name: "stable", TrafficPercent: 99}, {Name: "canary", TrafficPercent: 1}},
+			want:      map[string]int32{"stable": 10, "canary": 1},
+		},
+		{
+			name:      "blue/green cut-over",
+			revisions: []Revision{{Name: "blue", TrafficPercent: 0}, {Name: "green", TrafficPercent: 100}},
+			want:      map[string]int32{"blue": 0, "green": 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := manager.ScaleRevisions(tt.revisions, 10, now.Add(10*time.Second))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for name, want := range tt.want {
+				if got[name] != want {
+					t.Errorf("revision %q: expected %d pods, got %d", name, want, got[name])
+				}
+			}
+		})
+	}
+
+	if _, err := manager.ScaleRevisions([]Revision{{Name: "blue", TrafficPercent: 50}}, 10, now); err == nil {
+		t.Error("expected error for invalid revisions")
+	}
+}
+
+func BenchmarkScalerRecord(b *testing.B) {
+	scaler, err := NewScaler("bench", *libkpaconfig.NewDefaultAutoscalerConfig(), "linear")
+	if err != nil {
+		b.Fatalf("NewScaler failed: %v", err)
+	}
+	now := time.Now()
+	i := 0
+	for b.Loop() {
+		scaler.Record(float64(i%100), now.Add(time.Duration(i)*time.Second))
+		i++
+	}
+}
+
+func BenchmarkManagerScale(b *testing.B) {
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	for _, n := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("scalers=%d", n), func(b *testing.B) {
+			now := time.Now()
+			manager := NewManager(0, 0)
+			for i := range n {
+				scaler, err := NewScaler(fmt.Sprintf("scaler-%d", i), *config, "linear")
+				if err != nil {
+					b.Fatalf("NewScaler failed: %v", err)
+				}
+				for t := time.Duration(0); t < config.StableWindow; t += time.Second {
+					scaler.Record(1000, now.Add(t))
+				}
+				manager.Register(scaler)
+			}
+			i := 0
+			for b.Loop() {
+				_, _ = manager.Scale(10, now.Add(config.StableWindow+time.Duration(i)*time.Millisecond))
+				i++
+			}
+		})
+	}
+}
+
+func TestManagerSubscribe(t *testing.T) {
+	now := time.Now()
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 100
+
+	scaler, err := NewScaler("cpu", *config, "linear")
+	if err != nil {
+		t.Fatalf("failed to create scaler: %v", err)
+	}
+	manager := NewManager(0, 0, scaler)
+	ch := manager.Subscribe()
+
+	// The first decision is always emitted.
+	scaler.Record(300, now)
+	if _, err := manager.Scale(3, now); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+	got := <-ch
+	if got.DesiredPodCount != 3 || got.PreviousPodCount != -1 || got.ReadyPodCount != 3 {
+		t.Errorf("first event = %+v, want desired 3, previous -1, ready 3", got)
+	}
+
+	// Unchanged decisions are not emitted.
+	if _, err := manager.Scale(3, now.Add(time.Second)); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+	select {
+	case r := <-ch:
+		t.Errorf("unexpected event %+v", r)
+	default:
+	}
+
+	// Record evaluates a new decision for subscribers.
+	if err := manager.Record("cpu", 1500, now.Add(2*time.Second)); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	got = <-ch
+	if got.DesiredPodCount <= 3 || got.PreviousPodCount != 3 {
+		t.Errorf("event after Record = %+v, want desired > 3, previous 3", got)
+	}
+
+	manager.Unsubscribe(ch)
+	if _, ok := <-ch; ok {
+		t.Error("channel not closed by Unsubscribe")
+	}
+}
+
+func TestManagerSubscribeDoesNotChangeDecisions(t *testing.T) {
+	// Start past the initial burst period of the scalers.
+	start := time.Now().Truncate(time.Second).Add(2 * time.Minute)
+	newManager := func() *Manager {
+		t.Helper()
+		config := libkpaconfig.NewDefaultAutoscalerConfig()
+		config.TargetValue = 10
+		scaler, err := NewScaler("rps", *config, "linear")
+		if err != nil {
+			t.Fatalf("failed to create scaler: %v", err)
+		}
+		m := NewManager(0, 0, scaler)
+		m.SetRampDownIntervals(3)
+		m.SetMaxRecommendationChangesPerMinute(2)
+		return m
+	}
+	plain, subscribed := newManager(), newManager()
+	ch := subscribed.Subscribe(WithBufferSize(1))
+
+	// A burst followed by a drop, with several records per decision, ramps
+	// down and changes often enough to engage the churn guard.
+	values := []float64{20, 30, 300, 310, 290, 40, 30, 35, 20, 25, 30, 20}
+	pods := int32(2)
+	for i, value := range values {
+		now := start.Add(time.Duration(i) * time.Second)
+		for j := range 3 {
+			at := now.Add(time.Duration(j) * 300 * time.Millisecond)
+			_ = plain.Record("rps", value, at)
+			_ = subscribed.Record("rps", value, at)
+		}
+		want, _ := plain.Scale(pods, now.Add(time.Second))
+		got, _ := subscribed.Scale(pods, now.Add(time.Second))
+		if got != want {
+			t.Errorf("step %d: Scale with a subscriber = %d, without = %d", i, got, want)
+		}
+		pods = want
+	}
+	if got, want := subscribed.SuppressedRecommendationChanges(), plain.SuppressedRecommendationChanges(); got != want {
+		t.Errorf("suppressed changes with a subscriber = %d, without = %d", got, want)
+	}
+	if r := <-ch; r.DesiredPodCount != pods {
+		t.Errorf("latest event desired = %d, want %d", r.DesiredPodCount, pods)
+	}
+}
+
+func TestManagerSubscribeDebounce(t *testing.T) {
+	now := time.Now()
+	manager := NewManager(0, 0)
+	ch := manager.Subscribe(WithDebounce(10 * time.Second))
+
+	// With no scalers, Scale returns the minimum replicas.
+	steps := []struct {
+		minScale int32
+		offset   time.Duration
+		want     int32 // -1 means no event
+	}{
+		{minScale: 1, offset: 0, want: -1},
+		{minScale: 1, offset: 5 * time.Second, want: -1},
+		{minScale: 1, offset: 10 * time.Second, want: 1},
+		{minScale: 2, offset: 11 * time.Second, want: -1},
+		{minScale: 3, offset: 15 * time.Second, want: -1},
+		{minScale: 3, offset: 24 * time.Second, want: -1},
+		{minScale: 3, offset: 25 * time.Second, want: 3},
+	}
+
+	for i, step := range steps {
+		manager.SetMinScale(step.minScale)
+		if _, err := manager.Scale(1, now.Add(step.offset)); err != nil {
+			t.Fatalf("Scale failed: %v", err)
+		}
+		select {
+		case r := <-ch:
+			if r.DesiredPodCount != step.want {
+				t.Errorf("step %d: event desired = %d, want %d", i, r.DesiredPodCount, step.want)
+			}
+		default:
+			if step.want != -1 {
+				t.Errorf("step %d: no event, want desired %d", i, step.want)
+			}
+		}
+	}
+}
+
+func TestManagerSubscribeBufferAndClose(t *testing.T) {
+	now := time.Now()
+	manager := NewManager(0, 0)
+	ch := manager.Subscribe(WithBufferSize(1))
+
+	// A full buffer keeps the latest event.
+	for i := range int32(5) {
+		manager.SetMinScale(i + 1)
+		if _, err := manager.Scale(1, now); err != nil {
+			t.Fatalf("Scale failed: %v", err)
+		}
+	}
+	if got := <-ch; got.DesiredPodCount != 5 {
+		t.Errorf("buffered event desired = %d, want 5", got.DesiredPodCount)
+	}
+
+	if err := manager.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("channel not closed by Close")
+	}
+	if _, ok := <-manager.Subscribe(); ok {
+		t.Error("Subscribe after Close returned an open channel")
+	}
+}
+
+func TestChurnGuard(t *testing.T) {
+	tests := []struct {
+		name       string
+		limit      int
+		desired    []int32
+		interval   time.Duration
+		want       []int32
+		suppressed uint64
+	}{{
+		name:     "disabled",
+		limit:    0,
+		desired:  []int32{5, 4, 5, 4, 5, 4},
+		interval: time.Second,
+		want:     []int32{5, 4, 5, 4, 5, 4},
+	}, {
+		name:       "flip-flop suppressed after limit",
+		limit:      2,
+		desired:    []int32{5, 4, 5, 4, 5, 4},
+		interval:   time.Second,
+		want:       []int32{5, 4, 5, 5, 5, 5},
+		suppressed: 2,
+	}, {
+		name:       "scale up to adjacent count allowed",
+		limit:      1,
+		desired:    []int32{4, 5, 4, 5},
+		interval:   time.Second,
+		want:       []int32{4, 5, 5, 5},
+		suppressed: 1,
+	}, {
+		name:     "large changes pass",
+		limit:    1,
+		desired:  []int32{5, 10, 2, 8},
+		interval: time.Second,
+		want:     []int32{5, 10, 2, 8},
+	}, {
+		name:     "changes expire after a minute",
+		limit:    1,
+		desired:  []int32{5, 4, 5, 4},
+		interval: 61 * time.Second,
+		want:     []int32{5, 4, 5, 4},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := churnGuard{maxChangesPerMinute: tt.limit}
+			now := time.Now()
+			for i, d := range tt.desired {
+				if got := g.apply(d, now.Add(time.Duration(i)*tt.interval)); got != tt.want[i] {
+					t.Errorf("step %d: apply(%d) = %d, want %d", i, d, got, tt.want[i])
+				}
+			}
+			if g.suppressed != tt.suppressed {
+				t.Errorf("suppressed = %d, want %d", g.suppressed, tt.suppressed)
+			}
+		})
+	}
+}
+
+func TestManagerMaxRecommendationChangesPerMinute(t *testing.T) {
+	now := time.Now()
+	manager := NewManager(0, 0)
+	manager.SetMaxRecommendationChangesPerMinute(1)
+
+	// With no scalers, Scale returns the minimum replicas.
+	var got []int32
+	for i, minScale := range []int32{3, 2, 3, 2} {
+		manager.SetMinScale(minScale)
+		desired, err := manager.Scale(1, now.Add(time.Duration(i)*time.Second))
+		if err != nil {
+			t.Fatalf("Scale failed: %v", err)
+		}
+		got = append(got, desired)
+	}
+
+	want := []int32{3, 2, 3, 3}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Scale() sequence = %v, want %v", got, want)
+			break
+		}
+	}
+	if n := manager.SuppressedRecommendationChanges(); n != 1 {
+		t.Errorf("SuppressedRecommendationChanges() = %d, want 1", n)
+	}
+}
+
+func TestNewQueueScaler(t *testing.T) {
+	now := time.Now()
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 0
+	config.TotalTargetValue = 1000
+
+	// 10 items/s per worker, items should wait at most 5s: 50 items per worker.
+	scaler, err := NewQueueScaler("queue", *config, libkpaconfig.QueueTarget{
+		ProcessingRate: 10,
+		TargetLatency:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewQueueScaler failed: %v", err)
+	}
+	if got := scaler.Config(); got.TargetValue != 50 || got.TotalTargetValue != 0 {
+		t.Errorf("TargetValue = %v, TotalTargetValue = %v, want 50 and 0", got.TargetValue, got.TotalTargetValue)
+	}
+
+	scaler.Record(420, now)
+	if got := scaler.Scale(1, now).DesiredPodCount; got != 9 {
+		t.Errorf("DesiredPodCount = %d, want 9", got)
+	}
+
+	if _, err := NewQueueScaler("queue", *config, libkpaconfig.QueueTarget{}); err == nil {
+		t.Error("expected error for a zero processing rate")
+	}
+}
+
+func TestManagerPublishExpvar(t *testing.T) {
+	now := time.Now()
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 10
+
+	scaler, err := NewScaler("web", *config, "linear")
+	if err != nil {
+		t.Fatalf("NewScaler failed: %v", err)
+	}
+	manager := NewManager(1, 20, scaler)
+
+	if got := scaler.Status(now); got.StableAverage != -1 || got.BurstAverage != -1 || !got.LastScaleTime.IsZero() {
+		t.Errorf("Status() of an empty scaler = %+v", got)
+	}
+
+	scaler.Record(50, now)
+	if _, err := manager.Scale(1, now); err != nil {
+		t.Fatalf("Scale failed: %v", err)
+	}
+
+	status := manager.Status(now)
+	if status.MinScale != 1 || status.MaxScale != 20 {
+		t.Errorf("bounds = %d..%d, want 1..20", status.MinScale, status.MaxScale)
+	}
+	got := status.Scalers["web"]
+	if got.StableAverage != 50 || got.BurstAverage != 50 || got.DesiredPodCount != 5 || !got.ScaleValid ||
+		!got.InBurstMode || !got.LastScaleTime.Equal(now) {
+		t.Errorf("Status() = %+v", got)
+	}
+	if status.PeekDesiredPodCount != 5 {
+		t.Errorf("PeekDesiredPodCount = %d, want 5", status.PeekDesiredPodCount)
+	}
+
+	if err := manager.PublishExpvar("libkpa_test_manager"); err != nil {
+		t.Fatalf("PublishExpvar failed: %v", err)
+	}
+	if err := manager.PublishExpvar("libkpa_test_manager"); err == nil {
+		t.Error("expected error publishing the same name twice")
+	}
+	v := expvar.Get("libkpa_test_manager").String()
+	var decoded ManagerStatus
+	if err := json.Unmarshal([]byte(v), &decoded); err != nil {
+		t.Fatalf("invalid expvar JSON %q: %v", v, err)
+	}
+	if decoded.Scalers["web"].DesiredPodCount != 5 {
+		t.Errorf("published status = %s", v)
+	}
+}
+
+func TestManagerPublishExpvarConcurrently(t *testing.T) {
+	manager := NewManager(0, 0)
+
+	var (
+		wg        sync.WaitGroup
+		published atomic.Int32
+	)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := manager.PublishExpvar("libkpa_test_concurrent"); err == nil {
+				published.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := published.Load(); got != 1 {
+		t.Errorf("published %d times, want once", got)
+	}
+}
+
+func TestScalerValidators(t *testing.T) {
+	now := time.Now()
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 10
+
+	scaler, err := NewScaler("web", *config, "linear")
+	if err != nil {
+		t.Fatalf("NewScaler failed: %v", err)
+	}
+	scaler.AddValidator(Finite())
+	scaler.AddValidator(NonNegative())
+	scaler.AddValidator(AtMost(1000))
+	var buf bytes.Buffer
+	scaler.SetRejectionTransmitter(transmitter.NewLogTransmitter(log.New(&buf, "", 0), nil))
+	manager := NewManager(0, 0, scaler)
+
+	tests := []struct {
+		name    string
+		value   float64
+		wantErr bool
+	}{
+		{name: "valid", value: 50},
+		{name: "zero", value: 0},
+		{name: "negative", value: -1, wantErr: true},
+		{name: "NaN", value: math.NaN(), wantErr: true},
+		{name: "infinite", value: math.Inf(1), wantErr: true},
+		{name: "above cap", value: 1001, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := manager.Record("web", tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Record(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrRejected) {
+				t.Errorf("Record(%v) error = %v, want ErrRejected", tt.value, err)
+			}
+		})
+	}
+
+	// Rejected values are dropped by Record as well.
+	scaler.Record(-100, now)
+
+	if got := scaler.Rejected(); got != 5 {
+		t.Errorf("Rejected() = %d, want 5", got)
+	}
+	if got := scaler.Status(now).StableAverage; got != 50 {
+		t.Errorf("StableAverage = %v, want 50", got)
+	}
+	if got, want := strings.Count(buf.String(), "metric: rejected_records_total{} ="), 5; got != want {
+		t.Errorf("reported %d rejections, want %d: %q", got, want, buf.String())
+	}
+}
+
+func TestManagerRecordIn(t *testing.T) {
+	now := time.Now()
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.Unit = api.UnitMillicores
+	config.TargetValue = 500
+
+	scaler, err := NewScaler("cpu", *config, "linear")
+	if err != nil {
+		t.Fatalf("NewScaler failed: %v", err)
+	}
+	manager := NewManager(0, 0, scaler)
+
+	if got := scaler.Unit(); got != api.UnitMillicores {
+		t.Errorf("Unit() = %q, want millicores", got)
+	}
+	if err := manager.RecordIn("cpu", 1.5, api.UnitCores, now); err != nil {
+		t.Fatalf("RecordIn failed: %v", err)
+	}
+	if err := manager.RecordIn("cpu", 1<<30, api.UnitBytes, now); err == nil {
+		t.Error("expected error for a memory value")
+	}
+	if err := manager.RecordIn("unknown", 1, api.UnitCores, now); err == nil {
+		t.Error("expected error for an unknown scaler")
+	}
+
+	if got := scaler.Status(now).StableAverage; got != 1500 {
+		t.Errorf("StableAverage = %v, want 1500", got)
+	}
+	if got := scaler.Scale(1, now).DesiredPodCount; got != 3 {
+		t.Errorf("DesiredPodCount = %d, want 3", got)
+	}
+}
+
+func TestManagerPeekScale(t *testing.T) {
+	now := time.Now()
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 10
+	config.ScaleDownSoakTicks = 3
+
+	scaler, err := NewScaler("web", *config, "linear")
+	if err != nil {
+		t.Fatalf("NewScaler failed: %v", err)
+	}
+	manager := NewManager(1, 20, scaler)
+
+	scaler.Record(50, now)
+	if got, err := manager.PeekScale(1, now); err != nil || got != 5 {
+		t.Errorf("PeekScale() = %d, %v, want 5", got, err)
+	}
+	if got := scaler.Status(now); !got.LastScaleTime.IsZero() {
+		t.Errorf("PeekScale changed the latest recommendation: %+v", got)
+	}
+
+	// Peeking repeatedly doesn't count towards the scale-down soak.
+	for range 5 {
+		if rec := scaler.PeekScale(1, now); rec.DesiredPodCount != 5 {
+			t.Errorf("PeekScale().DesiredPodCount = %d, want 5", rec.DesiredPodCount)
+		}
+	}
+	if got, err := manager.Scale(1, now); err != nil || got != 5 {
+		t.Errorf("Scale() = %d, %v, want 5", got, err)
+	}
+
+	manager.Close()
+	if _, err := manager.PeekScale(1, now); !errors.Is(err, ErrClosed) {
+		t.Errorf("PeekScale() after Close error = %v, want ErrClosed", err)
+	}
+}
+
+func TestManagerScaleWithDetails(t *testing.T) {
+	now := time.Now()
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 10
+
+	var scalers []*Scaler
+	for _, name := range []string{"web", "api", "worker"} {
+		scaler, err := NewScaler(name, *config, "linear")
+		if err != nil {
+			t.Fatalf("NewScaler failed: %v", err)
+		}
+		scalers = append(scalers, scaler)
+	}
+	manager := NewManager(1, 20, scalers...)
+
+	// All scalers start in burst mode, worker has no metrics.
+	scalers[0].Record(50, now)
+	scalers[1].Record(30, now)
+	details, err := manager.ScaleWithDetails(1, now)
+	if err != nil {
+		t.Fatalf("ScaleWithDetails failed: %v", err)
+	}
+	if details.DesiredPodCount != 5 {
+		t.Errorf("DesiredPodCount = %d, want 5", details.DesiredPodCount)
+	}
+	if len(details.Recommendations) != 3 || details.Recommendations["api"].DesiredPodCount != 3 {
+		t.Errorf("Recommendations = %+v", details.Recommendations)
+	}
+	if !details.InBurstMode || !slices.Equal(details.BurstScalers, []string{"api", "web"}) {
+		t.Errorf("InBurstMode = %v, BurstScalers = %v, want true, [api web]", details.InBurstMode, details.BurstScalers)
+	}
+	if !slices.Equal(details.InvalidScalers, []string{"worker"}) {
+		t.Errorf("InvalidScalers = %v, want [worker]", details.InvalidScalers)
+	}
+
+	status := manager.Status(now)
+	if !status.InBurstMode || !slices.Equal(status.InvalidScalers, []string{"worker"}) {
+		t.Errorf("Status() InBurstMode = %v, InvalidScalers = %v", status.InBurstMode, status.InvalidScalers)
+	}
+
+	manager.Close()
+	if _, err := manager.ScaleWithDetails(1, now); !errors.Is(err, ErrClosed) {
+		t.Errorf("ScaleWithDetails() after Close error = %v, want ErrClosed", err)
+	}
+}
+
+func TestManagerScaleWithInputs(t *testing.T) {
+	now := time.Now()
+	config := libkpaconfig.NewDefaultAutoscalerConfigForMetric(api.ScalingMetricUtilization)
+	config.TargetValue = 50
+
+	frontend, err := NewScaler("frontend", *config, "linear")
+	if err != nil {
+		t.Fatalf("NewScaler failed: %v", err)
+	}
+	workers, err := NewScaler("workers", *config, "linear")
+	if err != nil {
+		t.Fatalf("NewScaler failed: %v", err)
+	}
+	manager := NewManager(0, 0, frontend, workers)
+
+	// Utilization scales proportionally to the ready pods the metric was
+	// measured against.
+	frontend.Record(50, now)
+	workers.Record(100, now)
+	details, err := manager.ScaleWithInputs(ScaleInputs{
+		ReadyPods:  2,
+		ScalerPods: map[string]int32{"workers": 5},
+	}, now)
+	if err != nil {
+		t.Fatalf("ScaleWithInputs failed: %v", err)
+	}
+	if got := details.Recommendations["frontend"].DesiredPodCount; got != 2 {
+		t.Errorf("frontend DesiredPodCount = %d, want 2", got)
+	}
+	if got := details.Recommendations["workers"].DesiredPodCount; got != 10 {
+		t.Errorf("workers DesiredPodCount = %d, want 10", got)
+	}
+	if details.DesiredPodCount != 10 {
+		t.Errorf("DesiredPodCount = %d, want 10", details.DesiredPodCount)
+	}
+
+	// Decisions on Record reuse the overrides.
+	ch := manager.Subscribe()
+	defer manager.Unsubscribe(ch)
+	later := now.Add(time.Second)
+	if err := manager.Record("workers", 100, later); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	select {
+	case r := <-ch:
+		if r.DesiredPodCount != 10 || r.ReadyPodCount != 2 {
+			t.Errorf("Recommendation = %+v, want 10 desired for 2 ready pods", r)
+		}
+	default:
+		t.Error("expected a recommendation")
+	}
+}
+
+func TestScalerEvaluationInterval(t *testing.T) {
+	now := time.Now()
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 10
+
+	fast, err := NewScaler("rps", *config, "linear")
+	if err != nil {
+		t.Fatalf("NewScaler failed: %v", err)
+	}
+	slow, err := NewScaler("memory", *config, "linear")
+	if err != nil {
+		t.Fatalf("NewScaler failed: %v", err)
+	}
+	slow.SetEvaluationInterval(30 * time.Second)
+	if got := slow.EvaluationInterval(); got != 30*time.Second {
+		t.Errorf("EvaluationInterval() = %v, want 30s", got)
+	}
+	manager := NewManager(0, 0, fast, slow)
+
+	// Without metrics the slow scaler is evaluated on every call.
+	fast.Record(10, now)
+	if details, err := manager.ScaleWithDetails(1, now); err != nil || details.Recommendations["memory"].ScaleValid {
+		t.Fatalf("ScaleWithDetails() = %+v, %v, want an invalid memory recommendation", details, err)
+	}
+	slow.Record(40, now)
+	details, err := manager.ScaleWithDetails(1, now)
+	if err != nil {
+		t.Fatalf("ScaleWithDetails failed: %v", err)
+	}
+	if got := details.Recommendations["memory"].DesiredPodCount; got != 4 {
+		t.Errorf("memory DesiredPodCount = %d, want 4", got)
+	}
+
+	// Within the interval the slow scaler keeps its recommendation.
+	later := now.Add(10 * time.Second)
+	fast.Record(80, later)
+	slow.Record(2000, later)
+	details, err = manager.ScaleWithDetails(1, later)
+	if err != nil {
+		t.Fatalf("ScaleWithDetails failed: %v", err)
+	}
+	if got := details.Recommendations["memory"].DesiredPodCount; got != 4 {
+		t.Errorf("memory DesiredPodCount = %d within the interval, want 4", got)
+	}
+	if got := details.Recommendations["rps"].DesiredPodCount; got <= 1 {
+		t.Errorf("rps DesiredPodCount = %d, want it to follow the load", got)
+	}
+
+	// After the interval it is evaluated again.
+	later = now.Add(30 * time.Second)
+	slow.Record(2000, later)
+	details, err = manager.ScaleWithDetails(1, later)
+	if err != nil {
+		t.Fatalf("ScaleWithDetails failed: %v", err)
+	}
+	if got := details.Recommendations["memory"].DesiredPodCount; got <= 4 {
+		t.Errorf("memory DesiredPodCount = %d after the interval, want more than 4", got)
+	}
+}
+
+// panickingAggregator wraps an aggregator and panics on Record and
+// WindowAverage while panicking is set.
+type panickingAggregator struct {
+	api.MetricAggregator
+	panicking bool
+}
+
+func (p *panickingAggregator) Record(t time.Time, value float64) {
+	if p.panicking {
+		panic("record")
+	}
+	p.MetricAggregator.Record(t, value)
+}
+
+func (p *panickingAggregator) WindowAverage(now time.Time) float64 {
+	if p.panicking {
+		panic("window average")
+	}
+	return p.MetricAggregator.WindowAverage(now)
+}
+
+func TestManagerRecoverPanics(t *testing.T) {
+	now := time.Now()
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+
+	scaler, err := NewScaler("web", *config, "linear")
+	if err != nil {
+		t.Fatalf("NewScaler failed: %v", err)
+	}
+	boom := errors.New("boom")
+	scaler.AddValidator(func(value float64, _ time.Time) error {
+		if value < 0 {
+			panic(boom)
+		}
+		return nil
+	})
+	// A scaler without aggregators panics on Scale.
+	broken := &Scaler{name: "broken"}
+	manager := NewManager(0, 0, scaler, broken)
+	manager.SetRecoverPanics(true)
+
+	err = manager.Record("web", -1, now)
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || !errors.Is(err, boom) {
+		t.Fatalf("Record() error = %v, want a PanicError wrapping boom", err)
+	}
+	if !strings.Contains(string(panicErr.Stack), "TestManagerRecoverPanics") {
+		t.Errorf("stack doesn't contain the test:\n%s", panicErr.Stack)
+	}
+
+	if _, err := manager.Scale(1, now); !errors.As(err, &panicErr) {
+		t.Errorf("Scale() error = %v, want a PanicError", err)
+	}
+	if _, err := broken.TryScale(1, now); !errors.As(err, &panicErr) {
+		t.Errorf("TryScale() error = %v, want a PanicError", err)
+	}
+
+	// The manager keeps working after a recovered panic.
+	manager.Unregister("broken")
+	if err := manager.Record("web", 10, now); err != nil {
+		t.Errorf("Record() error = %v", err)
+	}
+	if _, err := manager.Scale(1, now); err != nil {
+		t.Errorf("Scale() error = %v", err)
+	}
+
+	// A panicking aggregator doesn't leave the scaler locked.
+	agg := &panickingAggregator{MetricAggregator: scaler.stableAggregator, panicking: true}
+	scaler.stableAggregator = agg
+	if _, err := manager.Scale(1, now); !errors.As(err, &panicErr) {
+		t.Errorf("Scale() with a panicking aggregator error = %v, want a PanicError", err)
+	}
+	if err := manager.Record("web", 10, now); !errors.As(err, &panicErr) {
+		t.Errorf("Record() with a panicking aggregator error = %v, want a PanicError", err)
+	}
+	agg.panicking = false
+	done := make(chan error)
+	go func() {
+		if _, err := manager.Scale(1, now); err != nil {
+			done <- err
+			return
+		}
+		done <- scaler.ChangeAggregationAlgorithm("linear")
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Scale or ChangeAggregationAlgorithm after a recovered panic failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the scaler is still locked after a recovered panic")
+	}
+
+	manager.SetRecoverPanics(false)
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic with recovery disabled")
+		}
+	}()
+	_ = manager.Record("web", -1, now)
+}
+
+func TestScaleValidUntil(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := *libkpaconfig.NewDefaultAutoscalerConfig()
+	cfg.TargetValue = 10
+
+	fast, _ := NewScaler("rps", cfg, "linear")
+	slow, _ := NewScaler("memory", cfg, "linear")
+	slow.SetEvaluationInterval(30 * time.Second)
+	m := NewManager(0, 0, fast, slow)
+	sub := m.Subscribe()
+
+	_ = m.Record("rps", 50, start)
+	_ = m.Record("memory", 50, start)
+	now := start.Add(2 * time.Second)
+
+	details, err := m.ScaleWithDetails(1, now)
+	if err != nil {
+		t.Fatalf("ScaleWithDetails failed: %v", err)
+	}
+
+	// The recommendation expires when the 6s burst window moved past the
+	// latest record, unless the scaler is only evaluated every 30s.
+	if got, want := details.Recommendations["rps"].ValidUntil, start.Add(6*time.Second); !got.Equal(want) {
+		t.Errorf("ValidUntil of rps = %v, want %v", got, want)
+	}
+	if got, want := details.Recommendations["memory"].ValidUntil, now.Add(30*time.Second); !got.Equal(want) {
+		t.Errorf("ValidUntil of memory = %v, want %v", got, want)
+	}
+	if got, want := details.ValidUntil, start.Add(6*time.Second); !got.Equal(want) {
+		t.Errorf("ValidUntil = %v, want %v", got, want)
+	}
+	if got := (<-sub).ValidUntil; !got.Equal(details.ValidUntil) {
+		t.Errorf("ValidUntil of the event = %v, want %v", got, details.ValidUntil)
+	}
+	if details.Recommendations["rps"].Expired(start.Add(6*time.Second)) || !details.Recommendations["rps"].Expired(start.Add(7*time.Second)) {
+		t.Error("recommendation of rps doesn't expire after its ValidUntil")
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := *libkpaconfig.NewDefaultAutoscalerConfig()
+	cfg.TargetValue = 10
+
+	scaler, _ := NewScaler("rps", cfg, "linear")
+	m := NewManager(0, 0, scaler)
+	var buf bytes.Buffer
+	m.SetFreezeTransmitter(transmitter.NewLogTransmitter(log.New(&buf, "", 0), nil))
+
+	_ = m.Record("rps", 30, start)
+	if got, _ := m.Scale(1, start); got != 3 {
+		t.Fatalf("Scale = %d, want 3", got)
+	}
+
+	m.Freeze("maintenance")
+	if reason, frozen := m.Frozen(); !frozen || reason != "maintenance" {
+		t.Errorf("Frozen() = %q, %v, want maintenance, true", reason, frozen)
+	}
+
+	// The scaler keeps ingesting and recommending, but the replica count
+	// stays pinned.
+	now := start.Add(time.Second)
+	_ = m.Record("rps", 100, now)
+	details, err := m.ScaleWithDetails(3, now)
+	if err != nil {
+		t.Fatalf("ScaleWithDetails failed: %v", err)
+	}
+	if details.DesiredPodCount != 3 || !details.Frozen || details.FreezeReason != "maintenance" {
+		t.Errorf("details = %d, %v, %q, want 3 pinned for maintenance", details.DesiredPodCount, details.Frozen, details.FreezeReason)
+	}
+	if got := details.Recommendations["rps"].DesiredPodCount; got <= 3 {
+		t.Errorf("recommendation while frozen = %d, want more than 3", got)
+	}
+	if got, _ := m.PeekScale(3, now); got != 3 {
+		t.Errorf("PeekScale while frozen = %d, want 3", got)
+	}
+	if status := m.Status(now); !status.Frozen || status.FreezeReason != "maintenance" || status.PeekDesiredPodCount != 3 {
+		t.Errorf("Status() = %+v, want frozen at 3 for maintenance", status)
+	}
+
+	m.Unfreeze()
+	if _, frozen := m.Frozen(); frozen {
+		t.Error("Frozen() after Unfreeze = true")
+	}
+	if got, _ := m.Scale(3, now); got <= 3 {
+		t.Errorf("Scale after Unfreeze = %d, want more than 3", got)
+	}
+
+	if got, want := buf.String(), "metric: frozen{} = 0.00\nmetric: frozen{} = 1.00\nmetric: frozen{} = 0.00\n"; got != want {
+		t.Errorf("reported %q, want %q", got, want)
+	}
+}
+
+func TestFreezeBeforeScale(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := *libkpaconfig.NewDefaultAutoscalerConfig()
+	cfg.TargetValue = 10
+
+	scaler, _ := NewScaler("rps", cfg, "linear")
+	m := NewManager(0, 0, scaler)
+	m.Freeze("incident")
+
+	// The first replica count is pinned.
+	_ = m.Record("rps", 20, now)
+	if got, _ := m.Scale(1, now); got != 2 {
+		t.Fatalf("first Scale = %d, want 2", got)
+	}
+	_ = m.Record("rps", 100, now.Add(time.Second))
+	if got, _ := m.Scale(2, now.Add(time.Second)); got != 2 {
+		t.Errorf("Scale while frozen = %d, want 2", got)
+	}
+}
+
+func TestRampDown(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := *libkpaconfig.NewDefaultAutoscalerConfig()
+	cfg.TargetValue = 10
+
+	scaler, _ := NewScaler("rps", cfg, "linear")
+	m := NewManager(10, 0, scaler)
+	m.SetRampDownIntervals(4)
+
+	now := start
+	scale := func() ScaleDetails {
+		t.Helper()
+		now = now.Add(time.Second)
+		_ = m.Record("rps", 20, now)
+		details, err := m.ScaleWithDetails(2, now)
+		if err != nil {
+			t.Fatalf("ScaleWithDetails failed: %v", err)
+		}
+		return details
+	}
+
+	if got := scale().DesiredPodCount; got != 10 {
+		t.Fatalf("Scale with a minimum scale of 10 = %d, want 10", got)
+	}
+
+	// The recommendation of 2 pods is reached in 4 steps once the minimum
+	// scale is lowered.
+	m.SetMinScale(0)
+	for _, want := range []int32{8, 6, 4} {
+		if details := scale(); details.DesiredPodCount != want || !details.RampingDown {
+			t.Errorf("Scale while ramping down = %d, %v, want %d, true", details.DesiredPodCount, details.RampingDown, want)
+		}
+	}
+	if details := scale(); details.DesiredPodCount != 2 || details.RampingDown {
+		t.Errorf("Scale at the end of the ramp = %d, %v, want 2, false", details.DesiredPodCount, details.RampingDown)
+	}
+
+	// Drops of counts that weren't held up pass at once, and increases end
+	// the ramp.
+	m.SetMinScale(6)
+	if got := scale().DesiredPodCount; got != 6 {
+		t.Fatalf("Scale with a minimum scale of 6 = %d, want 6", got)
+	}
+	m.SetMinScale(0)
+	if got := scale().DesiredPodCount; got != 5 {
+		t.Errorf("first step of the ramp = %d, want 5", got)
+	}
+	m.SetMinScale(8)
+	if details := scale(); details.DesiredPodCount != 8 || details.RampingDown {
+		t.Errorf("Scale after an increase = %d, %v, want 8, false", details.DesiredPodCount, details.RampingDown)
+	}
+
+	m.SetRampDownIntervals(0)
+	m.SetMinScale(0)
+	if got := scale().DesiredPodCount; got != 2 {
+		t.Errorf("Scale without ramping = %d, want 2", got)
+	}
+}
+
+func TestMinScaleSchedule(t *testing.T) {
+	// Wednesday, 2025-01-01.
+	start := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	cfg := *libkpaconfig.NewDefaultAutoscalerConfig()
+	cfg.TargetValue = 10
+
+	sched, err := schedule.LoadFromMap(map[string]string{
+		"default-min-scale": "1",
+		"rules":             "* 09:00-18:00=12",
+	})
+	if err != nil {
+		t.Fatalf("LoadFromMap failed: %v", err)
+	}
+
+	scaler, _ := NewScaler("rps", cfg, "linear")
+	m := NewManager(3, 8, scaler)
+	m.SetMinScaleSchedule(sched)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want int32
+	}{
+		{name: "configured minimum is higher", now: start, want: 3},
+		{name: "scheduled minimum is bounded by the maximum", now: start.Add(2 * time.Hour), want: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = m.Record("rps", 20, tt.now)
+			if got, _ := m.Scale(2, tt.now); got != tt.want {
+				t.Errorf("Scale = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	m.SetMinScaleSchedule(nil)
+	now := start.Add(2*time.Hour + time.Second)
+	_ = m.Record("rps", 20, now)
+	if got, _ := m.Scale(2, now); got != 3 {
+		t.Errorf("Scale without a schedule = %d, want 3", got)
+	}
+}
+
+func TestRampDownAfterFreeze(t *testing.T) {
+	// Start past the initial burst period of the scaler.
+	start := time.Now().Truncate(time.Second).Add(2 * time.Minute)
+	cfg := *libkpaconfig.NewDefaultAutoscalerConfig()
+	cfg.TargetValue = 10
+
+	scaler, _ := NewScaler("rps", cfg, "linear")
+	m := NewManager(0, 0, scaler)
+	m.SetRampDownIntervals(2)
+
+	_ = m.Record("rps", 100, start)
+	if got, _ := m.Scale(10, start); got != 10 {
+		t.Fatalf("Scale = %d, want 10", got)
+	}
+	m.Freeze("incident")
+
+	now := start.Add(2 * time.Minute)
+	_ = m.Record("rps", 20, now)
+	if got, _ := m.Scale(10, now); got != 10 {
+		t.Fatalf("Scale while frozen = %d, want 10", got)
+	}
+
+	// The recommendation of 5 pods, limited by the scale-down rate, is
+	// reached in 2 steps after unfreezing.
+	m.Unfreeze()
+	var got []int32
+	for range 3 {
+		now = now.Add(time.Second)
+		_ = m.Record("rps", 20, now)
+		desired, _ := m.Scale(10, now)
+		got = append(got, desired)
+	}
+	if want := []int32{7, 5, 5}; !slices.Equal(got, want) {
+		t.Errorf("Scale after Unfreeze = %v, want %v", got, want)
+	}
+}
+
+func TestScaleFromZeroSeed(t *testing.T) {
+	// Start past the initial burst mode of the scalers.
+	start := time.Now().Truncate(time.Second).Add(2 * time.Minute)
+	cfg := *libkpaconfig.NewDefaultAutoscalerConfig()
+	cfg.TargetValue = 10
+	cfg.ActivationScale = 3
+	cfg.ScaleInvariant = true
+
+	// Scale invariant autoscalers only apply the activation scale at zero,
+	// so a single request recommends 1 pod once the 3 pods are ready,
+	// unless it was raised to the seed of 3 × 10.
+	for _, seed := range []bool{false, true} {
+		scaler, err := NewScaler("rps", cfg, "linear")
+		if err != nil {
+			t.Fatalf("NewScaler failed: %v", err)
+		}
+		scaler.SetScaleFromZeroSeed(seed)
+
+		if rec := scaler.Scale(0, start); rec.ScaleValid {
+			t.Fatalf("seed = %v: Scale without metrics = %+v, want invalid", seed, rec)
+		}
+		scaler.Record(1, start)
+		if got := scaler.Scale(0, start).DesiredPodCount; got != 3 {
+			t.Errorf("seed = %v: Scale from zero = %d, want 3", seed, got)
+		}
+
+		now := start.Add(time.Second)
+		scaler.Record(1, now)
+		want := int32(1)
+		if seed {
+			want = 3
+		}
+		if got := scaler.Scale(3, now).DesiredPodCount; got != want {
+			t.Errorf("seed = %v: Scale after the activation = %d, want %d", seed, got, want)
+		}
+	}
+}
+
+func TestRecordPending(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := *libkpaconfig.NewDefaultAutoscalerConfig()
+	cfg.TargetValue = 10
+	cfg.ActivationScale = 2
+
+	scaler, err := NewScaler("rps", cfg, "linear")
+	if err != nil {
+		t.Fatalf("NewScaler failed: %v", err)
+	}
+	m := NewManager(0, 0, scaler)
+
+	if err := m.RecordPending("rps", -1, start); err == nil {
+		t.Error("RecordPending with a negative count succeeded, want an error")
+	}
+
+	// 35 pending requests activate 4 pods at once, without any metrics in
+	// the windows, for the burst window of 6s.
+	if err := m.RecordPending("rps", 35, start); err != nil {
+		t.Fatalf("RecordPending failed: %v", err)
+	}
+	details, err := m.ScaleWithDetails(0, start.Add(time.Second))
+	if err != nil {
+		t.Fatalf("ScaleWithDetails failed: %v", err)
+	}
+	if details.DesiredPodCount != 4 || !details.ValidUntil.Equal(start.Add(6*time.Second)) {
+		t.Errorf("Scale with pending requests = %d valid until %v, want 4 valid until %v",
+			details.DesiredPodCount, details.ValidUntil, start.Add(6*time.Second))
+	}
+	if rec := scaler.Scale(1, start.Add(time.Second)); rec.ScaleValid {
+		t.Errorf("Scale with ready pods = %+v, want invalid without metrics", rec)
+	}
+	if rec := scaler.Scale(0, start.Add(6*time.Second)); rec.ScaleValid {
+		t.Errorf("Scale with expired pending requests = %+v, want invalid", rec)
+	}
+
+	// A few pending requests activate the activation scale.
+	if err := m.RecordPending("rps", 5, start.Add(7*time.Second)); err != nil {
+		t.Fatalf("RecordPending failed: %v", err)
+	}
+	if got := scaler.PeekScale(0, start.Add(7*time.Second)).DesiredPodCount; got != 2 {
+		t.Errorf("PeekScale with 5 pending requests = %d, want 2", got)
+	}
+}
+
+func TestRecordPendingBounds(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		modify  func(*api.AutoscalerConfig)
+		pending float64
+		want    int32
+	}{
+		{
+			name:    "max scale within the zones",
+			modify:  func(c *api.AutoscalerConfig) { c.MaxScale, c.Zones = 5, 3 },
+			pending: 100,
+			want:    3,
+		},
+		{
+			name:    "minimum pods per zone",
+			modify:  func(c *api.AutoscalerConfig) { c.Zones, c.MinPodsPerZone = 3, 2 },
+			pending: 5,
+			want:    6,
+		},
+		{
+			name:    "minimum scale",
+			modify:  func(c *api.AutoscalerConfig) { c.MinScale = 3 },
+			pending: 5,
+			want:    3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := *libkpaconfig.NewDefaultAutoscalerConfig()
+			cfg.TargetValue = 10
+			tt.modify(&cfg)
+			scaler, err := NewScaler("rps", cfg, "linear")
+			if err != nil {
+				t.Fatalf("NewScaler failed: %v", err)
+			}
+
+			if err := scaler.RecordPending(tt.pending, start); err != nil {
+				t.Fatalf("RecordPending failed: %v", err)
+			}
+			rec := scaler.Scale(0, start.Add(time.Second))
+			if rec.DesiredPodCount != tt.want || rec.PreviousDesiredPodCount != 0 || rec.Direction != api.ScaleUp {
+				t.Errorf("Scale = %d from %d (%v), want %d from 0 (%v)",
+					rec.DesiredPodCount, rec.PreviousDesiredPodCount, rec.Direction, tt.want, api.ScaleUp)
+			}
+		})
+	}
+}
+
+func TestRecordPendingPacing(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := *libkpaconfig.NewDefaultAutoscalerConfig()
+	cfg.TargetValue = 10
+	cfg.ProvisioningRate = 1
+
+	scaler, err := NewScaler("rps", cfg, "linear")
+	if err != nil {
+		t.Fatalf("NewScaler failed: %v", err)
+	}
+	if err := scaler.RecordPending(35, start); err != nil {
+		t.Fatalf("RecordPending failed: %v", err)
+	}
+	if got := scaler.PeekScale(0, start.Add(time.Second)).DesiredPodCount; got != 4 {
+		t.Fatalf("PeekScale with pending requests = %d, want 4", got)
+	}
+	if got := scaler.Scale(0, start.Add(time.Second)).DesiredPodCount; got != 4 {
+		t.Fatalf("Scale with pending requests = %d, want 4", got)
+	}
+
+	// The scale-up asked for by the windows is paced from the activation.
+	scaler.Record(100, start.Add(2*time.Second))
+	if got := scaler.Scale(0, start.Add(2*time.Second)).DesiredPodCount; got != 5 {
+		t.Errorf("Scale after the activation = %d, want 5", got)
+	}
+}
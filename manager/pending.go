@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// pendingRequests is the latest report of RecordPending.
+type pendingRequests struct {
+	value float64
+	at    time.Time
+}
+
+// RecordPending reports the number of requests waiting for the deployment
+// to scale from zero at time t, e.g. buffered by an activator in front of
+// it. While Scale sees no ready pods, the pending requests bypass the
+// windows and immediately recommend enough pods to serve them, the queue
+// depth divided by TargetValue but at least ActivationScale and at most
+// MaxScale. A report is used for the duration of the burst window, and a
+// report of 0 ends the activation. Recommendations of the windows above
+// the activation are kept. Utilization metrics and the total target mode
+// recommend ActivationScale for any pending request.
+func (s *Scaler) RecordPending(n float64, t time.Time) error {
+	if !(n >= 0) {
+		return fmt.Errorf("pending requests = %v, must be at least 0", n)
+	}
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	s.pending = pendingRequests{value: n, at: t}
+	return nil
+}
+
+// activate raises rec to the activation recommendation for the pending
+// requests if the deployment is at zero, see RecordPending. The algorithm
+// bounds the activation, spreads it across the zones and, with
+// SlidingWindowAutoscaler.Activate, remembers it as its latest
+// recommendation.
+func (s *Scaler) activate(rec api.ScaleRecommendation, readyPods int32, now time.Time, raise func(api.ScaleRecommendation, int32, int32) api.ScaleRecommendation) api.ScaleRecommendation {
+	if readyPods != 0 {
+		return rec
+	}
+	s.pendingMu.Lock()
+	pending := s.pending
+	s.pendingMu.Unlock()
+
+	cfg := s.EffectiveConfig()
+	if pending.value <= 0 || now.Sub(pending.at) >= cfg.BurstWindow {
+		return rec
+	}
+
+	count := cfg.ActivationScale
+	if cfg.TargetValue > 0 && cfg.ScalingMetricType != api.ScalingMetricUtilization {
+		count = max(count, int32(min(math.Ceil(pending.value/cfg.TargetValue), math.MaxInt32)))
+	}
+	activated := raise(rec, count, readyPods)
+	if activated.DesiredPodCount != rec.DesiredPodCount || !rec.ScaleValid {
+		activated.ValidUntil = pending.at.Add(cfg.BurstWindow)
+	}
+	return activated
+}
+
+// RecordPending reports the requests waiting for the deployment to scale
+// from zero to a specific scaler, see Scaler.RecordPending.
+func (m *Manager) RecordPending(name string, n float64, t time.Time) error {
+	return m.record(name, t, func(s *Scaler) error {
+		return s.RecordPending(n, t)
+	})
+}
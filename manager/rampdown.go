@@ -0,0 +1,97 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+// rampDown spreads the drop of the replica count after burst mode, a
+// freeze or a higher minimum scale ended over several evaluations.
+type rampDown struct {
+	intervals int
+
+	hasLast bool
+	last    int32
+
+	// elevated is whether last was held up by burst mode, a freeze or the
+	// minimum scale.
+	elevated bool
+
+	// remaining is the number of evaluations left of the current ramp, or 0
+	// if there is none.
+	remaining int
+}
+
+// apply returns the replica count to recommend for the desired count. A
+// drop below an elevated count starts a ramp, which moves the count a
+// remaining-th of the way down on every evaluation, so it reaches the
+// desired count after intervals evaluations unless the desired count
+// changes. Increases end the ramp, and drops below counts that weren't
+// elevated pass unchanged.
+func (r *rampDown) apply(desired int32, elevated bool) int32 {
+	result := r.ramp(desired)
+	r.hasLast, r.last, r.elevated = true, result, elevated
+	return result
+}
+
+func (r *rampDown) ramp(desired int32) int32 {
+	if r.intervals == 0 || !r.hasLast || desired >= r.last {
+		r.remaining = 0
+		return desired
+	}
+	if r.remaining == 0 {
+		if !r.elevated {
+			return desired
+		}
+		r.remaining = r.intervals
+	}
+
+	gap := r.last - desired
+	step := (gap + int32(r.remaining) - 1) / int32(r.remaining)
+	r.remaining--
+	return r.last - step
+}
+
+// SetRampDownIntervals makes the replica count returned by Scale drop over n
+// evaluations, rather than at once, when burst mode of a scaler, a freeze
+// or a higher minimum scale ends. Every Scale call moves the count an equal
+// share of the remaining way down to the recommendation. Scale-downs of
+// counts that weren't held up pass immediately, and a rising recommendation
+// ends the ramp. A value of 0, the default, disables ramping.
+func (m *Manager) SetRampDownIntervals(n int) {
+	m.rampMu.Lock()
+	defer m.rampMu.Unlock()
+	m.ramp.intervals = max(0, n)
+	if m.ramp.intervals == 0 {
+		m.ramp.remaining = 0
+	}
+}
+
+// applyRampDown passes the desired count through the ramp and reports
+// whether the ramp held it up.
+func (m *Manager) applyRampDown(desired int32, elevated bool) (int32, bool) {
+	m.rampMu.Lock()
+	defer m.rampMu.Unlock()
+	ramped := m.ramp.apply(desired, elevated)
+	return ramped, ramped > desired
+}
+
+// peekRampDown is applyRampDown without changing the state.
+func (m *Manager) peekRampDown(desired int32, elevated bool) (int32, bool) {
+	m.rampMu.Lock()
+	defer m.rampMu.Unlock()
+	r := m.ramp
+	ramped := r.apply(desired, elevated)
+	return ramped, ramped > desired
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// PanicError is returned in place of a recovered panic, see
+// Manager.SetRecoverPanics and Scaler.TryScale.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+// Error returns the panic value. The stack is not included.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("autoscaler panicked: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// recoverPanic stores a recovered panic in err. It must be deferred directly.
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = &PanicError{Value: r, Stack: debug.Stack()}
+	}
+}
+
+// SetRecoverPanics makes Scale, ScaleWithDetails, ScaleWithInputs,
+// ScaleRevisions, Record and RecordIn return a *PanicError instead of
+// panicking when a scaler or a record validator panics. The library itself
+// is not expected to panic, but a crash of a controller shared by many
+// workloads affects all of them. Disabled by default.
+func (m *Manager) SetRecoverPanics(enabled bool) {
+	m.recoverPanics.Store(enabled)
+}
+
+// TryScale is Scale, but returns a *PanicError if the scaler panics.
+func (s *Scaler) TryScale(readyPods int32, now time.Time) (rec api.ScaleRecommendation, err error) {
+	defer recoverPanic(&err)
+	return s.Scale(readyPods, now), nil
+}
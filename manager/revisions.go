@@ -0,0 +1,96 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// revisionPercentTolerance is the allowed deviation of the sum of traffic
+// percentages from 100, to absorb floating point rounding.
+const revisionPercentTolerance = 0.01
+
+// Revision describes a revision of a workload that receives a share of the
+// traffic measured by the Manager's scalers, e.g. the blue and green
+// deployments of a blue/green rollout or the stable and canary deployments
+// of a canary rollout.
+type Revision struct {
+	// Name identifies the revision.
+	Name string
+
+	// TrafficPercent is the share of the traffic routed to this revision,
+	// in the [0, 100] range.
+	TrafficPercent float64
+}
+
+// ValidateRevisions checks that revision names are unique and non-empty, and
+// that the traffic percentages are within [0, 100] and add up to 100.
+func ValidateRevisions(revisions []Revision) error {
+	if len(revisions) == 0 {
+		return fmt.Errorf("at least one revision is required")
+	}
+
+	names := make(map[string]struct{}, len(revisions))
+	total := 0.
+	for _, r := range revisions {
+		if r.Name == "" {
+			return fmt.Errorf("revision name cannot be empty")
+		}
+		if _, exists := names[r.Name]; exists {
+			return fmt.Errorf("duplicate revision %q", r.Name)
+		}
+		names[r.Name] = struct{}{}
+
+		if r.TrafficPercent < 0 || r.TrafficPercent > 100 {
+			return fmt.Errorf("revision %q traffic percent = %v, must be in [0, 100] range", r.Name, r.TrafficPercent)
+		}
+		total += r.TrafficPercent
+	}
+
+	if math.Abs(total-100) > revisionPercentTolerance {
+		return fmt.Errorf("revision traffic percents must add up to 100, got %v", total)
+	}
+	return nil
+}
+
+// ScaleRevisions computes the desired replica count for the whole workload
+// and splits it across the revisions according to their traffic percentages.
+// The readyPods parameter is the total number of ready pods across all
+// revisions, since the scalers observe the combined metric stream.
+//
+// Each revision's share is rounded up, so a revision is never
+// under-provisioned for its traffic and, when the workload is not scaled to
+// zero, any revision receiving traffic gets at least one pod. As a result the
+// sum of the per-revision counts can slightly exceed the workload total.
+func (m *Manager) ScaleRevisions(revisions []Revision, readyPods int32, now time.Time) (map[string]int32, error) {
+	if err := ValidateRevisions(revisions); err != nil {
+		return nil, err
+	}
+
+	total, err := m.Scale(readyPods, now)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int32, len(revisions))
+	for _, r := range revisions {
+		result[r.Name] = int32(math.Ceil(float64(total) * r.TrafficPercent / 100))
+	}
+	return result, nil
+}
@@ -18,20 +18,83 @@ package manager
 
 import (
 	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Fedosin/libkpa/algorithm"
 	"github.com/Fedosin/libkpa/api"
+	libkpaconfig "github.com/Fedosin/libkpa/config"
 	"github.com/Fedosin/libkpa/metrics"
+	"github.com/Fedosin/libkpa/transmitter"
 )
 
 // Scaler represents a single autoscaler instance that combines metric aggregation
 // with a sliding window autoscaling algorithm.
 type Scaler struct {
-	name             string
-	algorithm        *algorithm.SlidingWindowAutoscaler
+	name      string
+	algorithm *algorithm.SlidingWindowAutoscaler
+
+	// aggMu guards the aggregators, their algorithm types and the staleness
+	// threshold. The aggregators lock themselves, so recording and reading
+	// them only needs the read lock, which keeps them from being swapped and
+	// released meanwhile, see ChangeAggregationAlgorithms.
+	aggMu            sync.RWMutex
 	stableAggregator api.MetricAggregator
 	burstAggregator  api.MetricAggregator
+
+	// stableAlgoType and burstAlgoType are the aggregation algorithms of the
+	// aggregators, see ChangeAggregationAlgorithms.
+	stableAlgoType string
+	burstAlgoType  string
+
+	// pool is the pool the buckets of the aggregators are allocated from,
+	// see NewScalerFromPool.
+	pool *metrics.BucketPool
+
+	// stalenessThreshold is applied to the aggregators, see SetStalenessThreshold.
+	stalenessThreshold time.Duration
+
+	// lastMu guards the latest recommendation, reported by Status, and the
+	// evaluation interval.
+	lastMu             sync.Mutex
+	lastRecommendation api.ScaleRecommendation
+	lastScaleTime      time.Time
+	evaluationInterval time.Duration
+
+	// validateMu guards the validators, see AddValidator.
+	validateMu           sync.RWMutex
+	validators           []RecordValidator
+	rejectionTransmitter transmitter.MetricTransmitter
+	rejected             atomic.Uint64
+
+	// recorded and windowResizes are reported by SelfMetrics.
+	recorded      atomic.Uint64
+	windowResizes atomic.Uint64
+
+	// seedMu guards the seed state, see SetScaleFromZeroSeed.
+	seedMu sync.Mutex
+	seed   scaleFromZeroSeed
+
+	// pendingMu guards the pending requests, see RecordPending.
+	pendingMu sync.Mutex
+	pending   pendingRequests
+}
+
+var _ api.Recorder = (*Scaler)(nil)
+
+// stalenessAware is implemented by aggregators that track how long ago
+// metrics were recorded, like metrics.TimeWindow.
+type stalenessAware interface {
+	SetStalenessThreshold(d time.Duration)
+	TimeSinceLastRecord(now time.Time) time.Duration
+}
+
+// fillAware is implemented by aggregators that report how much of their
+// window carries data, like metrics.TimeWindow.
+type fillAware interface {
+	FillFraction(now time.Time) float64
 }
 
 // NewScaler creates a new Scaler instance with the specified configuration.
@@ -43,6 +106,28 @@ func NewScaler(
 	cfg api.AutoscalerConfig,
 	algoType string,
 ) (*Scaler, error) {
+	return newScaler(name, cfg, algoType, algoType, nil)
+}
+
+// NewScalerWithAggregationAlgorithms is NewScaler with different aggregation
+// algorithms for the stable and the burst windows. For example, weighting
+// recent values makes burst detection react faster, while a linear stable
+// window keeps the stable signal unbiased.
+func NewScalerWithAggregationAlgorithms(name string, cfg api.AutoscalerConfig, stableAlgoType, burstAlgoType string) (*Scaler, error) {
+	return newScaler(name, cfg, stableAlgoType, burstAlgoType, nil)
+}
+
+// NewScalerFromPool is NewScaler with the buckets of the metric aggregators
+// allocated from the pool, which saves memory and GC work when creating
+// thousands of scalers. Call Release once the scaler is no longer used.
+func NewScalerFromPool(name string, cfg api.AutoscalerConfig, algoType string, pool *metrics.BucketPool) (*Scaler, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("bucket pool cannot be nil")
+	}
+	return newScaler(name, cfg, algoType, algoType, pool)
+}
+
+func newScaler(name string, cfg api.AutoscalerConfig, stableAlgoType, burstAlgoType string, pool *metrics.BucketPool) (*Scaler, error) {
 	if name == "" {
 		return nil, fmt.Errorf("scaler name cannot be empty")
 	}
@@ -53,35 +138,9 @@ func NewScaler(
 		return nil, fmt.Errorf("failed to create sliding window autoscaler: %w", err)
 	}
 
-	// Calculate burst window duration
-	burstWindow := max(time.Second, time.Duration(float64(cfg.StableWindow)*cfg.BurstWindowPercentage/100.0))
-
-	// Default granularity of 1 second
-	granularity := time.Second
-
-	// Create the appropriate metric aggregators based on algoType
-	var stableAgg, burstAgg api.MetricAggregator
-	switch algoType {
-	case "linear":
-		stableAgg, err = metrics.NewTimeWindow(cfg.StableWindow, granularity)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create stable aggregator: %w", err)
-		}
-		burstAgg, err = metrics.NewTimeWindow(burstWindow, granularity)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create burst aggregator: %w", err)
-		}
-	case "weighted":
-		stableAgg, err = metrics.NewWeightedTimeWindow(cfg.StableWindow, granularity)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create stable aggregator: %w", err)
-		}
-		burstAgg, err = metrics.NewWeightedTimeWindow(burstWindow, granularity)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create burst aggregator: %w", err)
-		}
-	default:
-		return nil, fmt.Errorf("unknown algorithm type: %s (expected 'linear' or 'weighted')", algoType)
+	stableAgg, burstAgg, err := newAggregators(cfg, stableAlgoType, burstAlgoType, pool)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Scaler{
@@ -89,9 +148,21 @@ func NewScaler(
 		algorithm:        algoScaler,
 		stableAggregator: stableAgg,
 		burstAggregator:  burstAgg,
+		stableAlgoType:   stableAlgoType,
+		burstAlgoType:    burstAlgoType,
+		pool:             pool,
 	}, nil
 }
 
+// Release returns the memory of the metric aggregators of a scaler created
+// with NewScalerFromPool to the pool. The scaler must not be used
+// afterwards. Release does nothing for other scalers.
+func (s *Scaler) Release() {
+	s.aggMu.Lock()
+	defer s.aggMu.Unlock()
+	release(s.stableAggregator, s.burstAggregator)
+}
+
 // Name returns the scaler's name.
 func (s *Scaler) Name() string {
 	return s.name
@@ -101,58 +172,259 @@ func (s *Scaler) Name() string {
 // affecting the autoscaling algorithm. This allows runtime changes to how metrics
 // are aggregated.
 func (s *Scaler) ChangeAggregationAlgorithm(algoType string) error {
-	cfg := s.algorithm.GetConfig()
+	return s.ChangeAggregationAlgorithms(algoType, algoType)
+}
+
+// ChangeAggregationAlgorithms is ChangeAggregationAlgorithm with different
+// algorithms for the stable and the burst windows. It is safe to call
+// concurrently with Record and Scale, which use either the old or the new
+// aggregators.
+func (s *Scaler) ChangeAggregationAlgorithms(stableAlgoType, burstAlgoType string) error {
+	stableAgg, burstAgg, err := newAggregators(s.algorithm.GetConfig(), stableAlgoType, burstAlgoType, s.pool)
+	if err != nil {
+		return err
+	}
+
+	s.aggMu.Lock()
+	defer s.aggMu.Unlock()
+	oldStable, oldBurst := s.stableAggregator, s.burstAggregator
+	s.stableAggregator, s.burstAggregator = stableAgg, burstAgg
+	s.stableAlgoType, s.burstAlgoType = stableAlgoType, burstAlgoType
+	s.applyStalenessThresholdLocked()
+	// No one else holds the lock, so the old aggregators are no longer in
+	// use and their buckets can be reused.
+	release(oldStable, oldBurst)
+
+	return nil
+}
+
+// AggregationAlgorithms returns the aggregation algorithms of the stable and
+// the burst windows.
+func (s *Scaler) AggregationAlgorithms() (stableAlgoType, burstAlgoType string) {
+	s.aggMu.RLock()
+	defer s.aggMu.RUnlock()
+	return s.stableAlgoType, s.burstAlgoType
+}
+
+// newAggregators creates the stable and burst aggregators of the given
+// algorithm types, with the buckets allocated from the pool if it isn't nil.
+func newAggregators(cfg api.AutoscalerConfig, stableAlgoType, burstAlgoType string, pool *metrics.BucketPool) (stableAgg, burstAgg api.MetricAggregator, err error) {
+	for _, algoType := range []string{stableAlgoType, burstAlgoType} {
+		if err := validateAlgoType(algoType); err != nil {
+			return nil, nil, err
+		}
+	}
 
 	// Calculate burst window duration
 	burstWindow := max(time.Second, time.Duration(float64(cfg.StableWindow)*cfg.BurstWindowPercentage/100.0))
 
-	granularity := time.Second
+	granularity := libkpaconfig.Effective(cfg).WindowGranularity
 
-	var err error
+	stableAgg, err = newAggregator(stableAlgoType, cfg.StableWindow, granularity, pool)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create stable aggregator: %w", err)
+	}
+	burstAgg, err = newAggregator(burstAlgoType, burstWindow, granularity, pool)
+	if err != nil {
+		release(stableAgg)
+		return nil, nil, fmt.Errorf("failed to create burst aggregator: %w", err)
+	}
+	return stableAgg, burstAgg, nil
+}
 
-	switch algoType {
-	case "linear":
-		s.stableAggregator, err = metrics.NewTimeWindow(cfg.StableWindow, granularity)
-		if err != nil {
-			return fmt.Errorf("failed to create stable aggregator: %w", err)
+// newAggregator creates an aggregator of the given algorithm type, which
+// must be valid, with the buckets allocated from the pool if it isn't nil.
+func newAggregator(algoType string, window, granularity time.Duration, pool *metrics.BucketPool) (api.MetricAggregator, error) {
+	if algoType == "weighted" {
+		if pool != nil {
+			return metrics.NewWeightedTimeWindowFromPool(window, granularity, pool)
 		}
-		s.burstAggregator, err = metrics.NewTimeWindow(burstWindow, granularity)
-		if err != nil {
-			return fmt.Errorf("failed to create burst aggregator: %w", err)
-		}
-	case "weighted":
-		s.stableAggregator, err = metrics.NewWeightedTimeWindow(cfg.StableWindow, granularity)
-		if err != nil {
-			return fmt.Errorf("failed to create stable aggregator: %w", err)
+		return metrics.NewWeightedTimeWindow(window, granularity)
+	}
+	if pool != nil {
+		return metrics.NewTimeWindowFromPool(window, granularity, pool)
+	}
+	return metrics.NewTimeWindow(window, granularity)
+}
+
+// validateAlgoType returns an error if algoType is not a known aggregation
+// algorithm.
+func validateAlgoType(algoType string) error {
+	if algoType != "linear" && algoType != "weighted" {
+		return fmt.Errorf("unknown algorithm type: %s (expected 'linear' or 'weighted')", algoType)
+	}
+	return nil
+}
+
+// releaser is implemented by aggregators whose memory is pooled, like
+// metrics.TimeWindow.
+type releaser interface {
+	Release()
+}
+
+// release returns the memory of pooled aggregators to their pool.
+func release(aggs ...api.MetricAggregator) {
+	for _, agg := range aggs {
+		if r, ok := agg.(releaser); ok {
+			r.Release()
 		}
-		s.burstAggregator, err = metrics.NewWeightedTimeWindow(burstWindow, granularity)
-		if err != nil {
-			return fmt.Errorf("failed to create burst aggregator: %w", err)
+	}
+}
+
+// SetStalenessThreshold makes recommendations invalid once no metric has been
+// recorded for the given duration, instead of waiting for the stable window
+// to pass. Zero disables the threshold.
+func (s *Scaler) SetStalenessThreshold(d time.Duration) {
+	s.aggMu.Lock()
+	defer s.aggMu.Unlock()
+	s.stalenessThreshold = max(d, 0)
+	s.applyStalenessThresholdLocked()
+}
+
+// applyStalenessThresholdLocked applies the staleness threshold to the
+// aggregators. The caller must hold aggMu.
+func (s *Scaler) applyStalenessThresholdLocked() {
+	for _, agg := range []api.MetricAggregator{s.stableAggregator, s.burstAggregator} {
+		if sa, ok := agg.(stalenessAware); ok {
+			sa.SetStalenessThreshold(s.stalenessThreshold)
 		}
-	default:
-		return fmt.Errorf("unknown algorithm type: %s (expected 'linear' or 'weighted')", algoType)
 	}
+}
 
-	return nil
+// TimeSinceLastRecord returns the time passed between the most recent
+// metric record and now. It returns math.MaxInt64 if nothing has been
+// recorded yet.
+func (s *Scaler) TimeSinceLastRecord(now time.Time) time.Duration {
+	s.aggMu.RLock()
+	defer s.aggMu.RUnlock()
+	return s.timeSinceLastRecordLocked(now)
+}
+
+// timeSinceLastRecordLocked implements TimeSinceLastRecord. The caller must
+// hold aggMu.
+func (s *Scaler) timeSinceLastRecordLocked(now time.Time) time.Duration {
+	if sa, ok := s.stableAggregator.(stalenessAware); ok {
+		return sa.TimeSinceLastRecord(now)
+	}
+	return math.MaxInt64
+}
+
+// SetEvaluationInterval sets how often the scaler evaluates its metrics.
+// Between evaluations Scale returns the latest valid recommendation, so
+// scalers of slowly changing metrics, like memory, can be evaluated less
+// often than others, like request rates, registered to the same manager.
+// Zero, the default, evaluates on every call.
+func (s *Scaler) SetEvaluationInterval(d time.Duration) {
+	s.lastMu.Lock()
+	defer s.lastMu.Unlock()
+	s.evaluationInterval = max(d, 0)
+}
+
+// EvaluationInterval returns the interval set by SetEvaluationInterval.
+func (s *Scaler) EvaluationInterval() time.Duration {
+	s.lastMu.Lock()
+	defer s.lastMu.Unlock()
+	return s.evaluationInterval
+}
+
+// SetLatencyTransmitter sets the transmitter the duration of the scaling
+// decisions of the scaler is reported to, see
+// algorithm.SlidingWindowAutoscaler.SetLatencyTransmitter. Nil disables
+// reporting.
+func (s *Scaler) SetLatencyTransmitter(t transmitter.MetricTransmitter) {
+	s.algorithm.SetLatencyTransmitter(t)
 }
 
-// Scale calculates the desired scale based on current metrics.
+// Scale calculates the desired scale based on current metrics. If an
+// evaluation interval is set and hasn't passed since the latest valid
+// recommendation, that recommendation is returned instead.
 func (s *Scaler) Scale(readyPods int32, now time.Time) api.ScaleRecommendation {
-	// Get average values from the aggregators
-	stableValue := s.stableAggregator.WindowAverage(now)
-	burstValue := s.burstAggregator.WindowAverage(now)
+	s.observeReadyPods(readyPods)
+
+	s.lastMu.Lock()
+	if s.evaluationInterval > 0 && s.lastRecommendation.ScaleValid && now.Sub(s.lastScaleTime) < s.evaluationInterval {
+		rec := s.lastRecommendation
+		s.lastMu.Unlock()
+		return s.activate(rec, readyPods, now, s.algorithm.Activate)
+	}
+	s.lastMu.Unlock()
 
-	// If either window is empty, return invalid scale
-	if s.stableAggregator.IsEmpty(now) || s.burstAggregator.IsEmpty(now) {
+	rec := s.activate(s.recommend(readyPods, now, s.algorithm.Scale), readyPods, now, s.algorithm.Activate)
+
+	s.lastMu.Lock()
+	// The recommendation is returned until the next evaluation, so it stays
+	// valid at least until then.
+	if rec.ScaleValid && s.evaluationInterval > 0 {
+		rec.ValidUntil = later(rec.ValidUntil, now.Add(s.evaluationInterval))
+	}
+	s.lastRecommendation = rec
+	s.lastScaleTime = now
+	s.lastMu.Unlock()
+
+	return rec
+}
+
+// later returns the later of two times.
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// PeekScale returns the recommendation Scale would return, without changing
+// the state of the autoscaler or the latest recommendation reported by
+// Status.
+func (s *Scaler) PeekScale(readyPods int32, now time.Time) api.ScaleRecommendation {
+	return s.activate(s.recommend(readyPods, now, s.algorithm.PeekScale), readyPods, now, s.algorithm.PeekActivate)
+}
+
+// recommend passes the current window averages to the algorithm.
+func (s *Scaler) recommend(readyPods int32, now time.Time, scale func(api.MetricSnapshot, time.Time) api.ScaleRecommendation) api.ScaleRecommendation {
+	stableValue, burstValue, taken := s.windowValues(now)
+
+	// Create a metric snapshot timestamped with the latest record, so the
+	// recommendation expires with the data. The algorithm doesn't retain it,
+	// so it can be returned to the pool right after the decision.
+	snapshot := metrics.GetSnapshot(stableValue, burstValue, readyPods, taken)
+	defer metrics.PutSnapshot(snapshot)
+
+	// Delegate to the algorithm
+	return scale(snapshot, now)
+}
+
+// windowValues returns the stable and burst window averages at now, or -1 for
+// both if either window is empty or the stable window is not filled enough,
+// along with the time of the latest record. The lock is released even if an
+// aggregator panics.
+func (s *Scaler) windowValues(now time.Time) (stableValue, burstValue float64, taken time.Time) {
+	s.aggMu.RLock()
+	defer s.aggMu.RUnlock()
+
+	stableValue = s.stableAggregator.WindowAverage(now)
+	burstValue = s.burstAggregator.WindowAverage(now)
+	if s.stableAggregator.IsEmpty(now) || s.burstAggregator.IsEmpty(now) || !s.stableWindowFilled(now) {
 		stableValue = -1
 		burstValue = -1
 	}
 
-	// Create a metric snapshot
-	snapshot := metrics.NewMetricSnapshot(stableValue, burstValue, readyPods, now)
+	taken = now
+	if age := s.timeSinceLastRecordLocked(now); age > 0 && age < math.MaxInt64 {
+		taken = now.Add(-age)
+	}
+	return stableValue, burstValue, taken
+}
 
-	// Delegate to the algorithm
-	return s.algorithm.Scale(snapshot, now)
+// stableWindowFilled reports whether the stable aggregator carries data in
+// at least MinWindowFillFraction of its window. Aggregators that don't
+// report their fill are considered filled. The caller must hold aggMu.
+func (s *Scaler) stableWindowFilled(now time.Time) bool {
+	minFill := s.algorithm.GetConfig().MinWindowFillFraction
+	if minFill <= 0 {
+		return true
+	}
+	fa, ok := s.stableAggregator.(fillAware)
+	return !ok || fa.FillFraction(now) >= minFill
 }
 
 // Config returns the current autoscaler configuration.
@@ -160,8 +432,18 @@ func (s *Scaler) Config() api.AutoscalerConfig {
 	return s.algorithm.GetConfig()
 }
 
-// Update reconfigures the autoscaler with a new spec.
+// EffectiveConfig returns the current configuration as the autoscaler
+// interprets it, including the derived burst window.
+func (s *Scaler) EffectiveConfig() libkpaconfig.EffectiveConfig {
+	return s.algorithm.EffectiveConfig()
+}
+
+// Update reconfigures the autoscaler with a new spec. Changing the
+// WindowGranularity recreates the aggregators, so the recorded metrics are
+// lost.
 func (s *Scaler) Update(config api.AutoscalerConfig) error {
+	old := s.EffectiveConfig()
+
 	// Update the algorithm
 	if err := s.algorithm.Update(config); err != nil {
 		return err
@@ -169,16 +451,113 @@ func (s *Scaler) Update(config api.AutoscalerConfig) error {
 
 	// Calculate burst window duration
 	burstWindow := max(time.Second, time.Duration(float64(config.StableWindow)*config.BurstWindowPercentage/100.0))
+	if config.StableWindow != old.StableWindow || burstWindow != old.BurstWindow {
+		s.windowResizes.Add(1)
+	}
+
+	if s.EffectiveConfig().WindowGranularity != old.WindowGranularity {
+		return s.ChangeAggregationAlgorithms(s.AggregationAlgorithms())
+	}
 
 	// Resize the aggregators
+	s.aggMu.RLock()
+	defer s.aggMu.RUnlock()
 	s.stableAggregator.ResizeWindow(config.StableWindow)
 	s.burstAggregator.ResizeWindow(burstWindow)
 
 	return nil
 }
 
-// Record adds a metric value at the given time.
+// SetTarget switches the scaler between the per-pod and the total target
+// modes at runtime. If perPod is true, value becomes the TargetValue,
+// otherwise the TotalTargetValue. The target of the other mode is cleared.
+func (s *Scaler) SetTarget(value float64, perPod bool) error {
+	cfg := s.algorithm.GetConfig()
+
+	target, err := libkpaconfig.TargetFor(cfg.ScalingMetricType, value, perPod)
+	if err != nil {
+		return err
+	}
+	target.ApplyTo(&cfg)
+
+	return s.Update(cfg)
+}
+
+// Record adds a metric value at the given time. Values rejected by a
+// validator are dropped, see AddValidator.
 func (s *Scaler) Record(value float64, t time.Time) {
-	s.stableAggregator.Record(t, value)
-	s.burstAggregator.Record(t, value)
+	_ = s.TryRecord(value, t)
+}
+
+// RecordWeighted adds a metric value standing for weight samples at the given
+// time, e.g. a sample of one pod representing several pods, see
+// metrics.TimeWindow.RecordWeighted. The validators check the value before
+// it is weighted. A value with a weight of 0 stands for no samples and is
+// ignored. It returns an error if the weight is negative or if a validator
+// rejected the value.
+func (s *Scaler) RecordWeighted(value, weight float64, t time.Time) error {
+	if !(weight >= 0) {
+		return fmt.Errorf("weight = %v, must be at least 0", weight)
+	}
+	if weight == 0 {
+		return nil
+	}
+	if err := s.validate(value, t); err != nil {
+		return err
+	}
+	value = s.seedValue(value, weight)
+	s.recordAggregators(t, value, weight)
+	s.recorded.Add(1)
+	return nil
+}
+
+// recordAggregators records a value standing for weight samples into both
+// aggregators. The lock is released even if an aggregator panics.
+func (s *Scaler) recordAggregators(t time.Time, value, weight float64) {
+	s.aggMu.RLock()
+	defer s.aggMu.RUnlock()
+	recordWeighted(s.stableAggregator, t, value, weight)
+	recordWeighted(s.burstAggregator, t, value, weight)
+}
+
+// recordWeighted records a weighted value into agg. Aggregators that don't
+// implement api.WeightedRecorder record the value multiplied by the weight.
+func recordWeighted(agg api.MetricAggregator, t time.Time, value, weight float64) {
+	if wr, ok := agg.(api.WeightedRecorder); ok {
+		wr.RecordWeighted(t, value, weight)
+		return
+	}
+	agg.Record(t, value*weight)
+}
+
+// Unit returns the unit of the values recorded by the scaler, see
+// api.AutoscalerConfig.Unit.
+func (s *Scaler) Unit() api.Unit {
+	return s.algorithm.GetConfig().Unit
+}
+
+// RecordIn adds a metric value in the given unit at the given time. The value
+// is converted to the unit of the scaler. It returns an error if the units
+// can't be converted, e.g. bytes into cores, or if a validator rejected the
+// value.
+func (s *Scaler) RecordIn(value float64, unit api.Unit, t time.Time) error {
+	value, err := unit.Convert(value, s.Unit())
+	if err != nil {
+		return err
+	}
+	return s.TryRecord(value, t)
+}
+
+// NewQueueScaler creates a linear Scaler for workers consuming a queue.
+// Record the total queue depth as the metric value; the scaler recommends
+// enough workers to drain the queue within the target latency. All other
+// settings are taken from cfg.
+func NewQueueScaler(name string, cfg api.AutoscalerConfig, queue libkpaconfig.QueueTarget) (*Scaler, error) {
+	target, err := queue.Target()
+	if err != nil {
+		return nil, err
+	}
+	target.ApplyTo(&cfg)
+
+	return NewScaler(name, cfg, "linear")
 }
@@ -0,0 +1,48 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"time"
+
+	"github.com/Fedosin/libkpa/schedule"
+)
+
+// SetMinScaleSchedule makes the minimum scale follow the given schedule, e.g.
+// to keep more pods during business hours. Scale applies the higher of the
+// scheduled minimum scale and the one set by SetMinScale, bounded by the
+// maximum scale. When a scheduled minimum ends, the replica count ramps down
+// like after any other raised minimum, see SetRampDownIntervals. A nil
+// schedule removes the schedule.
+func (m *Manager) SetMinScaleSchedule(s *schedule.Schedule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.schedule = s
+}
+
+// minScaleAt returns the minimum scale that applies at the given time. The
+// caller must hold the read lock.
+func (m *Manager) minScaleAt(now time.Time) int32 {
+	minScale := m.minReplicas
+	if m.schedule != nil {
+		minScale = max(minScale, m.schedule.MinScale(now))
+	}
+	if m.maxReplicas > 0 {
+		minScale = min(minScale, m.maxReplicas)
+	}
+	return minScale
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"github.com/Fedosin/libkpa/api"
+)
+
+// scaleFromZeroSeed is the state of SetScaleFromZeroSeed.
+type scaleFromZeroSeed struct {
+	enabled bool
+
+	// atZero is whether the latest Scale call saw no ready pods.
+	atZero bool
+
+	// seeded is whether the windows were seeded since the deployment was
+	// last at zero.
+	seeded bool
+}
+
+// SetScaleFromZeroSeed makes the first request recorded while scaling from
+// zero seed the windows with ActivationScale × TargetValue. A single
+// request barely moves the window averages, so without the seed the
+// evaluations following the activation may recommend scaling back down
+// before real metrics accumulate. The recorded value is raised to the seed,
+// so the seed fades as the windows fill with real metrics. The deployment is
+// at zero once Scale was called with no ready pods. Seeding requires a per-pod
+// TargetValue and doesn't apply to utilization metrics, which are
+// proportional to the ready pods. It is disabled by default.
+func (s *Scaler) SetScaleFromZeroSeed(enabled bool) {
+	s.seedMu.Lock()
+	defer s.seedMu.Unlock()
+	s.seed.enabled = enabled
+}
+
+// ScaleFromZeroSeed reports whether seeding is enabled, see
+// SetScaleFromZeroSeed.
+func (s *Scaler) ScaleFromZeroSeed() bool {
+	s.seedMu.Lock()
+	defer s.seedMu.Unlock()
+	return s.seed.enabled
+}
+
+// observeReadyPods tracks whether the deployment is at zero.
+func (s *Scaler) observeReadyPods(readyPods int32) {
+	s.seedMu.Lock()
+	defer s.seedMu.Unlock()
+	s.seed.atZero = readyPods == 0
+	if readyPods > 0 {
+		s.seed.seeded = false
+	}
+}
+
+// seedValue returns the value to record for a value standing for weight
+// samples, raised to the seed if it is the first request while at zero.
+func (s *Scaler) seedValue(value, weight float64) float64 {
+	s.seedMu.Lock()
+	defer s.seedMu.Unlock()
+	if !s.seed.enabled || !s.seed.atZero || s.seed.seeded || weight <= 0 {
+		return value
+	}
+
+	cfg := s.algorithm.GetConfig()
+	if cfg.TargetValue <= 0 || cfg.ScalingMetricType == api.ScalingMetricUtilization {
+		return value
+	}
+	// Only requests activating the deployment seed the windows.
+	if value <= 0 || value*weight <= cfg.ActivationThreshold {
+		return value
+	}
+	s.seed.seeded = true
+	return max(value, float64(cfg.ActivationScale)*cfg.TargetValue/weight)
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/transmitter"
+)
+
+// Names of the gauges reported by SelfMetricsReporter. They describe the
+// library itself rather than the scaled workload, so they carry a libkpa_
+// prefix.
+const (
+	ScalersMetric             = "libkpa_scalers"
+	RecordsMetric             = "libkpa_records_total"
+	RecordRateMetric          = "libkpa_records_per_second"
+	SelfRejectedRecordsMetric = "libkpa_rejected_records_total"
+	WindowResizesMetric       = "libkpa_window_resizes_total"
+	MutexWaitMetric           = "libkpa_mutex_wait_seconds_total"
+	MutexContentionsMetric    = "libkpa_mutex_contentions_total"
+)
+
+const (
+	// mutexWaitRuntimeMetric is the runtime metric of MutexWaitSeconds.
+	mutexWaitRuntimeMetric = "/sync/mutex/wait/total:seconds"
+
+	// libkpaPackagePrefix is the prefix of the functions of the library in
+	// stack traces.
+	libkpaPackagePrefix = "github.com/Fedosin/libkpa/"
+)
+
+// SelfMetrics is a snapshot of the counters of a manager about the library
+// itself, e.g. for capacity planning and debugging the autoscaler rather
+// than the workload. The counters are sums over the registered scalers, so
+// they decrease when a scaler is unregistered.
+type SelfMetrics struct {
+	// Scalers is the number of registered scalers.
+	Scalers int `json:"scalers"`
+
+	// RecordsTotal is the number of values recorded, RejectedRecordsTotal
+	// the number of values rejected by validators.
+	RecordsTotal         uint64 `json:"recordsTotal"`
+	RejectedRecordsTotal uint64 `json:"rejectedRecordsTotal"`
+
+	// WindowResizesTotal is the number of updates that changed the size of
+	// the stable or the burst window.
+	WindowResizesTotal uint64 `json:"windowResizesTotal"`
+
+	// MutexWaitSeconds is the total time goroutines of the process spent
+	// blocked on sync.Mutex and sync.RWMutex, libkpa or not.
+	MutexWaitSeconds float64 `json:"mutexWaitSeconds"`
+
+	// MutexContentions is the number of contention events on the locks of
+	// the library sampled by the mutex profile, see MutexContentions.
+	MutexContentions int64 `json:"mutexContentions"`
+}
+
+// Recorded returns the number of values recorded, excluding rejected ones.
+func (s *Scaler) Recorded() uint64 {
+	return s.recorded.Load()
+}
+
+// WindowResizes returns the number of updates that changed the size of the
+// stable or the burst window.
+func (s *Scaler) WindowResizes() uint64 {
+	return s.windowResizes.Load()
+}
+
+// SelfMetrics returns the counters of the manager and its scalers.
+func (m *Manager) SelfMetrics() SelfMetrics {
+	m.mu.RLock()
+	self := SelfMetrics{Scalers: len(m.scalers)}
+	for _, s := range m.scalers {
+		self.RecordsTotal += s.Recorded()
+		self.RejectedRecordsTotal += s.Rejected()
+		self.WindowResizesTotal += s.WindowResizes()
+	}
+	m.mu.RUnlock()
+
+	self.MutexWaitSeconds = MutexWaitSeconds()
+	self.MutexContentions = MutexContentions()
+	return self
+}
+
+// MutexWaitSeconds returns the total time goroutines of the process spent
+// blocked on mutexes, as reported by the runtime. It doesn't require mutex
+// profiling, but covers all locks of the process.
+func MutexWaitSeconds() float64 {
+	sample := []metrics.Sample{{Name: mutexWaitRuntimeMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindFloat64 {
+		return 0
+	}
+	return sample[0].Value.Float64()
+}
+
+// MutexContentions returns the number of contention events on locks of the
+// library recorded by the mutex profile, i.e. events whose stack contains a
+// libkpa function. The profile is disabled by default: enable it with
+// runtime.SetMutexProfileFraction, where a fraction of n samples one in n
+// events, so the result is a sample rather than an exact count.
+func MutexContentions() int64 {
+	n, _ := runtime.MutexProfile(nil)
+	var records []runtime.BlockProfileRecord
+	for {
+		// Leave room for events recorded between the calls.
+		records = make([]runtime.BlockProfileRecord, n+16)
+		var ok bool
+		if n, ok = runtime.MutexProfile(records); ok {
+			records = records[:n]
+			break
+		}
+	}
+
+	var contentions int64
+	for _, r := range records {
+		if inLibrary(r.Stack()) {
+			contentions += r.Count
+		}
+	}
+	return contentions
+}
+
+// inLibrary reports whether a stack contains a function of the library.
+func inLibrary(stack []uintptr) bool {
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, libkpaPackagePrefix) {
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
+
+// SelfMetricsReporter reports the SelfMetrics of a manager as gauges, e.g.
+// to scrape them with the rest of the metrics of the autoscaler. Call Report
+// periodically; RecordRateMetric is the rate of records since the previous
+// call.
+type SelfMetricsReporter struct {
+	manager     *Manager
+	transmitter transmitter.MetricTransmitter
+
+	mu          sync.Mutex
+	lastRecords uint64
+	lastReport  time.Time
+}
+
+// NewSelfMetricsReporter creates a reporter of the SelfMetrics of m.
+func NewSelfMetricsReporter(m *Manager, t transmitter.MetricTransmitter) *SelfMetricsReporter {
+	return &SelfMetricsReporter{manager: m, transmitter: t}
+}
+
+// Report records the SelfMetrics of the manager at now and returns them.
+// The record rate is only reported from the second call on, and not if the
+// number of records decreased, e.g. because a scaler was unregistered.
+func (r *SelfMetricsReporter) Report(ctx context.Context, now time.Time) SelfMetrics {
+	self := r.manager.SelfMetrics()
+
+	r.transmitter.RecordGauge(ctx, ScalersMetric, float64(self.Scalers))
+	r.transmitter.RecordGauge(ctx, RecordsMetric, float64(self.RecordsTotal))
+	r.transmitter.RecordGauge(ctx, SelfRejectedRecordsMetric, float64(self.RejectedRecordsTotal))
+	r.transmitter.RecordGauge(ctx, WindowResizesMetric, float64(self.WindowResizesTotal))
+	r.transmitter.RecordGauge(ctx, MutexWaitMetric, self.MutexWaitSeconds)
+	r.transmitter.RecordGauge(ctx, MutexContentionsMetric, float64(self.MutexContentions))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if elapsed := now.Sub(r.lastReport); !r.lastReport.IsZero() && elapsed > 0 && self.RecordsTotal >= r.lastRecords {
+		rate := float64(self.RecordsTotal-r.lastRecords) / elapsed.Seconds()
+		r.transmitter.RecordGauge(ctx, RecordRateMetric, rate)
+	}
+	r.lastRecords, r.lastReport = self.RecordsTotal, now
+	return self
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	libkpaconfig "github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/transmitter"
+)
+
+func TestSelfMetrics(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := *libkpaconfig.NewDefaultAutoscalerConfig()
+	web, _ := NewScaler("web", cfg, "linear")
+	web.AddValidator(NonNegative())
+	cpu, _ := NewScaler("cpu", cfg, "weighted")
+	m := NewManager(1, 10, web, cpu)
+
+	for range 3 {
+		_ = m.Record("web", 10, now)
+	}
+	_ = m.Record("web", -1, now)
+	_ = cpu.RecordWeighted(10, 2, now)
+
+	// Only updates changing a window size count as resizes.
+	if err := web.Update(cfg); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	cfg.StableWindow = 120 * time.Second
+	if err := web.Update(cfg); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	cfg.BurstWindowPercentage = 20
+	if err := cpu.Update(cfg); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	self := m.SelfMetrics()
+	if self.Scalers != 2 {
+		t.Errorf("Scalers = %d, want 2", self.Scalers)
+	}
+	if self.RecordsTotal != 4 {
+		t.Errorf("RecordsTotal = %d, want 4", self.RecordsTotal)
+	}
+	if self.RejectedRecordsTotal != 1 {
+		t.Errorf("RejectedRecordsTotal = %d, want 1", self.RejectedRecordsTotal)
+	}
+	if self.WindowResizesTotal != 2 {
+		t.Errorf("WindowResizesTotal = %d, want 2", self.WindowResizesTotal)
+	}
+	if self.MutexWaitSeconds < 0 {
+		t.Errorf("MutexWaitSeconds = %v, want at least 0", self.MutexWaitSeconds)
+	}
+}
+
+func TestSelfMetricsReporter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	scaler, _ := NewScaler("web", *libkpaconfig.NewDefaultAutoscalerConfig(), "linear")
+	m := NewManager(1, 10, scaler)
+	var buf bytes.Buffer
+	r := NewSelfMetricsReporter(m, transmitter.NewLogTransmitter(log.New(&buf, "", 0), nil))
+	ctx := context.Background()
+
+	r.Report(ctx, now)
+	if strings.Contains(buf.String(), RecordRateMetric) {
+		t.Errorf("first report includes the record rate: %q", buf.String())
+	}
+	for _, name := range []string{ScalersMetric, RecordsMetric, SelfRejectedRecordsMetric, WindowResizesMetric, MutexWaitMetric, MutexContentionsMetric} {
+		if !strings.Contains(buf.String(), "metric: "+name+"{} =") {
+			t.Errorf("report misses %s: %q", name, buf.String())
+		}
+	}
+
+	for range 10 {
+		scaler.Record(10, now)
+	}
+	buf.Reset()
+	r.Report(ctx, now.Add(5*time.Second))
+	if want := "metric: libkpa_records_per_second{} = 2.00"; !strings.Contains(buf.String(), want) {
+		t.Errorf("report = %q, want %q", buf.String(), want)
+	}
+
+	// A decreasing number of records doesn't report a negative rate.
+	m.Unregister("web")
+	buf.Reset()
+	r.Report(ctx, now.Add(10*time.Second))
+	if strings.Contains(buf.String(), RecordRateMetric) {
+		t.Errorf("report after unregistering includes the record rate: %q", buf.String())
+	}
+}
+
+func TestInLibrary(t *testing.T) {
+	stack := make([]uintptr, 32)
+	if n := runtime.Callers(0, stack); !inLibrary(stack[:n]) {
+		t.Error("inLibrary of a manager stack = false, want true")
+	}
+	n := runtime.Callers(0, stack)
+	// Skip runtime.Callers and this function.
+	if inLibrary(stack[2:n]) {
+		t.Error("inLibrary of the testing stack = true, want false")
+	}
+	if got := MutexContentions(); got < 0 {
+		t.Errorf("MutexContentions() = %d, want at least 0", got)
+	}
+}
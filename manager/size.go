@@ -0,0 +1,78 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"time"
+	"unsafe"
+)
+
+// sizer is implemented by aggregators reporting their memory usage, like
+// metrics.TimeWindow.
+type sizer interface {
+	SizeBytes() int64
+}
+
+// SizeBytes returns the approximate memory used by the scaler in bytes: the
+// autoscaler, the metric windows and the scaler itself. Aggregators that
+// don't report their size, like custom ones, are not included.
+func (s *Scaler) SizeBytes() int64 {
+	size := int64(unsafe.Sizeof(*s)) + int64(len(s.name)) + s.algorithm.SizeBytes()
+	size += s.aggregatorsSizeBytes()
+
+	s.validateMu.RLock()
+	size += int64(cap(s.validators)) * int64(unsafe.Sizeof(RecordValidator(nil)))
+	s.validateMu.RUnlock()
+	return size
+}
+
+// SizeBytes returns the approximate memory used by the manager and all its
+// scalers in bytes.
+func (m *Manager) SizeBytes() int64 {
+	size := int64(unsafe.Sizeof(*m))
+
+	m.mu.RLock()
+	for name, s := range m.scalers {
+		size += int64(unsafe.Sizeof(name)+unsafe.Sizeof(s)) + int64(len(name)) + s.SizeBytes()
+	}
+	m.mu.RUnlock()
+
+	m.churnMu.Lock()
+	size += int64(cap(m.churn.changes)) * int64(unsafe.Sizeof(time.Time{}))
+	m.churnMu.Unlock()
+
+	m.subMu.Lock()
+	for _, sub := range m.subscriptions {
+		size += int64(unsafe.Sizeof(sub)+unsafe.Sizeof(*sub)) + int64(cap(sub.ch))*int64(unsafe.Sizeof(Recommendation{}))
+	}
+	m.subMu.Unlock()
+	return size
+}
+
+// aggregatorsSizeBytes returns the memory used by the aggregators that report
+// their size.
+func (s *Scaler) aggregatorsSizeBytes() int64 {
+	s.aggMu.RLock()
+	defer s.aggMu.RUnlock()
+	var size int64
+	for _, agg := range []any{s.stableAggregator, s.burstAggregator} {
+		if sz, ok := agg.(sizer); ok {
+			size += sz.SizeBytes()
+		}
+	}
+	return size
+}
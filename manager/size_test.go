@@ -0,0 +1,49 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"testing"
+	"time"
+
+	libkpaconfig "github.com/Fedosin/libkpa/config"
+)
+
+func TestSizeBytes(t *testing.T) {
+	cfg := *libkpaconfig.NewDefaultAutoscalerConfig()
+	short, _ := NewScaler("short", cfg, "linear")
+	cfg.StableWindow = 600 * time.Second
+	long, _ := NewScaler("long", cfg, "weighted")
+
+	// The stable and burst windows of the long scaler have 540 + 54 more
+	// buckets of a float64 value and an int count.
+	if got, want := long.SizeBytes()-short.SizeBytes(), int64(594*16); got < want {
+		t.Errorf("size difference of a 600s and a 60s scaler = %d, want at least %d", got, want)
+	}
+
+	m := NewManager(1, 10)
+	empty := m.SizeBytes()
+	m.Register(short)
+	m.Register(long)
+	if got, want := m.SizeBytes(), empty+short.SizeBytes()+long.SizeBytes(); got < want {
+		t.Errorf("SizeBytes() = %d, want at least %d", got, want)
+	}
+	m.Unregister("long")
+	if got, want := m.SizeBytes(), empty+long.SizeBytes(); got >= want {
+		t.Errorf("SizeBytes() after Unregister = %d, want less than %d", got, want)
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"time"
+)
+
+// defaultSubscriptionBuffer is the channel capacity used when none is specified.
+const defaultSubscriptionBuffer = 16
+
+// Recommendation is an event emitted to subscribers when the desired replica
+// count of a manager changes.
+type Recommendation struct {
+	// DesiredPodCount is the new desired replica count.
+	DesiredPodCount int32
+
+	// PreviousPodCount is the previously emitted desired replica count.
+	// It is -1 for the first event of a subscription.
+	PreviousPodCount int32
+
+	// ReadyPodCount is the number of ready pods the decision was based on.
+	ReadyPodCount int32
+
+	// Time is when the decision was made.
+	Time time.Time
+
+	// ValidUntil is when the decision becomes stale, see
+	// ScaleDetails.ValidUntil. Consumers applying events later should
+	// discard them afterwards.
+	ValidUntil time.Time
+}
+
+// SubscribeOption configures a subscription.
+type SubscribeOption func(*subscription)
+
+// WithDebounce delays events until the desired replica count has been
+// unchanged for at least d. The delay is measured with the timestamps passed
+// to Record and Scale, so a pending change is emitted by the first evaluation
+// after the delay has passed.
+func WithDebounce(d time.Duration) SubscribeOption {
+	return func(s *subscription) {
+		s.debounce = max(0, d)
+	}
+}
+
+// WithBufferSize sets the capacity of the subscription channel.
+// Default is 16.
+func WithBufferSize(n int) SubscribeOption {
+	return func(s *subscription) {
+		if n > 0 {
+			s.buffer = n
+		}
+	}
+}
+
+// subscription holds the state of a single subscriber.
+type subscription struct {
+	ch       chan Recommendation
+	debounce time.Duration
+	buffer   int
+
+	emitted     bool
+	lastEmitted int32
+
+	pending      bool
+	pendingValue int32
+	pendingSince time.Time
+}
+
+// observe processes a new decision and emits an event if it is due.
+func (s *subscription) observe(desired, readyPods int32, validUntil, now time.Time) {
+	if s.emitted && desired == s.lastEmitted {
+		s.pending = false
+		return
+	}
+
+	if s.debounce > 0 {
+		if !s.pending || s.pendingValue != desired {
+			s.pending = true
+			s.pendingValue = desired
+			s.pendingSince = now
+			return
+		}
+		if now.Sub(s.pendingSince) < s.debounce {
+			return
+		}
+	}
+
+	previous := int32(-1)
+	if s.emitted {
+		previous = s.lastEmitted
+	}
+	s.emitted = true
+	s.lastEmitted = desired
+	s.pending = false
+
+	s.send(Recommendation{
+		DesiredPodCount:  desired,
+		PreviousPodCount: previous,
+		ReadyPodCount:    readyPods,
+		Time:             now,
+		ValidUntil:       validUntil,
+	})
+}
+
+// send delivers the event without blocking. If the channel is full, the
+// oldest event is dropped, so the subscriber always receives the latest one.
+func (s *subscription) send(r Recommendation) {
+	for {
+		select {
+		case s.ch <- r:
+			return
+		default:
+		}
+		select {
+		case <-s.ch:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel that receives an event whenever the desired
+// replica count changes. Decisions are made by every Scale call and, while
+// there are subscribers, by every Record call using the ready pod count of the
+// latest Scale call, so consumers don't have to poll Scale on a timer. The
+// decisions on Record are evaluated like PeekScale, so they don't change the
+// state of the manager or its scalers, and Scale returns the same replica
+// counts with or without subscribers.
+// The channel is closed by Unsubscribe or Close.
+func (m *Manager) Subscribe(opts ...SubscribeOption) <-chan Recommendation {
+	s := &subscription{buffer: defaultSubscriptionBuffer}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.ch = make(chan Recommendation, s.buffer)
+
+	m.subMu.Lock()
+	defer m.subMu.Unlock()
+
+	if m.subsClosed {
+		close(s.ch)
+		return s.ch
+	}
+	m.subscriptions = append(m.subscriptions, s)
+	return s.ch
+}
+
+// Unsubscribe stops delivering events to the channel and closes it.
+func (m *Manager) Unsubscribe(ch <-chan Recommendation) {
+	m.subMu.Lock()
+	defer m.subMu.Unlock()
+
+	for i, s := range m.subscriptions {
+		if s.ch == ch {
+			close(s.ch)
+			m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+			return
+		}
+	}
+}
+
+// hasSubscribers reports whether there are active subscriptions.
+func (m *Manager) hasSubscribers() bool {
+	m.subMu.Lock()
+	defer m.subMu.Unlock()
+	return len(m.subscriptions) > 0
+}
+
+// publish passes a decision to all subscribers.
+func (m *Manager) publish(desired, readyPods int32, validUntil, now time.Time) {
+	m.subMu.Lock()
+	defer m.subMu.Unlock()
+
+	for _, s := range m.subscriptions {
+		s.observe(desired, readyPods, validUntil, now)
+	}
+}
+
+// closeSubscriptions closes all subscription channels.
+func (m *Manager) closeSubscriptions() {
+	m.subMu.Lock()
+	defer m.subMu.Unlock()
+
+	for _, s := range m.subscriptions {
+		close(s.ch)
+	}
+	m.subscriptions = nil
+	m.subsClosed = true
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Fedosin/libkpa/transmitter"
+)
+
+// RejectedRecordsMetric is the name of the gauge reporting the number of
+// values rejected by the validators of a scaler.
+const RejectedRecordsMetric = "rejected_records_total"
+
+// ErrRejected is wrapped by the errors of values rejected by a validator.
+var ErrRejected = errors.New("metric value rejected")
+
+// RecordValidator checks a metric value before it is recorded. A non-nil
+// error rejects the value.
+type RecordValidator func(value float64, t time.Time) error
+
+// NonNegative rejects negative values, e.g. of concurrency or request rates.
+func NonNegative() RecordValidator {
+	return func(value float64, _ time.Time) error {
+		if value < 0 {
+			return fmt.Errorf("value %v is negative", value)
+		}
+		return nil
+	}
+}
+
+// Finite rejects NaN and infinite values.
+func Finite() RecordValidator {
+	return func(value float64, _ time.Time) error {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("value %v is not finite", value)
+		}
+		return nil
+	}
+}
+
+// AtMost rejects values above limit, e.g. a sanity cap for the metric.
+func AtMost(limit float64) RecordValidator {
+	return func(value float64, _ time.Time) error {
+		if value > limit {
+			return fmt.Errorf("value %v exceeds %v", value, limit)
+		}
+		return nil
+	}
+}
+
+// AddValidator registers a validator executed on every Record. Rejected
+// values are not recorded. Validators run in the order they were added.
+func (s *Scaler) AddValidator(v RecordValidator) {
+	if v == nil {
+		return
+	}
+	s.validateMu.Lock()
+	defer s.validateMu.Unlock()
+	s.validators = append(s.validators, v)
+}
+
+// SetRejectionTransmitter sets the transmitter the number of rejected values
+// is reported to as RejectedRecordsMetric after every rejection. Nil
+// disables reporting.
+func (s *Scaler) SetRejectionTransmitter(t transmitter.MetricTransmitter) {
+	s.validateMu.Lock()
+	defer s.validateMu.Unlock()
+	s.rejectionTransmitter = t
+}
+
+// Rejected returns the number of values rejected by the validators.
+func (s *Scaler) Rejected() uint64 {
+	return s.rejected.Load()
+}
+
+// TryRecord is Record, but returns an error wrapping ErrRejected if a
+// validator rejected the value.
+func (s *Scaler) TryRecord(value float64, t time.Time) error {
+	if err := s.validate(value, t); err != nil {
+		return err
+	}
+	value = s.seedValue(value, 1)
+	s.recordAggregators(t, value, 1)
+	s.recorded.Add(1)
+	return nil
+}
+
+// validate runs the validators and counts rejections.
+func (s *Scaler) validate(value float64, t time.Time) error {
+	s.validateMu.RLock()
+	defer s.validateMu.RUnlock()
+
+	for _, v := range s.validators {
+		if err := v(value, t); err != nil {
+			rejected := s.rejected.Add(1)
+			if s.rejectionTransmitter != nil {
+				s.rejectionTransmitter.RecordGauge(context.Background(), RejectedRecordsMetric, float64(rejected))
+			}
+			return fmt.Errorf("%w: %w", ErrRejected, err)
+		}
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ package maxtimewindow
 import (
 	"math"
 	"time"
+	"unsafe"
 )
 
 // TimeWindow is a descending minima window whose indexes are calculated based
@@ -30,13 +31,18 @@ type TimeWindow struct {
 	granularity time.Duration
 }
 
-// NewTimeWindow creates a new TimeWindow.
+// NewTimeWindow creates a new TimeWindow. The granularity is rounded down to
+// whole seconds and is at least a second, and the window has at least one
+// bucket.
 func NewTimeWindow(duration, granularity time.Duration) *TimeWindow {
-	buckets := int(math.Ceil(float64(duration) / float64(granularity)))
+	granularity = max(granularity.Truncate(time.Second), time.Second)
+	buckets := max(int(math.Ceil(float64(duration)/float64(granularity))), 1)
 	return &TimeWindow{window: newWindow(buckets), granularity: granularity}
 }
 
-// Record records a value in the bucket derived from the given time.
+// Record records a value in the bucket derived from the given time. Values
+// recorded with a time before the latest one are recorded in the latest
+// bucket.
 func (t *TimeWindow) Record(now time.Time, value int32) {
 	index := int(now.Unix()) / int(t.granularity.Seconds())
 	t.window.Record(index, value)
@@ -47,3 +53,13 @@ func (t *TimeWindow) Record(now time.Time, value int32) {
 func (t *TimeWindow) Current() int32 {
 	return t.window.Current()
 }
+
+// Clone returns an independent copy of the window.
+func (t *TimeWindow) Clone() *TimeWindow {
+	return &TimeWindow{window: t.window.clone(), granularity: t.granularity}
+}
+
+// SizeBytes returns the approximate memory used by the window in bytes.
+func (t *TimeWindow) SizeBytes() int64 {
+	return int64(unsafe.Sizeof(*t)) + t.window.sizeBytes()
+}
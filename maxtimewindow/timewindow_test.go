@@ -90,6 +90,47 @@ func TestTimedWindowMax(t *testing.T) {
 	}
 }
 
+func TestTimeWindowDegenerate(t *testing.T) {
+	now := time.Now()
+	for _, tt := range []struct {
+		name                  string
+		duration, granularity time.Duration
+	}{
+		{name: "zero duration", duration: 0, granularity: time.Second},
+		{name: "sub-second granularity", duration: 5 * time.Second, granularity: 100 * time.Millisecond},
+		{name: "zero granularity", duration: 5 * time.Second, granularity: 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewTimeWindow(tt.duration, tt.granularity)
+			m.Record(now, 5)
+			m.Record(now.Add(-time.Minute), 3)
+			if got := m.Current(); got != 5 {
+				t.Errorf("Current() = %d, expected 5", got)
+			}
+		})
+	}
+}
+
+func TestTimeWindowClone(t *testing.T) {
+	now := time.Now()
+	m := NewTimeWindow(5*time.Second, 1*time.Second)
+	m.Record(now, 5)
+
+	c := m.Clone()
+	c.Record(now.Add(time.Second), 10)
+	if got := m.Current(); got != 5 {
+		t.Errorf("Current() of the original = %d, expected 5", got)
+	}
+	if got := c.Current(); got != 10 {
+		t.Errorf("Current() of the clone = %d, expected 10", got)
+	}
+
+	m.Record(now.Add(time.Second), 3)
+	if got := c.Current(); got != 10 {
+		t.Errorf("Current() of the clone = %d after recording into the original, expected 10", got)
+	}
+}
+
 func BenchmarkLargeTimeWindowCreate(b *testing.B) {
 	for _, duration := range []time.Duration{5 * time.Minute, 15 * time.Minute, 30 * time.Minute, 45 * time.Minute} {
 		b.Run(fmt.Sprintf("duration-%v", duration), func(b *testing.B) {
@@ -133,3 +174,14 @@ func BenchmarkLargeTimeWindowDescendingRecord(b *testing.B) {
 		})
 	}
 }
+
+func TestTimeWindowSizeBytes(t *testing.T) {
+	small := NewTimeWindow(10*time.Second, time.Second)
+	large := NewTimeWindow(100*time.Second, time.Second)
+	if got := small.SizeBytes(); got <= 0 {
+		t.Errorf("SizeBytes() = %d, want positive", got)
+	}
+	if small.SizeBytes() >= large.SizeBytes() {
+		t.Errorf("SizeBytes() of 10 buckets = %d, want less than %d of 100 buckets", small.SizeBytes(), large.SizeBytes())
+	}
+}
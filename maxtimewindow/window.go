@@ -20,6 +20,7 @@ package maxtimewindow
 
 import (
 	"fmt"
+	"unsafe"
 )
 
 type entry struct {
@@ -41,8 +42,14 @@ func newWindow(size int) *window {
 	}
 }
 
-// Record records a value for a monotonically increasing index.
+// Record records a value for a monotonically increasing index. A value
+// recorded for an index lower than the latest one is recorded for the latest
+// index, so late values can't overflow the buffer.
 func (m *window) Record(index int, v int32) {
+	if m.length > 0 {
+		index = max(index, m.maxima[m.index(m.first+m.length-1)].index)
+	}
+
 	// Step One: Remove any elements where v > element.
 	// An element that's lower than the new element can never influence the
 	// maximum again, because the new element is both larger _and_ more
@@ -101,6 +108,20 @@ func (m *window) Current() int32 {
 	return m.maxima[m.first].value
 }
 
+// clone returns an independent copy of the window buffer.
+func (m *window) clone() *window {
+	return &window{
+		maxima: append([]entry(nil), m.maxima...),
+		first:  m.first,
+		length: m.length,
+	}
+}
+
 func (m *window) index(i int) int {
 	return i % len(m.maxima)
 }
+
+// sizeBytes returns the approximate memory used by the window in bytes.
+func (m *window) sizeBytes() int64 {
+	return int64(unsafe.Sizeof(*m)) + int64(cap(m.maxima))*int64(unsafe.Sizeof(entry{}))
+}
@@ -86,6 +86,20 @@ func TestWindowMax(t *testing.T) {
 		name:   "windowing out 8",
 		values: []int32{5, 8, 5, 7, 5, 5, 1, 4, 4, 4, 4, 9, 3, 4, 2, 1, 0},
 		expect: []int32{5, 8, 8, 8, 8, 8, 7, 7, 5, 5, 4, 9, 9, 9, 9, 9, 4},
+	}, {
+		name:   "late values",
+		values: []int32{1, 2, 3, 4, 5, 6, 7},
+		indexFunc: func(i int) int {
+			return 10 - i
+		},
+		expect: []int32{1, 2, 3, 4, 5, 6, 7},
+	}, {
+		name:   "late descending values",
+		values: []int32{7, 6, 5, 4, 3, 2, 1},
+		indexFunc: func(i int) int {
+			return 10 - i
+		},
+		expect: []int32{7, 7, 7, 7, 7, 7, 7},
 	}, {
 		name:   "multiple with same index, ascending",
 		values: []int32{1, 2, 3, 4, 5, 6, 7},
@@ -0,0 +1,199 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultSlabBuckets is the number of buckets of a slab of a BucketPool
+// created with a non-positive slab size. A slab holds the buckets of about a
+// hundred 600s windows with a granularity of 1s.
+const DefaultSlabBuckets = 64 * 1024
+
+// BucketPool allocates the buckets of many windows from a few large slabs,
+// rather than allocating two slices per window. With tens of thousands of
+// windows, e.g. of a multitenant.Manager, this reduces heap fragmentation
+// and the number of objects the garbage collector has to track. Buckets of
+// released windows are reused by windows of the same size.
+//
+// A slab is only freed once all windows allocated from it were released or
+// garbage collected, so a pool suits many windows of similar lifetimes.
+// BucketPool is safe for concurrent use.
+type BucketPool struct {
+	mu     sync.Mutex
+	values slab[float64]
+	counts slab[int]
+}
+
+// BucketPoolStats describes the memory held by a BucketPool.
+type BucketPoolStats struct {
+	// Slabs is the number of slabs allocated so far.
+	Slabs int
+
+	// InUse is the number of buckets used by windows.
+	InUse int
+
+	// Free is the number of buckets of released windows waiting for reuse.
+	Free int
+}
+
+// NewBucketPool creates a pool allocating slabs of the given number of
+// buckets. If slabBuckets is not positive, DefaultSlabBuckets is used.
+// Windows with more buckets than a slab are allocated on their own.
+func NewBucketPool(slabBuckets int) *BucketPool {
+	if slabBuckets <= 0 {
+		slabBuckets = DefaultSlabBuckets
+	}
+	return &BucketPool{
+		values: slab[float64]{size: slabBuckets},
+		counts: slab[int]{size: slabBuckets},
+	}
+}
+
+// Stats returns the current usage of the pool.
+func (p *BucketPool) Stats() BucketPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return BucketPoolStats{
+		Slabs: p.values.slabs,
+		InUse: p.values.inUse,
+		Free:  p.values.free,
+	}
+}
+
+// alloc returns zeroed buckets and counts of length n.
+func (p *BucketPool) alloc(n int) ([]float64, []int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.values.alloc(n), p.counts.alloc(n)
+}
+
+// release returns buckets and counts allocated by alloc for reuse.
+func (p *BucketPool) release(buckets []float64, counts []int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.values.release(buckets)
+	p.counts.release(counts)
+}
+
+// slab carves fixed size chunks out of large slices.
+type slab[T any] struct {
+	size     int
+	current  []T           // the unused rest of the latest slab
+	freeList map[int][][]T // released chunks by length
+
+	slabs int
+	inUse int
+	free  int
+}
+
+// alloc returns a zeroed chunk of length n.
+func (s *slab[T]) alloc(n int) []T {
+	s.inUse += n
+	if chunks := s.freeList[n]; len(chunks) > 0 {
+		chunk := chunks[len(chunks)-1]
+		s.freeList[n] = chunks[:len(chunks)-1]
+		s.free -= n
+		clear(chunk)
+		return chunk
+	}
+	if n > s.size {
+		return make([]T, n)
+	}
+	if len(s.current) < n {
+		s.current = make([]T, s.size)
+		s.slabs++
+	}
+	// Limit the capacity, so appending to a chunk never overwrites the next one.
+	chunk := s.current[:n:n]
+	s.current = s.current[n:]
+	return chunk
+}
+
+// release makes a chunk returned by alloc available for reuse.
+func (s *slab[T]) release(chunk []T) {
+	n := len(chunk)
+	if n == 0 {
+		return
+	}
+	if s.freeList == nil {
+		s.freeList = make(map[int][][]T)
+	}
+	s.freeList[n] = append(s.freeList[n], chunk)
+	s.inUse -= n
+	s.free += n
+}
+
+// NewTimeWindowFromPool is NewTimeWindow with the buckets allocated from the
+// pool. Call Release once the window is no longer used to make its buckets
+// available to other windows.
+func NewTimeWindowFromPool(window, granularity time.Duration, pool *BucketPool) (*TimeWindow, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("bucket pool cannot be nil")
+	}
+	return newTimeWindow(window, granularity, pool)
+}
+
+// NewWeightedTimeWindowFromPool is NewWeightedTimeWindow with the buckets
+// allocated from the pool, see NewTimeWindowFromPool.
+func NewWeightedTimeWindowFromPool(window, granularity time.Duration, pool *BucketPool) (*WeightedTimeWindow, error) {
+	tw, err := NewTimeWindowFromPool(window, granularity, pool)
+	if err != nil {
+		return nil, err
+	}
+	return &WeightedTimeWindow{
+		TimeWindow:     tw,
+		smoothingCoeff: computeSmoothingCoeff(float64(len(tw.buckets))),
+	}, nil
+}
+
+// Release returns the buckets of a window created from a BucketPool to the
+// pool. The window must not be used afterwards: it keeps working without
+// affecting other windows, but only remembers a single bucket. Release does
+// nothing for windows not created from a pool.
+func (t *TimeWindow) Release() {
+	t.bucketsMutex.Lock()
+	defer t.bucketsMutex.Unlock()
+
+	if t.pool == nil {
+		return
+	}
+	t.pool.release(t.buckets, t.bucketCounts)
+	t.pool = nil
+	t.buckets, t.bucketCounts = make([]float64, 1), make([]int, 1)
+	t.windowTotal = 0
+}
+
+// allocBuckets returns zeroed buckets and counts of length n, from the pool
+// of the window if it has one.
+func (t *TimeWindow) allocBuckets(n int) ([]float64, []int) {
+	if t.pool != nil {
+		return t.pool.alloc(n)
+	}
+	return make([]float64, n), make([]int, n)
+}
+
+// releaseBucketsLocked returns the buckets to the pool of the window if it
+// has one. The caller must hold the lock and replace the buckets.
+func (t *TimeWindow) releaseBucketsLocked() {
+	if t.pool != nil {
+		t.pool.release(t.buckets, t.bucketCounts)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+func TestBucketPool(t *testing.T) {
+	pool := NewBucketPool(100)
+
+	a, ac := pool.alloc(40)
+	b, _ := pool.alloc(40)
+	if got := pool.Stats(); got != (BucketPoolStats{Slabs: 1, InUse: 80}) {
+		t.Errorf("Stats() = %+v, want 1 slab with 80 buckets in use", got)
+	}
+	if len(a) != 40 || cap(a) != 40 || len(ac) != 40 {
+		t.Errorf("alloc(40) returned len %d, cap %d and %d counts", len(a), cap(a), len(ac))
+	}
+	// Chunks of a slab don't overlap.
+	a[39] = 1
+	if b[0] != 0 {
+		t.Error("chunks overlap")
+	}
+	_ = append(a, 2)
+	if b[0] != 0 {
+		t.Error("appending to a chunk overwrote the next one")
+	}
+
+	// The rest of the slab is too small, so a new one is allocated.
+	pool.alloc(40)
+	if got := pool.Stats().Slabs; got != 2 {
+		t.Errorf("Slabs = %d, want 2", got)
+	}
+
+	// Released chunks are reused zeroed by chunks of the same size.
+	pool.release(a, ac)
+	if got := pool.Stats(); got != (BucketPoolStats{Slabs: 2, InUse: 80, Free: 40}) {
+		t.Errorf("Stats() after release = %+v", got)
+	}
+	reused, _ := pool.alloc(40)
+	if &reused[0] != &a[0] || reused[39] != 0 {
+		t.Error("released chunk was not reused zeroed")
+	}
+	if got := pool.Stats(); got != (BucketPoolStats{Slabs: 2, InUse: 120}) {
+		t.Errorf("Stats() after reuse = %+v", got)
+	}
+
+	// Chunks larger than a slab are allocated on their own.
+	pool.alloc(101)
+	if got := pool.Stats(); got.Slabs != 2 || got.InUse != 221 {
+		t.Errorf("Stats() after a large alloc = %+v", got)
+	}
+
+	if got := NewBucketPool(0).values.size; got != DefaultSlabBuckets {
+		t.Errorf("default slab size = %d, want %d", got, DefaultSlabBuckets)
+	}
+}
+
+func TestWindowsFromPool(t *testing.T) {
+	if _, err := NewTimeWindowFromPool(time.Minute, time.Second, nil); err == nil {
+		t.Error("expected error for a nil pool")
+	}
+	pool := NewBucketPool(1000)
+	if _, err := NewTimeWindowFromPool(time.Second, 2*time.Second, pool); err == nil {
+		t.Error("expected error for a window smaller than the granularity")
+	}
+
+	tn := time.Now().Truncate(time.Second)
+	heap, _ := NewTimeWindow(time.Minute, time.Second)
+	pooled, err := NewTimeWindowFromPool(time.Minute, time.Second, pool)
+	if err != nil {
+		t.Fatalf("NewTimeWindowFromPool failed: %v", err)
+	}
+	weighted, _ := NewWeightedTimeWindow(time.Minute, time.Second)
+	pooledWeighted, err := NewWeightedTimeWindowFromPool(time.Minute, time.Second, pool)
+	if err != nil {
+		t.Fatalf("NewWeightedTimeWindowFromPool failed: %v", err)
+	}
+	if got := pool.Stats().InUse; got != 120 {
+		t.Errorf("InUse = %d, want 120", got)
+	}
+	if got, want := pooledWeighted.SmoothingCoeff(), weighted.SmoothingCoeff(); got != want {
+		t.Errorf("SmoothingCoeff() = %v, want %v", got, want)
+	}
+
+	// Windows from a pool compute the same results as the others.
+	for i := range 90 {
+		now := tn.Add(time.Duration(i) * time.Second)
+		for _, w := range []api.MetricAggregator{heap, pooled, weighted, pooledWeighted} {
+			w.Record(now, float64(i%7))
+		}
+		if got, want := pooled.WindowAverage(now), heap.WindowAverage(now); got != want {
+			t.Errorf("WindowAverage at %d = %v, want %v", i, got, want)
+		}
+		if got, want := pooledWeighted.WindowAverage(now), weighted.WindowAverage(now); got != want {
+			t.Errorf("weighted WindowAverage at %d = %v, want %v", i, got, want)
+		}
+	}
+
+	// Resizing moves the window to buckets of the new size from the pool.
+	pooled.ResizeWindow(30 * time.Second)
+	heap.ResizeWindow(30 * time.Second)
+	now := tn.Add(90 * time.Second)
+	if got, want := pooled.WindowAverage(now), heap.WindowAverage(now); got != want {
+		t.Errorf("WindowAverage after resize = %v, want %v", got, want)
+	}
+	if got := pool.Stats(); got.InUse != 90 || got.Free != 60 {
+		t.Errorf("Stats() after resize = %+v, want 90 buckets in use and 60 free", got)
+	}
+
+	// Released windows return their buckets and stay safe to call.
+	pooled.Release()
+	pooledWeighted.Release()
+	pooled.Release()
+	if got := pool.Stats(); got.InUse != 0 || got.Free != 150 {
+		t.Errorf("Stats() after release = %+v, want all 150 buckets free", got)
+	}
+	pooled.Record(now, 1)
+	pooled.WindowAverage(now)
+
+	// Windows not created from a pool ignore Release.
+	want := heap.WindowAverage(now)
+	heap.Release()
+	if got := heap.WindowAverage(now); got != want {
+		t.Errorf("WindowAverage after Release of a heap window = %v, want %v", got, want)
+	}
+}
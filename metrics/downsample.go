@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Point is a single value of a time series.
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Aggregation defines how the points of one downsampling interval are
+// combined into one.
+type Aggregation int
+
+const (
+	// AggregateMean takes the mean of the points.
+	AggregateMean Aggregation = iota
+
+	// AggregateMax takes the maximum of the points.
+	AggregateMax
+
+	// AggregateMin takes the minimum of the points.
+	AggregateMin
+
+	// AggregateSum takes the sum of the points.
+	AggregateSum
+)
+
+// Downsample reduces a series of points, sorted by time, to one point per
+// resolution interval, e.g. to embed the history of a window in a status
+// field or to draw a sparkline without shipping every bucket. Intervals are
+// aligned to the resolution and each output point carries the start of its
+// interval. Intervals without points are omitted.
+func Downsample(points []Point, resolution time.Duration, agg Aggregation) ([]Point, error) {
+	if resolution <= 0 {
+		return nil, fmt.Errorf("resolution must be positive, got %v", resolution)
+	}
+	if agg < AggregateMean || agg > AggregateSum {
+		return nil, fmt.Errorf("unknown aggregation %d", agg)
+	}
+
+	var ret []Point
+	n := 0
+	for _, p := range points {
+		start := p.Time.Truncate(resolution)
+		if len(ret) == 0 || !ret[len(ret)-1].Time.Equal(start) {
+			finishInterval(ret, n, agg)
+			ret = append(ret, Point{Time: start, Value: p.Value})
+			n = 1
+			continue
+		}
+
+		last := &ret[len(ret)-1]
+		switch agg {
+		case AggregateMean, AggregateSum:
+			last.Value += p.Value
+		case AggregateMax:
+			last.Value = math.Max(last.Value, p.Value)
+		case AggregateMin:
+			last.Value = math.Min(last.Value, p.Value)
+		}
+		n++
+	}
+	finishInterval(ret, n, agg)
+	return ret, nil
+}
+
+// finishInterval turns the sum of the last interval into the mean.
+func finishInterval(points []Point, n int, agg Aggregation) {
+	if agg == AggregateMean && len(points) > 0 && n > 1 {
+		points[len(points)-1].Value /= float64(n)
+	}
+}
+
+// Buckets returns the buckets of the window ending at now, oldest first,
+// starting with the first recorded value within the window. Buckets without
+// data are resolved according to the empty bucket policy, see
+// SetEmptyBucketPolicy.
+func (t *TimeWindow) Buckets(now time.Time) []Point {
+	now = now.Truncate(t.granularity)
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+	return t.policyBucketsLocked(now)
+}
+
+// History returns the buckets of the window ending at now, downsampled to
+// the given resolution.
+func (t *TimeWindow) History(now time.Time, resolution time.Duration, agg Aggregation) ([]Point, error) {
+	return Downsample(t.Buckets(now), resolution, agg)
+}
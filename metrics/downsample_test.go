@@ -0,0 +1,112 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDownsample(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+
+	// Points at 0..4s, 10s, 12s and 25s.
+	points := []Point{
+		{at(0), 1}, {at(1), 2}, {at(2), 3}, {at(3), 4}, {at(4), 5},
+		{at(10), 10}, {at(12), 20},
+		{at(25), 7},
+	}
+
+	tests := []struct {
+		name string
+		agg  Aggregation
+		want []Point
+	}{
+		{"mean", AggregateMean, []Point{{at(0), 3}, {at(10), 15}, {at(20), 7}}},
+		{"max", AggregateMax, []Point{{at(0), 5}, {at(10), 20}, {at(20), 7}}},
+		{"min", AggregateMin, []Point{{at(0), 1}, {at(10), 10}, {at(20), 7}}},
+		{"sum", AggregateSum, []Point{{at(0), 15}, {at(10), 30}, {at(20), 7}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Downsample(points, 10*time.Second, tt.agg)
+			if err != nil {
+				t.Fatalf("Downsample failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Downsample() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got, err := Downsample(nil, time.Minute, AggregateMean); err != nil || len(got) != 0 {
+		t.Errorf("Downsample(nil) = %v, %v, want no points", got, err)
+	}
+	if _, err := Downsample(points, 0, AggregateMean); err == nil {
+		t.Error("Downsample succeeded with zero resolution, want error")
+	}
+	if _, err := Downsample(points, time.Minute, Aggregation(42)); err == nil {
+		t.Error("Downsample succeeded with an unknown aggregation, want error")
+	}
+}
+
+func TestTimeWindowHistory(t *testing.T) {
+	now := time.Now().Truncate(time.Minute)
+	w, err := NewTimeWindow(time.Minute, time.Second)
+	if err != nil {
+		t.Fatalf("NewTimeWindow failed: %v", err)
+	}
+
+	// A value every 5 seconds for 30 seconds, read 5 seconds later.
+	for s := 0; s <= 30; s += 5 {
+		w.Record(now.Add(time.Duration(s)*time.Second), float64(s))
+	}
+	at := now.Add(35 * time.Second)
+
+	// Gaps between the values are zeros, the trailing buckets are left out.
+	buckets := w.Buckets(at)
+	if len(buckets) != 31 {
+		t.Fatalf("Buckets() returned %d points, want 31", len(buckets))
+	}
+	if !buckets[0].Time.Equal(now) || buckets[30].Value != 30 {
+		t.Errorf("Buckets() = %v ... %v, want to start at %v and end with 30", buckets[0], buckets[30], now)
+	}
+
+	if err := w.SetEmptyBucketPolicy(CarryForwardLast); err != nil {
+		t.Fatalf("SetEmptyBucketPolicy failed: %v", err)
+	}
+	history, err := w.History(at, 10*time.Second, AggregateMax)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	want := []Point{
+		{now, 5},
+		{now.Add(10 * time.Second), 15},
+		{now.Add(20 * time.Second), 25},
+		{now.Add(30 * time.Second), 30},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("History() = %v, want %v", history, want)
+	}
+	for i := range want {
+		if !history[i].Time.Equal(want[i].Time) || history[i].Value != want[i].Value {
+			t.Errorf("History()[%d] = %v, want %v", i, history[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"time"
+)
+
+// EmptyBucketPolicy defines how buckets without recorded values are treated.
+// Buckets before the first recorded value are never taken into account,
+// the window is considered partial until it fills up.
+type EmptyBucketPolicy int
+
+const (
+	// DefaultEmptyBuckets is the behavior of windows without an explicit
+	// policy: buckets without data between recorded values count as zeros,
+	// while the buckets after the last recorded value are excluded.
+	DefaultEmptyBuckets EmptyBucketPolicy = iota
+
+	// TreatAsZero treats buckets without data as if 0 was recorded.
+	TreatAsZero
+
+	// Exclude ignores buckets without data.
+	Exclude
+
+	// CarryForwardLast fills buckets without data with the value of the
+	// closest preceding bucket carrying data. This suits sparse metrics,
+	// e.g. a gauge scraped every 30 seconds into 1 second buckets.
+	CarryForwardLast
+)
+
+// String implements the Stringer interface.
+func (p EmptyBucketPolicy) String() string {
+	switch p {
+	case DefaultEmptyBuckets:
+		return "Default"
+	case TreatAsZero:
+		return "TreatAsZero"
+	case Exclude:
+		return "Exclude"
+	case CarryForwardLast:
+		return "CarryForwardLast"
+	}
+	return fmt.Sprintf("EmptyBucketPolicy(%d)", int(p))
+}
+
+// validate ensures the policy is known.
+func (p EmptyBucketPolicy) validate() error {
+	switch p {
+	case DefaultEmptyBuckets, TreatAsZero, Exclude, CarryForwardLast:
+		return nil
+	}
+	return fmt.Errorf("unknown empty bucket policy %v", p)
+}
+
+// SetEmptyBucketPolicy sets how buckets without data are treated when
+// computing the window average.
+func (t *TimeWindow) SetEmptyBucketPolicy(p EmptyBucketPolicy) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+
+	t.bucketsMutex.Lock()
+	defer t.bucketsMutex.Unlock()
+	t.emptyBucketPolicy = p
+	return nil
+}
+
+// EmptyBucketPolicy returns how buckets without data are treated.
+func (t *TimeWindow) EmptyBucketPolicy() EmptyBucketPolicy {
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+	return t.emptyBucketPolicy
+}
+
+// policyBucketsLocked returns the buckets from the first write within the
+// window up to now, oldest first, with buckets without data resolved
+// according to the empty bucket policy. Excluded buckets are omitted.
+// It expects `now` to be truncated and at least Read Lock held.
+func (t *TimeWindow) policyBucketsLocked(now time.Time) []Point {
+	if t.isEmptyLocked(now) || t.lastWrite.IsZero() {
+		return nil
+	}
+
+	numB := len(t.buckets)
+	nowIdx := t.timeToIndex(now)
+	lastIdx := t.timeToIndex(t.lastWrite)
+	startIdx := max(nowIdx-numB+1, t.timeToIndex(t.firstWrite))
+
+	ret := make([]Point, 0, nowIdx-startIdx+1)
+	last, hasLast := 0., false
+	for i := startIdx; i <= nowIdx; i++ {
+		tm := t.indexToTime(i)
+		// Buckets after the last write may still hold data of a previous cycle.
+		if i <= lastIdx && t.bucketCounts[i%numB] > 0 {
+			last, hasLast = t.buckets[i%numB], true
+			ret = append(ret, Point{Time: tm, Value: last})
+			continue
+		}
+		switch t.emptyBucketPolicy {
+		case DefaultEmptyBuckets:
+			if i <= lastIdx {
+				ret = append(ret, Point{Time: tm})
+			}
+		case TreatAsZero:
+			ret = append(ret, Point{Time: tm})
+		case CarryForwardLast:
+			if hasLast {
+				ret = append(ret, Point{Time: tm, Value: last})
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+)
+
+// ExtrapolationPolicy defines how a total is estimated when only some of the
+// expected pods reported metrics.
+type ExtrapolationPolicy string
+
+const (
+	// ExtrapolateMissingAsZero treats pods without metrics as idle and uses
+	// the reported total as is. This under-counts load on partial scrapes.
+	ExtrapolateMissingAsZero ExtrapolationPolicy = "zero"
+
+	// ExtrapolateScaleSum scales the reported total by N/M, where N is the
+	// expected number of pods and M the number of reporting pods.
+	ExtrapolateScaleSum ExtrapolationPolicy = "scale-sum"
+
+	// ExtrapolateMean multiplies the mean value of the reporting pods by the
+	// expected number of pods, like Knative's scraper does. For a plain sum
+	// this yields the same total as ExtrapolateScaleSum.
+	ExtrapolateMean ExtrapolationPolicy = "mean"
+)
+
+// ParseExtrapolationPolicy parses an extrapolation policy name.
+func ParseExtrapolationPolicy(value string) (ExtrapolationPolicy, error) {
+	switch p := ExtrapolationPolicy(value); p {
+	case ExtrapolateMissingAsZero, ExtrapolateScaleSum, ExtrapolateMean:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown extrapolation policy %q (expected %q, %q or %q)",
+			value, ExtrapolateMissingAsZero, ExtrapolateScaleSum, ExtrapolateMean)
+	}
+}
+
+// Extrapolate estimates the total across expectedPods pods given the total
+// reported by reportingPods pods. If no pods reported, or at least the
+// expected number did, the reported total is returned unchanged.
+func Extrapolate(total float64, reportingPods, expectedPods int32, policy ExtrapolationPolicy) float64 {
+	if reportingPods <= 0 || reportingPods >= expectedPods {
+		return total
+	}
+
+	switch policy {
+	case ExtrapolateScaleSum:
+		return total * float64(expectedPods) / float64(reportingPods)
+	case ExtrapolateMean:
+		mean := total / float64(reportingPods)
+		return mean * float64(expectedPods)
+	default:
+		return total
+	}
+}
+
+// Extrapolated estimates the total across expectedPods pods from the
+// aggregate using the given policy.
+func (a PodAggregate) Extrapolated(expectedPods int32, policy ExtrapolationPolicy) float64 {
+	return Extrapolate(a.Total, a.ReportingPods, expectedPods, policy)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+)
+
+func TestExtrapolate(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     float64
+		reporting int32
+		expected  int32
+		policy    ExtrapolationPolicy
+		want      float64
+	}{{
+		name:      "missing as zero",
+		total:     30,
+		reporting: 3,
+		expected:  5,
+		policy:    ExtrapolateMissingAsZero,
+		want:      30,
+	}, {
+		name:      "scale sum",
+		total:     30,
+		reporting: 3,
+		expected:  5,
+		policy:    ExtrapolateScaleSum,
+		want:      50,
+	}, {
+		name:      "mean",
+		total:     30,
+		reporting: 3,
+		expected:  5,
+		policy:    ExtrapolateMean,
+		want:      50,
+	}, {
+		name:      "all pods reported",
+		total:     30,
+		reporting: 5,
+		expected:  5,
+		policy:    ExtrapolateScaleSum,
+		want:      30,
+	}, {
+		name:      "no pods reported",
+		total:     0,
+		reporting: 0,
+		expected:  5,
+		policy:    ExtrapolateScaleSum,
+		want:      0,
+	}, {
+		name:      "unknown policy",
+		total:     30,
+		reporting: 3,
+		expected:  5,
+		policy:    "unknown",
+		want:      30,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Extrapolate(tc.total, tc.reporting, tc.expected, tc.policy); got != tc.want {
+				t.Errorf("Extrapolate() = %v, want %v", got, tc.want)
+			}
+			agg := PodAggregate{Total: tc.total, ReportingPods: tc.reporting}
+			if got := agg.Extrapolated(tc.expected, tc.policy); got != tc.want {
+				t.Errorf("Extrapolated() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseExtrapolationPolicy(t *testing.T) {
+	for _, p := range []ExtrapolationPolicy{ExtrapolateMissingAsZero, ExtrapolateScaleSum, ExtrapolateMean} {
+		got, err := ParseExtrapolationPolicy(string(p))
+		if err != nil {
+			t.Errorf("ParseExtrapolationPolicy(%q) unexpected error: %v", p, err)
+		}
+		if got != p {
+			t.Errorf("ParseExtrapolationPolicy(%q) = %q", p, got)
+		}
+	}
+
+	if _, err := ParseExtrapolationPolicy("average"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
@@ -0,0 +1,356 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// HistogramSample is a scrape of a Prometheus style histogram. Counts are
+// cumulative per upper bound, i.e. Counts[i] is the number of observations
+// less than or equal to the i-th upper bound of the window.
+type HistogramSample struct {
+	// Counts holds the count of every bucket, in the order of the upper bounds.
+	Counts []float64
+
+	// Sum is the sum of all observed values.
+	Sum float64
+
+	// Count is the total number of observations. If zero, the count of the
+	// last bucket is used.
+	Count float64
+}
+
+// histogramBucket holds the observations of one granularity bucket.
+type histogramBucket struct {
+	time   time.Time
+	counts []float64 // Non-cumulative.
+	sum    float64
+	count  float64
+}
+
+// HistogramWindow aggregates pre-bucketed histogram data over a time window,
+// e.g. request latencies exposed as Prometheus histograms. It estimates
+// quantiles the same way as PromQL's histogram_quantile, so users scaling on
+// latency don't need raw samples.
+type HistogramWindow struct {
+	mu          sync.Mutex
+	window      time.Duration
+	granularity time.Duration
+	bounds      []float64
+	buckets     []histogramBucket
+
+	// last is the previous cumulative sample, to compute increments.
+	last    HistogramSample
+	hasLast bool
+}
+
+var _ api.MetricAggregator = (*HistogramWindow)(nil)
+
+// NewHistogramWindow creates a HistogramWindow with the given bucket upper
+// bounds, which must be sorted in increasing order. A +Inf bound is added if
+// the last bound is finite.
+func NewHistogramWindow(window, granularity time.Duration, bounds []float64) (*HistogramWindow, error) {
+	if granularity <= 0 {
+		return nil, fmt.Errorf("granularity must be positive, got %v", granularity)
+	}
+	if window < granularity {
+		return nil, fmt.Errorf("window must be >= granularity, got window=%v, granularity=%v", window, granularity)
+	}
+	if len(bounds) == 0 {
+		return nil, errors.New("at least one bucket upper bound is required")
+	}
+	for i, b := range bounds {
+		if math.IsNaN(b) || (i > 0 && b <= bounds[i-1]) {
+			return nil, fmt.Errorf("bucket upper bounds must be increasing, got %v", bounds)
+		}
+	}
+
+	bounds = append([]float64(nil), bounds...)
+	if !math.IsInf(bounds[len(bounds)-1], 1) {
+		bounds = append(bounds, math.Inf(1))
+	}
+
+	h := &HistogramWindow{
+		window:      window,
+		granularity: granularity,
+		bounds:      bounds,
+	}
+	h.buckets = h.allocate(window)
+	return h, nil
+}
+
+func (h *HistogramWindow) allocate(window time.Duration) []histogramBucket {
+	buckets := make([]histogramBucket, int(math.Ceil(float64(window)/float64(h.granularity))))
+	for i := range buckets {
+		buckets[i].counts = make([]float64, len(h.bounds))
+	}
+	return buckets
+}
+
+// Bounds returns the bucket upper bounds, including the +Inf bound.
+func (h *HistogramWindow) Bounds() []float64 {
+	return append([]float64(nil), h.bounds...)
+}
+
+// bucketLocked returns the bucket for the given time, resetting it if it
+// holds older data. It returns nil if the time is older than the bucket.
+func (h *HistogramWindow) bucketLocked(now time.Time) *histogramBucket {
+	bucketTime := now.Truncate(h.granularity)
+	b := &h.buckets[int((bucketTime.UnixNano()/int64(h.granularity))%int64(len(h.buckets)))]
+	switch {
+	case b.time.Equal(bucketTime):
+	case b.time.Before(bucketTime):
+		clear(b.counts)
+		b.time, b.sum, b.count = bucketTime, 0, 0
+	default:
+		return nil
+	}
+	return b
+}
+
+// Record adds a single observation.
+func (h *HistogramWindow) Record(now time.Time, value float64) {
+	if math.IsNaN(value) {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	b := h.bucketLocked(now)
+	if b == nil {
+		return
+	}
+	b.counts[sort.SearchFloat64s(h.bounds, value)]++
+	b.sum += value
+	b.count++
+}
+
+// validate ensures the sample matches the bounds and its counts are cumulative.
+func (h *HistogramWindow) validate(s HistogramSample) error {
+	if len(s.Counts) != len(h.bounds) && len(s.Counts) != len(h.bounds)-1 {
+		return fmt.Errorf("histogram sample has %d buckets, want %d", len(s.Counts), len(h.bounds))
+	}
+	for i, c := range s.Counts {
+		if c < 0 || (i > 0 && c < s.Counts[i-1]) {
+			return fmt.Errorf("histogram sample counts must be cumulative, got %v", s.Counts)
+		}
+	}
+	return nil
+}
+
+// normalize adds the +Inf bucket count if it is missing and fills in Count.
+func (s HistogramSample) normalize(buckets int) HistogramSample {
+	counts := s.Counts
+	if len(counts) < buckets {
+		total := s.Count
+		if len(counts) > 0 {
+			total = max(total, counts[len(counts)-1])
+		}
+		counts = append(append([]float64(nil), counts...), total)
+	}
+	count := s.Count
+	if count == 0 {
+		count = counts[len(counts)-1]
+	}
+	return HistogramSample{Counts: counts, Sum: s.Sum, Count: count}
+}
+
+// RecordCumulative adds a scrape of a histogram whose counters accumulate
+// over the lifetime of the process, like Prometheus histograms do. The
+// increments since the previous scrape are recorded, hence the first scrape
+// only serves as a starting point. A decreasing count is treated as a
+// counter reset.
+func (h *HistogramWindow) RecordCumulative(now time.Time, sample HistogramSample) error {
+	if err := h.validate(sample); err != nil {
+		return err
+	}
+	sample = sample.normalize(len(h.bounds))
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	last, hasLast := h.last, h.hasLast
+	h.last, h.hasLast = sample, true
+	if !hasLast {
+		return nil
+	}
+
+	delta := HistogramSample{
+		Counts: make([]float64, len(sample.Counts)),
+		Sum:    sample.Sum - last.Sum,
+		Count:  sample.Count - last.Count,
+	}
+	reset := delta.Count < 0
+	for i := range sample.Counts {
+		delta.Counts[i] = sample.Counts[i] - last.Counts[i]
+		reset = reset || delta.Counts[i] < 0
+	}
+	if reset {
+		delta = sample
+	}
+	h.addLocked(now, delta)
+	return nil
+}
+
+// RecordDelta adds the observations of a histogram that only covers the
+// interval since the previous sample, with cumulative counts per bound.
+func (h *HistogramWindow) RecordDelta(now time.Time, sample HistogramSample) error {
+	if err := h.validate(sample); err != nil {
+		return err
+	}
+	sample = sample.normalize(len(h.bounds))
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.addLocked(now, sample)
+	return nil
+}
+
+func (h *HistogramWindow) addLocked(now time.Time, sample HistogramSample) {
+	b := h.bucketLocked(now)
+	if b == nil {
+		return
+	}
+	prev := 0.0
+	for i, c := range sample.Counts {
+		b.counts[i] += c - prev
+		prev = c
+	}
+	b.sum += sample.Sum
+	b.count += sample.Count
+}
+
+// mergedLocked returns the cumulative counts, sum and count of all buckets
+// within the window.
+func (h *HistogramWindow) mergedLocked(now time.Time) ([]float64, float64, float64) {
+	now = now.Truncate(h.granularity)
+	counts := make([]float64, len(h.bounds))
+	var sum, count float64
+	for _, b := range h.buckets {
+		if b.time.IsZero() || b.time.After(now) || now.Sub(b.time) >= h.window {
+			continue
+		}
+		for i, c := range b.counts {
+			counts[i] += c
+		}
+		sum += b.sum
+		count += b.count
+	}
+	for i := 1; i < len(counts); i++ {
+		counts[i] += counts[i-1]
+	}
+	return counts, sum, count
+}
+
+// Quantile returns the estimated value at quantile q in [0, 1] of the
+// observations within the window. Values are interpolated linearly within a
+// bucket, assuming a lower bound of 0 for the first bucket if its upper bound
+// is positive. If the quantile falls into the +Inf bucket, the highest finite
+// bound is returned. It returns 0 if there are no observations.
+func (h *HistogramWindow) Quantile(now time.Time, q float64) float64 {
+	h.mu.Lock()
+	counts, _, _ := h.mergedLocked(now)
+	h.mu.Unlock()
+
+	total := counts[len(counts)-1]
+	if total == 0 {
+		return 0
+	}
+	q = math.Max(0, math.Min(1, q))
+	rank := q * total
+
+	i := sort.Search(len(counts), func(i int) bool { return counts[i] >= rank })
+	if i == len(h.bounds)-1 {
+		if len(h.bounds) == 1 {
+			return 0
+		}
+		return h.bounds[len(h.bounds)-2]
+	}
+
+	lower, upper := 0.0, h.bounds[i]
+	prevCount := 0.0
+	if i > 0 {
+		lower, prevCount = h.bounds[i-1], counts[i-1]
+	} else if upper <= 0 {
+		return upper
+	}
+	inBucket := counts[i] - prevCount
+	if inBucket == 0 {
+		return upper
+	}
+	return lower + (upper-lower)*(rank-prevCount)/inBucket
+}
+
+// WindowAverage returns the mean of the observations within the window.
+func (h *HistogramWindow) WindowAverage(now time.Time) float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, sum, count := h.mergedLocked(now)
+	if count == 0 {
+		return 0
+	}
+	return sum / count
+}
+
+// Count returns the number of observations within the window.
+func (h *HistogramWindow) Count(now time.Time) float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, _, count := h.mergedLocked(now)
+	return count
+}
+
+// IsEmpty returns true if there are no observations within the window.
+func (h *HistogramWindow) IsEmpty(now time.Time) bool {
+	return h.Count(now) == 0
+}
+
+// ResizeWindow changes the window duration, keeping the buckets that are
+// still within the new window.
+func (h *HistogramWindow) ResizeWindow(w time.Duration) {
+	w = max(w, h.granularity)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if w == h.window {
+		return
+	}
+	old := h.buckets
+	h.window = w
+	h.buckets = h.allocate(w)
+	for _, b := range old {
+		if b.time.IsZero() {
+			continue
+		}
+		nb := &h.buckets[int((b.time.UnixNano()/int64(h.granularity))%int64(len(h.buckets)))]
+		if b.time.After(nb.time) {
+			*nb = b
+		}
+	}
+}
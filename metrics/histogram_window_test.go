@@ -0,0 +1,181 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var latencyBounds = []float64{0.1, 0.25, 0.5, 1}
+
+func TestNewHistogramWindowValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		bounds  []float64
+		wantErr bool
+	}{
+		{"valid", latencyBounds, false},
+		{"with inf", []float64{1, math.Inf(1)}, false},
+		{"no bounds", nil, true},
+		{"not increasing", []float64{1, 0.5}, true},
+		{"duplicate", []float64{1, 1}, true},
+		{"nan", []float64{math.NaN()}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHistogramWindow(10*time.Second, time.Second, tt.bounds)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewHistogramWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	h, err := NewHistogramWindow(10*time.Second, time.Second, latencyBounds)
+	if err != nil {
+		t.Fatalf("NewHistogramWindow failed: %v", err)
+	}
+	if got := h.Bounds(); len(got) != 5 || !math.IsInf(got[4], 1) {
+		t.Errorf("Bounds() = %v, want the +Inf bound appended", got)
+	}
+}
+
+func TestHistogramWindowQuantile(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	h, err := NewHistogramWindow(10*time.Second, time.Second, latencyBounds)
+	if err != nil {
+		t.Fatalf("NewHistogramWindow failed: %v", err)
+	}
+
+	if got := h.Quantile(now, 0.5); got != 0 {
+		t.Errorf("Quantile() of an empty window = %v, want 0", got)
+	}
+
+	// 50 observations <= 0.1, 30 in (0.1, 0.25], 20 in (0.25, 0.5].
+	if err := h.RecordDelta(now, HistogramSample{Counts: []float64{50, 80, 100, 100}, Sum: 15}); err != nil {
+		t.Fatalf("RecordDelta failed: %v", err)
+	}
+
+	tests := []struct {
+		q    float64
+		want float64
+	}{
+		{0, 0},
+		{0.25, 0.05},
+		{0.5, 0.1},
+		{0.65, 0.175},
+		{0.9, 0.375},
+		{1, 0.5},
+	}
+	for _, tt := range tests {
+		if got := h.Quantile(now, tt.q); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Quantile(%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+	if got := h.WindowAverage(now); got != 0.15 {
+		t.Errorf("WindowAverage() = %v, want 0.15", got)
+	}
+
+	// Observations above the highest finite bound land in the +Inf bucket.
+	for range 200 {
+		h.Record(now, 5)
+	}
+	if got := h.Quantile(now, 0.99); got != 1 {
+		t.Errorf("Quantile(0.99) = %v, want the highest finite bound 1", got)
+	}
+	if got := h.Count(now); got != 300 {
+		t.Errorf("Count() = %v, want 300", got)
+	}
+}
+
+func TestHistogramWindowRecordCumulative(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	h, err := NewHistogramWindow(5*time.Second, time.Second, latencyBounds)
+	if err != nil {
+		t.Fatalf("NewHistogramWindow failed: %v", err)
+	}
+
+	// The first scrape is only a starting point.
+	scrape := HistogramSample{Counts: []float64{100, 100, 100, 100, 100}, Sum: 5}
+	if err := h.RecordCumulative(now, scrape); err != nil {
+		t.Fatalf("RecordCumulative failed: %v", err)
+	}
+	if !h.IsEmpty(now) {
+		t.Error("IsEmpty() = false after the first scrape")
+	}
+
+	// 10 new observations in (0.5, 1]; the +Inf count is optional.
+	scrape = HistogramSample{Counts: []float64{100, 100, 100, 110}, Sum: 13, Count: 110}
+	if err := h.RecordCumulative(now.Add(time.Second), scrape); err != nil {
+		t.Fatalf("RecordCumulative failed: %v", err)
+	}
+	if got := h.Count(now.Add(time.Second)); got != 10 {
+		t.Errorf("Count() = %v, want 10", got)
+	}
+	if got := h.WindowAverage(now.Add(time.Second)); math.Abs(got-0.8) > 1e-9 {
+		t.Errorf("WindowAverage() = %v, want 0.8", got)
+	}
+	if got := h.Quantile(now.Add(time.Second), 0.5); got != 0.75 {
+		t.Errorf("Quantile(0.5) = %v, want 0.75", got)
+	}
+
+	// A counter reset records the new counts as they are.
+	scrape = HistogramSample{Counts: []float64{4, 4, 4, 4, 4}, Sum: 0.2}
+	if err := h.RecordCumulative(now.Add(2*time.Second), scrape); err != nil {
+		t.Fatalf("RecordCumulative failed: %v", err)
+	}
+	if got := h.Count(now.Add(2 * time.Second)); got != 14 {
+		t.Errorf("Count() after reset = %v, want 14", got)
+	}
+
+	// Observations leave the window.
+	if got := h.Count(now.Add(6 * time.Second)); got != 4 {
+		t.Errorf("Count() = %v, want 4", got)
+	}
+
+	for _, bad := range []HistogramSample{
+		{Counts: []float64{1, 2}},
+		{Counts: []float64{2, 1, 3, 4}},
+	} {
+		if err := h.RecordCumulative(now, bad); err == nil {
+			t.Errorf("RecordCumulative(%v) succeeded, want error", bad.Counts)
+		}
+	}
+}
+
+func TestHistogramWindowResizeWindow(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	h, err := NewHistogramWindow(5*time.Second, time.Second, latencyBounds)
+	if err != nil {
+		t.Fatalf("NewHistogramWindow failed: %v", err)
+	}
+	for s := range 5 {
+		h.Record(now.Add(time.Duration(s)*time.Second), 0.2)
+	}
+	last := now.Add(4 * time.Second)
+
+	h.ResizeWindow(20 * time.Second)
+	if got := h.Count(last); got != 5 {
+		t.Errorf("Count() after growing = %v, want 5", got)
+	}
+	h.ResizeWindow(3 * time.Second)
+	if got := h.Count(last); got != 3 {
+		t.Errorf("Count() after shrinking = %v, want 3", got)
+	}
+}
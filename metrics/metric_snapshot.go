@@ -16,13 +16,17 @@ limitations under the License.
 
 package metrics
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 // MetricSnapshot represents a point-in-time view of metrics.
 type MetricSnapshot struct {
 	stableValue   float64
 	burstValue    float64
 	readyPodCount int32
+	stalePodCount int32
 	timestamp     time.Time
 }
 
@@ -36,6 +40,45 @@ func NewMetricSnapshot(stableValue, burstValue float64, readyPods int32, timesta
 	}
 }
 
+// snapshotPool holds reusable snapshots for GetSnapshot and PutSnapshot.
+var snapshotPool = sync.Pool{
+	New: func() any {
+		return new(MetricSnapshot)
+	},
+}
+
+// GetSnapshot returns a snapshot from a shared pool, initialized with the
+// given values. It behaves like NewMetricSnapshot, but reduces allocations
+// for callers that create snapshots at high frequency. The snapshot should be
+// returned with PutSnapshot once it is no longer used.
+func GetSnapshot(stableValue, burstValue float64, readyPods int32, timestamp time.Time) *MetricSnapshot {
+	s := snapshotPool.Get().(*MetricSnapshot)
+	s.stableValue = stableValue
+	s.burstValue = burstValue
+	s.readyPodCount = readyPods
+	s.timestamp = timestamp
+	return s
+}
+
+// PutSnapshot returns a snapshot obtained from GetSnapshot to the pool.
+// The snapshot must not be used after this call.
+func PutSnapshot(s *MetricSnapshot) {
+	if s == nil {
+		return
+	}
+	*s = MetricSnapshot{}
+	snapshotPool.Put(s)
+}
+
+// NewMetricSnapshotWithStalePods creates a new metric snapshot that also
+// records how many pods were excluded from aggregation because their metrics
+// were stale.
+func NewMetricSnapshotWithStalePods(stableValue, burstValue float64, readyPods, stalePods int32, timestamp time.Time) *MetricSnapshot {
+	s := NewMetricSnapshot(stableValue, burstValue, readyPods, timestamp)
+	s.stalePodCount = stalePods
+	return s
+}
+
 // StableValue returns the metric value averaged over the stable window.
 func (s *MetricSnapshot) StableValue() float64 {
 	return s.stableValue
@@ -55,3 +98,9 @@ func (s *MetricSnapshot) ReadyPodCount() int32 {
 func (s *MetricSnapshot) Timestamp() time.Time {
 	return s.timestamp
 }
+
+// StalePodCount returns the number of pods excluded from aggregation
+// because their metrics were stale.
+func (s *MetricSnapshot) StalePodCount() int32 {
+	return s.stalePodCount
+}
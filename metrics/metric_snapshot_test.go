@@ -0,0 +1,73 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetPutSnapshot(t *testing.T) {
+	now := time.Now()
+
+	s := GetSnapshot(10, 20, 3, now)
+	if got, want := s.StableValue(), 10.0; got != want {
+		t.Errorf("StableValue() = %v, want %v", got, want)
+	}
+	if got, want := s.BurstValue(), 20.0; got != want {
+		t.Errorf("BurstValue() = %v, want %v", got, want)
+	}
+	if got, want := s.ReadyPodCount(), int32(3); got != want {
+		t.Errorf("ReadyPodCount() = %v, want %v", got, want)
+	}
+	if got := s.Timestamp(); !got.Equal(now) {
+		t.Errorf("Timestamp() = %v, want %v", got, now)
+	}
+
+	// Fields set outside of GetSnapshot must not leak into reused snapshots.
+	s.stalePodCount = 5
+	PutSnapshot(s)
+	for range 10 {
+		s = GetSnapshot(1, 2, 1, now)
+		if got := s.StalePodCount(); got != 0 {
+			t.Fatalf("StalePodCount() = %v after reuse, want 0", got)
+		}
+		PutSnapshot(s)
+	}
+
+	// Returning nil is a no-op.
+	PutSnapshot(nil)
+}
+
+// snapshotSink keeps benchmarked snapshots escaping to the heap, like they do
+// when passed to an autoscaler through the api.MetricSnapshot interface.
+var snapshotSink *MetricSnapshot
+
+func BenchmarkNewMetricSnapshot(b *testing.B) {
+	now := time.Now()
+	for b.Loop() {
+		snapshotSink = NewMetricSnapshot(10, 20, 3, now)
+	}
+}
+
+func BenchmarkGetSnapshot(b *testing.B) {
+	now := time.Now()
+	for b.Loop() {
+		snapshotSink = GetSnapshot(10, 20, 3, now)
+		PutSnapshot(snapshotSink)
+	}
+}
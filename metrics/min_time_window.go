@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// MinTimeWindow tracks the minimum value recorded over a time window. It is
+// the counterpart of the maximum window used for the scale-down delay, e.g.
+// to only scale up once the load has been sustained for a whole window.
+type MinTimeWindow struct {
+	mu          sync.RWMutex
+	window      time.Duration
+	granularity time.Duration
+	policy      EmptyBucketPolicy
+
+	// buckets holds the minimum of every bucket, indexed like TimeWindow.
+	buckets     []float64
+	bucketTimes []time.Time
+
+	firstWrite time.Time
+	lastWrite  time.Time
+}
+
+// NewMinTimeWindow creates a MinTimeWindow. The policy defines how buckets
+// without data between the first recorded value and now are treated: with
+// TreatAsZero any such bucket makes the minimum 0, with Exclude they are
+// ignored. CarryForwardLast behaves like Exclude, since carrying recorded
+// values forward never lowers the minimum, and DefaultEmptyBuckets only
+// treats buckets between the first and the last recorded value as zeros.
+func NewMinTimeWindow(window, granularity time.Duration, policy EmptyBucketPolicy) (*MinTimeWindow, error) {
+	if granularity <= 0 {
+		return nil, fmt.Errorf("granularity must be positive, got %v", granularity)
+	}
+	if window < granularity {
+		return nil, fmt.Errorf("window must be >= granularity, got window=%v, granularity=%v", window, granularity)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+
+	nb := int(math.Ceil(float64(window) / float64(granularity)))
+	return &MinTimeWindow{
+		window:      window,
+		granularity: granularity,
+		policy:      policy,
+		buckets:     make([]float64, nb),
+		bucketTimes: make([]time.Time, nb),
+	}, nil
+}
+
+func (t *MinTimeWindow) index(bucketTime time.Time) int {
+	return int((bucketTime.UnixNano() / int64(t.granularity)) % int64(len(t.buckets)))
+}
+
+// Record records a value at the given time.
+func (t *MinTimeWindow) Record(now time.Time, value float64) {
+	bucketTime := now.Truncate(t.granularity)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	idx := t.index(bucketTime)
+	switch {
+	case t.bucketTimes[idx].Equal(bucketTime):
+		t.buckets[idx] = math.Min(t.buckets[idx], value)
+	case t.bucketTimes[idx].Before(bucketTime):
+		t.buckets[idx], t.bucketTimes[idx] = value, bucketTime
+	default:
+		return
+	}
+
+	// Start over after a whole window without data.
+	if t.lastWrite.IsZero() || bucketTime.Sub(t.lastWrite) >= t.window {
+		t.firstWrite = bucketTime
+	}
+	if bucketTime.After(t.lastWrite) {
+		t.lastWrite = bucketTime
+	}
+}
+
+// Min returns the minimum value recorded within the window. It returns false
+// if no value was recorded within the window.
+func (t *MinTimeWindow) Min(now time.Time) (float64, bool) {
+	now = now.Truncate(t.granularity)
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.isEmptyLocked(now) {
+		return 0, false
+	}
+
+	ret, found := math.Inf(1), false
+	start := now.Add(-t.window + t.granularity)
+	for i, bt := range t.bucketTimes {
+		if bt.Before(start) || bt.After(now) {
+			continue
+		}
+		ret, found = math.Min(ret, t.buckets[i]), true
+	}
+	if !found {
+		return 0, false
+	}
+
+	if t.policy == TreatAsZero || t.policy == DefaultEmptyBuckets {
+		// Every bucket since the first write, or the start of the window,
+		// must carry data.
+		from, to := maxTime(start, t.firstWrite), now
+		if t.policy == DefaultEmptyBuckets {
+			to = t.lastWrite
+		}
+		expected := int(to.Sub(from)/t.granularity) + 1
+		present := 0
+		for _, bt := range t.bucketTimes {
+			if !bt.Before(from) && !bt.After(to) {
+				present++
+			}
+		}
+		if present < expected {
+			ret = math.Min(ret, 0)
+		}
+	}
+	return ret, true
+}
+
+// IsEmpty returns true if no value was recorded within the window.
+func (t *MinTimeWindow) IsEmpty(now time.Time) bool {
+	now = now.Truncate(t.granularity)
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.isEmptyLocked(now)
+}
+
+func (t *MinTimeWindow) isEmptyLocked(now time.Time) bool {
+	return t.lastWrite.IsZero() || now.Sub(t.lastWrite) >= t.window
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewMinTimeWindowValidation(t *testing.T) {
+	if _, err := NewMinTimeWindow(time.Second, 2*time.Second, Exclude); err == nil {
+		t.Error("NewMinTimeWindow succeeded with window < granularity, want error")
+	}
+	if _, err := NewMinTimeWindow(10*time.Second, 0, Exclude); err == nil {
+		t.Error("NewMinTimeWindow succeeded with zero granularity, want error")
+	}
+	if _, err := NewMinTimeWindow(10*time.Second, time.Second, EmptyBucketPolicy(42)); err == nil {
+		t.Error("NewMinTimeWindow succeeded with an unknown policy, want error")
+	}
+}
+
+func TestMinTimeWindow(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name   string
+		policy EmptyBucketPolicy
+		record map[time.Duration]float64
+		at     time.Duration
+		want   float64
+		wantOK bool
+	}{{
+		name:   "empty",
+		policy: Exclude,
+		wantOK: false,
+	}, {
+		name:   "minimum of contiguous buckets",
+		policy: TreatAsZero,
+		record: map[time.Duration]float64{0: 7, time.Second: 5, 2 * time.Second: 9},
+		at:     2 * time.Second,
+		want:   5,
+		wantOK: true,
+	}, {
+		name:   "minimum within a bucket",
+		policy: Exclude,
+		record: map[time.Duration]float64{0: 7, 500 * time.Millisecond: 3},
+		want:   3,
+		wantOK: true,
+	}, {
+		name:   "hole excluded",
+		policy: Exclude,
+		record: map[time.Duration]float64{0: 7, 2 * time.Second: 9},
+		at:     2 * time.Second,
+		want:   7,
+		wantOK: true,
+	}, {
+		name:   "hole as zero",
+		policy: TreatAsZero,
+		record: map[time.Duration]float64{0: 7, 2 * time.Second: 9},
+		at:     2 * time.Second,
+		want:   0,
+		wantOK: true,
+	}, {
+		name:   "trailing hole as zero",
+		policy: TreatAsZero,
+		record: map[time.Duration]float64{0: 7, time.Second: 9},
+		at:     3 * time.Second,
+		want:   0,
+		wantOK: true,
+	}, {
+		name:   "old buckets leave the window",
+		policy: Exclude,
+		record: map[time.Duration]float64{0: 1, 3 * time.Second: 8, 6 * time.Second: 9},
+		at:     6 * time.Second,
+		want:   8,
+		wantOK: true,
+	}, {
+		name:   "no data within the window",
+		policy: Exclude,
+		record: map[time.Duration]float64{0: 1},
+		at:     5 * time.Second,
+		wantOK: false,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := NewMinTimeWindow(5*time.Second, time.Second, tt.policy)
+			if err != nil {
+				t.Fatalf("NewMinTimeWindow failed: %v", err)
+			}
+			for _, d := range []time.Duration{0, 500 * time.Millisecond, time.Second, 2 * time.Second, 3 * time.Second, 6 * time.Second} {
+				if v, ok := tt.record[d]; ok {
+					w.Record(now.Add(d), v)
+				}
+			}
+
+			got, ok := w.Min(now.Add(tt.at))
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Min() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+			if w.IsEmpty(now.Add(tt.at)) == tt.wantOK {
+				t.Errorf("IsEmpty() = %v, want %v", !tt.wantOK, !tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMinTimeWindowRestartsAfterInactivity(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	w, err := NewMinTimeWindow(5*time.Second, time.Second, TreatAsZero)
+	if err != nil {
+		t.Fatalf("NewMinTimeWindow failed: %v", err)
+	}
+
+	w.Record(now, 10)
+	w.Record(now.Add(10*time.Second), 20)
+	w.Record(now.Add(11*time.Second), 30)
+
+	// The buckets before the restart are not holes.
+	if got, ok := w.Min(now.Add(11 * time.Second)); !ok || got != 20 {
+		t.Errorf("Min() = %v, %v, want 20, true", got, ok)
+	}
+}
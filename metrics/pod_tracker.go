@@ -0,0 +1,127 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// PodAggregate is the result of aggregating the latest per-pod samples.
+type PodAggregate struct {
+	// Total is the sum of the latest values of all fresh pods.
+	Total float64
+
+	// ReportingPods is the number of pods with fresh metrics.
+	ReportingPods int32
+
+	// StalePods is the number of known pods whose metrics are older than the
+	// TTL and were excluded from the total.
+	StalePods int32
+}
+
+// podSample is the latest sample received from a pod.
+type podSample struct {
+	value    float64
+	lastSeen time.Time
+}
+
+// PodTracker keeps the latest metric sample of every pod, so that pods which
+// stopped reporting (e.g. because they are being terminated or the scrape
+// failed) are excluded from aggregation instead of silently contributing
+// outdated values.
+type PodTracker struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	pods map[string]podSample
+}
+
+// NewPodTracker creates a new PodTracker. Samples older than ttl are
+// considered stale.
+func NewPodTracker(ttl time.Duration) (*PodTracker, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("ttl must be positive, got %v", ttl)
+	}
+	return &PodTracker{
+		ttl:  ttl,
+		pods: make(map[string]podSample),
+	}, nil
+}
+
+// Record stores the given per-pod samples. Samples without a pod name are
+// ignored, as are samples older than the latest one known for the pod.
+func (p *PodTracker) Record(samples ...api.Metrics) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, s := range samples {
+		if s.PodName == "" {
+			continue
+		}
+		if prev, ok := p.pods[s.PodName]; ok && prev.lastSeen.After(s.Timestamp) {
+			continue
+		}
+		p.pods[s.PodName] = podSample{value: s.Value, lastSeen: s.Timestamp}
+	}
+}
+
+// Aggregate sums the latest values of all pods seen within the TTL before now.
+func (p *PodTracker) Aggregate(now time.Time) PodAggregate {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var result PodAggregate
+	for _, s := range p.pods {
+		if p.isStaleLocked(s, now) {
+			result.StalePods++
+			continue
+		}
+		result.Total += s.value
+		result.ReportingPods++
+	}
+	return result
+}
+
+// Forget removes a pod, e.g. after it has been deleted.
+func (p *PodTracker) Forget(podName string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pods, podName)
+}
+
+// Prune removes all stale pods and returns how many were removed.
+func (p *PodTracker) Prune(now time.Time) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	removed := 0
+	for name, s := range p.pods {
+		if p.isStaleLocked(s, now) {
+			delete(p.pods, name)
+			removed++
+		}
+	}
+	return removed
+}
+
+// isStaleLocked expects the lock to be held.
+func (p *PodTracker) isStaleLocked(s podSample, now time.Time) bool {
+	return now.Sub(s.lastSeen) > p.ttl
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+func TestPodTrackerAggregate(t *testing.T) {
+	now := time.Now()
+
+	tracker, err := NewPodTracker(10 * time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tracker.Record(
+		api.Metrics{PodName: "a", Timestamp: now, Value: 10},
+		api.Metrics{PodName: "b", Timestamp: now.Add(-5 * time.Second), Value: 20},
+		api.Metrics{PodName: "c", Timestamp: now.Add(-15 * time.Second), Value: 30},
+		api.Metrics{Timestamp: now, Value: 1000}, // no pod name, ignored
+	)
+
+	got := tracker.Aggregate(now)
+	want := PodAggregate{Total: 30, ReportingPods: 2, StalePods: 1}
+	if got != want {
+		t.Errorf("Aggregate() = %+v, want %+v", got, want)
+	}
+
+	// An out of order sample does not replace a newer one.
+	tracker.Record(api.Metrics{PodName: "a", Timestamp: now.Add(-time.Second), Value: 100})
+	if got := tracker.Aggregate(now); got.Total != 30 {
+		t.Errorf("Aggregate().Total = %v, want 30", got.Total)
+	}
+
+	// A fresh sample makes a stale pod count again.
+	tracker.Record(api.Metrics{PodName: "c", Timestamp: now, Value: 5})
+	got = tracker.Aggregate(now)
+	want = PodAggregate{Total: 35, ReportingPods: 3, StalePods: 0}
+	if got != want {
+		t.Errorf("Aggregate() = %+v, want %+v", got, want)
+	}
+
+	// Everything becomes stale eventually.
+	got = tracker.Aggregate(now.Add(time.Minute))
+	want = PodAggregate{Total: 0, ReportingPods: 0, StalePods: 3}
+	if got != want {
+		t.Errorf("Aggregate() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPodTrackerForgetAndPrune(t *testing.T) {
+	now := time.Now()
+
+	tracker, err := NewPodTracker(10 * time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tracker.Record(
+		api.Metrics{PodName: "a", Timestamp: now, Value: 10},
+		api.Metrics{PodName: "b", Timestamp: now.Add(-20 * time.Second), Value: 20},
+		api.Metrics{PodName: "c", Timestamp: now.Add(-30 * time.Second), Value: 30},
+	)
+
+	tracker.Forget("a")
+	if got := tracker.Aggregate(now); got.ReportingPods != 0 || got.StalePods != 2 {
+		t.Errorf("Aggregate() = %+v, want 0 reporting and 2 stale pods", got)
+	}
+
+	if removed := tracker.Prune(now); removed != 2 {
+		t.Errorf("Prune() = %d, want 2", removed)
+	}
+	if got := tracker.Aggregate(now); got != (PodAggregate{}) {
+		t.Errorf("Aggregate() = %+v, want empty aggregate", got)
+	}
+}
+
+func TestNewPodTrackerInvalidTTL(t *testing.T) {
+	if _, err := NewPodTracker(0); err == nil {
+		t.Error("expected error for zero ttl")
+	}
+}
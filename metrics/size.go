@@ -0,0 +1,90 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"time"
+	"unsafe"
+)
+
+// The SizeBytes methods report the approximate memory used by a window: its
+// struct and the backing arrays it references. Allocator overhead and
+// memory shared with other values, like the rest of a BucketPool slab, are
+// not included.
+
+const (
+	float64Size = int64(unsafe.Sizeof(float64(0)))
+	intSize     = int64(unsafe.Sizeof(int(0)))
+	timeSize    = int64(unsafe.Sizeof(time.Time{}))
+	pointerSize = int64(unsafe.Sizeof(uintptr(0)))
+)
+
+// SizeBytes returns the approximate memory used by the window in bytes.
+func (t *TimeWindow) SizeBytes() int64 {
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+	return int64(unsafe.Sizeof(*t)) + int64(cap(t.buckets))*float64Size + int64(cap(t.bucketCounts))*intSize
+}
+
+// SizeBytes returns the approximate memory used by the window in bytes.
+func (t *WeightedTimeWindow) SizeBytes() int64 {
+	return int64(unsafe.Sizeof(*t)) + t.TimeWindow.SizeBytes()
+}
+
+// SizeBytes returns the approximate memory used by the window in bytes.
+func (t *MinTimeWindow) SizeBytes() int64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return int64(unsafe.Sizeof(*t)) + int64(cap(t.buckets))*float64Size + int64(cap(t.bucketTimes))*timeSize
+}
+
+// SizeBytes returns the approximate memory used by the window in bytes.
+func (t *TrendWindow) SizeBytes() int64 {
+	return int64(unsafe.Sizeof(*t))
+}
+
+// SizeBytes returns the approximate memory used by the window in bytes.
+func (h *HistogramWindow) SizeBytes() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	size := int64(unsafe.Sizeof(*h)) + int64(cap(h.bounds))*float64Size + int64(cap(h.last.Counts))*float64Size
+	size += int64(cap(h.buckets)) * int64(unsafe.Sizeof(histogramBucket{}))
+	for _, b := range h.buckets {
+		size += int64(cap(b.counts)) * float64Size
+	}
+	return size
+}
+
+// SizeBytes returns the approximate memory used by the digest in bytes.
+func (d *TDigest) SizeBytes() int64 {
+	return int64(unsafe.Sizeof(*d)) + int64(cap(d.centroids)+cap(d.buffer))*int64(unsafe.Sizeof(centroid{}))
+}
+
+// SizeBytes returns the approximate memory used by the window in bytes.
+func (t *TDigestWindow) SizeBytes() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	size := int64(unsafe.Sizeof(*t)) + int64(cap(t.buckets))*pointerSize + int64(cap(t.bucketTimes))*timeSize
+	for _, d := range t.buckets {
+		if d != nil {
+			size += d.SizeBytes()
+		}
+	}
+	return size
+}
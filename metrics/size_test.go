@@ -0,0 +1,65 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSizeBytes(t *testing.T) {
+	tn := time.Now().Truncate(time.Second)
+
+	short, _ := NewTimeWindow(60*time.Second, time.Second)
+	long, _ := NewTimeWindow(600*time.Second, time.Second)
+	// 540 more buckets of a value and a count.
+	if got, want := long.SizeBytes()-short.SizeBytes(), 540*(float64Size+intSize); got != want {
+		t.Errorf("size difference of a 600s and a 60s window = %d, want %d", got, want)
+	}
+	if got := long.SizeBytes(); got < 600*(float64Size+intSize) {
+		t.Errorf("SizeBytes() = %d, want at least the size of the buckets", got)
+	}
+
+	pool := NewBucketPool(0)
+	pooled, _ := NewTimeWindowFromPool(600*time.Second, time.Second, pool)
+	if got, want := pooled.SizeBytes(), long.SizeBytes(); got != want {
+		t.Errorf("SizeBytes() of a pooled window = %d, want %d", got, want)
+	}
+	weighted, _ := NewWeightedTimeWindow(600*time.Second, time.Second)
+	if got := weighted.SizeBytes(); got <= long.SizeBytes() {
+		t.Errorf("SizeBytes() of a weighted window = %d, want more than %d", got, long.SizeBytes())
+	}
+
+	histogram, _ := NewHistogramWindow(10*time.Second, time.Second, []float64{1, 10})
+	minWindow, _ := NewMinTimeWindow(10*time.Second, time.Second, DefaultEmptyBuckets)
+	trend, _ := NewTrendWindow(10*time.Second, time.Second, 0.5, 0.5)
+	for _, w := range []interface{ SizeBytes() int64 }{histogram, minWindow, trend} {
+		if w.SizeBytes() <= 0 {
+			t.Errorf("SizeBytes() of %T = %d, want positive", w, w.SizeBytes())
+		}
+	}
+
+	// The digests of a t-digest window grow with the recorded values.
+	digest, _ := NewTDigestWindow(10*time.Second, time.Second, DefaultCompression)
+	emptyDigest := digest.SizeBytes()
+	for i := range 5 {
+		digest.Record(tn.Add(time.Duration(i)*time.Second), float64(i))
+	}
+	if got := digest.SizeBytes(); got <= emptyDigest {
+		t.Errorf("SizeBytes() of a t-digest window = %d after recording, want more than %d", got, emptyDigest)
+	}
+}
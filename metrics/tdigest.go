@@ -0,0 +1,355 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// DefaultCompression is the default compression of a TDigest. It keeps the
+// digest below a few hundred centroids, while quantile errors stay well below
+// 1% in the tails.
+const DefaultCompression = 100
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// TDigest is a merging t-digest, a sketch for estimating arbitrary quantiles
+// of a stream of values with bounded memory. The accuracy is highest for
+// quantiles close to 0 and 1. It is not safe for concurrent use.
+type TDigest struct {
+	compression float64
+	centroids   []centroid
+	buffer      []centroid
+	total       float64
+	sum         float64
+	min, max    float64
+}
+
+// NewTDigest creates a TDigest with the given compression. A higher
+// compression gives more accurate quantiles at the cost of memory.
+func NewTDigest(compression float64) (*TDigest, error) {
+	if !(compression >= 10) {
+		return nil, fmt.Errorf("compression = %v, must be at least 10", compression)
+	}
+	return &TDigest{
+		compression: compression,
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}, nil
+}
+
+// Add adds a value to the digest.
+func (d *TDigest) Add(value float64) {
+	d.add(centroid{mean: value, weight: 1})
+}
+
+// AddWeighted adds a value that stands for weight values, which may be
+// fractional. Values with a weight that is not positive are ignored.
+func (d *TDigest) AddWeighted(value, weight float64) {
+	d.add(centroid{mean: value, weight: weight})
+}
+
+func (d *TDigest) add(c centroid) {
+	if math.IsNaN(c.mean) || c.weight <= 0 {
+		return
+	}
+	d.buffer = append(d.buffer, c)
+	d.total += c.weight
+	d.sum += c.mean * c.weight
+	d.min = math.Min(d.min, c.mean)
+	d.max = math.Max(d.max, c.mean)
+	if len(d.buffer) >= int(5*d.compression) {
+		d.compress()
+	}
+}
+
+// Merge adds all values of another digest to this one.
+func (d *TDigest) Merge(other *TDigest) {
+	for _, c := range other.centroids {
+		d.add(c)
+	}
+	for _, c := range other.buffer {
+		d.add(c)
+	}
+}
+
+// Reset drops all values from the digest.
+func (d *TDigest) Reset() {
+	d.centroids = d.centroids[:0]
+	d.buffer = d.buffer[:0]
+	d.total, d.sum = 0, 0
+	d.min, d.max = math.Inf(1), math.Inf(-1)
+}
+
+// Count returns the number of values added to the digest.
+func (d *TDigest) Count() float64 {
+	return d.total
+}
+
+// Mean returns the exact mean of the values added to the digest.
+func (d *TDigest) Mean() float64 {
+	if d.total == 0 {
+		return 0
+	}
+	return d.sum / d.total
+}
+
+// scale is the k1 scale function, which makes centroids small near the
+// tails and large around the median.
+func (d *TDigest) scale(q float64) float64 {
+	return d.compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+// compress merges the buffered values into the centroids.
+func (d *TDigest) compress() {
+	if len(d.buffer) == 0 {
+		return
+	}
+
+	all := append(d.centroids, d.buffer...)
+	d.buffer = d.buffer[:0]
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := all[:1]
+	cur := &merged[0]
+	weightSoFar := 0.0
+	kLow := d.scale(0)
+	for _, c := range all[1:] {
+		q := (weightSoFar + cur.weight + c.weight) / d.total
+		if d.scale(q)-kLow <= 1 {
+			cur.mean += (c.mean - cur.mean) * c.weight / (cur.weight + c.weight)
+			cur.weight += c.weight
+			continue
+		}
+		weightSoFar += cur.weight
+		kLow = d.scale(weightSoFar / d.total)
+		merged = append(merged, c)
+		cur = &merged[len(merged)-1]
+	}
+	d.centroids = merged
+}
+
+// Quantile returns the estimated value at quantile q in [0, 1]. It returns 0
+// for an empty digest.
+func (d *TDigest) Quantile(q float64) float64 {
+	d.compress()
+	if len(d.centroids) == 0 {
+		return 0
+	}
+	q = math.Max(0, math.Min(1, q))
+	if len(d.centroids) == 1 || q == 0 {
+		if q == 1 {
+			return d.max
+		}
+		if q == 0 {
+			return d.min
+		}
+		return d.centroids[0].mean
+	}
+
+	target := q * d.total
+	first := d.centroids[0]
+	if target < first.weight/2 {
+		return d.min + (first.mean-d.min)*target/(first.weight/2)
+	}
+
+	// Interpolate between the centers of adjacent centroids.
+	cumulative := 0.0
+	for i := 0; i < len(d.centroids)-1; i++ {
+		left, right := d.centroids[i], d.centroids[i+1]
+		leftCenter := cumulative + left.weight/2
+		rightCenter := cumulative + left.weight + right.weight/2
+		if target <= rightCenter {
+			return left.mean + (right.mean-left.mean)*(target-leftCenter)/(rightCenter-leftCenter)
+		}
+		cumulative += left.weight
+	}
+
+	last := d.centroids[len(d.centroids)-1]
+	lastCenter := d.total - last.weight/2
+	return last.mean + (d.max-last.mean)*(target-lastCenter)/(last.weight/2)
+}
+
+// TDigestWindow is a time window of t-digests. Every granularity bucket
+// keeps a digest of its values, which are merged to answer quantile queries
+// over the window. Unlike storing raw samples, memory is bounded by the
+// number of buckets and the compression, regardless of the sample rate.
+type TDigestWindow struct {
+	mu          sync.Mutex
+	window      time.Duration
+	granularity time.Duration
+	compression float64
+
+	buckets     []*TDigest
+	bucketTimes []time.Time
+}
+
+var _ api.MetricAggregator = (*TDigestWindow)(nil)
+
+// NewTDigestWindow creates a TDigestWindow with the given compression, see
+// NewTDigest.
+func NewTDigestWindow(window, granularity time.Duration, compression float64) (*TDigestWindow, error) {
+	if granularity <= 0 {
+		return nil, fmt.Errorf("granularity must be positive, got %v", granularity)
+	}
+	if window < granularity {
+		return nil, fmt.Errorf("window must be >= granularity, got window=%v, granularity=%v", window, granularity)
+	}
+	if _, err := NewTDigest(compression); err != nil {
+		return nil, err
+	}
+
+	t := &TDigestWindow{
+		window:      window,
+		granularity: granularity,
+		compression: compression,
+	}
+	t.allocate(window)
+	return t, nil
+}
+
+// allocate creates the ring of buckets for the given window.
+func (t *TDigestWindow) allocate(window time.Duration) {
+	nb := int(math.Ceil(float64(window) / float64(t.granularity)))
+	t.buckets = make([]*TDigest, nb)
+	t.bucketTimes = make([]time.Time, nb)
+	for i := range t.buckets {
+		t.buckets[i], _ = NewTDigest(t.compression)
+	}
+}
+
+func (t *TDigestWindow) index(bucketTime time.Time) int {
+	return int((bucketTime.UnixNano() / int64(t.granularity)) % int64(len(t.buckets)))
+}
+
+// Record adds a value at the given time. Values older than the window are
+// dropped.
+func (t *TDigestWindow) Record(now time.Time, value float64) {
+	t.RecordWeighted(now, value, 1)
+}
+
+// RecordWeighted adds a value that stands for weight values at the given
+// time, e.g. a sample of one pod representing several pods. The weight
+// counts towards quantiles and the average like that many values. Values
+// with a weight that is not positive are ignored.
+func (t *TDigestWindow) RecordWeighted(now time.Time, value, weight float64) {
+	if !(weight > 0) {
+		return
+	}
+	bucketTime := now.Truncate(t.granularity)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	idx := t.index(bucketTime)
+	switch {
+	case t.bucketTimes[idx].Equal(bucketTime):
+	case t.bucketTimes[idx].Before(bucketTime):
+		t.buckets[idx].Reset()
+		t.bucketTimes[idx] = bucketTime
+	default:
+		return
+	}
+	t.buckets[idx].AddWeighted(value, weight)
+}
+
+// mergedLocked merges the digests of all buckets within the window.
+func (t *TDigestWindow) mergedLocked(now time.Time) *TDigest {
+	merged, _ := NewTDigest(t.compression)
+	now = now.Truncate(t.granularity)
+	for i, bt := range t.bucketTimes {
+		if !bt.After(now) && now.Sub(bt) < t.window {
+			merged.Merge(t.buckets[i])
+		}
+	}
+	return merged
+}
+
+// Quantile returns the estimated value at quantile q in [0, 1] of the values
+// recorded within the window.
+func (t *TDigestWindow) Quantile(now time.Time, q float64) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.mergedLocked(now).Quantile(q)
+}
+
+// Quantiles returns the estimated values at multiple quantiles, merging the
+// buckets only once.
+func (t *TDigestWindow) Quantiles(now time.Time, qs ...float64) []float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	merged := t.mergedLocked(now)
+	ret := make([]float64, len(qs))
+	for i, q := range qs {
+		ret[i] = merged.Quantile(q)
+	}
+	return ret
+}
+
+// Count returns the number of values recorded within the window.
+func (t *TDigestWindow) Count(now time.Time) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.mergedLocked(now).Count()
+}
+
+// WindowAverage returns the mean of the values recorded within the window.
+func (t *TDigestWindow) WindowAverage(now time.Time) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.mergedLocked(now).Mean()
+}
+
+// IsEmpty returns true if no value was recorded within the window.
+func (t *TDigestWindow) IsEmpty(now time.Time) bool {
+	return t.Count(now) == 0
+}
+
+// ResizeWindow changes the window duration, keeping the buckets that are
+// still within the new window.
+func (t *TDigestWindow) ResizeWindow(w time.Duration) {
+	w = max(w, t.granularity)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if w == t.window {
+		return
+	}
+	buckets, bucketTimes := t.buckets, t.bucketTimes
+	t.window = w
+	t.allocate(w)
+	for i, bt := range bucketTimes {
+		if bt.IsZero() {
+			continue
+		}
+		idx := t.index(bt)
+		if bt.After(t.bucketTimes[idx]) {
+			t.buckets[idx], t.bucketTimes[idx] = buckets[i], bt
+		}
+	}
+}
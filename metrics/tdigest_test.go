@@ -0,0 +1,216 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestTDigestQuantiles(t *testing.T) {
+	d, err := NewTDigest(DefaultCompression)
+	if err != nil {
+		t.Fatalf("NewTDigest failed: %v", err)
+	}
+
+	const n = 100000
+	r := rand.New(rand.NewSource(1))
+	for _, i := range r.Perm(n) {
+		d.Add(float64(i))
+	}
+
+	tests := []struct {
+		q         float64
+		tolerance float64
+	}{
+		{0, 0},
+		{0.01, 0.002},
+		{0.5, 0.01},
+		{0.9, 0.005},
+		{0.99, 0.002},
+		{0.999, 0.001},
+		{1, 0},
+	}
+	for _, tt := range tests {
+		want := tt.q * (n - 1)
+		if got := d.Quantile(tt.q); math.Abs(got-want) > tt.tolerance*n {
+			t.Errorf("Quantile(%v) = %v, want %v ± %v", tt.q, got, want, tt.tolerance*n)
+		}
+	}
+
+	if got := d.Count(); got != n {
+		t.Errorf("Count() = %v, want %v", got, n)
+	}
+	if got, want := d.Mean(), float64(n-1)/2; got != want {
+		t.Errorf("Mean() = %v, want %v", got, want)
+	}
+	if got := len(d.centroids); got > 2*DefaultCompression {
+		t.Errorf("digest has %d centroids, want at most %d", got, 2*DefaultCompression)
+	}
+}
+
+func TestTDigestEdgeCases(t *testing.T) {
+	if _, err := NewTDigest(1); err == nil {
+		t.Error("NewTDigest(1) succeeded, want error")
+	}
+
+	d, _ := NewTDigest(DefaultCompression)
+	if got := d.Quantile(0.5); got != 0 {
+		t.Errorf("Quantile() of an empty digest = %v, want 0", got)
+	}
+
+	d.Add(42)
+	d.Add(math.NaN())
+	for _, q := range []float64{0, 0.5, 1} {
+		if got := d.Quantile(q); got != 42 {
+			t.Errorf("Quantile(%v) = %v, want 42", q, got)
+		}
+	}
+
+	other, _ := NewTDigest(DefaultCompression)
+	other.Add(58)
+	d.Merge(other)
+	if got := d.Mean(); got != 50 {
+		t.Errorf("Mean() after Merge = %v, want 50", got)
+	}
+	if got := d.Quantile(1); got != 58 {
+		t.Errorf("Quantile(1) after Merge = %v, want 58", got)
+	}
+
+	d.Reset()
+	if got := d.Count(); got != 0 {
+		t.Errorf("Count() after Reset = %v, want 0", got)
+	}
+}
+
+func TestTDigestWindow(t *testing.T) {
+	if _, err := NewTDigestWindow(time.Second, 2*time.Second, DefaultCompression); err == nil {
+		t.Error("NewTDigestWindow succeeded with window < granularity, want error")
+	}
+
+	now := time.Now().Truncate(time.Second)
+	w, err := NewTDigestWindow(5*time.Second, time.Second, DefaultCompression)
+	if err != nil {
+		t.Fatalf("NewTDigestWindow failed: %v", err)
+	}
+	if !w.IsEmpty(now) {
+		t.Error("IsEmpty() = false for a new window")
+	}
+
+	// Every second records 1..100, plus 1000 in the last second.
+	for s := range 5 {
+		for v := 1; v <= 100; v++ {
+			w.Record(now.Add(time.Duration(s)*time.Second), float64(v))
+		}
+	}
+	last := now.Add(4 * time.Second)
+	w.Record(last, 1000)
+
+	if got := w.Count(last); got != 501 {
+		t.Errorf("Count() = %v, want 501", got)
+	}
+	if got := w.Quantile(last, 1); got != 1000 {
+		t.Errorf("Quantile(1) = %v, want 1000", got)
+	}
+	qs := w.Quantiles(last, 0.5, 0.9)
+	if math.Abs(qs[0]-50) > 2 || math.Abs(qs[1]-90) > 2 {
+		t.Errorf("Quantiles(0.5, 0.9) = %v, want about [50 90]", qs)
+	}
+
+	// Old buckets leave the window.
+	later := now.Add(8 * time.Second)
+	if got := w.Count(later); got != 101 {
+		t.Errorf("Count() = %v, want 101", got)
+	}
+	if got := w.WindowAverage(later); math.Abs(got-(5050+1000)/101.) > 1e-9 {
+		t.Errorf("WindowAverage() = %v, want %v", got, (5050+1000)/101.)
+	}
+	if !w.IsEmpty(now.Add(9 * time.Second)) {
+		t.Error("IsEmpty() = false after the window")
+	}
+
+	// Records older than the bucket they map to are dropped.
+	w.Record(now.Add(-time.Second), 5000)
+	if got := w.Quantile(last, 1); got != 1000 {
+		t.Errorf("Quantile(1) = %v after a stale record, want 1000", got)
+	}
+}
+
+func TestTDigestWindowRecordWeighted(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	w, err := NewTDigestWindow(5*time.Second, time.Second, DefaultCompression)
+	if err != nil {
+		t.Fatalf("NewTDigestWindow failed: %v", err)
+	}
+
+	// One pod at 10 represents three pods, a pod at 50 only itself.
+	w.RecordWeighted(now, 10, 3)
+	w.RecordWeighted(now, 50, 1)
+	w.RecordWeighted(now, 1000, 0)
+
+	if got := w.Count(now); got != 4 {
+		t.Errorf("Count() = %v, want 4", got)
+	}
+	if got := w.WindowAverage(now); got != 20 {
+		t.Errorf("WindowAverage() = %v, want 20", got)
+	}
+	if got := w.Quantile(now, 1); got != 50 {
+		t.Errorf("Quantile(1) = %v, want 50", got)
+	}
+
+	// A value with weight 0 doesn't replace the bucket it maps to.
+	w.RecordWeighted(now.Add(5*time.Second), 1000, 0)
+	if got := w.Count(now); got != 4 {
+		t.Errorf("Count() = %v after a record with weight 0, want 4", got)
+	}
+}
+
+func TestTDigestWindowResizeWindow(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	w, err := NewTDigestWindow(5*time.Second, time.Second, DefaultCompression)
+	if err != nil {
+		t.Fatalf("NewTDigestWindow failed: %v", err)
+	}
+	for s := range 5 {
+		w.Record(now.Add(time.Duration(s)*time.Second), float64(s))
+	}
+	last := now.Add(4 * time.Second)
+
+	w.ResizeWindow(10 * time.Second)
+	if got := w.Count(last); got != 5 {
+		t.Errorf("Count() after growing = %v, want 5", got)
+	}
+
+	w.ResizeWindow(2 * time.Second)
+	if got := w.Count(last); got != 2 {
+		t.Errorf("Count() after shrinking = %v, want 2", got)
+	}
+	if got := w.Quantile(last, 0); got != 3 {
+		t.Errorf("Quantile(0) after shrinking = %v, want 3", got)
+	}
+}
+
+func BenchmarkTDigestAdd(b *testing.B) {
+	d, _ := NewTDigest(DefaultCompression)
+	r := rand.New(rand.NewSource(1))
+
+	for b.Loop() {
+		d.Add(r.Float64())
+	}
+}
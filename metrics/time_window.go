@@ -20,10 +20,10 @@ package metrics
 import (
 	"fmt"
 	"math"
-	"sync"
 	"time"
 
 	"github.com/Fedosin/libkpa/api"
+	"github.com/Fedosin/libkpa/internal/syncutil"
 )
 
 const (
@@ -36,12 +36,17 @@ const (
 
 // TimeWindow keeps buckets that have been collected at a certain time.
 type TimeWindow struct {
-	bucketsMutex sync.RWMutex
+	bucketsMutex syncutil.RWMutex
 	// buckets is a ring buffer indexed by timeToIndex() % len(buckets).
 	// Each element represents a certain granularity of time, and the total
 	// represented duration adds up to a window length of time.
 	buckets []float64
 
+	// bucketCounts is a ring buffer parallel to buckets, holding the number
+	// of values recorded into each bucket. It distinguishes buckets carrying
+	// data from buckets that are zero because nothing was recorded.
+	bucketCounts []int
+
 	// firstWrite holds the time when the first write has been made.
 	// This time is reset to `now` when the very first write happens,
 	// or when a first write happens after `window` time of inactivity.
@@ -60,10 +65,22 @@ type TimeWindow struct {
 	granularity time.Duration
 	// window is the total time represented by the buckets ring buffer.
 	window time.Duration
+	// emptyBucketPolicy defines how buckets without data are treated.
+	emptyBucketPolicy EmptyBucketPolicy
+	// interpolate enables filling the buckets between two writes linearly.
+	interpolate bool
+	// stalenessThreshold is the time without writes after which the window
+	// is considered empty, even if the window has not passed yet.
+	stalenessThreshold time.Duration
+	// lastRecord stores the exact time of the most recent record.
+	lastRecord time.Time
 	// The total sum of all buckets within the window. This total includes
 	// invalid buckets, e.g. buckets written to before firstTime or after
 	// lastTime are included in this total.
 	windowTotal float64
+
+	// pool is the pool the buckets were allocated from, if any.
+	pool *BucketPool
 }
 
 var _ api.MetricAggregator = (*TimeWindow)(nil)
@@ -76,6 +93,12 @@ func (t *TimeWindow) String() string {
 // NewTimeWindow generates a new TimeWindow with the given
 // granularity.
 func NewTimeWindow(window, granularity time.Duration) (*TimeWindow, error) {
+	return newTimeWindow(window, granularity, nil)
+}
+
+// newTimeWindow creates a TimeWindow with the buckets allocated from the
+// pool, or the heap if pool is nil.
+func newTimeWindow(window, granularity time.Duration, pool *BucketPool) (*TimeWindow, error) {
 	if granularity <= 0 {
 		return nil, fmt.Errorf("granularity must be positive, got %v", granularity)
 	}
@@ -86,11 +109,26 @@ func NewTimeWindow(window, granularity time.Duration) (*TimeWindow, error) {
 	// Number of buckets is `window` divided by `granularity`, rounded up.
 	// e.g. 60s / 2s = 30.
 	nb := math.Ceil(float64(window) / float64(granularity))
-	return &TimeWindow{
-		buckets:     make([]float64, int(nb)),
+	t := &TimeWindow{
 		granularity: granularity,
 		window:      window,
-	}, nil
+		pool:        pool,
+	}
+	t.buckets, t.bucketCounts = t.allocBuckets(int(nb))
+	return t, nil
+}
+
+// NewTimeWindowUnsynchronized is NewTimeWindow for windows only ever used by
+// a single goroutine, e.g. in tight simulation loops. The window doesn't
+// lock at all, so concurrent calls of any of its methods, including reads,
+// are a data race. Use NewTimeWindow whenever the window is shared.
+func NewTimeWindowUnsynchronized(window, granularity time.Duration) (*TimeWindow, error) {
+	t, err := NewTimeWindow(window, granularity)
+	if err != nil {
+		return nil, err
+	}
+	t.bucketsMutex.Disable()
+	return t, nil
 }
 
 // IsEmpty returns true if no data has been recorded for the `window` period.
@@ -103,7 +141,36 @@ func (t *TimeWindow) IsEmpty(now time.Time) bool {
 
 // isEmptyLocked expects `now` to be truncated and at least Read Lock held.
 func (t *TimeWindow) isEmptyLocked(now time.Time) bool {
-	return now.Sub(t.lastWrite) > t.window
+	d := now.Sub(t.lastWrite)
+	return d > t.window || (t.stalenessThreshold > 0 && d > t.stalenessThreshold)
+}
+
+// SetStalenessThreshold sets the time without records after which IsEmpty
+// returns true, even if data is still within the window. This allows
+// detecting quickly that metric ingestion stopped. Zero disables it.
+func (t *TimeWindow) SetStalenessThreshold(d time.Duration) {
+	t.bucketsMutex.Lock()
+	defer t.bucketsMutex.Unlock()
+	t.stalenessThreshold = max(d, 0)
+}
+
+// StalenessThreshold returns the time without records after which the
+// window is considered empty, or zero if disabled.
+func (t *TimeWindow) StalenessThreshold() time.Duration {
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+	return t.stalenessThreshold
+}
+
+// TimeSinceLastRecord returns the time passed between the most recent record
+// and now. It returns math.MaxInt64 if nothing has been recorded yet.
+func (t *TimeWindow) TimeSinceLastRecord(now time.Time) time.Duration {
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+	if t.lastRecord.IsZero() {
+		return math.MaxInt64
+	}
+	return now.Sub(t.lastRecord)
 }
 
 // WindowAverage returns the average bucket value over the window.
@@ -122,10 +189,24 @@ func (t *TimeWindow) isEmptyLocked(now time.Time) bool {
 // In other cases, for example if there are gaps in the data shorter than the
 // window length, the missing data is assumed to be 0 and the average is over
 // the whole window length inclusive of the missing data.
+//
+// The above describes DefaultEmptyBuckets, other policies set with
+// SetEmptyBucketPolicy define explicitly how buckets without data are treated.
 func (t *TimeWindow) WindowAverage(now time.Time) float64 {
 	now = now.Truncate(t.granularity)
 	t.bucketsMutex.RLock()
 	defer t.bucketsMutex.RUnlock()
+	if t.emptyBucketPolicy != DefaultEmptyBuckets {
+		points := t.policyBucketsLocked(now)
+		if len(points) == 0 {
+			return 0
+		}
+		total := 0.
+		for _, p := range points {
+			total += p.Value
+		}
+		return roundToNDigits(precision, total/float64(len(points)))
+	}
 	switch d := now.Sub(t.lastWrite); {
 	case d <= 0:
 		// If LastWrite equal or greater than Now
@@ -141,25 +222,112 @@ func (t *TimeWindow) WindowAverage(now time.Time) float64 {
 		// of valid buckets
 		stIdx := t.timeToIndex(t.lastWrite)
 		eIdx := t.timeToIndex(now)
-		ret := t.windowTotal
-		for i := stIdx + 1; i <= eIdx; i++ {
-			ret -= t.buckets[i%len(t.buckets)]
-		}
 		numB := min(
 			int(t.lastWrite.Sub(t.firstWrite)/t.granularity)+1, // +1 since the times are inclusive.
 			len(t.buckets)-(eIdx-stIdx))
-		return roundToNDigits(precision, ret/float64(numB))
+		return roundToNDigits(precision, t.windowSumLocked(now)/float64(numB))
 	default: // Nothing for more than a window time, just 0.
 		return 0.
 	}
 }
 
+// WindowSum returns the sum of all values recorded within the window ending
+// at now. Unlike WindowAverage, it does not depend on how many buckets carry
+// data, which is what formulas based on totals, like TotalTargetValue or
+// throughput budgets, need.
+func (t *TimeWindow) WindowSum(now time.Time) float64 {
+	now = now.Truncate(t.granularity)
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+	return roundToNDigits(precision, t.windowSumLocked(now))
+}
+
+// windowSumLocked expects `now` to be truncated and at least Read Lock held.
+func (t *TimeWindow) windowSumLocked(now time.Time) float64 {
+	switch d := now.Sub(t.lastWrite); {
+	case d <= 0:
+		return t.windowTotal
+	case d < t.window:
+		// Remove the buckets that left the window since the last write.
+		ret := t.windowTotal
+		for i := t.timeToIndex(t.lastWrite) + 1; i <= t.timeToIndex(now); i++ {
+			ret -= t.buckets[i%len(t.buckets)]
+		}
+		return ret
+	default:
+		return 0
+	}
+}
+
+// SampleCount returns the number of values recorded within the window
+// ending at now. A value recorded with RecordWeighted counts once, whatever
+// its weight.
+func (t *TimeWindow) SampleCount(now time.Time) int {
+	now = now.Truncate(t.granularity)
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+
+	if t.isEmptyLocked(now) || t.lastWrite.IsZero() {
+		return 0
+	}
+	numB := len(t.buckets)
+	nowIdx := t.timeToIndex(now)
+	count := 0
+	for i := nowIdx - numB + 1; i <= min(nowIdx, t.timeToIndex(t.lastWrite)); i++ {
+		count += t.bucketCounts[i%numB]
+	}
+	return count
+}
+
+// WindowAverageWithStats returns the same average as WindowAverage, along
+// with the fraction of the window's buckets carrying data (see FillFraction).
+// This allows callers to treat an average computed from a barely populated
+// window differently from one computed from a fully populated window.
+func (t *TimeWindow) WindowAverageWithStats(now time.Time) (float64, float64) {
+	return t.WindowAverage(now), t.FillFraction(now)
+}
+
+// FillFraction returns the fraction, in the [0, 1] range, of the buckets
+// within the window ending at now that had at least one value recorded.
+func (t *TimeWindow) FillFraction(now time.Time) float64 {
+	now = now.Truncate(t.granularity)
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+	return t.fillFractionLocked(now)
+}
+
+// fillFractionLocked expects `now` to be truncated and at least Read Lock held.
+func (t *TimeWindow) fillFractionLocked(now time.Time) float64 {
+	if t.isEmptyLocked(now) || t.lastWrite.IsZero() {
+		return 0
+	}
+	// Only buckets up to the last write hold data of the current window,
+	// the ones after it may still contain values from a previous cycle.
+	numB := len(t.buckets)
+	nowIdx := t.timeToIndex(now)
+	eIdx := min(nowIdx, t.timeToIndex(t.lastWrite))
+	filled := 0
+	for i := nowIdx - numB + 1; i <= eIdx; i++ {
+		if t.bucketCounts[i%numB] > 0 {
+			filled++
+		}
+	}
+	return float64(filled) / float64(numB)
+}
+
 // timeToIndex converts time to an integer that can be used for modulo
 // operations to find the index in the bucket list.
 // bucketMutex needs to be held.
 func (t *TimeWindow) timeToIndex(tm time.Time) int {
-	// Use int64 to avoid Y2038 problem, then safely convert
-	return int(tm.Unix() / int64(t.granularity.Seconds()))
+	// Use int64 to avoid Y2038 problem, then safely convert. Nanoseconds
+	// support granularities that are not whole seconds.
+	return int(tm.UnixNano() / int64(t.granularity))
+}
+
+// indexToTime converts a bucket index back to the time of the bucket,
+// the inverse of timeToIndex.
+func (t *TimeWindow) indexToTime(idx int) time.Time {
+	return time.Unix(0, int64(idx)*int64(t.granularity))
 }
 
 // Record adds a value with an associated time to the correct bucket.
@@ -191,6 +359,7 @@ func (t *TimeWindow) Record(now time.Time, value float64) {
 					// Reset all the buckets.
 					for i := range t.buckets {
 						t.buckets[i] = 0
+						t.bucketCounts[i] = 0
 					}
 					t.windowTotal = 0
 				} else {
@@ -198,10 +367,20 @@ func (t *TimeWindow) Record(now time.Time, value float64) {
 					// Thus we need to clean not only the current index, but also
 					// all the ones from the last write. This is slower than the loop above
 					// due to possible wrap-around, so they are not merged together.
-					for i := t.timeToIndex(t.lastWrite) + 1; i <= writeIdx; i++ {
+					lastIdx := t.timeToIndex(t.lastWrite)
+					lastValue := t.buckets[lastIdx%len(t.buckets)]
+					for i := lastIdx + 1; i <= writeIdx; i++ {
 						idx := i % len(t.buckets)
 						t.windowTotal -= t.buckets[idx]
 						t.buckets[idx] = 0
+						t.bucketCounts[idx] = 0
+						if t.interpolate && i < writeIdx {
+							// Fill the gap linearly between the last and the new value.
+							v := lastValue + (value-lastValue)*float64(i-lastIdx)/float64(writeIdx-lastIdx)
+							t.buckets[idx] = v
+							t.bucketCounts[idx] = 1
+							t.windowTotal += v
+						}
 					}
 				}
 				// Update the last write time.
@@ -215,7 +394,45 @@ func (t *TimeWindow) Record(now time.Time, value float64) {
 		}
 	}
 	t.buckets[writeIdx%len(t.buckets)] += value
+	t.bucketCounts[writeIdx%len(t.buckets)]++
 	t.windowTotal += value
+	if now.After(t.lastRecord) {
+		t.lastRecord = now
+	}
+}
+
+// RecordWeighted adds a value that stands for weight samples of the value,
+// e.g. a sample of one pod representing several pods, or a scrape that
+// reached only part of the pods weighted by the inverse of its coverage.
+// Buckets hold the sum of their values, so the value is added multiplied by
+// the weight, but the record counts as a single sample in SampleCount. A
+// weight of 1 is equivalent to Record. Values with a weight that is not
+// positive, including NaN, are ignored: they neither count as samples nor as
+// writes.
+func (t *TimeWindow) RecordWeighted(now time.Time, value, weight float64) {
+	if !(weight > 0) {
+		return
+	}
+	t.Record(now, value*weight)
+}
+
+// SetInterpolation enables or disables linear interpolation between records
+// that are more than one bucket apart. With interpolation enabled, a metric
+// scraped every 15s into 1s buckets fills every bucket with a value between
+// the two scrapes, instead of one bucket with data followed by fourteen
+// empty ones. Interpolated buckets count as carrying data, with one sample
+// each. Gaps of a whole window or more are never interpolated.
+func (t *TimeWindow) SetInterpolation(enabled bool) {
+	t.bucketsMutex.Lock()
+	defer t.bucketsMutex.Unlock()
+	t.interpolate = enabled
+}
+
+// Interpolation returns whether linear interpolation between records is enabled.
+func (t *TimeWindow) Interpolation() bool {
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+	return t.interpolate
 }
 
 // ResizeWindow resizes the window. This is an O(N) operation,
@@ -231,7 +448,7 @@ func (t *TimeWindow) ResizeWindow(w time.Duration) {
 		return
 	}
 	numBuckets := int(math.Ceil(float64(w) / float64(t.granularity)))
-	newBuckets := make([]float64, numBuckets)
+	newBuckets, newCounts := t.allocBuckets(numBuckets)
 	newTotal := 0.
 
 	// We need write lock here.
@@ -249,6 +466,7 @@ func (t *TimeWindow) ResizeWindow(w time.Duration) {
 			oi := tIdx % oldNumBuckets
 			ni := tIdx % numBuckets
 			newBuckets[ni] = t.buckets[oi]
+			newCounts[ni] = t.bucketCounts[oi]
 			// In case we're shrinking, make sure the total
 			// window sum will match. This is no-op in case if
 			// window is getting bigger.
@@ -263,7 +481,9 @@ func (t *TimeWindow) ResizeWindow(w time.Duration) {
 		t.firstWrite = time.Time{}
 	}
 	t.window = w
+	t.releaseBucketsLocked()
 	t.buckets = newBuckets
+	t.bucketCounts = newCounts
 	t.windowTotal = newTotal
 }
 
@@ -23,6 +23,9 @@ import (
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/Fedosin/libkpa/api"
+	"github.com/Fedosin/libkpa/loadgen"
 )
 
 const granularity = time.Second
@@ -633,6 +636,32 @@ func TestTimeWindowWindowUpdate3sGranularity(t *testing.T) {
 	}
 }
 
+func TestTimeWindowSubSecondGranularity(t *testing.T) {
+	const granularity = 250 * time.Millisecond
+	now := time.Now().Truncate(time.Second)
+
+	buckets, err := NewTimeWindow(time.Second, granularity)
+	if err != nil {
+		t.Fatalf("NewTimeWindow failed: %v", err)
+	}
+	for i, v := range []float64{1, 2, 3, 4} {
+		buckets.Record(now.Add(time.Duration(i)*granularity), v)
+	}
+	last := now.Add(3 * granularity)
+	if got, want := buckets.WindowAverage(last), 2.5; got != want {
+		t.Errorf("WindowAverage = %v, want: %v", got, want)
+	}
+	if got, want := buckets.FillFraction(last), 1.0; got != want {
+		t.Errorf("FillFraction = %v, want: %v", got, want)
+	}
+
+	// The first bucket leaves the window.
+	buckets.Record(now.Add(4*granularity), 5)
+	if got, want := buckets.WindowAverage(now.Add(4*granularity)), 3.5; got != want {
+		t.Errorf("WindowAverage = %v, want: %v", got, want)
+	}
+}
+
 func TestTimeWindowWindowUpdateNoOp(t *testing.T) {
 	startTime := time.Now().Add(-time.Minute)
 	buckets, err := NewTimeWindow(5*time.Second, granularity)
@@ -660,8 +689,12 @@ func BenchmarkWindowAverage(b *testing.B) {
 				b.Fatalf("NewTimeWindow failed: %v", err)
 			}
 			// Populate with some random data.
-			for i := range wl {
-				buckets.Record(tn.Add(time.Duration(i)*time.Second), rand.Float64()*100)
+			load, err := loadgen.NewStream(loadgen.Constant(50), tn, time.Second, 50, 1)
+			if err != nil {
+				b.Fatalf("NewStream failed: %v", err)
+			}
+			for range wl {
+				buckets.Record(load.Next())
 			}
 			for b.Loop() {
 				buckets.WindowAverage(tn.Add(time.Duration(wl) * time.Second))
@@ -670,6 +703,67 @@ func BenchmarkWindowAverage(b *testing.B) {
 	}
 }
 
+func BenchmarkTimeWindowRecord(b *testing.B) {
+	for _, c := range []struct {
+		name string
+		new  func(window, granularity time.Duration) (*TimeWindow, error)
+	}{
+		{"synchronized", NewTimeWindow},
+		{"unsynchronized", NewTimeWindowUnsynchronized},
+	} {
+		b.Run(c.name, func(b *testing.B) {
+			tn := time.Now().Truncate(time.Second)
+			buckets, err := c.new(60*time.Second, time.Second)
+			if err != nil {
+				b.Fatalf("NewTimeWindow failed: %v", err)
+			}
+			i := 0
+			for b.Loop() {
+				buckets.Record(tn.Add(time.Duration(i)*time.Second), 42)
+				i++
+			}
+		})
+	}
+}
+
+func TestUnsynchronizedWindows(t *testing.T) {
+	tn := time.Now().Truncate(time.Second)
+	if _, err := NewTimeWindowUnsynchronized(time.Second, 2*time.Second); err == nil {
+		t.Error("expected error for a window smaller than the granularity")
+	}
+	if _, err := NewWeightedTimeWindowUnsynchronized(time.Second, 0); err == nil {
+		t.Error("expected error for a zero granularity")
+	}
+
+	synced, _ := NewTimeWindow(10*time.Second, time.Second)
+	unsynced, err := NewTimeWindowUnsynchronized(10*time.Second, time.Second)
+	if err != nil {
+		t.Fatalf("NewTimeWindowUnsynchronized failed: %v", err)
+	}
+	weighted, _ := NewWeightedTimeWindow(10*time.Second, time.Second)
+	unsyncedWeighted, err := NewWeightedTimeWindowUnsynchronized(10*time.Second, time.Second)
+	if err != nil {
+		t.Fatalf("NewWeightedTimeWindowUnsynchronized failed: %v", err)
+	}
+	if !unsynced.bucketsMutex.Disabled() || !unsyncedWeighted.bucketsMutex.Disabled() {
+		t.Error("unsynchronized windows lock")
+	}
+
+	// Without locking the windows compute the same results.
+	for i := range 15 {
+		now := tn.Add(time.Duration(i) * time.Second)
+		for _, w := range []api.MetricAggregator{synced, unsynced, weighted, unsyncedWeighted} {
+			w.Record(now, float64(i*i))
+		}
+		if got, want := unsynced.WindowAverage(now), synced.WindowAverage(now); got != want {
+			t.Errorf("WindowAverage at %d = %v, want %v", i, got, want)
+		}
+		if got, want := unsyncedWeighted.WindowAverage(now), weighted.WindowAverage(now); got != want {
+			t.Errorf("weighted WindowAverage at %d = %v, want %v", i, got, want)
+		}
+	}
+}
+
 func TestRoundToNDigits(t *testing.T) {
 	if got, want := roundToNDigits(6, 3.6e-17), 0.; got != want {
 		t.Errorf("Rounding = %v, want: %v", got, want)
@@ -780,4 +874,288 @@ func TestNegativeWindowAndGranularity(t *testing.T) {
 	if err == nil {
 		t.Errorf("NewTimeWindow should fail with negative window")
 	}
-}
\ No newline at end of file
+}
+func TestTimeWindowWindowAverageWithStats(t *testing.T) {
+	now := time.Now().Truncate(granularity)
+
+	buckets, err := NewTimeWindow(10*time.Second, granularity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if avg, fill := buckets.WindowAverageWithStats(now); avg != 0 || fill != 0 {
+		t.Errorf("WindowAverageWithStats() = (%v, %v), want (0, 0) for an empty window", avg, fill)
+	}
+
+	// A single bucket of data, including an explicit zero.
+	buckets.Record(now, 0)
+	if _, fill := buckets.WindowAverageWithStats(now); fill != 0.1 {
+		t.Errorf("FillFraction = %v, want 0.1", fill)
+	}
+
+	// Every other bucket carries data.
+	for i := 2; i < 10; i += 2 {
+		buckets.Record(now.Add(time.Duration(i)*time.Second), 10)
+	}
+	avg, fill := buckets.WindowAverageWithStats(now.Add(9 * time.Second))
+	if fill != 0.5 {
+		t.Errorf("FillFraction = %v, want 0.5", fill)
+	}
+	if want := buckets.WindowAverage(now.Add(9 * time.Second)); avg != want {
+		t.Errorf("average = %v, want %v", avg, want)
+	}
+
+	// Old buckets fall out of the window.
+	if got := buckets.FillFraction(now.Add(15 * time.Second)); got != 0.2 {
+		t.Errorf("FillFraction = %v, want 0.2", got)
+	}
+
+	// Nothing recorded for longer than the window.
+	if got := buckets.FillFraction(now.Add(time.Minute)); got != 0 {
+		t.Errorf("FillFraction = %v, want 0", got)
+	}
+
+	// A fully populated window.
+	for i := range 10 {
+		buckets.Record(now.Add(time.Minute+time.Duration(i)*time.Second), 1)
+	}
+	if got := buckets.FillFraction(now.Add(time.Minute + 9*time.Second)); got != 1 {
+		t.Errorf("FillFraction = %v, want 1", got)
+	}
+}
+
+func TestTimeWindowWindowSumAndSampleCount(t *testing.T) {
+	now := time.Now().Truncate(granularity)
+
+	buckets, err := NewTimeWindow(5*time.Second, granularity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sum, count := buckets.WindowSum(now), buckets.SampleCount(now); sum != 0 || count != 0 {
+		t.Errorf("WindowSum, SampleCount = %v, %v, want 0, 0 for an empty window", sum, count)
+	}
+
+	// Two values in the first bucket, one in each of the next two.
+	buckets.Record(now, 1)
+	buckets.Record(now, 2)
+	buckets.Record(now.Add(time.Second), 3)
+	buckets.Record(now.Add(3*time.Second), 4)
+
+	tests := []struct {
+		name      string
+		at        time.Duration
+		wantSum   float64
+		wantCount int
+	}{
+		{"at last write", 3 * time.Second, 10, 4},
+		{"within window", 4 * time.Second, 10, 4},
+		{"first bucket left", 5 * time.Second, 7, 2},
+		{"only last bucket", 7 * time.Second, 4, 1},
+		{"window passed", 8 * time.Second, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := now.Add(tt.at)
+			if got := buckets.WindowSum(at); got != tt.wantSum {
+				t.Errorf("WindowSum() = %v, want %v", got, tt.wantSum)
+			}
+			if got := buckets.SampleCount(at); got != tt.wantCount {
+				t.Errorf("SampleCount() = %d, want %d", got, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestTimeWindowRecordWeighted(t *testing.T) {
+	now := time.Now().Truncate(granularity)
+
+	buckets, err := NewTimeWindow(5*time.Second, granularity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A sample of one pod representing three pods, and one of a scrape that
+	// reached half of the pods.
+	buckets.RecordWeighted(now, 10, 3)
+	buckets.RecordWeighted(now.Add(time.Second), 20, 2)
+	buckets.RecordWeighted(now.Add(time.Second), 100, -1)
+	buckets.RecordWeighted(now.Add(time.Second), 100, math.NaN())
+
+	at := now.Add(time.Second)
+	if got, want := buckets.WindowSum(at), 70.; got != want {
+		t.Errorf("WindowSum() = %v, want %v", got, want)
+	}
+	if got, want := buckets.WindowAverage(at), 35.; got != want {
+		t.Errorf("WindowAverage() = %v, want %v", got, want)
+	}
+	if got, want := buckets.SampleCount(at), 2; got != want {
+		t.Errorf("SampleCount() = %d, want %d", got, want)
+	}
+}
+
+func TestTimeWindowRecordWeightedIgnoresZeroWeight(t *testing.T) {
+	now := time.Now().Truncate(granularity)
+
+	buckets, err := NewTimeWindow(5*time.Second, granularity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buckets.RecordWeighted(now, 10, 0)
+	if !buckets.IsEmpty(now) || buckets.SampleCount(now) != 0 || buckets.TimeSinceLastRecord(now) != math.MaxInt64 {
+		t.Errorf("window after a record with weight 0 = empty %v, %d samples, last record %v ago, want empty",
+			buckets.IsEmpty(now), buckets.SampleCount(now), buckets.TimeSinceLastRecord(now))
+	}
+
+	// Weights scale the sum, but each record counts as one sample.
+	buckets.RecordWeighted(now, 10, 1)
+	buckets.RecordWeighted(now, 10, 4)
+	if got, want := buckets.WindowSum(now), 50.; got != want {
+		t.Errorf("WindowSum() = %v, want %v", got, want)
+	}
+	if got, want := buckets.SampleCount(now), 2; got != want {
+		t.Errorf("SampleCount() = %d, want %d", got, want)
+	}
+}
+
+func TestTimeWindowEmptyBucketPolicy(t *testing.T) {
+	now := time.Now().Truncate(granularity)
+
+	tests := []struct {
+		policy       EmptyBucketPolicy
+		wantAverage  float64
+		wantWeighted float64
+	}{
+		{DefaultEmptyBuckets, 10, 0},
+		{TreatAsZero, 8.333333, 40*0.25 + 10*0.5*0.5*0.5*0.5*0.5*0.5},
+		{Exclude, 25, 40*0.5 + 10*0.5*0.5},
+		{CarryForwardLast, 20, 40*0.5 + 40*0.25 + 10*(0.125+0.0625+0.03125+0.015625)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			buckets, err := NewTimeWindow(10*time.Second, granularity)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			weighted, err := NewWeightedTimeWindowWithAlpha(10*time.Second, granularity, 0.5)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := buckets.SetEmptyBucketPolicy(tt.policy); err != nil {
+				t.Fatalf("SetEmptyBucketPolicy failed: %v", err)
+			}
+			if err := weighted.SetEmptyBucketPolicy(tt.policy); err != nil {
+				t.Fatalf("SetEmptyBucketPolicy failed: %v", err)
+			}
+			if got := buckets.EmptyBucketPolicy(); got != tt.policy {
+				t.Errorf("EmptyBucketPolicy() = %v, want %v", got, tt.policy)
+			}
+
+			// Data in the 1st and 5th bucket, read at the 6th.
+			for _, w := range []interface{ Record(time.Time, float64) }{buckets, weighted} {
+				w.Record(now, 10)
+				w.Record(now.Add(4*time.Second), 40)
+			}
+			at := now.Add(5 * time.Second)
+
+			if got := buckets.WindowAverage(at); got != tt.wantAverage {
+				t.Errorf("WindowAverage() = %v, want %v", got, tt.wantAverage)
+			}
+			if tt.policy == DefaultEmptyBuckets {
+				// The default weighted average decays with the trailing bucket,
+				// see TestTimeWindowWeightedAverage.
+				return
+			}
+			if got := weighted.WindowAverage(at); math.Abs(got-tt.wantWeighted) > 1e-9 {
+				t.Errorf("weighted WindowAverage() = %v, want %v", got, tt.wantWeighted)
+			}
+		})
+	}
+
+	buckets, _ := NewTimeWindow(10*time.Second, granularity)
+	if err := buckets.SetEmptyBucketPolicy(EmptyBucketPolicy(42)); err == nil {
+		t.Error("SetEmptyBucketPolicy succeeded with an unknown policy, want error")
+	}
+}
+
+func TestTimeWindowInterpolation(t *testing.T) {
+	now := time.Now().Truncate(granularity)
+
+	buckets, err := NewTimeWindow(30*time.Second, granularity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buckets.SetInterpolation(true)
+	if !buckets.Interpolation() {
+		t.Error("Interpolation() = false, want true")
+	}
+
+	// Scrapes every 5 seconds.
+	buckets.Record(now, 10)
+	buckets.Record(now.Add(5*time.Second), 20)
+	buckets.Record(now.Add(10*time.Second), 20)
+
+	at := now.Add(10 * time.Second)
+	if got := buckets.FillFraction(at); got != 11./30 {
+		t.Errorf("FillFraction() = %v, want %v", got, 11./30)
+	}
+	// 10, 12, 14, 16, 18, 20, 20, 20, 20, 20, 20
+	if got := buckets.WindowSum(at); got != 190 {
+		t.Errorf("WindowSum() = %v, want 190", got)
+	}
+	if got, want := buckets.WindowAverage(at), roundToNDigits(precision, 190./11); got != want {
+		t.Errorf("WindowAverage() = %v, want %v", got, want)
+	}
+
+	// Without interpolation the gaps are zeros.
+	plain, _ := NewTimeWindow(30*time.Second, granularity)
+	plain.Record(now, 10)
+	plain.Record(now.Add(5*time.Second), 20)
+	plain.Record(now.Add(10*time.Second), 20)
+	if got, want := plain.WindowAverage(at), roundToNDigits(precision, 50./11); got != want {
+		t.Errorf("WindowAverage() without interpolation = %v, want %v", got, want)
+	}
+
+	// A gap of a whole window is not interpolated.
+	buckets.Record(now.Add(time.Minute), 40)
+	if got := buckets.WindowAverage(now.Add(time.Minute)); got != 40 {
+		t.Errorf("WindowAverage() after a long gap = %v, want 40", got)
+	}
+}
+
+func TestTimeWindowStalenessThreshold(t *testing.T) {
+	now := time.Now().Truncate(granularity)
+
+	buckets, err := NewTimeWindow(60*time.Second, granularity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := buckets.TimeSinceLastRecord(now); got != math.MaxInt64 {
+		t.Errorf("TimeSinceLastRecord() = %v, want max duration before any record", got)
+	}
+
+	buckets.Record(now.Add(500*time.Millisecond), 10)
+	if got := buckets.TimeSinceLastRecord(now.Add(2 * time.Second)); got != 1500*time.Millisecond {
+		t.Errorf("TimeSinceLastRecord() = %v, want 1.5s", got)
+	}
+	// Older records don't move the last record time back.
+	buckets.Record(now, 10)
+	if got := buckets.TimeSinceLastRecord(now.Add(2 * time.Second)); got != 1500*time.Millisecond {
+		t.Errorf("TimeSinceLastRecord() = %v, want 1.5s", got)
+	}
+
+	if buckets.IsEmpty(now.Add(10 * time.Second)) {
+		t.Error("IsEmpty() = true within the window without a staleness threshold")
+	}
+	buckets.SetStalenessThreshold(5 * time.Second)
+	if got := buckets.StalenessThreshold(); got != 5*time.Second {
+		t.Errorf("StalenessThreshold() = %v, want 5s", got)
+	}
+	if buckets.IsEmpty(now.Add(5 * time.Second)) {
+		t.Error("IsEmpty() = true at the staleness threshold")
+	}
+	if !buckets.IsEmpty(now.Add(6 * time.Second)) {
+		t.Error("IsEmpty() = false after the staleness threshold")
+	}
+}
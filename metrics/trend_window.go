@@ -0,0 +1,185 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+)
+
+// TrendWindow aggregates metrics with Holt's double exponential smoothing.
+// Besides the smoothed level it tracks the slope of the metric, so consumers
+// can anticipate where the metric is heading, e.g. to compensate for pod
+// startup time.
+//
+// Like TimeWindow, values recorded within the same granularity bucket are
+// summed up. Each bucket updates the level and the slope once it is
+// complete; the bucket in progress is taken into account by queries without
+// being committed.
+type TrendWindow struct {
+	mu          sync.RWMutex
+	window      time.Duration
+	granularity time.Duration
+
+	// alpha is the smoothing coefficient of the level, beta of the slope.
+	alpha float64
+	beta  float64
+
+	// The bucket in progress.
+	bucketTime time.Time
+	bucketSum  float64
+	hasBucket  bool
+
+	// The committed smoothing state. The slope is per granularity step.
+	level      float64
+	slope      float64
+	hasLevel   bool
+	hasSlope   bool
+	lastCommit time.Time
+}
+
+var _ api.MetricAggregator = (*TrendWindow)(nil)
+
+// NewTrendWindow creates a TrendWindow with the level smoothing coefficient
+// alpha and the slope smoothing coefficient beta, both in (0, 1]. The window
+// is empty when no value was recorded within the window duration.
+func NewTrendWindow(window, granularity time.Duration, alpha, beta float64) (*TrendWindow, error) {
+	if granularity <= 0 {
+		return nil, fmt.Errorf("granularity must be positive, got %v", granularity)
+	}
+	if window < granularity {
+		return nil, fmt.Errorf("window must be >= granularity, got window=%v, granularity=%v", window, granularity)
+	}
+	if err := validateSmoothingCoeff(alpha); err != nil {
+		return nil, fmt.Errorf("level %w", err)
+	}
+	if err := validateSmoothingCoeff(beta); err != nil {
+		return nil, fmt.Errorf("slope %w", err)
+	}
+
+	return &TrendWindow{
+		window:      window,
+		granularity: granularity,
+		alpha:       alpha,
+		beta:        beta,
+	}, nil
+}
+
+// Record adds a value at the given time. Values older than the bucket in
+// progress are ignored.
+func (t *TrendWindow) Record(now time.Time, value float64) {
+	bucketTime := now.Truncate(t.granularity)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case !t.hasBucket:
+		t.bucketTime, t.bucketSum, t.hasBucket = bucketTime, value, true
+	case bucketTime.Equal(t.bucketTime):
+		t.bucketSum += value
+	case bucketTime.After(t.bucketTime):
+		t.commitLocked()
+		t.bucketTime, t.bucketSum = bucketTime, value
+	}
+}
+
+// commitLocked folds the bucket in progress into the smoothing state.
+func (t *TrendWindow) commitLocked() {
+	t.level, t.slope, t.hasLevel, t.hasSlope = t.stateLocked()
+	t.lastCommit = t.bucketTime
+}
+
+// stateLocked returns the smoothing state including the bucket in progress.
+func (t *TrendWindow) stateLocked() (level, slope float64, hasLevel, hasSlope bool) {
+	level, slope, hasLevel, hasSlope = t.level, t.slope, t.hasLevel, t.hasSlope
+	if !t.hasBucket {
+		return level, slope, hasLevel, hasSlope
+	}
+
+	v := t.bucketSum
+	if !hasLevel {
+		return v, 0, true, false
+	}
+
+	// Number of granularity steps since the last committed bucket.
+	k := float64(t.bucketTime.Sub(t.lastCommit) / t.granularity)
+	if k < 1 {
+		k = 1
+	}
+	if !hasSlope {
+		return v, (v - level) / k, true, true
+	}
+
+	newLevel := t.alpha*v + (1-t.alpha)*(level+k*slope)
+	newSlope := t.beta*(newLevel-level)/k + (1-t.beta)*slope
+	return newLevel, newSlope, true, true
+}
+
+// LevelAndSlope returns the smoothed level and its slope per second.
+func (t *TrendWindow) LevelAndSlope(now time.Time) (float64, float64) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.isEmptyLocked(now) {
+		return 0, 0
+	}
+	level, slope, _, _ := t.stateLocked()
+	return level, slope / t.granularity.Seconds()
+}
+
+// Slope returns the slope of the smoothed metric per second.
+func (t *TrendWindow) Slope(now time.Time) float64 {
+	_, slope := t.LevelAndSlope(now)
+	return slope
+}
+
+// Forecast returns the expected value of the metric after horizon,
+// extrapolating the level linearly along the slope.
+func (t *TrendWindow) Forecast(now time.Time, horizon time.Duration) float64 {
+	level, slope := t.LevelAndSlope(now)
+	return level + slope*horizon.Seconds()
+}
+
+// WindowAverage returns the smoothed level of the metric.
+func (t *TrendWindow) WindowAverage(now time.Time) float64 {
+	level, _ := t.LevelAndSlope(now)
+	return level
+}
+
+// IsEmpty returns true if no data has been recorded for the window period.
+func (t *TrendWindow) IsEmpty(now time.Time) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.isEmptyLocked(now)
+}
+
+// isEmptyLocked is IsEmpty without taking the lock.
+func (t *TrendWindow) isEmptyLocked(now time.Time) bool {
+	return !t.hasBucket || now.Truncate(t.granularity).Sub(t.bucketTime) >= t.window
+}
+
+// ResizeWindow changes the duration after which the window is considered
+// empty. The smoothing state is kept.
+func (t *TrendWindow) ResizeWindow(w time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.window = max(w, t.granularity)
+}
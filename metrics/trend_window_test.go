@@ -0,0 +1,166 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewTrendWindowValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		window      time.Duration
+		granularity time.Duration
+		alpha       float64
+		beta        float64
+		wantErr     bool
+	}{
+		{"valid", 10 * time.Second, time.Second, 0.5, 0.3, false},
+		{"alpha and beta of one", 10 * time.Second, time.Second, 1, 1, false},
+		{"zero granularity", 10 * time.Second, 0, 0.5, 0.3, true},
+		{"window below granularity", time.Second, 2 * time.Second, 0.5, 0.3, true},
+		{"zero alpha", 10 * time.Second, time.Second, 0, 0.3, true},
+		{"alpha above one", 10 * time.Second, time.Second, 1.5, 0.3, true},
+		{"zero beta", 10 * time.Second, time.Second, 0.5, 0, true},
+		{"negative beta", 10 * time.Second, time.Second, 0.5, -0.1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTrendWindow(tt.window, tt.granularity, tt.alpha, tt.beta)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewTrendWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTrendWindowLinearGrowth(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	tw, err := NewTrendWindow(30*time.Second, time.Second, 0.5, 0.5)
+	if err != nil {
+		t.Fatalf("NewTrendWindow failed: %v", err)
+	}
+
+	if !tw.IsEmpty(now) {
+		t.Error("IsEmpty() = false for a new window")
+	}
+
+	// A metric growing by 2 every second is tracked exactly.
+	for i := range 10 {
+		tw.Record(now.Add(time.Duration(i)*time.Second), float64(10+2*i))
+	}
+	last := now.Add(9 * time.Second)
+
+	level, slope := tw.LevelAndSlope(last)
+	if math.Abs(level-28) > 1e-9 {
+		t.Errorf("level = %v, want 28", level)
+	}
+	if math.Abs(slope-2) > 1e-9 {
+		t.Errorf("slope = %v, want 2", slope)
+	}
+	if got := tw.WindowAverage(last); math.Abs(got-28) > 1e-9 {
+		t.Errorf("WindowAverage() = %v, want 28", got)
+	}
+	if got := tw.Forecast(last, 5*time.Second); math.Abs(got-38) > 1e-9 {
+		t.Errorf("Forecast() = %v, want 38", got)
+	}
+}
+
+func TestTrendWindowSumsBucket(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	tw, err := NewTrendWindow(30*time.Second, time.Second, 0.5, 0.5)
+	if err != nil {
+		t.Fatalf("NewTrendWindow failed: %v", err)
+	}
+
+	tw.Record(now, 3)
+	tw.Record(now.Add(100*time.Millisecond), 4)
+	if got := tw.WindowAverage(now); got != 7 {
+		t.Errorf("WindowAverage() = %v, want 7", got)
+	}
+	if got := tw.Slope(now); got != 0 {
+		t.Errorf("Slope() = %v, want 0 with a single bucket", got)
+	}
+
+	// Values older than the bucket in progress are ignored.
+	tw.Record(now.Add(-5*time.Second), 100)
+	if got := tw.WindowAverage(now); got != 7 {
+		t.Errorf("WindowAverage() = %v, want 7 after a stale record", got)
+	}
+}
+
+func TestTrendWindowGaps(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	tw, err := NewTrendWindow(30*time.Second, time.Second, 0.5, 0.5)
+	if err != nil {
+		t.Fatalf("NewTrendWindow failed: %v", err)
+	}
+
+	// The slope is normalized by the gap between buckets.
+	tw.Record(now, 10)
+	tw.Record(now.Add(4*time.Second), 30)
+	if got := tw.Slope(now.Add(4 * time.Second)); math.Abs(got-5) > 1e-9 {
+		t.Errorf("Slope() = %v, want 5", got)
+	}
+
+	if tw.IsEmpty(now.Add(33 * time.Second)) {
+		t.Error("IsEmpty() = true within the window")
+	}
+	if !tw.IsEmpty(now.Add(34 * time.Second)) {
+		t.Error("IsEmpty() = false after the window")
+	}
+	if level, slope := tw.LevelAndSlope(now.Add(34 * time.Second)); level != 0 || slope != 0 {
+		t.Errorf("LevelAndSlope() = (%v, %v), want (0, 0) for an empty window", level, slope)
+	}
+}
+
+func TestTrendWindowResizeWindow(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	tw, err := NewTrendWindow(10*time.Second, time.Second, 0.5, 0.5)
+	if err != nil {
+		t.Fatalf("NewTrendWindow failed: %v", err)
+	}
+
+	tw.Record(now, 10)
+	if !tw.IsEmpty(now.Add(15 * time.Second)) {
+		t.Error("IsEmpty() = false after the window")
+	}
+
+	tw.ResizeWindow(20 * time.Second)
+	if tw.IsEmpty(now.Add(15 * time.Second)) {
+		t.Error("IsEmpty() = true within the resized window")
+	}
+	if got := tw.WindowAverage(now.Add(15 * time.Second)); got != 10 {
+		t.Errorf("WindowAverage() = %v, want 10", got)
+	}
+}
+
+func BenchmarkTrendWindowRecord(b *testing.B) {
+	tw, err := NewTrendWindow(60*time.Second, time.Second, 0.5, 0.3)
+	if err != nil {
+		b.Fatalf("NewTrendWindow failed: %v", err)
+	}
+	now := time.Now()
+
+	for b.Loop() {
+		now = now.Add(100 * time.Millisecond)
+		tw.Record(now, 10)
+	}
+}
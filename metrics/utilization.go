@@ -0,0 +1,48 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+)
+
+// Utilization converts raw resource usage of a pod into a utilization
+// percentage of the pod's resource request. Usage and request must be
+// expressed in the same units (e.g. millicores or bytes).
+func Utilization(usage, request float64) (float64, error) {
+	if request <= 0 {
+		return 0, fmt.Errorf("request must be positive, got %v", request)
+	}
+	return usage / request * 100, nil
+}
+
+// AverageUtilization converts raw resource usage of several pods sharing the
+// same per-pod request into their average utilization percentage. This is the
+// value to record when scaling with the utilization metric type.
+func AverageUtilization(usages []float64, request float64) (float64, error) {
+	if request <= 0 {
+		return 0, fmt.Errorf("request must be positive, got %v", request)
+	}
+	if len(usages) == 0 {
+		return 0, nil
+	}
+	total := 0.
+	for _, u := range usages {
+		total += u
+	}
+	return total / float64(len(usages)) / request * 100, nil
+}
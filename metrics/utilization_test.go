@@ -0,0 +1,95 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+)
+
+func TestUtilization(t *testing.T) {
+	tests := []struct {
+		name    string
+		usage   float64
+		request float64
+		want    float64
+		wantErr bool
+	}{{
+		name:    "half of request",
+		usage:   250,
+		request: 500,
+		want:    50,
+	}, {
+		name:    "above request",
+		usage:   750,
+		request: 500,
+		want:    150,
+	}, {
+		name:    "zero request",
+		usage:   100,
+		request: 0,
+		wantErr: true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Utilization(tc.usage, tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Utilization() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("Utilization() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAverageUtilization(t *testing.T) {
+	tests := []struct {
+		name    string
+		usages  []float64
+		request float64
+		want    float64
+		wantErr bool
+	}{{
+		name:    "multiple pods",
+		usages:  []float64{100, 200, 300},
+		request: 400,
+		want:    50,
+	}, {
+		name:    "no pods",
+		usages:  nil,
+		request: 400,
+		want:    0,
+	}, {
+		name:    "negative request",
+		usages:  []float64{100},
+		request: -1,
+		wantErr: true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := AverageUtilization(tc.usages, tc.request)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("AverageUtilization() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("AverageUtilization() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
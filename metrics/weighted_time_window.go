@@ -17,6 +17,7 @@ limitations under the License.
 package metrics
 
 import (
+	"fmt"
 	"math"
 	"time"
 
@@ -31,8 +32,12 @@ type WeightedTimeWindow struct {
 	// smoothingCoeff contains the speed with which the importance
 	// of items in the past decays. The larger the faster weights decay.
 	// It is autocomputed from window size and weightPrecision constant
-	// and is bounded by minExponent below.
+	// and is bounded by minExponent below, unless set explicitly.
 	smoothingCoeff float64
+
+	// explicitCoeff is true if smoothingCoeff was set explicitly, in which
+	// case it is kept when the window is resized.
+	explicitCoeff bool
 }
 
 var _ api.MetricAggregator = (*WeightedTimeWindow)(nil)
@@ -54,6 +59,67 @@ func NewWeightedTimeWindow(window, granularity time.Duration) (*WeightedTimeWind
 	}, nil
 }
 
+// NewWeightedTimeWindowWithAlpha generates a new WeightedTimeWindow with the
+// given granularity and an explicit smoothing coefficient alpha in (0, 1].
+// The larger alpha, the faster the weights of older buckets decay and the more
+// responsive the average is to recent values.
+func NewWeightedTimeWindowWithAlpha(window, granularity time.Duration, alpha float64) (*WeightedTimeWindow, error) {
+	if err := validateSmoothingCoeff(alpha); err != nil {
+		return nil, err
+	}
+
+	tw, err := NewTimeWindow(window, granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WeightedTimeWindow{
+		TimeWindow:     tw,
+		smoothingCoeff: alpha,
+		explicitCoeff:  true,
+	}, nil
+}
+
+// NewWeightedTimeWindowUnsynchronized is NewWeightedTimeWindow for windows
+// only ever used by a single goroutine, see NewTimeWindowUnsynchronized.
+func NewWeightedTimeWindowUnsynchronized(window, granularity time.Duration) (*WeightedTimeWindow, error) {
+	t, err := NewWeightedTimeWindow(window, granularity)
+	if err != nil {
+		return nil, err
+	}
+	t.bucketsMutex.Disable()
+	return t, nil
+}
+
+// validateSmoothingCoeff ensures the smoothing coefficient is in (0, 1].
+func validateSmoothingCoeff(alpha float64) error {
+	if !(alpha > 0 && alpha <= 1) {
+		return fmt.Errorf("smoothing coefficient = %v, must be in (0, 1]", alpha)
+	}
+	return nil
+}
+
+// SmoothingCoeff returns the smoothing coefficient of the window.
+func (t *WeightedTimeWindow) SmoothingCoeff() float64 {
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+	return t.smoothingCoeff
+}
+
+// SetSmoothingCoeff sets an explicit smoothing coefficient alpha in (0, 1],
+// which is kept when the window is resized.
+func (t *WeightedTimeWindow) SetSmoothingCoeff(alpha float64) error {
+	if err := validateSmoothingCoeff(alpha); err != nil {
+		return err
+	}
+
+	t.bucketsMutex.Lock()
+	defer t.bucketsMutex.Unlock()
+	t.smoothingCoeff = alpha
+	t.explicitCoeff = true
+	return nil
+}
+
 // WindowAverage returns the exponential weighted average. This means
 // that more recent items have much greater impact on the average than
 // the older ones.
@@ -70,6 +136,15 @@ func (t *WeightedTimeWindow) WindowAverage(now time.Time) float64 {
 	if t.isEmptyLocked(now) {
 		return 0
 	}
+	if t.emptyBucketPolicy != DefaultEmptyBuckets {
+		points := t.policyBucketsLocked(now)
+		ret, multiplier := 0., t.smoothingCoeff
+		for i := len(points) - 1; i >= 0; i-- {
+			ret += points[i].Value * multiplier
+			multiplier *= (1 - t.smoothingCoeff)
+		}
+		return ret
+	}
 
 	totalB := len(t.buckets)
 	numB := len(t.buckets)
@@ -96,8 +171,19 @@ func (t *WeightedTimeWindow) WindowAverage(now time.Time) float64 {
 	return ret
 }
 
+// WindowAverageWithStats returns the same weighted average as WindowAverage,
+// along with the fraction of the window's buckets carrying data.
+func (t *WeightedTimeWindow) WindowAverageWithStats(now time.Time) (float64, float64) {
+	return t.WindowAverage(now), t.FillFraction(now)
+}
+
 // ResizeWindow implements window resizing for the weighted averaging buckets object.
 func (t *WeightedTimeWindow) ResizeWindow(w time.Duration) {
 	t.TimeWindow.ResizeWindow(w)
-	t.smoothingCoeff = computeSmoothingCoeff(math.Ceil(float64(w) / float64(t.granularity)))
+
+	t.bucketsMutex.Lock()
+	defer t.bucketsMutex.Unlock()
+	if !t.explicitCoeff {
+		t.smoothingCoeff = computeSmoothingCoeff(math.Ceil(float64(w) / float64(t.granularity)))
+	}
 }
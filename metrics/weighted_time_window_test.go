@@ -191,3 +191,86 @@ func TestWeightedTimeWindowNegativeWindowAndGranularity(t *testing.T) {
 		t.Errorf("NewWeightedTimeWindow should fail with negative window")
 	}
 }
+
+func TestWeightedTimeWindowWindowAverageWithStats(t *testing.T) {
+	now := time.Now().Truncate(granularity)
+
+	buckets, err := NewWeightedTimeWindow(4*time.Second, granularity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	buckets.Record(now, 10)
+	buckets.Record(now.Add(time.Second), 20)
+
+	avg, fill := buckets.WindowAverageWithStats(now.Add(time.Second))
+	if want := buckets.WindowAverage(now.Add(time.Second)); avg != want {
+		t.Errorf("average = %v, want weighted average %v", avg, want)
+	}
+	if fill != 0.5 {
+		t.Errorf("FillFraction = %v, want 0.5", fill)
+	}
+}
+
+func TestNewWeightedTimeWindowWithAlpha(t *testing.T) {
+	for _, alpha := range []float64{0, -0.5, 1.5, math.NaN()} {
+		if _, err := NewWeightedTimeWindowWithAlpha(5*time.Second, granularity, alpha); err == nil {
+			t.Errorf("expected error for alpha %v", alpha)
+		}
+	}
+	if _, err := NewWeightedTimeWindowWithAlpha(-5*time.Second, granularity, 0.5); err == nil {
+		t.Error("expected error for a negative window")
+	}
+
+	now := time.Now()
+	buckets, err := NewWeightedTimeWindowWithAlpha(5*time.Second, granularity, 0.5)
+	if err != nil {
+		t.Fatalf("NewWeightedTimeWindowWithAlpha failed: %v", err)
+	}
+	buckets.Record(now, 10)
+	buckets.Record(now.Add(time.Second), 20)
+	// 20*0.5 + 10*0.5*0.5
+	if got, want := buckets.WindowAverage(now.Add(time.Second)), 12.5; got != want {
+		t.Errorf("WindowAverage() = %v, want %v", got, want)
+	}
+
+	// An explicit coefficient is kept on resize.
+	buckets.ResizeWindow(10 * time.Second)
+	if got := buckets.SmoothingCoeff(); got != 0.5 {
+		t.Errorf("SmoothingCoeff() after resize = %v, want 0.5", got)
+	}
+}
+
+func TestWeightedTimeWindowSetSmoothingCoeff(t *testing.T) {
+	buckets, err := NewWeightedTimeWindow(5*time.Second, granularity)
+	if err != nil {
+		t.Fatalf("NewWeightedTimeWindow failed: %v", err)
+	}
+	derived := buckets.SmoothingCoeff()
+
+	// A derived coefficient follows the window size.
+	buckets.ResizeWindow(20 * time.Second)
+	if got := buckets.SmoothingCoeff(); got == derived {
+		t.Errorf("SmoothingCoeff() after resize = %v, want a recomputed value", got)
+	}
+
+	if err := buckets.SetSmoothingCoeff(2); err == nil {
+		t.Error("expected error for alpha 2")
+	}
+	if err := buckets.SetSmoothingCoeff(1); err != nil {
+		t.Fatalf("SetSmoothingCoeff failed: %v", err)
+	}
+
+	// With alpha 1 only the latest bucket counts.
+	now := time.Now()
+	buckets.Record(now, 10)
+	buckets.Record(now.Add(time.Second), 20)
+	if got, want := buckets.WindowAverage(now.Add(time.Second)), 20.0; got != want {
+		t.Errorf("WindowAverage() = %v, want %v", got, want)
+	}
+
+	buckets.ResizeWindow(5 * time.Second)
+	if got := buckets.SmoothingCoeff(); got != 1 {
+		t.Errorf("SmoothingCoeff() after resize = %v, want 1", got)
+	}
+}
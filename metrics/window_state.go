@@ -0,0 +1,751 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Fedosin/libkpa/internal/protowire"
+)
+
+// WindowKind identifies the window type of serialized window state.
+type WindowKind int
+
+// Window kinds, matching the WindowKind enum of window.proto.
+const (
+	WindowKindUnspecified WindowKind = iota
+	WindowKindTime
+	WindowKindWeightedTime
+	WindowKindTrend
+	WindowKindMinTime
+	WindowKindTDigest
+	WindowKindHistogram
+)
+
+// String implements the Stringer interface.
+func (k WindowKind) String() string {
+	switch k {
+	case WindowKindTime:
+		return "TimeWindow"
+	case WindowKindWeightedTime:
+		return "WeightedTimeWindow"
+	case WindowKindTrend:
+		return "TrendWindow"
+	case WindowKindMinTime:
+		return "MinTimeWindow"
+	case WindowKindTDigest:
+		return "TDigestWindow"
+	case WindowKindHistogram:
+		return "HistogramWindow"
+	}
+	return fmt.Sprintf("WindowKind(%d)", int(k))
+}
+
+// KindOf returns the window kind of data encoded by MarshalBinary of any
+// window type, so that it can be restored into the right type.
+func KindOf(data []byte) (WindowKind, error) {
+	var s windowState
+	if err := s.decode(data); err != nil {
+		return WindowKindUnspecified, err
+	}
+	return s.kind, nil
+}
+
+// windowState mirrors the WindowState message of window.proto.
+type windowState struct {
+	kind        WindowKind
+	window      time.Duration
+	granularity time.Duration
+	buckets     []bucketState
+
+	firstWrite time.Time
+	lastWrite  time.Time
+	lastRecord time.Time
+
+	emptyBucketPolicy  EmptyBucketPolicy
+	interpolate        bool
+	stalenessThreshold time.Duration
+	smoothingCoeff     float64
+	explicitCoeff      bool
+
+	alpha, beta        float64
+	level, slope       float64
+	hasLevel, hasSlope bool
+
+	compression float64
+
+	bounds     []float64
+	lastSample *bucketState
+}
+
+// bucketState mirrors the Bucket message of window.proto.
+type bucketState struct {
+	time      time.Time
+	value     float64
+	count     int64
+	sum       float64
+	min, max  float64
+	centroids []centroid
+	counts    []float64
+}
+
+func appendVarintField(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.AppendUvarint(protowire.AppendTag(b, field, protowire.Varint), v)
+}
+
+func appendBoolField(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+	return appendVarintField(b, field, 1)
+}
+
+func appendTimeField(b []byte, field int, t time.Time) []byte {
+	if t.IsZero() {
+		return b
+	}
+	return appendVarintField(b, field, uint64(t.UnixNano()))
+}
+
+func appendDoubleField(b []byte, field int, v float64) []byte {
+	if v == 0 && !math.Signbit(v) {
+		return b
+	}
+	return binary.LittleEndian.AppendUint64(protowire.AppendTag(b, field, protowire.Fixed64), math.Float64bits(v))
+}
+
+func appendPackedDoubles(b []byte, field int, vs []float64) []byte {
+	if len(vs) == 0 {
+		return b
+	}
+	packed := make([]byte, 0, 8*len(vs))
+	for _, v := range vs {
+		packed = binary.LittleEndian.AppendUint64(packed, math.Float64bits(v))
+	}
+	return protowire.AppendBytesField(b, field, packed)
+}
+
+// consumeFields calls fn for every field of an encoded message. For varint
+// and fixed64 fields v holds the value, for length-delimited fields data.
+func consumeFields(b []byte, fn func(field, wireType int, v uint64, data []byte) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errors.New("invalid field tag")
+		}
+		b = b[n:]
+		field, wireType := int(tag>>3), int(tag&7)
+
+		var v uint64
+		var data []byte
+		switch wireType {
+		case protowire.Varint:
+			v, n = binary.Uvarint(b)
+			if n <= 0 {
+				return fmt.Errorf("invalid varint in field %d", field)
+			}
+			b = b[n:]
+		case protowire.Fixed64:
+			if len(b) < 8 {
+				return fmt.Errorf("truncated fixed64 in field %d", field)
+			}
+			v, b = binary.LittleEndian.Uint64(b), b[8:]
+		case protowire.Bytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return fmt.Errorf("truncated bytes in field %d", field)
+			}
+			data, b = b[n:n+int(l)], b[n+int(l):]
+		case protowire.Fixed32:
+			if len(b) < 4 {
+				return fmt.Errorf("truncated fixed32 in field %d", field)
+			}
+			b = b[4:]
+			continue
+		default:
+			return fmt.Errorf("unsupported wire type %d in field %d", wireType, field)
+		}
+		if err := fn(field, wireType, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeDoubles decodes a repeated double field, packed or not.
+func decodeDoubles(dst []float64, wireType int, v uint64, data []byte) ([]float64, error) {
+	switch wireType {
+	case protowire.Fixed64:
+		return append(dst, math.Float64frombits(v)), nil
+	case protowire.Bytes:
+		if len(data)%8 != 0 {
+			return nil, errors.New("invalid packed doubles")
+		}
+		for i := 0; i < len(data); i += 8 {
+			dst = append(dst, math.Float64frombits(binary.LittleEndian.Uint64(data[i:])))
+		}
+		return dst, nil
+	}
+	return nil, fmt.Errorf("unexpected wire type %d for doubles", wireType)
+}
+
+func decodeTime(v uint64) time.Time {
+	if v == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(v))
+}
+
+func (s *bucketState) encode() []byte {
+	var b []byte
+	b = appendTimeField(b, 1, s.time)
+	b = appendDoubleField(b, 2, s.value)
+	b = appendVarintField(b, 3, uint64(s.count))
+	b = appendDoubleField(b, 4, s.sum)
+	b = appendDoubleField(b, 5, s.min)
+	b = appendDoubleField(b, 6, s.max)
+	for _, c := range s.centroids {
+		var cb []byte
+		cb = appendDoubleField(cb, 1, c.mean)
+		cb = appendDoubleField(cb, 2, c.weight)
+		b = protowire.AppendBytesField(b, 7, cb)
+	}
+	return appendPackedDoubles(b, 8, s.counts)
+}
+
+func (s *bucketState) decode(data []byte) error {
+	return consumeFields(data, func(field, wireType int, v uint64, data []byte) (err error) {
+		switch field {
+		case 1:
+			s.time = decodeTime(v)
+		case 2:
+			s.value = math.Float64frombits(v)
+		case 3:
+			s.count = int64(v)
+		case 4:
+			s.sum = math.Float64frombits(v)
+		case 5:
+			s.min = math.Float64frombits(v)
+		case 6:
+			s.max = math.Float64frombits(v)
+		case 7:
+			var c centroid
+			err = consumeFields(data, func(field, _ int, v uint64, _ []byte) error {
+				switch field {
+				case 1:
+					c.mean = math.Float64frombits(v)
+				case 2:
+					c.weight = math.Float64frombits(v)
+				}
+				return nil
+			})
+			s.centroids = append(s.centroids, c)
+		case 8:
+			s.counts, err = decodeDoubles(s.counts, wireType, v, data)
+		}
+		return err
+	})
+}
+
+func (s *windowState) encode() []byte {
+	var b []byte
+	b = appendVarintField(b, 1, uint64(s.kind))
+	b = appendVarintField(b, 2, uint64(s.window))
+	b = appendVarintField(b, 3, uint64(s.granularity))
+	for i := range s.buckets {
+		b = protowire.AppendBytesField(b, 4, s.buckets[i].encode())
+	}
+	b = appendTimeField(b, 5, s.firstWrite)
+	b = appendTimeField(b, 6, s.lastWrite)
+	b = appendTimeField(b, 7, s.lastRecord)
+	b = appendVarintField(b, 8, uint64(s.emptyBucketPolicy))
+	b = appendBoolField(b, 9, s.interpolate)
+	b = appendVarintField(b, 10, uint64(s.stalenessThreshold))
+	b = appendDoubleField(b, 11, s.smoothingCoeff)
+	b = appendBoolField(b, 12, s.explicitCoeff)
+	b = appendDoubleField(b, 13, s.alpha)
+	b = appendDoubleField(b, 14, s.beta)
+	b = appendDoubleField(b, 15, s.level)
+	b = appendDoubleField(b, 16, s.slope)
+	b = appendBoolField(b, 17, s.hasLevel)
+	b = appendBoolField(b, 18, s.hasSlope)
+	b = appendDoubleField(b, 19, s.compression)
+	b = appendPackedDoubles(b, 20, s.bounds)
+	if s.lastSample != nil {
+		b = protowire.AppendBytesField(b, 21, s.lastSample.encode())
+	}
+	return b
+}
+
+func (s *windowState) decode(data []byte) error {
+	err := consumeFields(data, func(field, wireType int, v uint64, data []byte) (err error) {
+		switch field {
+		case 1:
+			s.kind = WindowKind(v)
+		case 2:
+			s.window = time.Duration(v)
+		case 3:
+			s.granularity = time.Duration(v)
+		case 4:
+			var bs bucketState
+			err = bs.decode(data)
+			s.buckets = append(s.buckets, bs)
+		case 5:
+			s.firstWrite = decodeTime(v)
+		case 6:
+			s.lastWrite = decodeTime(v)
+		case 7:
+			s.lastRecord = decodeTime(v)
+		case 8:
+			s.emptyBucketPolicy = EmptyBucketPolicy(v)
+		case 9:
+			s.interpolate = v != 0
+		case 10:
+			s.stalenessThreshold = time.Duration(v)
+		case 11:
+			s.smoothingCoeff = math.Float64frombits(v)
+		case 12:
+			s.explicitCoeff = v != 0
+		case 13:
+			s.alpha = math.Float64frombits(v)
+		case 14:
+			s.beta = math.Float64frombits(v)
+		case 15:
+			s.level = math.Float64frombits(v)
+		case 16:
+			s.slope = math.Float64frombits(v)
+		case 17:
+			s.hasLevel = v != 0
+		case 18:
+			s.hasSlope = v != 0
+		case 19:
+			s.compression = math.Float64frombits(v)
+		case 20:
+			s.bounds, err = decodeDoubles(s.bounds, wireType, v, data)
+		case 21:
+			s.lastSample = &bucketState{}
+			err = s.lastSample.decode(data)
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("invalid window state: %w", err)
+	}
+	return nil
+}
+
+// Limits of decoded window state, so corrupt or crafted input can't make
+// UnmarshalBinary allocate huge windows.
+const (
+	maxStateWindow  = 30 * 24 * time.Hour
+	maxStateBuckets = 1 << 20
+)
+
+// decodeKind decodes data and ensures it holds valid state of the given
+// kind.
+func decodeKind(data []byte, kind WindowKind) (*windowState, error) {
+	var s windowState
+	if err := s.decode(data); err != nil {
+		return nil, err
+	}
+	if s.kind != kind {
+		return nil, fmt.Errorf("window state is a %v, want %v", s.kind, kind)
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("invalid window state: %w", err)
+	}
+	return &s, nil
+}
+
+// validate checks the sizes and times the window types rely on, before any
+// window is allocated.
+func (s *windowState) validate() error {
+	if s.granularity <= 0 {
+		return fmt.Errorf("granularity = %v, must be positive", s.granularity)
+	}
+	if s.window < s.granularity {
+		return fmt.Errorf("window = %v, must be at least the granularity %v", s.window, s.granularity)
+	}
+	if s.window > maxStateWindow {
+		return fmt.Errorf("window = %v, must be at most %v", s.window, maxStateWindow)
+	}
+	if buckets := s.window / s.granularity; buckets > maxStateBuckets {
+		return fmt.Errorf("window of %d buckets, must be at most %d", buckets, maxStateBuckets)
+	}
+	for _, t := range []time.Time{s.firstWrite, s.lastWrite, s.lastRecord} {
+		if err := validateStateTime(t); err != nil {
+			return err
+		}
+	}
+	for _, b := range s.buckets {
+		if b.time.IsZero() {
+			return errors.New("bucket without a time")
+		}
+		if err := validateStateTime(b.time); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateStateTime rejects times before the Unix epoch, which map to
+// negative bucket indexes. Zero times mark unset fields.
+func validateStateTime(t time.Time) error {
+	if !t.IsZero() && t.UnixNano() < 0 {
+		return fmt.Errorf("time %v is before the Unix epoch", t)
+	}
+	return nil
+}
+
+// stateLocked captures the state of the window. At least Read Lock needs to be held.
+func (t *TimeWindow) stateLocked(kind WindowKind) *windowState {
+	s := &windowState{
+		kind:               kind,
+		window:             t.window,
+		granularity:        t.granularity,
+		firstWrite:         t.firstWrite,
+		lastWrite:          t.lastWrite,
+		lastRecord:         t.lastRecord,
+		emptyBucketPolicy:  t.emptyBucketPolicy,
+		interpolate:        t.interpolate,
+		stalenessThreshold: t.stalenessThreshold,
+	}
+	if t.lastWrite.IsZero() {
+		return s
+	}
+	// The ring holds the buckets up to the last write.
+	lastIdx := t.timeToIndex(t.lastWrite)
+	for i := lastIdx - len(t.buckets) + 1; i <= lastIdx; i++ {
+		idx := i % len(t.buckets)
+		if t.bucketCounts[idx] == 0 && t.buckets[idx] == 0 {
+			continue
+		}
+		s.buckets = append(s.buckets, bucketState{
+			time:  t.indexToTime(i),
+			value: t.buckets[idx],
+			count: int64(t.bucketCounts[idx]),
+		})
+	}
+	return s
+}
+
+// restoreLocked replaces the state of the window. Write Lock needs to be held.
+func (t *TimeWindow) restoreLocked(s *windowState) error {
+	if err := s.emptyBucketPolicy.validate(); err != nil {
+		return fmt.Errorf("invalid window state: %w", err)
+	}
+	tw, err := newTimeWindow(s.window, s.granularity, t.pool)
+	if err != nil {
+		return fmt.Errorf("invalid window state: %w", err)
+	}
+	for _, b := range s.buckets {
+		idx := tw.timeToIndex(b.time) % len(tw.buckets)
+		tw.buckets[idx] = b.value
+		tw.bucketCounts[idx] = int(b.count)
+		tw.windowTotal += b.value
+	}
+
+	t.releaseBucketsLocked()
+	t.buckets, t.bucketCounts, t.windowTotal = tw.buckets, tw.bucketCounts, tw.windowTotal
+	t.window, t.granularity = s.window, s.granularity
+	t.firstWrite, t.lastWrite, t.lastRecord = s.firstWrite, s.lastWrite, s.lastRecord
+	t.emptyBucketPolicy = s.emptyBucketPolicy
+	t.interpolate = s.interpolate
+	t.stalenessThreshold = s.stalenessThreshold
+	return nil
+}
+
+// MarshalBinary encodes the window state in the WindowState protobuf format
+// defined in window.proto.
+func (t *TimeWindow) MarshalBinary() ([]byte, error) {
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+	return t.stateLocked(WindowKindTime).encode(), nil
+}
+
+// UnmarshalBinary restores a window encoded by MarshalBinary, including its
+// window size and granularity.
+func (t *TimeWindow) UnmarshalBinary(data []byte) error {
+	s, err := decodeKind(data, WindowKindTime)
+	if err != nil {
+		return err
+	}
+
+	t.bucketsMutex.Lock()
+	defer t.bucketsMutex.Unlock()
+	return t.restoreLocked(s)
+}
+
+// MarshalBinary encodes the window state in the WindowState protobuf format
+// defined in window.proto.
+func (t *WeightedTimeWindow) MarshalBinary() ([]byte, error) {
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+
+	s := t.stateLocked(WindowKindWeightedTime)
+	s.smoothingCoeff, s.explicitCoeff = t.smoothingCoeff, t.explicitCoeff
+	return s.encode(), nil
+}
+
+// UnmarshalBinary restores a window encoded by MarshalBinary, including its
+// window size, granularity and smoothing coefficient.
+func (t *WeightedTimeWindow) UnmarshalBinary(data []byte) error {
+	s, err := decodeKind(data, WindowKindWeightedTime)
+	if err != nil {
+		return err
+	}
+	if err := validateSmoothingCoeff(s.smoothingCoeff); err != nil {
+		return fmt.Errorf("invalid window state: %w", err)
+	}
+
+	if t.TimeWindow == nil {
+		t.TimeWindow = &TimeWindow{}
+	}
+	t.bucketsMutex.Lock()
+	defer t.bucketsMutex.Unlock()
+	if err := t.restoreLocked(s); err != nil {
+		return err
+	}
+	t.smoothingCoeff, t.explicitCoeff = s.smoothingCoeff, s.explicitCoeff
+	return nil
+}
+
+// MarshalBinary encodes the window state in the WindowState protobuf format
+// defined in window.proto.
+func (t *TrendWindow) MarshalBinary() ([]byte, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	s := &windowState{
+		kind:        WindowKindTrend,
+		window:      t.window,
+		granularity: t.granularity,
+		lastWrite:   t.lastCommit,
+		alpha:       t.alpha,
+		beta:        t.beta,
+		level:       t.level,
+		slope:       t.slope,
+		hasLevel:    t.hasLevel,
+		hasSlope:    t.hasSlope,
+	}
+	if t.hasBucket {
+		s.buckets = []bucketState{{time: t.bucketTime, value: t.bucketSum}}
+	}
+	return s.encode(), nil
+}
+
+// UnmarshalBinary restores a window encoded by MarshalBinary, including its
+// window size, granularity and smoothing coefficients.
+func (t *TrendWindow) UnmarshalBinary(data []byte) error {
+	s, err := decodeKind(data, WindowKindTrend)
+	if err != nil {
+		return err
+	}
+	if _, err := NewTrendWindow(s.window, s.granularity, s.alpha, s.beta); err != nil {
+		return fmt.Errorf("invalid window state: %w", err)
+	}
+	if len(s.buckets) > 1 {
+		return fmt.Errorf("invalid window state: %d buckets, want at most 1", len(s.buckets))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.window, t.granularity = s.window, s.granularity
+	t.alpha, t.beta = s.alpha, s.beta
+	t.level, t.slope, t.hasLevel, t.hasSlope = s.level, s.slope, s.hasLevel, s.hasSlope
+	t.lastCommit = s.lastWrite
+	t.bucketTime, t.bucketSum, t.hasBucket = time.Time{}, 0, false
+	if len(s.buckets) == 1 {
+		t.bucketTime, t.bucketSum, t.hasBucket = s.buckets[0].time, s.buckets[0].value, true
+	}
+	return nil
+}
+
+// MarshalBinary encodes the window state in the WindowState protobuf format
+// defined in window.proto.
+func (t *MinTimeWindow) MarshalBinary() ([]byte, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	s := &windowState{
+		kind:              WindowKindMinTime,
+		window:            t.window,
+		granularity:       t.granularity,
+		firstWrite:        t.firstWrite,
+		lastWrite:         t.lastWrite,
+		emptyBucketPolicy: t.policy,
+	}
+	for i, bt := range t.bucketTimes {
+		if !bt.IsZero() {
+			s.buckets = append(s.buckets, bucketState{time: bt, value: t.buckets[i]})
+		}
+	}
+	return s.encode(), nil
+}
+
+// UnmarshalBinary restores a window encoded by MarshalBinary, including its
+// window size, granularity and empty bucket policy.
+func (t *MinTimeWindow) UnmarshalBinary(data []byte) error {
+	s, err := decodeKind(data, WindowKindMinTime)
+	if err != nil {
+		return err
+	}
+	mw, err := NewMinTimeWindow(s.window, s.granularity, s.emptyBucketPolicy)
+	if err != nil {
+		return fmt.Errorf("invalid window state: %w", err)
+	}
+	for _, b := range s.buckets {
+		idx := mw.index(b.time)
+		mw.buckets[idx], mw.bucketTimes[idx] = b.value, b.time
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.window, t.granularity, t.policy = mw.window, mw.granularity, mw.policy
+	t.buckets, t.bucketTimes = mw.buckets, mw.bucketTimes
+	t.firstWrite, t.lastWrite = s.firstWrite, s.lastWrite
+	return nil
+}
+
+// MarshalBinary encodes the window state in the WindowState protobuf format
+// defined in window.proto.
+func (t *TDigestWindow) MarshalBinary() ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := &windowState{
+		kind:        WindowKindTDigest,
+		window:      t.window,
+		granularity: t.granularity,
+		compression: t.compression,
+	}
+	for i, bt := range t.bucketTimes {
+		if bt.IsZero() {
+			continue
+		}
+		d := t.buckets[i]
+		d.compress()
+		s.buckets = append(s.buckets, bucketState{
+			time:      bt,
+			sum:       d.sum,
+			min:       d.min,
+			max:       d.max,
+			centroids: append([]centroid(nil), d.centroids...),
+		})
+	}
+	return s.encode(), nil
+}
+
+// UnmarshalBinary restores a window encoded by MarshalBinary, including its
+// window size, granularity and compression.
+func (t *TDigestWindow) UnmarshalBinary(data []byte) error {
+	s, err := decodeKind(data, WindowKindTDigest)
+	if err != nil {
+		return err
+	}
+	tw, err := NewTDigestWindow(s.window, s.granularity, s.compression)
+	if err != nil {
+		return fmt.Errorf("invalid window state: %w", err)
+	}
+	for _, b := range s.buckets {
+		idx := tw.index(b.time)
+		d := tw.buckets[idx]
+		d.Reset()
+		for _, c := range b.centroids {
+			if c.weight <= 0 {
+				return fmt.Errorf("invalid window state: centroid weight = %v, must be positive", c.weight)
+			}
+			d.total += c.weight
+		}
+		d.centroids = append(d.centroids, b.centroids...)
+		d.sum, d.min, d.max = b.sum, b.min, b.max
+		tw.bucketTimes[idx] = b.time
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.window, t.granularity, t.compression = tw.window, tw.granularity, tw.compression
+	t.buckets, t.bucketTimes = tw.buckets, tw.bucketTimes
+	return nil
+}
+
+// MarshalBinary encodes the window state in the WindowState protobuf format
+// defined in window.proto.
+func (h *HistogramWindow) MarshalBinary() ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := &windowState{
+		kind:        WindowKindHistogram,
+		window:      h.window,
+		granularity: h.granularity,
+		bounds:      h.bounds,
+	}
+	for _, b := range h.buckets {
+		if !b.time.IsZero() {
+			s.buckets = append(s.buckets, bucketState{time: b.time, value: b.count, sum: b.sum, counts: b.counts})
+		}
+	}
+	if h.hasLast {
+		s.lastSample = &bucketState{value: h.last.Count, sum: h.last.Sum, counts: h.last.Counts}
+	}
+	return s.encode(), nil
+}
+
+// UnmarshalBinary restores a window encoded by MarshalBinary, including its
+// window size, granularity and bucket bounds.
+func (h *HistogramWindow) UnmarshalBinary(data []byte) error {
+	s, err := decodeKind(data, WindowKindHistogram)
+	if err != nil {
+		return err
+	}
+	hw, err := NewHistogramWindow(s.window, s.granularity, s.bounds)
+	if err != nil {
+		return fmt.Errorf("invalid window state: %w", err)
+	}
+	if len(hw.bounds) != len(s.bounds) {
+		return errors.New("invalid window state: missing +Inf bound")
+	}
+	for _, b := range s.buckets {
+		if len(b.counts) != len(hw.bounds) {
+			return fmt.Errorf("invalid window state: bucket has %d counts, want %d", len(b.counts), len(hw.bounds))
+		}
+		hb := &hw.buckets[int((b.time.UnixNano()/int64(hw.granularity))%int64(len(hw.buckets)))]
+		hb.time, hb.sum, hb.count = b.time, b.sum, b.value
+		copy(hb.counts, b.counts)
+	}
+	if s.lastSample != nil {
+		if len(s.lastSample.counts) != len(hw.bounds) {
+			return fmt.Errorf("invalid window state: last sample has %d counts, want %d", len(s.lastSample.counts), len(hw.bounds))
+		}
+		hw.last = HistogramSample{Counts: s.lastSample.counts, Sum: s.lastSample.sum, Count: s.lastSample.value}
+		hw.hasLast = true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.window, h.granularity, h.bounds = hw.window, hw.granularity, hw.bounds
+	h.buckets, h.last, h.hasLast = hw.buckets, hw.last, hw.hasLast
+	return nil
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"encoding"
+	"testing"
+	"time"
+)
+
+type binaryWindow interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+func TestWindowMarshalBinary(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	at := now.Add(9 * time.Second)
+
+	newTime := func() binaryWindow {
+		w, _ := NewTimeWindow(10*time.Second, time.Second)
+		return w
+	}
+	newWeighted := func() binaryWindow {
+		w, _ := NewWeightedTimeWindow(10*time.Second, time.Second)
+		return w
+	}
+	newTrend := func() binaryWindow {
+		w, _ := NewTrendWindow(10*time.Second, time.Second, 0.5, 0.3)
+		return w
+	}
+	newMin := func() binaryWindow {
+		w, _ := NewMinTimeWindow(10*time.Second, time.Second, Exclude)
+		return w
+	}
+	newTDigest := func() binaryWindow {
+		w, _ := NewTDigestWindow(10*time.Second, time.Second, DefaultCompression)
+		return w
+	}
+	newHistogram := func() binaryWindow {
+		w, _ := NewHistogramWindow(10*time.Second, time.Second, []float64{10, 20, 50})
+		return w
+	}
+
+	tests := []struct {
+		name string
+		kind WindowKind
+		new  func() binaryWindow
+		// empty creates the receiver for UnmarshalBinary, with a different setup.
+		empty func() binaryWindow
+		setup func(w binaryWindow)
+		read  func(w binaryWindow) []float64
+	}{{
+		name: "time window",
+		kind: WindowKindTime,
+		new:  newTime,
+		empty: func() binaryWindow {
+			w, _ := NewTimeWindow(time.Minute, 2*time.Second)
+			return w
+		},
+		setup: func(w binaryWindow) {
+			tw := w.(*TimeWindow)
+			_ = tw.SetEmptyBucketPolicy(Exclude)
+			tw.SetStalenessThreshold(5 * time.Second)
+			for i := range 12 {
+				if i%3 != 0 {
+					tw.Record(now.Add(time.Duration(i-3)*time.Second), float64(i))
+				}
+			}
+		},
+		read: func(w binaryWindow) []float64 {
+			tw := w.(*TimeWindow)
+			return []float64{tw.WindowAverage(at), tw.WindowSum(at), float64(tw.SampleCount(at)),
+				tw.FillFraction(at), float64(tw.TimeSinceLastRecord(at)), float64(tw.StalenessThreshold())}
+		},
+	}, {
+		name:  "weighted time window",
+		kind:  WindowKindWeightedTime,
+		new:   newWeighted,
+		empty: newWeighted,
+		setup: func(w binaryWindow) {
+			ww := w.(*WeightedTimeWindow)
+			_ = ww.SetSmoothingCoeff(0.4)
+			for i := range 10 {
+				ww.Record(now.Add(time.Duration(i)*time.Second), float64(i*i))
+			}
+		},
+		read: func(w binaryWindow) []float64 {
+			ww := w.(*WeightedTimeWindow)
+			return []float64{ww.WindowAverage(at), ww.SmoothingCoeff()}
+		},
+	}, {
+		name:  "trend window",
+		kind:  WindowKindTrend,
+		new:   newTrend,
+		empty: newTrend,
+		setup: func(w binaryWindow) {
+			for i := range 10 {
+				w.(*TrendWindow).Record(now.Add(time.Duration(i)*time.Second), float64(3*i))
+			}
+		},
+		read: func(w binaryWindow) []float64 {
+			level, slope := w.(*TrendWindow).LevelAndSlope(at)
+			return []float64{level, slope}
+		},
+	}, {
+		name:  "min time window",
+		kind:  WindowKindMinTime,
+		new:   newMin,
+		empty: newMin,
+		setup: func(w binaryWindow) {
+			for i := range 10 {
+				w.(*MinTimeWindow).Record(now.Add(time.Duration(i)*time.Second), float64(20-i))
+			}
+		},
+		read: func(w binaryWindow) []float64 {
+			v, _ := w.(*MinTimeWindow).Min(at)
+			return []float64{v}
+		},
+	}, {
+		name:  "t-digest window",
+		kind:  WindowKindTDigest,
+		new:   newTDigest,
+		empty: newTDigest,
+		setup: func(w binaryWindow) {
+			for i := range 1000 {
+				w.(*TDigestWindow).Record(now.Add(time.Duration(i%10)*time.Second), float64(i))
+			}
+		},
+		read: func(w binaryWindow) []float64 {
+			tw := w.(*TDigestWindow)
+			return append(tw.Quantiles(at, 0, 0.5, 0.99, 1), tw.Count(at), tw.WindowAverage(at))
+		},
+	}, {
+		name:  "histogram window",
+		kind:  WindowKindHistogram,
+		new:   newHistogram,
+		empty: newHistogram,
+		setup: func(w binaryWindow) {
+			hw := w.(*HistogramWindow)
+			_ = hw.RecordCumulative(now, HistogramSample{Counts: []float64{1, 2, 3}, Sum: 30})
+			_ = hw.RecordCumulative(now.Add(time.Second), HistogramSample{Counts: []float64{5, 9, 12}, Sum: 200})
+		},
+		read: func(w binaryWindow) []float64 {
+			hw := w.(*HistogramWindow)
+			// Recording after the restore must continue from the last sample.
+			_ = hw.RecordCumulative(at, HistogramSample{Counts: []float64{6, 10, 14}, Sum: 240})
+			return []float64{hw.Quantile(at, 0.5), hw.WindowAverage(at), hw.Count(at)}
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := tt.new()
+			tt.setup(w)
+
+			data, err := w.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary failed: %v", err)
+			}
+			if kind, err := KindOf(data); err != nil || kind != tt.kind {
+				t.Errorf("KindOf() = %v, %v, want %v", kind, err, tt.kind)
+			}
+
+			restored := tt.empty()
+			if err := restored.UnmarshalBinary(data); err != nil {
+				t.Fatalf("UnmarshalBinary failed: %v", err)
+			}
+
+			want, got := tt.read(w), tt.read(restored)
+			if len(got) != len(want) {
+				t.Fatalf("read %d values, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("value %d = %v after restore, want %v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWindowUnmarshalBinaryErrors(t *testing.T) {
+	tw, _ := NewTimeWindow(10*time.Second, time.Second)
+	tw.Record(time.Now(), 1)
+	data, err := tw.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	mw, _ := NewMinTimeWindow(10*time.Second, time.Second, Exclude)
+	if err := mw.UnmarshalBinary(data); err == nil {
+		t.Error("UnmarshalBinary of another window kind succeeded, want error")
+	}
+	if err := tw.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Error("UnmarshalBinary of truncated data succeeded, want error")
+	}
+	if err := tw.UnmarshalBinary(nil); err == nil {
+		t.Error("UnmarshalBinary of empty data succeeded, want error")
+	}
+	if _, err := KindOf([]byte{0xff}); err == nil {
+		t.Error("KindOf of invalid data succeeded, want error")
+	}
+}
+
+func TestWindowUnmarshalBinaryLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		state windowState
+	}{
+		{
+			name: "bucket before the epoch",
+			state: windowState{kind: WindowKindTime, window: 10 * time.Second, granularity: time.Second,
+				buckets: []bucketState{{time: time.Unix(-6, 0), value: 1, count: 1}}},
+		},
+		{
+			name: "bucket without a time",
+			state: windowState{kind: WindowKindWeightedTime, window: 10 * time.Second, granularity: time.Second,
+				buckets: []bucketState{{value: 1, count: 1}}},
+		},
+		{
+			name:  "window shorter than granularity",
+			state: windowState{kind: WindowKindTime, window: -time.Second, granularity: time.Second},
+		},
+		{
+			name:  "huge window",
+			state: windowState{kind: WindowKindTime, window: 1 << 62, granularity: time.Hour},
+		},
+		{
+			name:  "too many buckets",
+			state: windowState{kind: WindowKindHistogram, window: 24 * time.Hour, granularity: time.Millisecond, bounds: []float64{1}},
+		},
+		{
+			name:  "last write before the epoch",
+			state: windowState{kind: WindowKindMinTime, window: 10 * time.Second, granularity: time.Second, lastWrite: time.Unix(-1, 0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := unmarshalAnyWindow(tt.state.encode()); err == nil {
+				t.Error("UnmarshalBinary succeeded, want error")
+			}
+		})
+	}
+}
+
+// unmarshalAnyWindow restores data into a window of the kind it encodes.
+func unmarshalAnyWindow(data []byte) error {
+	kind, err := KindOf(data)
+	if err != nil {
+		return err
+	}
+	var w encoding.BinaryUnmarshaler
+	switch kind {
+	case WindowKindTime:
+		w = &TimeWindow{}
+	case WindowKindWeightedTime:
+		w = &WeightedTimeWindow{}
+	case WindowKindTrend:
+		w = &TrendWindow{}
+	case WindowKindMinTime:
+		w = &MinTimeWindow{}
+	case WindowKindTDigest:
+		w = &TDigestWindow{}
+	case WindowKindHistogram:
+		w = &HistogramWindow{}
+	default:
+		return nil
+	}
+	return w.UnmarshalBinary(data)
+}
+
+func FuzzWindowUnmarshalBinary(f *testing.F) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tw, _ := NewTimeWindow(10*time.Second, time.Second)
+	ww, _ := NewWeightedTimeWindow(10*time.Second, time.Second)
+	trend, _ := NewTrendWindow(10*time.Second, time.Second, 0.5, 0.3)
+	mw, _ := NewMinTimeWindow(10*time.Second, time.Second, Exclude)
+	dw, _ := NewTDigestWindow(10*time.Second, time.Second, DefaultCompression)
+	hw, _ := NewHistogramWindow(10*time.Second, time.Second, []float64{10, 20, 50})
+	for _, w := range []interface {
+		binaryWindow
+		Record(time.Time, float64)
+	}{tw, ww, trend, mw, dw, hw} {
+		for i := range 3 {
+			w.Record(now.Add(time.Duration(i)*time.Second), float64(10*i))
+		}
+		data, err := w.MarshalBinary()
+		if err != nil {
+			f.Fatalf("MarshalBinary failed: %v", err)
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Invalid input must fail with an error, never panic.
+		_ = unmarshalAnyWindow(data)
+	})
+}
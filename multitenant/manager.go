@@ -0,0 +1,574 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package multitenant provides a Manager that manages autoscalers for many
+// independent targets, e.g. all services of a cluster. Targets are keyed by
+// namespace and name and are spread across shards, so metric ingestion for
+// different targets doesn't contend on a single lock.
+package multitenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+	"github.com/Fedosin/libkpa/manager"
+	"github.com/Fedosin/libkpa/metrics"
+)
+
+// defaultShardCount is the number of shards used when none is specified.
+const defaultShardCount = 32
+
+// ErrClosed is returned by Manager methods called after Close.
+var ErrClosed = errors.New("multitenant manager is closed")
+
+// Key identifies a scale target.
+type Key struct {
+	Namespace string
+	Name      string
+}
+
+// String returns the key in the "namespace/name" form.
+func (k Key) String() string {
+	return k.Namespace + "/" + k.Name
+}
+
+// ParseKey parses a key in the "namespace/name" form.
+func ParseKey(s string) (Key, error) {
+	namespace, name, ok := strings.Cut(s, "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return Key{}, fmt.Errorf("invalid key %q, expected namespace/name", s)
+	}
+	return Key{Namespace: namespace, Name: name}, nil
+}
+
+// Sample is a metric value recorded for a target.
+type Sample struct {
+	Key   Key
+	Value float64
+	Time  time.Time
+}
+
+// target holds the autoscaler of a single scale target.
+type target struct {
+	scaler    *manager.Scaler
+	readyPods atomic.Int32
+	priority  atomic.Int32
+}
+
+// shard is a subset of the targets protected by its own lock.
+type shard struct {
+	mu      sync.RWMutex
+	targets map[Key]*target
+}
+
+// Manager manages autoscalers for many independent scale targets.
+type Manager struct {
+	shards   []*shard
+	capacity atomic.Int32
+	closed   atomic.Bool
+
+	// profilerLabels enables pprof labels in scaling passes.
+	profilerLabels atomic.Bool
+
+	// pool is the pool the metric windows of the targets are allocated
+	// from, see NewManagerWithBucketPool.
+	pool *metrics.BucketPool
+}
+
+// NewManager creates a new Manager with the given number of shards.
+// If shards is not positive, a default of 32 is used.
+func NewManager(shards int) *Manager {
+	if shards <= 0 {
+		shards = defaultShardCount
+	}
+
+	m := &Manager{shards: make([]*shard, shards)}
+	for i := range m.shards {
+		m.shards[i] = &shard{targets: make(map[Key]*target)}
+	}
+	return m
+}
+
+// NewManagerWithBucketPool is NewManager with the metric windows of all
+// targets allocated from the pool, see metrics.BucketPool. With tens of
+// thousands of targets this reduces heap fragmentation and GC work. The
+// windows of removed targets are returned to the pool and reused by the
+// targets added later.
+func NewManagerWithBucketPool(shards int, pool *metrics.BucketPool) *Manager {
+	m := NewManager(shards)
+	m.pool = pool
+	return m
+}
+
+// shardFor returns the shard that owns the key.
+func (m *Manager) shardFor(key Key) *shard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key.Namespace))
+	_, _ = h.Write([]byte{'/'})
+	_, _ = h.Write([]byte(key.Name))
+	return m.shards[h.Sum32()%uint32(len(m.shards))]
+}
+
+// get returns the target for the key.
+func (m *Manager) get(key Key) (*target, error) {
+	if m.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	s := m.shardFor(key)
+	s.mu.RLock()
+	t, exists := s.targets[key]
+	s.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("target %q not found", key)
+	}
+	return t, nil
+}
+
+// Add creates an autoscaler for the target with its own configuration.
+// The algoType parameter selects the metric aggregation algorithm, see
+// manager.NewScaler.
+func (m *Manager) Add(key Key, cfg api.AutoscalerConfig, algoType string) error {
+	if key.Namespace == "" || key.Name == "" {
+		return fmt.Errorf("target key must have a namespace and a name, got %q", key)
+	}
+
+	var scaler *manager.Scaler
+	var err error
+	if m.pool != nil {
+		scaler, err = manager.NewScalerFromPool(key.String(), cfg, algoType, m.pool)
+	} else {
+		scaler, err = manager.NewScaler(key.String(), cfg, algoType)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create scaler for %q: %w", key, err)
+	}
+
+	s := m.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if m.closed.Load() {
+		scaler.Release()
+		return ErrClosed
+	}
+	if _, exists := s.targets[key]; exists {
+		scaler.Release()
+		return fmt.Errorf("target %q already exists", key)
+	}
+	s.targets[key] = &target{scaler: scaler}
+	return nil
+}
+
+// Remove deletes the target's autoscaler.
+func (m *Manager) Remove(key Key) {
+	s := m.shardFor(key)
+	s.mu.Lock()
+	t, exists := s.targets[key]
+	delete(s.targets, key)
+	s.mu.Unlock()
+
+	if exists {
+		t.scaler.Release()
+	}
+}
+
+// Update reconfigures the target's autoscaler.
+func (m *Manager) Update(key Key, cfg api.AutoscalerConfig) error {
+	t, err := m.get(key)
+	if err != nil {
+		return err
+	}
+	return t.scaler.Update(cfg)
+}
+
+// Config returns the target's autoscaler configuration.
+func (m *Manager) Config(key Key) (api.AutoscalerConfig, error) {
+	t, err := m.get(key)
+	if err != nil {
+		return api.AutoscalerConfig{}, err
+	}
+	return t.scaler.Config(), nil
+}
+
+// Len returns the number of targets.
+func (m *Manager) Len() int {
+	n := 0
+	for _, s := range m.shards {
+		s.mu.RLock()
+		n += len(s.targets)
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+// Keys returns the keys of all targets, sorted by namespace and name.
+func (m *Manager) Keys() []Key {
+	var keys []Key
+	for _, s := range m.shards {
+		s.mu.RLock()
+		for k := range s.targets {
+			keys = append(keys, k)
+		}
+		s.mu.RUnlock()
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Namespace != keys[j].Namespace {
+			return keys[i].Namespace < keys[j].Namespace
+		}
+		return keys[i].Name < keys[j].Name
+	})
+	return keys
+}
+
+// SetReadyPods sets the current number of ready pods of the target,
+// which is used by the next scaling pass.
+func (m *Manager) SetReadyPods(key Key, readyPods int32) error {
+	t, err := m.get(key)
+	if err != nil {
+		return err
+	}
+	t.readyPods.Store(readyPods)
+	return nil
+}
+
+// Record records a metric value for the target.
+func (m *Manager) Record(key Key, value float64, t time.Time) error {
+	tgt, err := m.get(key)
+	if err != nil {
+		return err
+	}
+	tgt.scaler.Record(value, t)
+	return nil
+}
+
+// RecordBatch records many samples at once. Samples for unknown targets are
+// skipped and reported in the returned error; all other samples are recorded.
+func (m *Manager) RecordBatch(samples []Sample) error {
+	if m.closed.Load() {
+		return ErrClosed
+	}
+
+	var errs []error
+	for _, sample := range samples {
+		if err := m.Record(sample.Key, sample.Value, sample.Time); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetPriority sets the priority class of the target. Targets with a higher
+// priority get their recommendations satisfied first when the global capacity
+// is exceeded. The default priority is 0.
+func (m *Manager) SetPriority(key Key, priority int32) error {
+	t, err := m.get(key)
+	if err != nil {
+		return err
+	}
+	t.priority.Store(priority)
+	return nil
+}
+
+// SetCapacity sets the maximum total number of pods across all targets.
+// The clamp only limits growth: targets keep their ready pods and minimum
+// scale even beyond the capacity. A value of 0 means no limit.
+func (m *Manager) SetCapacity(maxPods int32) {
+	m.capacity.Store(max(0, maxPods))
+}
+
+// Capacity returns the maximum total number of pods across all targets.
+func (m *Manager) Capacity() int32 {
+	return m.capacity.Load()
+}
+
+// SetProfilerLabels enables or disables pprof labels in scaling passes.
+// When enabled, the autoscaler of every target is evaluated with the
+// "namespace" and "scaler" labels of its key, so CPU profiles of large
+// deployments attribute the cost per workload, e.g. with
+// "go tool pprof -tagfocus=scaler=web". It is disabled by default, because
+// labeling allocates for every target.
+func (m *Manager) SetProfilerLabels(enabled bool) {
+	m.profilerLabels.Store(enabled)
+}
+
+// withProfilerLabels runs scale with the pprof labels of the key if they
+// are enabled.
+func (m *Manager) withProfilerLabels(key Key, scale func() api.ScaleRecommendation) api.ScaleRecommendation {
+	if !m.profilerLabels.Load() {
+		return scale()
+	}
+	var rec api.ScaleRecommendation
+	pprof.Do(context.Background(), pprof.Labels("namespace", key.Namespace, "scaler", key.Name), func(context.Context) {
+		rec = scale()
+	})
+	return rec
+}
+
+// Scale evaluates the autoscalers of all targets in a single pass and returns
+// their recommendations keyed by target. The recommendations are clamped to
+// the global capacity, see SetCapacity.
+func (m *Manager) Scale(now time.Time) map[Key]api.ScaleRecommendation {
+	n := m.Len()
+	recommendations := make(map[Key]api.ScaleRecommendation, n)
+	targets := make(map[Key]capacityTarget, n)
+	for _, s := range m.shards {
+		s.mu.RLock()
+		for k, t := range s.targets {
+			ct := newCapacityTarget(t, t.priority.Load())
+			recommendations[k] = m.withProfilerLabels(k, func() api.ScaleRecommendation {
+				return t.scaler.Scale(ct.readyPods, now)
+			})
+			targets[k] = ct
+		}
+		s.mu.RUnlock()
+	}
+	clampToCapacity(recommendations, targets, m.capacity.Load())
+	return recommendations
+}
+
+// Close stops the manager and releases the autoscalers of all targets.
+// After Close, Add, Record and the other per-target methods return ErrClosed,
+// and scaling passes return no recommendations. Close is idempotent.
+func (m *Manager) Close() error {
+	m.closed.Store(true)
+	for _, s := range m.shards {
+		s.mu.Lock()
+		targets := s.targets
+		s.targets = make(map[Key]*target)
+		s.mu.Unlock()
+
+		for _, t := range targets {
+			t.scaler.Release()
+		}
+	}
+	return nil
+}
+
+// ErrNoRecommendation is reported by ScaleAll for targets whose autoscaler
+// could not produce a valid recommendation, e.g. because no metrics were
+// recorded within the stable window.
+var ErrNoRecommendation = errors.New("no valid scale recommendation")
+
+// entry is a target captured for a scaling pass.
+type entry struct {
+	key      Key
+	target   *target
+	priority int32
+}
+
+// ScaleAll evaluates the autoscalers of all targets using at most parallelism
+// concurrent workers. If parallelism is not positive, one worker is used.
+// Valid recommendations are returned keyed by target and clamped to the global
+// capacity, see SetCapacity. Targets that didn't produce a valid
+// recommendation are reported in the errors map instead.
+func (m *Manager) ScaleAll(now time.Time, parallelism int) (map[Key]api.ScaleRecommendation, map[Key]error) {
+	var entries []entry
+	for _, s := range m.shards {
+		s.mu.RLock()
+		for k, t := range s.targets {
+			entries = append(entries, entry{key: k, target: t, priority: t.priority.Load()})
+		}
+		s.mu.RUnlock()
+	}
+
+	recs := make([]api.ScaleRecommendation, len(entries))
+	errs := make([]error, len(entries))
+
+	parallelism = max(1, min(parallelism, len(entries)))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range parallelism {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				e := entries[i]
+				recs[i] = m.withProfilerLabels(e.key, func() api.ScaleRecommendation {
+					var rec api.ScaleRecommendation
+					rec, errs[i] = scaleTarget(e.target, now)
+					return rec
+				})
+			}
+		}()
+	}
+	for i := range entries {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	recommendations := make(map[Key]api.ScaleRecommendation, len(entries))
+	targets := make(map[Key]capacityTarget, len(entries))
+	targetErrs := make(map[Key]error)
+	for i, e := range entries {
+		targets[e.key] = newCapacityTarget(e.target, e.priority)
+		if errs[i] != nil {
+			targetErrs[e.key] = errs[i]
+			continue
+		}
+		recommendations[e.key] = recs[i]
+	}
+	clampToCapacity(recommendations, targets, m.capacity.Load())
+	return recommendations, targetErrs
+}
+
+// scaleTarget evaluates the autoscaler of a single target. A panic in the
+// autoscaler is reported as an error, so one broken target doesn't abort the
+// whole pass.
+func scaleTarget(t *target, now time.Time) (rec api.ScaleRecommendation, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("autoscaler panicked: %v", r)
+		}
+	}()
+
+	rec = t.scaler.Scale(t.readyPods.Load(), now)
+	if !rec.ScaleValid {
+		return rec, ErrNoRecommendation
+	}
+	return rec, nil
+}
+
+// capacityTarget is what the capacity clamp needs to know about a target.
+type capacityTarget struct {
+	priority  int32
+	minScale  int32
+	readyPods int32
+}
+
+// newCapacityTarget returns the capacity clamp view of t.
+func newCapacityTarget(t *target, priority int32) capacityTarget {
+	return capacityTarget{
+		priority:  priority,
+		minScale:  t.scaler.Config().MinScale,
+		readyPods: t.readyPods.Load(),
+	}
+}
+
+// floor returns the number of pods the capacity clamp never takes from a
+// target recommending desired pods: its minimum scale and its ready pods.
+func (t capacityTarget) floor(desired int32) int32 {
+	return min(max(t.minScale, t.readyPods), desired)
+}
+
+// clampToCapacity reduces the valid recommendations so that their total
+// doesn't exceed capacity. Targets without a valid recommendation keep
+// their ready pods, which count towards the capacity. Every target keeps its
+// floor, its minimum scale and its ready pods, even beyond the capacity, so
+// the clamp limits growth but never scales running pods down. The pods
+// above the floors are granted to the priority classes in descending order.
+// The first class that doesn't fit into the remaining capacity gets it
+// proportionally to the pods its targets want above their floors, and lower
+// classes keep their floors. Reduced recommendations are limited by
+// api.LimitCapacity. A capacity of 0 means no limit.
+func clampToCapacity(recs map[Key]api.ScaleRecommendation, targets map[Key]capacityTarget, capacity int32) {
+	if capacity <= 0 {
+		return
+	}
+
+	classes := make(map[int32][]Key)
+	var total, floor int64
+	for k, t := range targets {
+		rec, ok := recs[k]
+		if !ok || !rec.ScaleValid {
+			total += int64(max(0, t.readyPods))
+			floor += int64(max(0, t.readyPods))
+			continue
+		}
+		classes[t.priority] = append(classes[t.priority], k)
+		total += int64(rec.DesiredPodCount)
+		floor += int64(t.floor(rec.DesiredPodCount))
+	}
+	if total <= int64(capacity) {
+		return
+	}
+
+	order := make([]int32, 0, len(classes))
+	for p := range classes {
+		order = append(order, p)
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i] > order[j] })
+
+	remaining := max(0, int64(capacity)-floor)
+	for _, p := range order {
+		keys := classes[p]
+		extra := make(map[Key]int64, len(keys))
+		var desired int64
+		for _, k := range keys {
+			extra[k] = int64(recs[k].DesiredPodCount - targets[k].floor(recs[k].DesiredPodCount))
+			desired += extra[k]
+		}
+		if desired <= remaining {
+			remaining -= desired
+			continue
+		}
+		for k, pods := range shareProportionally(keys, extra, desired, remaining) {
+			rec := recs[k]
+			pods += targets[k].floor(rec.DesiredPodCount)
+			if pods == rec.DesiredPodCount {
+				continue
+			}
+			rec.DesiredPodCount = pods
+			rec.Direction = api.DirectionOf(rec.PreviousDesiredPodCount, pods)
+			rec.LimitedBy = api.LimitCapacity
+			recs[k] = rec
+		}
+		remaining = 0
+	}
+}
+
+// shareProportionally splits available pods between the targets proportionally
+// to the pods they want. Pods left over by rounding down go to the targets
+// with the largest remainders, ties broken by key.
+func shareProportionally(keys []Key, wants map[Key]int64, desired, available int64) map[Key]int32 {
+	type share struct {
+		key       Key
+		remainder int64
+	}
+
+	shares := make(map[Key]int32, len(keys))
+	order := make([]share, 0, len(keys))
+	assigned := int64(0)
+	for _, k := range keys {
+		want := wants[k] * available
+		shares[k] = int32(want / desired)
+		assigned += want / desired
+		order = append(order, share{key: k, remainder: want % desired})
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].remainder != order[j].remainder {
+			return order[i].remainder > order[j].remainder
+		}
+		return order[i].key.String() < order[j].key.String()
+	})
+	for i := int64(0); i < available-assigned; i++ {
+		shares[order[i].key]++
+	}
+	return shares
+}
@@ -0,0 +1,628 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multitenant
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+	libkpaconfig "github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/metrics"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Key
+		wantErr bool
+	}{
+		{input: "default/web", want: Key{Namespace: "default", Name: "web"}},
+		{input: "web", wantErr: true},
+		{input: "/web", wantErr: true},
+		{input: "default/", wantErr: true},
+		{input: "a/b/c", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseKey(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseKey() = %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && got.String() != tt.input {
+				t.Errorf("String() = %q, want %q", got.String(), tt.input)
+			}
+		})
+	}
+}
+
+func TestManagerAddRemove(t *testing.T) {
+	m := NewManager(4)
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	key := Key{Namespace: "default", Name: "web"}
+
+	if err := m.Add(key, config, "linear"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := m.Add(key, config, "linear"); err == nil {
+		t.Error("expected error when adding a duplicate target")
+	}
+	if err := m.Add(Key{Name: "web"}, config, "linear"); err == nil {
+		t.Error("expected error for a key without namespace")
+	}
+	if err := m.Add(Key{Namespace: "default", Name: "bad"}, config, "invalid"); err == nil {
+		t.Error("expected error for an invalid algorithm type")
+	}
+	if got := m.Len(); got != 1 {
+		t.Errorf("Len() = %d, want 1", got)
+	}
+
+	m.Remove(key)
+	if got := m.Len(); got != 0 {
+		t.Errorf("Len() after Remove = %d, want 0", got)
+	}
+	if err := m.Record(key, 1, time.Now()); err == nil {
+		t.Error("expected error when recording for a removed target")
+	}
+}
+
+func TestManagerPerTargetConfig(t *testing.T) {
+	m := NewManager(0)
+	now := time.Now()
+
+	small := *libkpaconfig.NewDefaultAutoscalerConfig()
+	small.TargetValue = 10
+	large := *libkpaconfig.NewDefaultAutoscalerConfig()
+	large.TargetValue = 100
+
+	a := Key{Namespace: "team-a", Name: "api"}
+	b := Key{Namespace: "team-b", Name: "api"}
+	if err := m.Add(a, small, "linear"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := m.Add(b, large, "weighted"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	for _, k := range []Key{a, b} {
+		if err := m.SetReadyPods(k, 1); err != nil {
+			t.Fatalf("SetReadyPods failed: %v", err)
+		}
+	}
+
+	err := m.RecordBatch([]Sample{
+		{Key: a, Value: 200, Time: now},
+		{Key: b, Value: 200, Time: now},
+		{Key: Key{Namespace: "team-c", Name: "api"}, Value: 200, Time: now},
+	})
+	if err == nil || !strings.Contains(err.Error(), "team-c/api") {
+		t.Errorf("RecordBatch() error = %v, want error for team-c/api", err)
+	}
+
+	got := m.Scale(now)
+	if len(got) != 2 {
+		t.Fatalf("Scale() returned %d recommendations, want 2", len(got))
+	}
+	if got[a].DesiredPodCount != 20 {
+		t.Errorf("DesiredPodCount of %v = %d, want 20", a, got[a].DesiredPodCount)
+	}
+	if got[b].DesiredPodCount != 2 {
+		t.Errorf("DesiredPodCount of %v = %d, want 2", b, got[b].DesiredPodCount)
+	}
+
+	large.TargetValue = 50
+	if err := m.Update(b, large); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	cfg, err := m.Config(b)
+	if err != nil {
+		t.Fatalf("Config failed: %v", err)
+	}
+	if cfg.TargetValue != 50 {
+		t.Errorf("TargetValue = %v, want 50", cfg.TargetValue)
+	}
+	if _, err := m.Config(Key{Namespace: "x", Name: "y"}); err == nil {
+		t.Error("expected error for an unknown target")
+	}
+}
+
+func TestManagerKeys(t *testing.T) {
+	m := NewManager(8)
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+
+	for _, k := range []Key{{"b", "x"}, {"a", "z"}, {"a", "y"}} {
+		if err := m.Add(k, config, "linear"); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	got := m.Keys()
+	want := []Key{{"a", "y"}, {"a", "z"}, {"b", "x"}}
+	if len(got) != len(want) {
+		t.Fatalf("Keys() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Keys()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestManagerConcurrentAccess(t *testing.T) {
+	m := NewManager(0)
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := Key{Namespace: "ns", Name: fmt.Sprintf("svc-%d", i)}
+			if err := m.Add(key, config, "linear"); err != nil {
+				t.Errorf("Add failed: %v", err)
+				return
+			}
+			for j := range 10 {
+				_ = m.Record(key, float64(j), now.Add(time.Duration(j)*time.Second))
+			}
+			_ = m.SetReadyPods(key, 1)
+			m.Scale(now)
+		}()
+	}
+	wg.Wait()
+
+	if got := m.Len(); got != 50 {
+		t.Errorf("Len() = %d, want 50", got)
+	}
+}
+
+func TestManagerScaleAll(t *testing.T) {
+	m := NewManager(0)
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 10
+	now := time.Now()
+
+	for i := range 100 {
+		key := Key{Namespace: "ns", Name: fmt.Sprintf("svc-%d", i)}
+		if err := m.Add(key, config, "linear"); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		if err := m.SetReadyPods(key, 1); err != nil {
+			t.Fatalf("SetReadyPods failed: %v", err)
+		}
+		// Every tenth target has no metrics.
+		if i%10 != 0 {
+			if err := m.Record(key, float64(i*10), now); err != nil {
+				t.Fatalf("Record failed: %v", err)
+			}
+		}
+	}
+
+	for _, tt := range []struct {
+		parallelism    int
+		profilerLabels bool
+	}{
+		{parallelism: 0},
+		{parallelism: 1},
+		{parallelism: 8},
+		{parallelism: 1000},
+		{parallelism: 8, profilerLabels: true},
+	} {
+		t.Run(fmt.Sprintf("parallelism=%d,labels=%v", tt.parallelism, tt.profilerLabels), func(t *testing.T) {
+			m.SetProfilerLabels(tt.profilerLabels)
+			if got := m.Scale(now)[Key{Namespace: "ns", Name: "svc-42"}].DesiredPodCount; got != 42 {
+				t.Errorf("Scale: DesiredPodCount of svc-42 = %d, want 42", got)
+			}
+
+			recs, errs := m.ScaleAll(now, tt.parallelism)
+			if len(recs) != 90 {
+				t.Errorf("got %d recommendations, want 90", len(recs))
+			}
+			if len(errs) != 10 {
+				t.Errorf("got %d errors, want 10", len(errs))
+			}
+			for k, err := range errs {
+				if !errors.Is(err, ErrNoRecommendation) {
+					t.Errorf("error for %v = %v, want ErrNoRecommendation", k, err)
+				}
+			}
+			key := Key{Namespace: "ns", Name: "svc-42"}
+			if got := recs[key].DesiredPodCount; got != 42 {
+				t.Errorf("DesiredPodCount of %v = %d, want 42", key, got)
+			}
+		})
+	}
+}
+
+func TestManagerScaleAllEmpty(t *testing.T) {
+	recs, errs := NewManager(0).ScaleAll(time.Now(), 4)
+	if len(recs) != 0 || len(errs) != 0 {
+		t.Errorf("ScaleAll() = %v, %v, want empty results", recs, errs)
+	}
+}
+
+func BenchmarkManagerScaleAll(b *testing.B) {
+	m := NewManager(0)
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	now := time.Now()
+	for i := range 10000 {
+		key := Key{Namespace: "ns", Name: fmt.Sprintf("svc-%d", i)}
+		if err := m.Add(key, config, "linear"); err != nil {
+			b.Fatalf("Add failed: %v", err)
+		}
+		_ = m.Record(key, 100, now)
+	}
+
+	for _, parallelism := range []int{1, 8} {
+		b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+			for b.Loop() {
+				m.ScaleAll(now, parallelism)
+			}
+		})
+	}
+}
+
+func TestManagerBucketPool(t *testing.T) {
+	pool := metrics.NewBucketPool(0)
+	m := NewManagerWithBucketPool(4, pool)
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 10
+	now := time.Now()
+
+	// Every target holds a 60 bucket stable and a 6 bucket burst window.
+	for i := range 100 {
+		key := Key{Namespace: "ns", Name: fmt.Sprintf("svc-%d", i)}
+		algoType := "linear"
+		if i%2 == 1 {
+			algoType = "weighted"
+		}
+		if err := m.Add(key, config, algoType); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		_ = m.SetReadyPods(key, 1)
+		_ = m.Record(key, float64(i*10), now)
+	}
+	if got := pool.Stats(); got.InUse != 6600 || got.Slabs != 1 {
+		t.Errorf("Stats() = %+v, want 6600 buckets in use in a single slab", got)
+	}
+	if err := m.Add(Key{Namespace: "ns", Name: "svc-0"}, config, "linear"); err == nil {
+		t.Error("expected error when adding a duplicate target")
+	}
+	if got := pool.Stats(); got.InUse != 6600 || got.Free != 66 {
+		t.Errorf("Stats() after a failed Add = %+v, want the windows of the duplicate free", got)
+	}
+
+	if got := m.Scale(now)[Key{Namespace: "ns", Name: "svc-42"}].DesiredPodCount; got != 42 {
+		t.Errorf("DesiredPodCount of svc-42 = %d, want 42", got)
+	}
+
+	// Removed targets return their windows, which are reused.
+	m.Remove(Key{Namespace: "ns", Name: "svc-0"})
+	if got := pool.Stats(); got.InUse != 6534 || got.Free != 132 {
+		t.Errorf("Stats() after Remove = %+v, want 132 free buckets", got)
+	}
+	if err := m.Add(Key{Namespace: "ns", Name: "new"}, config, "linear"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if got := pool.Stats(); got.InUse != 6600 || got.Free != 66 {
+		t.Errorf("Stats() after reuse = %+v, want 66 free buckets", got)
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if got := pool.Stats(); got.InUse != 0 || got.Free != 6666 {
+		t.Errorf("Stats() after Close = %+v, want all buckets free", got)
+	}
+}
+
+func TestManagerSizeBytes(t *testing.T) {
+	m := NewManager(4)
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	empty := m.SizeBytes()
+
+	small := Key{Namespace: "ns", Name: "small"}
+	large := Key{Namespace: "ns", Name: "large"}
+	_ = m.Add(small, config, "linear")
+	config.StableWindow = 600 * time.Second
+	_ = m.Add(large, config, "linear")
+
+	smallSize, err := m.TargetSizeBytes(small)
+	if err != nil {
+		t.Fatalf("TargetSizeBytes failed: %v", err)
+	}
+	largeSize, _ := m.TargetSizeBytes(large)
+	if smallSize <= 0 || largeSize <= smallSize {
+		t.Errorf("TargetSizeBytes() = %d and %d, want the 600s target to be larger", smallSize, largeSize)
+	}
+	if got, want := m.SizeBytes(), empty+smallSize+largeSize; got != want {
+		t.Errorf("SizeBytes() = %d, want %d", got, want)
+	}
+	if _, err := m.TargetSizeBytes(Key{Namespace: "ns", Name: "missing"}); err == nil {
+		t.Error("expected error for a missing target")
+	}
+}
+
+func BenchmarkManagerAdd(b *testing.B) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.StableWindow = 600 * time.Second
+	for _, tt := range []struct {
+		name string
+		new  func() *Manager
+	}{
+		{"heap", func() *Manager { return NewManager(0) }},
+		{"pool", func() *Manager { return NewManagerWithBucketPool(0, metrics.NewBucketPool(0)) }},
+	} {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				m := tt.new()
+				for i := range 1000 {
+					_ = m.Add(Key{Namespace: "ns", Name: fmt.Sprintf("svc-%d", i)}, config, "linear")
+				}
+			}
+		})
+	}
+}
+
+func TestManagerCapacityPriorities(t *testing.T) {
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = 1
+	now := time.Now()
+
+	// Workloads scale up from their ready pods, or have no data and keep
+	// their ready pods.
+	type workload struct {
+		name     string
+		priority int32
+		ready    int32
+		desired  int32
+		noData   bool
+	}
+
+	tests := []struct {
+		name      string
+		capacity  int32
+		workloads []workload
+		want      map[string]int32
+	}{{
+		name:     "no limit",
+		capacity: 0,
+		workloads: []workload{
+			{name: "a", priority: 1, ready: 1, desired: 10},
+			{name: "b", priority: 0, ready: 1, desired: 10},
+		},
+		want: map[string]int32{"a": 10, "b": 10},
+	}, {
+		name:     "within capacity",
+		capacity: 20,
+		workloads: []workload{
+			{name: "a", priority: 1, ready: 1, desired: 10},
+			{name: "b", priority: 0, ready: 1, desired: 10},
+		},
+		want: map[string]int32{"a": 10, "b": 10},
+	}, {
+		name:     "low priority reduced",
+		capacity: 15,
+		workloads: []workload{
+			{name: "a", priority: 1, ready: 1, desired: 10},
+			{name: "b", priority: 0, ready: 1, desired: 10},
+		},
+		want: map[string]int32{"a": 10, "b": 5},
+	}, {
+		name:     "same priority reduced proportionally",
+		capacity: 16,
+		workloads: []workload{
+			{name: "a", priority: 1, ready: 1, desired: 10},
+			{name: "b", priority: 0, ready: 1, desired: 12},
+			{name: "c", priority: 0, ready: 1, desired: 4},
+		},
+		want: map[string]int32{"a": 10, "b": 4, "c": 2},
+	}, {
+		name:     "rounding remainder distributed",
+		capacity: 10,
+		workloads: []workload{
+			{name: "a", priority: 0, ready: 1, desired: 10},
+			{name: "b", priority: 0, ready: 1, desired: 10},
+			{name: "c", priority: 0, ready: 1, desired: 10},
+		},
+		want: map[string]int32{"a": 4, "b": 3, "c": 3},
+	}, {
+		name:     "lower classes keep their ready pods",
+		capacity: 8,
+		workloads: []workload{
+			{name: "a", priority: 2, ready: 1, desired: 10},
+			{name: "b", priority: 1, ready: 2, desired: 10},
+			{name: "c", priority: 0, ready: 3, desired: 10},
+		},
+		want: map[string]int32{"a": 3, "b": 2, "c": 3},
+	}, {
+		name:     "floors of 0 get nothing",
+		capacity: 8,
+		workloads: []workload{
+			{name: "a", priority: 2, ready: 1, desired: 10},
+			{name: "b", priority: 1, ready: 0, desired: 10},
+			{name: "c", priority: 0, ready: 2, desired: 10},
+		},
+		want: map[string]int32{"a": 6, "b": 0, "c": 2},
+	}, {
+		name:     "remaining capacity shared across classes",
+		capacity: 20,
+		workloads: []workload{
+			{name: "a", priority: 3, ready: 2, desired: 6},
+			{name: "b", priority: 2, ready: 1, desired: 5},
+			{name: "c", priority: 1, ready: 1, desired: 10},
+			{name: "d", priority: 0, ready: 2, desired: 10},
+		},
+		want: map[string]int32{"a": 6, "b": 5, "c": 7, "d": 2},
+	}, {
+		name:     "ready pods beyond the capacity are kept",
+		capacity: 10,
+		workloads: []workload{
+			{name: "a", priority: 2, ready: 4, desired: 10},
+			{name: "b", priority: 1, ready: 4, desired: 10},
+			{name: "c", priority: 0, ready: 4, desired: 10},
+		},
+		want: map[string]int32{"a": 4, "b": 4, "c": 4},
+	}, {
+		name:     "targets without data count their ready pods",
+		capacity: 12,
+		workloads: []workload{
+			{name: "a", priority: 1, ready: 1, desired: 10},
+			{name: "b", priority: 0, ready: 1, desired: 10},
+			{name: "idle", priority: 2, ready: 5, noData: true},
+		},
+		want: map[string]int32{"a": 6, "b": 1},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(0)
+			m.SetCapacity(tt.capacity)
+			for _, w := range tt.workloads {
+				key := Key{Namespace: "ns", Name: w.name}
+				if err := m.Add(key, config, "linear"); err != nil {
+					t.Fatalf("Add failed: %v", err)
+				}
+				if err := m.SetPriority(key, w.priority); err != nil {
+					t.Fatalf("SetPriority failed: %v", err)
+				}
+				if err := m.SetReadyPods(key, w.ready); err != nil {
+					t.Fatalf("SetReadyPods failed: %v", err)
+				}
+				if w.noData {
+					continue
+				}
+				if err := m.Record(key, float64(w.desired), now); err != nil {
+					t.Fatalf("Record failed: %v", err)
+				}
+			}
+
+			recs, _ := m.ScaleAll(now, 2)
+			scaled := m.Scale(now)
+			for name, want := range tt.want {
+				key := Key{Namespace: "ns", Name: name}
+				if got := recs[key].DesiredPodCount; got != want {
+					t.Errorf("ScaleAll: DesiredPodCount of %s = %d, want %d", name, got, want)
+				}
+				if got := scaled[key].DesiredPodCount; got != want {
+					t.Errorf("Scale: DesiredPodCount of %s = %d, want %d", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestManagerCapacityMinScale(t *testing.T) {
+	now := time.Now()
+	m := NewManager(0)
+	m.SetCapacity(12)
+
+	workloads := []struct {
+		name     string
+		priority int32
+		minScale int32
+		desired  int32
+	}{
+		{name: "a", priority: 2, desired: 10},
+		{name: "b", priority: 1, minScale: 2, desired: 10},
+		{name: "c", priority: 0, minScale: 3, desired: 10},
+		{name: "d", priority: 0, minScale: 1, desired: 10},
+	}
+	for _, w := range workloads {
+		config := *libkpaconfig.NewDefaultAutoscalerConfig()
+		config.TargetValue = 1
+		config.MinScale = w.minScale
+		key := Key{Namespace: "ns", Name: w.name}
+		if err := m.Add(key, config, "linear"); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		if err := m.SetPriority(key, w.priority); err != nil {
+			t.Fatalf("SetPriority failed: %v", err)
+		}
+		if err := m.SetReadyPods(key, 1); err != nil {
+			t.Fatalf("SetReadyPods failed: %v", err)
+		}
+		if err := m.Record(key, float64(w.desired), now); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	// The floors, the minimum scales and the ready pod of a, take 7 pods,
+	// and the highest class gets the remaining 5 of the 9 pods it wants
+	// above its floor.
+	want := map[string]int32{"a": 6, "b": 2, "c": 3, "d": 1}
+	recs := m.Scale(now)
+	for name, pods := range want {
+		rec := recs[Key{Namespace: "ns", Name: name}]
+		if rec.DesiredPodCount != pods || rec.LimitedBy != api.LimitCapacity {
+			t.Errorf("recommendation of %s = %d limited by %q, want %d limited by %q",
+				name, rec.DesiredPodCount, rec.LimitedBy, pods, api.LimitCapacity)
+		}
+	}
+
+	// Floors beyond the capacity are kept.
+	m.SetCapacity(4)
+	recs = m.Scale(now)
+	for name, pods := range map[string]int32{"a": 1, "b": 2, "c": 3, "d": 1} {
+		if got := recs[Key{Namespace: "ns", Name: name}].DesiredPodCount; got != pods {
+			t.Errorf("recommendation of %s beyond the capacity = %d, want %d", name, got, pods)
+		}
+	}
+}
+
+func TestManagerClose(t *testing.T) {
+	m := NewManager(0)
+	config := *libkpaconfig.NewDefaultAutoscalerConfig()
+	now := time.Now()
+	key := Key{Namespace: "ns", Name: "web"}
+
+	if err := m.Add(key, config, "linear"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Errorf("second Close failed: %v", err)
+	}
+
+	if got := m.Len(); got != 0 {
+		t.Errorf("Len() after Close = %d, want 0", got)
+	}
+	if err := m.Add(key, config, "linear"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Add after Close error = %v, want ErrClosed", err)
+	}
+	if err := m.Record(key, 1, now); !errors.Is(err, ErrClosed) {
+		t.Errorf("Record after Close error = %v, want ErrClosed", err)
+	}
+	if err := m.RecordBatch([]Sample{{Key: key, Value: 1, Time: now}}); !errors.Is(err, ErrClosed) {
+		t.Errorf("RecordBatch after Close error = %v, want ErrClosed", err)
+	}
+	if recs, errs := m.ScaleAll(now, 1); len(recs) != 0 || len(errs) != 0 {
+		t.Errorf("ScaleAll after Close = %v, %v, want empty results", recs, errs)
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multitenant
+
+import "unsafe"
+
+// SizeBytes returns the approximate memory used by the manager and the
+// autoscalers of all targets in bytes. Memory of a BucketPool not used by
+// any window is not included.
+func (m *Manager) SizeBytes() int64 {
+	size := int64(unsafe.Sizeof(*m))
+	for _, s := range m.shards {
+		s.mu.RLock()
+		size += int64(unsafe.Sizeof(*s))
+		for key, t := range s.targets {
+			size += targetSize(key, t)
+		}
+		s.mu.RUnlock()
+	}
+	return size
+}
+
+// TargetSizeBytes returns the approximate memory used by the autoscaler of
+// the target in bytes, e.g. to budget and alert on the cost of a tenant.
+func (m *Manager) TargetSizeBytes(key Key) (int64, error) {
+	t, err := m.get(key)
+	if err != nil {
+		return 0, err
+	}
+	return targetSize(key, t), nil
+}
+
+// targetSize returns the size of a target and its map entry.
+func targetSize(key Key, t *target) int64 {
+	return int64(unsafe.Sizeof(key)+unsafe.Sizeof(t)+unsafe.Sizeof(*t)) +
+		int64(len(key.Namespace)+len(key.Name)) + t.scaler.SizeBytes()
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package perf measures the cost of the autoscaler hot paths for a given
+// configuration and checks the results against performance budgets.
+// Results can be written in the Go benchmark format, so they can be compared
+// across library versions or configurations with benchstat.
+package perf
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Fedosin/libkpa/algorithm"
+	"github.com/Fedosin/libkpa/api"
+	"github.com/Fedosin/libkpa/manager"
+	"github.com/Fedosin/libkpa/metrics"
+)
+
+const (
+	defaultIterations = 10000
+
+	// Names of the measured operations.
+	AutoscalerScale = "SlidingWindowAutoscaler.Scale"
+	ScalerRecord    = "Scaler.Record"
+	ManagerScale1   = "Manager.Scale/scalers=1"
+	ManagerScale10  = "Manager.Scale/scalers=10"
+	ManagerScale100 = "Manager.Scale/scalers=100"
+)
+
+// Result is the measured cost of a single operation.
+type Result struct {
+	// Name identifies the measured operation.
+	Name string
+
+	// Iterations is the number of times the operation was executed.
+	Iterations int
+
+	// NsPerOp is the average duration of the operation in nanoseconds.
+	NsPerOp float64
+
+	// AllocsPerOp is the average number of heap allocations per operation.
+	AllocsPerOp uint64
+
+	// BytesPerOp is the average number of heap allocated bytes per operation.
+	BytesPerOp uint64
+}
+
+// Budget is the maximum acceptable cost of an operation.
+type Budget struct {
+	// Name identifies the operation the budget applies to.
+	Name string
+
+	// MaxNsPerOp is the maximum average duration in nanoseconds.
+	// 0 means no limit.
+	MaxNsPerOp float64
+
+	// MaxAllocsPerOp is the maximum average number of heap allocations.
+	// A negative value means no limit.
+	MaxAllocsPerOp int64
+}
+
+// DefaultBudgets are the performance budgets of the library with the default
+// configuration. They are intentionally generous, so they hold on slow CI
+// machines, and are meant to catch order of magnitude regressions.
+var DefaultBudgets = []Budget{
+	{Name: AutoscalerScale, MaxNsPerOp: 20_000, MaxAllocsPerOp: 2},
+	{Name: ScalerRecord, MaxNsPerOp: 20_000, MaxAllocsPerOp: 0},
+	{Name: ManagerScale1, MaxNsPerOp: 50_000, MaxAllocsPerOp: 5},
+	{Name: ManagerScale10, MaxNsPerOp: 500_000, MaxAllocsPerOp: 50},
+	{Name: ManagerScale100, MaxNsPerOp: 5_000_000, MaxAllocsPerOp: 500},
+}
+
+// Options configures a measurement run.
+type Options struct {
+	// Iterations is the number of times each operation is executed.
+	// Default is 10000.
+	Iterations int
+}
+
+// Run measures the hot paths of the library with the given configuration.
+func Run(cfg api.AutoscalerConfig, opts Options) ([]Result, error) {
+	iterations := opts.Iterations
+	if iterations <= 0 {
+		iterations = defaultIterations
+	}
+
+	start := time.Now()
+	results := make([]Result, 0, 5)
+
+	autoscaler, err := algorithm.NewSlidingWindowAutoscaler(cfg)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := metrics.NewMetricSnapshot(cfg.TargetValue*10, cfg.TargetValue*10, 10, start)
+	results = append(results, measure(AutoscalerScale, iterations, func(i int) {
+		autoscaler.Scale(snapshot, start.Add(time.Duration(i)*time.Second))
+	}))
+
+	scaler, err := manager.NewScaler("perf", cfg, "linear")
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, measure(ScalerRecord, iterations, func(i int) {
+		scaler.Record(float64(i%100), start.Add(time.Duration(i)*time.Second))
+	}))
+
+	for _, n := range []struct {
+		name    string
+		scalers int
+	}{{ManagerScale1, 1}, {ManagerScale10, 10}, {ManagerScale100, 100}} {
+		mgr, err := newManager(cfg, n.scalers, start)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, measure(n.name, iterations, func(i int) {
+			_, _ = mgr.Scale(10, start.Add(cfg.StableWindow+time.Duration(i)*time.Millisecond))
+		}))
+	}
+
+	return results, nil
+}
+
+// newManager creates a manager with the given number of scalers, each with a
+// fully populated stable window.
+func newManager(cfg api.AutoscalerConfig, scalers int, start time.Time) (*manager.Manager, error) {
+	mgr := manager.NewManager(0, 0)
+	for i := range scalers {
+		s, err := manager.NewScaler(fmt.Sprintf("scaler-%d", i), cfg, "linear")
+		if err != nil {
+			return nil, err
+		}
+		for t := time.Duration(0); t < cfg.StableWindow; t += time.Second {
+			s.Record(cfg.TargetValue*10, start.Add(t))
+		}
+		mgr.Register(s)
+	}
+	return mgr, nil
+}
+
+// measure executes op the given number of times and reports its average cost.
+func measure(name string, iterations int, op func(i int)) Result {
+	var before, after runtime.MemStats
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := range iterations {
+		op(i)
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	n := uint64(iterations)
+	return Result{
+		Name:        name,
+		Iterations:  iterations,
+		NsPerOp:     float64(elapsed.Nanoseconds()) / float64(iterations),
+		AllocsPerOp: (after.Mallocs - before.Mallocs) / n,
+		BytesPerOp:  (after.TotalAlloc - before.TotalAlloc) / n,
+	}
+}
+
+// Check compares the results against the budgets and returns an error
+// listing every exceeded budget. Results without a budget are ignored.
+func Check(results []Result, budgets []Budget) error {
+	byName := make(map[string]Budget, len(budgets))
+	for _, b := range budgets {
+		byName[b.Name] = b
+	}
+
+	var violations []string
+	for _, r := range results {
+		b, ok := byName[r.Name]
+		if !ok {
+			continue
+		}
+		if b.MaxNsPerOp > 0 && r.NsPerOp > b.MaxNsPerOp {
+			violations = append(violations, fmt.Sprintf("%s: %.0f ns/op exceeds budget of %.0f ns/op", r.Name, r.NsPerOp, b.MaxNsPerOp))
+		}
+		if b.MaxAllocsPerOp >= 0 && r.AllocsPerOp > uint64(b.MaxAllocsPerOp) {
+			violations = append(violations, fmt.Sprintf("%s: %d allocs/op exceeds budget of %d allocs/op", r.Name, r.AllocsPerOp, b.MaxAllocsPerOp))
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("performance budgets exceeded:\n  - %s", strings.Join(violations, "\n  - "))
+}
+
+// WriteBenchmarkFormat writes the results in the Go benchmark output format.
+// Files written from several runs can be compared with benchstat, e.g.
+// `benchstat before.txt after.txt`.
+func WriteBenchmarkFormat(w io.Writer, results []Result) error {
+	for _, r := range results {
+		name := "Benchmark" + strings.NewReplacer(".", "_", " ", "_").Replace(r.Name)
+		if _, err := fmt.Fprintf(w, "%s\t%d\t%.1f ns/op\t%d B/op\t%d allocs/op\n",
+			name, r.Iterations, r.NsPerOp, r.BytesPerOp, r.AllocsPerOp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
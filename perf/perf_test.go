@@ -0,0 +1,121 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package perf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	libkpaconfig "github.com/Fedosin/libkpa/config"
+)
+
+func TestRun(t *testing.T) {
+	results, err := Run(*libkpaconfig.NewDefaultAutoscalerConfig(), Options{Iterations: 10})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := []string{AutoscalerScale, ScalerRecord, ManagerScale1, ManagerScale10, ManagerScale100}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, r := range results {
+		if r.Name != want[i] {
+			t.Errorf("results[%d].Name = %q, want %q", i, r.Name, want[i])
+		}
+		if r.Iterations != 10 {
+			t.Errorf("results[%d].Iterations = %d, want 10", i, r.Iterations)
+		}
+		if r.NsPerOp <= 0 {
+			t.Errorf("results[%d].NsPerOp = %v, want > 0", i, r.NsPerOp)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	budgets := []Budget{
+		{Name: "a", MaxNsPerOp: 100, MaxAllocsPerOp: 1},
+		{Name: "b", MaxNsPerOp: 0, MaxAllocsPerOp: -1},
+	}
+
+	tests := []struct {
+		name    string
+		results []Result
+		wantErr []string
+	}{{
+		name:    "within budget",
+		results: []Result{{Name: "a", NsPerOp: 100, AllocsPerOp: 1}},
+	}, {
+		name:    "too slow",
+		results: []Result{{Name: "a", NsPerOp: 101}},
+		wantErr: []string{"a: 101 ns/op exceeds budget of 100 ns/op"},
+	}, {
+		name:    "too many allocations",
+		results: []Result{{Name: "a", NsPerOp: 10, AllocsPerOp: 2}},
+		wantErr: []string{"a: 2 allocs/op exceeds budget of 1 allocs/op"},
+	}, {
+		name:    "unlimited budget",
+		results: []Result{{Name: "b", NsPerOp: 1e9, AllocsPerOp: 1000}},
+	}, {
+		name:    "no budget",
+		results: []Result{{Name: "c", NsPerOp: 1e9, AllocsPerOp: 1000}},
+	}, {
+		name:    "multiple violations",
+		results: []Result{{Name: "a", NsPerOp: 200, AllocsPerOp: 5}},
+		wantErr: []string{"200 ns/op", "5 allocs/op"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.results, budgets)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Check() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Check() = nil, want error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Check() = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteBenchmarkFormat(t *testing.T) {
+	var buf bytes.Buffer
+	results := []Result{{
+		Name:        ManagerScale10,
+		Iterations:  1000,
+		NsPerOp:     4321.5,
+		AllocsPerOp: 10,
+		BytesPerOp:  480,
+	}}
+	if err := WriteBenchmarkFormat(&buf, results); err != nil {
+		t.Fatalf("WriteBenchmarkFormat failed: %v", err)
+	}
+
+	want := "BenchmarkManager_Scale/scalers=10\t1000\t4321.5 ns/op\t480 B/op\t10 allocs/op\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteBenchmarkFormat() = %q, want %q", got, want)
+	}
+}
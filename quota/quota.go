@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package quota derives the maximum scale of a workload from the
+// ResourceQuota of its namespace, so recommendations never exceed the pods
+// the quota admits.
+package quota
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/manager"
+)
+
+// tolerance absorbs floating point errors when dividing quantities, so e.g.
+// 0.3 CPU fit 3 pods of 0.1 CPU.
+const tolerance = 1e-9
+
+// Resources are amounts of CPU in cores and memory in bytes. A zero amount
+// is not constrained.
+type Resources struct {
+	CPU    float64
+	Memory float64
+}
+
+// ParseResources parses Kubernetes quantities of CPU and memory, e.g. "500m"
+// and "1Gi". An empty string is a zero amount.
+func ParseResources(cpu, memory string) (Resources, error) {
+	var r Resources
+	if cpu != "" {
+		v, err := config.ParseQuantity(cpu)
+		if err != nil {
+			return Resources{}, fmt.Errorf("invalid CPU quantity: %w", err)
+		}
+		r.CPU = v
+	}
+	if memory != "" {
+		v, err := config.ParseQuantity(memory)
+		if err != nil {
+			return Resources{}, fmt.Errorf("invalid memory quantity: %w", err)
+		}
+		r.Memory = v
+	}
+	return r, nil
+}
+
+// Quota is the state of a ResourceQuota, e.g. its requests.cpu and
+// requests.memory or its limits.cpu and limits.memory.
+type Quota struct {
+	// Hard is the quota of the namespace.
+	Hard Resources
+
+	// Used is the amount used by all pods of the namespace, including the
+	// pods of the scaled workload.
+	Used Resources
+}
+
+// MaxScale returns the most pods with the given resources the quota admits,
+// given that current pods of the workload are already included in the used
+// amount. Compare like with like: pod requests with a requests quota, and
+// pod limits with a limits quota. It returns false if the quota doesn't
+// constrain the pods, e.g. because it has no CPU or memory quota.
+func MaxScale(q Quota, pod Resources, current int32) (int32, bool) {
+	pods := math.Inf(1)
+	for _, d := range []struct{ hard, used, pod float64 }{
+		{q.Hard.CPU, q.Used.CPU, pod.CPU},
+		{q.Hard.Memory, q.Used.Memory, pod.Memory},
+	} {
+		if d.hard <= 0 || d.pod <= 0 {
+			continue
+		}
+		available := d.hard - d.used + float64(current)*d.pod
+		pods = min(pods, math.Floor(available/d.pod+tolerance))
+	}
+	if math.IsInf(pods, 1) {
+		return 0, false
+	}
+	return int32(min(max(pods, 0), math.MaxInt32)), true
+}
+
+// Apply sets the maximum scale of the manager to the lower of maxScale and
+// the pods the quota admits, and returns it. A maxScale of 0 means no
+// configured limit. As a manager treats a maximum scale of 0 as unlimited, a
+// quota that admits no pod at all results in a maximum scale of 1; the quota
+// rejects that pod anyway.
+func Apply(m *manager.Manager, maxScale int32, q Quota, pod Resources, current int32) int32 {
+	if quotaScale, ok := MaxScale(q, pod, current); ok {
+		quotaScale = max(quotaScale, 1)
+		if maxScale <= 0 || quotaScale < maxScale {
+			maxScale = quotaScale
+		}
+	}
+	m.SetMaxScale(maxScale)
+	return maxScale
+}
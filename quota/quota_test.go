@@ -0,0 +1,141 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"testing"
+
+	"github.com/Fedosin/libkpa/manager"
+)
+
+func TestParseResources(t *testing.T) {
+	r, err := ParseResources("500m", "1Gi")
+	if err != nil {
+		t.Fatalf("ParseResources failed: %v", err)
+	}
+	if want := (Resources{CPU: 0.5, Memory: 1 << 30}); r != want {
+		t.Errorf("ParseResources() = %+v, want %+v", r, want)
+	}
+
+	if r, err := ParseResources("", "256Mi"); err != nil || r != (Resources{Memory: 256 << 20}) {
+		t.Errorf("ParseResources() = %+v, %v, want only memory", r, err)
+	}
+	if _, err := ParseResources("lots", ""); err == nil {
+		t.Error("expected error for an invalid CPU quantity")
+	}
+	if _, err := ParseResources("1", "lots"); err == nil {
+		t.Error("expected error for an invalid memory quantity")
+	}
+}
+
+func TestMaxScale(t *testing.T) {
+	pod := Resources{CPU: 0.5, Memory: 1 << 30}
+	tests := []struct {
+		name    string
+		quota   Quota
+		pod     Resources
+		current int32
+		want    int32
+		wantOK  bool
+	}{
+		{
+			name:   "no quota",
+			pod:    pod,
+			wantOK: false,
+		},
+		{
+			name:   "CPU bound",
+			quota:  Quota{Hard: Resources{CPU: 10, Memory: 100 << 30}},
+			pod:    pod,
+			want:   20,
+			wantOK: true,
+		},
+		{
+			name:   "memory bound",
+			quota:  Quota{Hard: Resources{CPU: 10, Memory: 8 << 30}},
+			pod:    pod,
+			want:   8,
+			wantOK: true,
+		},
+		{
+			name:    "current pods are part of the used amount",
+			quota:   Quota{Hard: Resources{CPU: 10}, Used: Resources{CPU: 6}},
+			pod:     pod,
+			current: 4,
+			want:    12,
+			wantOK:  true,
+		},
+		{
+			name:   "floating point errors",
+			quota:  Quota{Hard: Resources{CPU: 0.3}},
+			pod:    Resources{CPU: 0.1},
+			want:   3,
+			wantOK: true,
+		},
+		{
+			name:   "exhausted quota",
+			quota:  Quota{Hard: Resources{CPU: 10}, Used: Resources{CPU: 12}},
+			pod:    pod,
+			want:   0,
+			wantOK: true,
+		},
+		{
+			name:   "pod without the quota's resource",
+			quota:  Quota{Hard: Resources{CPU: 10}},
+			pod:    Resources{Memory: 1 << 30},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MaxScale(tt.quota, tt.pod, tt.current)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("MaxScale() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	m := manager.NewManager(0, 0)
+	pod := Resources{CPU: 1}
+
+	tests := []struct {
+		name     string
+		maxScale int32
+		quota    Quota
+		want     int32
+	}{
+		{name: "quota below the configured maximum", maxScale: 50, quota: Quota{Hard: Resources{CPU: 20}}, want: 20},
+		{name: "configured maximum below the quota", maxScale: 10, quota: Quota{Hard: Resources{CPU: 20}}, want: 10},
+		{name: "no configured maximum", quota: Quota{Hard: Resources{CPU: 20}}, want: 20},
+		{name: "no quota", maxScale: 10, want: 10},
+		{name: "exhausted quota", maxScale: 10, quota: Quota{Hard: Resources{CPU: 20}, Used: Resources{CPU: 30}}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Apply(m, tt.maxScale, tt.quota, pod, 0); got != tt.want {
+				t.Errorf("Apply() = %d, want %d", got, tt.want)
+			}
+			if got := m.GetMaxScale(); got != tt.want {
+				t.Errorf("GetMaxScale() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
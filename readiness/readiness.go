@@ -0,0 +1,211 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package readiness tracks the ready pods of a workload from Kubernetes
+// informer events, so callers don't have to list and count pods before
+// every scaling decision.
+package readiness
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/manager"
+)
+
+// Pod is the state of a pod relevant for scaling.
+type Pod struct {
+	Namespace string
+	Name      string
+	Labels    map[string]string
+
+	// Ready is true if the Ready condition of the pod is true.
+	Ready bool
+
+	// Terminating is true if the pod has a deletion timestamp.
+	Terminating bool
+}
+
+// Counts are the numbers of pods matching the selector of a Tracker.
+type Counts struct {
+	// Ready pods are ready and not terminating.
+	Ready int32
+
+	// NotReady pods are neither ready nor terminating, e.g. pods that are
+	// starting.
+	NotReady int32
+
+	// Terminating pods have been deleted but not yet removed.
+	Terminating int32
+}
+
+// ConvertFunc converts an object of an informer event to a Pod. It returns
+// false for objects that are not pods. On deletion, informers may pass a
+// tombstone with the last known state of the object, which ConvertFunc has to
+// unwrap.
+//
+// With client-go it is usually implemented as:
+//
+//	func convert(obj any) (readiness.Pod, bool) {
+//		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+//			obj = tombstone.Obj
+//		}
+//		pod, ok := obj.(*corev1.Pod)
+//		if !ok {
+//			return readiness.Pod{}, false
+//		}
+//		ready := false
+//		for _, c := range pod.Status.Conditions {
+//			if c.Type == corev1.PodReady {
+//				ready = c.Status == corev1.ConditionTrue
+//			}
+//		}
+//		return readiness.Pod{
+//			Namespace:   pod.Namespace,
+//			Name:        pod.Name,
+//			Labels:      pod.Labels,
+//			Ready:       ready,
+//			Terminating: pod.DeletionTimestamp != nil,
+//		}, true
+//	}
+type ConvertFunc func(obj any) (Pod, bool)
+
+// Tracker counts the ready pods matching a label selector. Its OnAdd,
+// OnUpdate and OnDelete methods implement the ResourceEventHandler interface
+// of client-go, so a Tracker can be added to a pod informer directly, while
+// this library stays free of the client-go dependency:
+//
+//	informer.AddEventHandler(tracker)
+//
+// Tracker is safe for concurrent use.
+type Tracker struct {
+	selector map[string]string
+	convert  ConvertFunc
+
+	mu     sync.RWMutex
+	pods   map[string]Pod
+	counts Counts
+}
+
+// NewTracker creates a tracker of the pods whose labels contain all labels of
+// the selector, like the matchLabels of a Deployment. An empty selector
+// matches all pods.
+func NewTracker(selector map[string]string, convert ConvertFunc) (*Tracker, error) {
+	if convert == nil {
+		return nil, fmt.Errorf("convert function cannot be nil")
+	}
+	return &Tracker{
+		selector: selector,
+		convert:  convert,
+		pods:     make(map[string]Pod),
+	}, nil
+}
+
+// OnAdd handles a pod added to the informer's cache.
+func (t *Tracker) OnAdd(obj any, _ bool) {
+	if pod, ok := t.convert(obj); ok {
+		t.set(pod)
+	}
+}
+
+// OnUpdate handles a pod updated in the informer's cache, including periodic
+// resyncs.
+func (t *Tracker) OnUpdate(_, newObj any) {
+	if pod, ok := t.convert(newObj); ok {
+		t.set(pod)
+	}
+}
+
+// OnDelete handles a pod removed from the informer's cache.
+func (t *Tracker) OnDelete(obj any) {
+	pod, ok := t.convert(obj)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.removeLocked(key(pod))
+}
+
+// set records the state of a pod. Pods whose labels no longer match the
+// selector are removed.
+func (t *Tracker) set(pod Pod) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	k := key(pod)
+	t.removeLocked(k)
+	if !t.matches(pod) {
+		return
+	}
+	t.pods[k] = pod
+	t.count(pod, 1)
+}
+
+// removeLocked removes the pod with the given key. The caller must hold the
+// lock.
+func (t *Tracker) removeLocked(k string) {
+	if old, ok := t.pods[k]; ok {
+		t.count(old, -1)
+		delete(t.pods, k)
+	}
+}
+
+// count adds delta to the count the pod belongs to. The caller must hold the
+// lock.
+func (t *Tracker) count(pod Pod, delta int32) {
+	switch {
+	case pod.Terminating:
+		t.counts.Terminating += delta
+	case pod.Ready:
+		t.counts.Ready += delta
+	default:
+		t.counts.NotReady += delta
+	}
+}
+
+// matches reports whether the labels of the pod match the selector.
+func (t *Tracker) matches(pod Pod) bool {
+	for k, v := range t.selector {
+		if l, ok := pod.Labels[k]; !ok || l != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Counts returns the current numbers of pods.
+func (t *Tracker) Counts() Counts {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.counts
+}
+
+// ReadyPods returns the current number of ready pods, the readyPods argument
+// of the Scale methods of the manager package.
+func (t *Tracker) ReadyPods() int32 {
+	return t.Counts().Ready
+}
+
+// Scale calls m.Scale with the current number of ready pods.
+func (t *Tracker) Scale(m *manager.Manager, now time.Time) (int32, error) {
+	return m.Scale(t.ReadyPods(), now)
+}
+
+func key(pod Pod) string {
+	return pod.Namespace + "/" + pod.Name
+}
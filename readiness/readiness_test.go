@@ -0,0 +1,109 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+	libkpaconfig "github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/manager"
+)
+
+// tombstone mimics the DeletedFinalStateUnknown objects of client-go.
+type tombstone struct {
+	obj any
+}
+
+func convert(obj any) (Pod, bool) {
+	if t, ok := obj.(tombstone); ok {
+		obj = t.obj
+	}
+	pod, ok := obj.(*Pod)
+	if !ok {
+		return Pod{}, false
+	}
+	return *pod, true
+}
+
+func newPod(name string, ready bool, labels map[string]string) *Pod {
+	return &Pod{Namespace: "default", Name: name, Labels: labels, Ready: ready}
+}
+
+func TestTracker(t *testing.T) {
+	if _, err := NewTracker(nil, nil); err == nil {
+		t.Error("NewTracker succeeded without a convert function, want error")
+	}
+
+	app := map[string]string{"app": "web", "pod-template-hash": "abc"}
+	tracker, err := NewTracker(map[string]string{"app": "web"}, convert)
+	if err != nil {
+		t.Fatalf("NewTracker failed: %v", err)
+	}
+
+	a, b, c := newPod("a", true, app), newPod("b", false, app), newPod("c", true, app)
+	tracker.OnAdd(a, true)
+	tracker.OnAdd(b, true)
+	tracker.OnAdd(c, false)
+	tracker.OnAdd(newPod("other", true, map[string]string{"app": "api"}), false)
+	tracker.OnAdd("not a pod", false)
+	if got, want := tracker.Counts(), (Counts{Ready: 2, NotReady: 1}); got != want {
+		t.Errorf("Counts() = %+v, want %+v", got, want)
+	}
+
+	// b becomes ready, c starts terminating and a is relabeled out of the
+	// selector. Resyncs don't change anything.
+	tracker.OnUpdate(b, newPod("b", true, app))
+	tracker.OnUpdate(c, &Pod{Namespace: "default", Name: "c", Labels: app, Ready: true, Terminating: true})
+	tracker.OnUpdate(a, newPod("a", true, map[string]string{"app": "debug"}))
+	tracker.OnUpdate(b, newPod("b", true, app))
+	if got, want := tracker.Counts(), (Counts{Ready: 1, Terminating: 1}); got != want {
+		t.Errorf("Counts() = %+v, want %+v", got, want)
+	}
+
+	tracker.OnDelete(tombstone{obj: c})
+	tracker.OnDelete(a)
+	if got, want := tracker.Counts(), (Counts{Ready: 1}); got != want {
+		t.Errorf("Counts() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTrackerScale(t *testing.T) {
+	config := libkpaconfig.NewDefaultAutoscalerConfigForMetric(api.ScalingMetricUtilization)
+	config.TargetValue = 80
+	scaler, err := manager.NewScaler("cpu", *config, "linear")
+	if err != nil {
+		t.Fatalf("NewScaler failed: %v", err)
+	}
+	m := manager.NewManager(0, 0, scaler)
+
+	tracker, err := NewTracker(nil, convert)
+	if err != nil {
+		t.Fatalf("NewTracker failed: %v", err)
+	}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		tracker.OnAdd(newPod(name, true, nil), true)
+	}
+
+	// 4 pods at 100% utilization need 5 pods at 80%.
+	now := time.Now()
+	scaler.Record(100, now)
+	if got, err := tracker.Scale(m, now); err != nil || got != 5 {
+		t.Errorf("Scale() = %d, %v, want 5", got, err)
+	}
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry provides a concurrency-safe registry of named autoscaler
+// instances, so that programs embedding many autoscalers can enumerate them,
+// look them up by name and close them. A package-level default registry is
+// available through the top-level functions.
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Stats describes the instances of a registry.
+type Stats struct {
+	// Instances is the number of currently registered instances.
+	Instances int
+
+	// Registered is the total number of instances registered so far.
+	Registered uint64
+
+	// Closed is the total number of instances closed so far.
+	Closed uint64
+}
+
+// Registry is a concurrency-safe set of named instances, e.g. *manager.Scaler,
+// *manager.Manager or *multitenant.Manager. Instances implementing io.Closer
+// are closed when they are closed through the registry.
+type Registry struct {
+	mu         sync.RWMutex
+	instances  map[string]any
+	registered uint64
+	closed     uint64
+}
+
+// New creates an empty registry.
+func New() *Registry {
+	return &Registry{instances: make(map[string]any)}
+}
+
+// Register adds an instance under the given name.
+// It returns an error if the name is already taken.
+func (r *Registry) Register(name string, instance any) error {
+	if name == "" {
+		return fmt.Errorf("instance name cannot be empty")
+	}
+	if instance == nil {
+		return fmt.Errorf("instance %q cannot be nil", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.instances[name]; exists {
+		return fmt.Errorf("instance %q already registered", name)
+	}
+	r.instances[name] = instance
+	r.registered++
+	return nil
+}
+
+// Unregister removes the instance without closing it.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.instances, name)
+}
+
+// Get returns the instance registered under the given name.
+func (r *Registry) Get(name string) (any, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	instance, exists := r.instances[name]
+	return instance, exists
+}
+
+// Names returns the names of all registered instances in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.instances))
+	for name := range r.instances {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// Len returns the number of registered instances.
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.instances)
+}
+
+// Stats returns the instance counters of the registry.
+func (r *Registry) Stats() Stats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return Stats{
+		Instances:  len(r.instances),
+		Registered: r.registered,
+		Closed:     r.closed,
+	}
+}
+
+// Close unregisters the instance and closes it if it implements io.Closer.
+func (r *Registry) Close(name string) error {
+	r.mu.Lock()
+	instance, exists := r.instances[name]
+	if !exists {
+		r.mu.Unlock()
+		return fmt.Errorf("instance %q not found", name)
+	}
+	delete(r.instances, name)
+	r.closed++
+	r.mu.Unlock()
+
+	return closeInstance(name, instance)
+}
+
+// CloseAll unregisters and closes all instances. Errors of individual
+// instances are joined into the returned error.
+func (r *Registry) CloseAll() error {
+	r.mu.Lock()
+	instances := r.instances
+	r.instances = make(map[string]any)
+	r.closed += uint64(len(instances))
+	r.mu.Unlock()
+
+	var errs []error
+	for name, instance := range instances {
+		if err := closeInstance(name, instance); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeInstance closes the instance if it implements io.Closer.
+func closeInstance(name string, instance any) error {
+	c, ok := instance.(io.Closer)
+	if !ok {
+		return nil
+	}
+	if err := c.Close(); err != nil {
+		return fmt.Errorf("failed to close instance %q: %w", name, err)
+	}
+	return nil
+}
+
+// defaultRegistry is the package-level registry.
+var defaultRegistry = New()
+
+// Default returns the package-level registry.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register adds an instance to the default registry.
+func Register(name string, instance any) error {
+	return defaultRegistry.Register(name, instance)
+}
+
+// Unregister removes an instance from the default registry without closing it.
+func Unregister(name string) {
+	defaultRegistry.Unregister(name)
+}
+
+// Get returns an instance from the default registry.
+func Get(name string) (any, bool) {
+	return defaultRegistry.Get(name)
+}
+
+// Names returns the names of all instances in the default registry.
+func Names() []string {
+	return defaultRegistry.Names()
+}
+
+// Close unregisters and closes an instance of the default registry.
+func Close(name string) error {
+	return defaultRegistry.Close(name)
+}
+
+// CloseAll unregisters and closes all instances of the default registry.
+func CloseAll() error {
+	return defaultRegistry.CloseAll()
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// closer records whether it was closed.
+type closer struct {
+	closed bool
+	err    error
+}
+
+func (c *closer) Close() error {
+	c.closed = true
+	return c.err
+}
+
+func TestRegistryRegister(t *testing.T) {
+	r := New()
+
+	if err := r.Register("a", &closer{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := r.Register("a", &closer{}); err == nil {
+		t.Error("expected error for a duplicate name")
+	}
+	if err := r.Register("", &closer{}); err == nil {
+		t.Error("expected error for an empty name")
+	}
+	if err := r.Register("b", nil); err == nil {
+		t.Error("expected error for a nil instance")
+	}
+	if err := r.Register("b", "not a closer"); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if got, want := r.Names(), []string{"a", "b"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+	if instance, ok := r.Get("b"); !ok || instance != "not a closer" {
+		t.Errorf("Get(b) = %v, %v", instance, ok)
+	}
+	if _, ok := r.Get("c"); ok {
+		t.Error("Get(c) found an unregistered instance")
+	}
+
+	r.Unregister("b")
+	if got := r.Len(); got != 1 {
+		t.Errorf("Len() = %d, want 1", got)
+	}
+}
+
+func TestRegistryClose(t *testing.T) {
+	r := New()
+	a, b, c := &closer{}, &closer{err: errors.New("boom")}, &closer{}
+	for name, instance := range map[string]any{"a": a, "b": b, "c": c, "d": 42} {
+		if err := r.Register(name, instance); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	if err := r.Close("a"); err != nil {
+		t.Errorf("Close(a) = %v", err)
+	}
+	if !a.closed {
+		t.Error("instance a was not closed")
+	}
+	if err := r.Close("a"); err == nil {
+		t.Error("expected error when closing an unregistered instance")
+	}
+
+	err := r.CloseAll()
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("CloseAll() = %v, want error containing boom", err)
+	}
+	if !b.closed || !c.closed {
+		t.Error("CloseAll did not close all instances")
+	}
+
+	want := Stats{Instances: 0, Registered: 4, Closed: 4}
+	if got := r.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	t.Cleanup(func() { _ = CloseAll() })
+
+	c := &closer{}
+	if err := Register("default-test", c); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if _, ok := Get("default-test"); !ok {
+		t.Error("Get did not find the instance")
+	}
+	if got := Names(); len(got) != 1 || got[0] != "default-test" {
+		t.Errorf("Names() = %v", got)
+	}
+	if err := Close("default-test"); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if !c.closed {
+		t.Error("instance was not closed")
+	}
+	if Default().Len() != 0 {
+		t.Error("default registry is not empty")
+	}
+
+	Unregister("missing") // no-op
+}
+
+func TestRegistryConcurrentAccess(t *testing.T) {
+	r := New()
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("instance-%d", i)
+			if err := r.Register(name, &closer{}); err != nil {
+				t.Errorf("Register failed: %v", err)
+			}
+			r.Names()
+			r.Stats()
+			if i%2 == 0 {
+				_ = r.Close(name)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := r.Stats(); got.Instances != 25 || got.Registered != 50 || got.Closed != 25 {
+		t.Errorf("Stats() = %+v", got)
+	}
+}
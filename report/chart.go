@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fedosin/libkpa/audit"
+)
+
+// Default size of the plot area of WriteChart in characters.
+const (
+	DefaultChartWidth  = 72
+	DefaultChartHeight = 12
+)
+
+// Markers of the series of WriteChart. Where series overlap, the desired
+// pods are drawn over the ready pods, which are drawn over the load.
+const (
+	loadMarker    = '.'
+	readyMarker   = 'o'
+	desiredMarker = '#'
+)
+
+// ChartOptions configure WriteChart.
+type ChartOptions struct {
+	// Width and Height are the size of the plot area in characters. Zero
+	// values mean DefaultChartWidth and DefaultChartHeight.
+	Width  int
+	Height int
+
+	// Name selects the records of a single autoscaler. If empty, all records
+	// are plotted, so they should be the decisions of one autoscaler.
+	Name string
+}
+
+// WriteChart plots the load, the stable value of the decisions, against the
+// desired and the ready pods over time as a text chart, e.g. to make the
+// output of a simulation interpretable in a terminal. The pods are scaled
+// to the axis on the left and the load to the axis on the right. Every
+// column shows the highest values of the decisions within its time span.
+func WriteChart(w io.Writer, records []audit.Record, opts ChartOptions) error {
+	width, height := opts.Width, opts.Height
+	if width <= 0 {
+		width = DefaultChartWidth
+	}
+	if height <= 0 {
+		height = DefaultChartHeight
+	}
+
+	var selected []audit.Record
+	for _, r := range records {
+		if opts.Name == "" || r.Name == opts.Name {
+			selected = append(selected, r)
+		}
+	}
+	if len(selected) == 0 {
+		_, err := fmt.Fprintln(w, "no decisions to plot")
+		return err
+	}
+
+	start, end := selected[0].Time, selected[0].Time
+	for _, r := range selected {
+		if r.Time.Before(start) {
+			start = r.Time
+		}
+		if r.Time.After(end) {
+			end = r.Time
+		}
+	}
+
+	// The highest values per column, or -1 for columns without any.
+	load, ready, desired := newColumns(width), newColumns(width), newColumns(width)
+	maxPods, maxLoad := 1.0, 1.0
+	for _, r := range selected {
+		col := 0
+		if span := end.Sub(start); span > 0 {
+			col = min(width-1, int(float64(width)*float64(r.Time.Sub(start))/float64(span)))
+		}
+		// Decisions without enough data carry a negative load.
+		if r.StableValue >= 0 {
+			load[col] = max(load[col], r.StableValue)
+			maxLoad = max(maxLoad, r.StableValue)
+		}
+		ready[col] = max(ready[col], float64(r.ReadyPodCount))
+		maxPods = max(maxPods, float64(r.ReadyPodCount))
+		if r.ScaleValid {
+			desired[col] = max(desired[col], float64(r.DesiredPodCount))
+			maxPods = max(maxPods, float64(r.DesiredPodCount))
+		}
+	}
+
+	grid := make([][]byte, height)
+	for i := range grid {
+		grid[i] = []byte(strings.Repeat(" ", width))
+	}
+	plot := func(values []float64, maxValue float64, marker byte) {
+		for col, v := range values {
+			if v < 0 {
+				continue
+			}
+			row := height - 1 - int(math.Round(v/maxValue*float64(height-1)))
+			grid[row][col] = marker
+		}
+	}
+	plot(load, maxLoad, loadMarker)
+	plot(ready, maxPods, readyMarker)
+	plot(desired, maxPods, desiredMarker)
+
+	podsLabel := strconv.FormatFloat(maxPods, 'f', -1, 64)
+	loadLabel := strconv.FormatFloat(maxLoad, 'g', 4, 64)
+	labelWidth := max(len(podsLabel), len("pods"))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%*s %s load\n", labelWidth, "pods", strings.Repeat(" ", width+2))
+	for i, line := range grid {
+		left, right := "", ""
+		switch i {
+		case 0:
+			left, right = podsLabel, loadLabel
+		case height - 1:
+			left, right = "0", "0"
+		}
+		fmt.Fprintln(&b, strings.TrimRight(fmt.Sprintf("%*s |%s| %s", labelWidth, left, line, right), " "))
+	}
+	fmt.Fprintf(&b, "%*s +%s+\n", labelWidth, "", strings.Repeat("-", width))
+	startLabel, endLabel := start.Format(time.TimeOnly), end.Format(time.TimeOnly)
+	gap := max(1, width+2-len(startLabel)-len(endLabel))
+	fmt.Fprintf(&b, "%*s %s%s%s\n", labelWidth, "", startLabel, strings.Repeat(" ", gap), endLabel)
+	fmt.Fprintf(&b, "%*s %c desired pods  %c ready pods  %c load\n", labelWidth, "", desiredMarker, readyMarker, loadMarker)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// newColumns returns n columns without values.
+func newColumns(n int) []float64 {
+	columns := make([]float64, n)
+	for i := range columns {
+		columns[i] = -1
+	}
+	return columns
+}
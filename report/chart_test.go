@@ -0,0 +1,76 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/audit"
+)
+
+func TestWriteChart(t *testing.T) {
+	// The load quadruples after 4s, and the ready pods follow the desired
+	// pods a decision later.
+	var records []audit.Record
+	for i := range 8 {
+		r := audit.Record{
+			Time:            start.Add(time.Duration(i) * time.Second),
+			Name:            "web",
+			StableValue:     50,
+			ReadyPodCount:   1,
+			DesiredPodCount: 1,
+			ScaleValid:      true,
+		}
+		if i >= 4 {
+			r.StableValue, r.DesiredPodCount = 200, 2
+		}
+		if i >= 5 {
+			r.ReadyPodCount = 2
+		}
+		records = append(records, r)
+	}
+	records = append(records, audit.Record{Time: start, Name: "api", StableValue: 1000, DesiredPodCount: 50, ScaleValid: true})
+
+	var buf bytes.Buffer
+	if err := WriteChart(&buf, records, ChartOptions{Width: 8, Height: 5, Name: "web"}); err != nil {
+		t.Fatalf("WriteChart failed: %v", err)
+	}
+	want := `pods            load
+   2 |    ####| 200
+     |        |
+     |####o   |
+     |....    |
+   0 |        | 0
+     +--------+
+     00:00:00 00:00:07
+     # desired pods  o ready pods  . load
+`
+	if buf.String() != want {
+		t.Errorf("chart =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteChart(&buf, records, ChartOptions{Name: "missing"}); err != nil {
+		t.Fatalf("WriteChart failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "no decisions") {
+		t.Errorf("chart without decisions = %q", buf.String())
+	}
+}
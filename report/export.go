@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/audit"
+)
+
+// History keeps the records written to it, e.g. the decisions of a
+// simulation, for exporting them with WriteCSV, WriteJSON or WriteHTML.
+// History is safe for concurrent use.
+type History struct {
+	mu      sync.Mutex
+	records []audit.Record
+}
+
+var _ audit.Sink = (*History)(nil)
+
+// Write appends the record. It never fails.
+func (h *History) Write(r audit.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+// Records returns a copy of the records written so far.
+func (h *History) Records() []audit.Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.records)
+}
+
+// csvHeader are the columns written by WriteCSV.
+var csvHeader = []string{
+	"time", "name", "stable_value", "burst_value", "ready_pods", "desired_pods",
+	"valid", "burst_mode", "reason", "limited_by", "latency_ns", "config_hash",
+}
+
+// WriteCSV writes the records as CSV with a header row, one row per
+// decision, e.g. for spreadsheets.
+func WriteCSV(w io.Writer, records []audit.Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		err := cw.Write([]string{
+			r.Time.Format(time.RFC3339Nano),
+			r.Name,
+			strconv.FormatFloat(r.StableValue, 'g', -1, 64),
+			strconv.FormatFloat(r.BurstValue, 'g', -1, 64),
+			strconv.Itoa(int(r.ReadyPodCount)),
+			strconv.Itoa(int(r.DesiredPodCount)),
+			strconv.FormatBool(r.ScaleValid),
+			strconv.FormatBool(r.InBurstMode),
+			r.Reason,
+			string(r.LimitedBy),
+			strconv.FormatInt(int64(r.Latency), 10),
+			r.ConfigHash,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Export is the document written by WriteJSON.
+type Export struct {
+	// Summary is the summary of the records.
+	Summary Summary `json:"summary"`
+
+	// Records are the exported decisions.
+	Records []audit.Record `json:"records"`
+}
+
+// WriteJSON writes the records and their summary as an indented Export
+// document.
+func WriteJSON(w io.Writer, records []audit.Record) error {
+	if records == nil {
+		records = []audit.Record{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(Export{Summary: Summarize(records), Records: records})
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/algorithm"
+	"github.com/Fedosin/libkpa/audit"
+	libkpaconfig "github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/metrics"
+)
+
+func TestHistory(t *testing.T) {
+	scaler, err := algorithm.NewSlidingWindowAutoscalerAt(*libkpaconfig.NewDefaultAutoscalerConfig(), start)
+	if err != nil {
+		t.Fatalf("NewSlidingWindowAutoscalerAt failed: %v", err)
+	}
+	history := &History{}
+	audited, err := audit.NewAutoscaler("web", scaler, history)
+	if err != nil {
+		t.Fatalf("NewAutoscaler failed: %v", err)
+	}
+
+	for i := range 3 {
+		now := start.Add(time.Duration(i) * time.Second)
+		audited.Scale(metrics.NewMetricSnapshot(100, 100, 1, now), now)
+	}
+	records := history.Records()
+	if len(records) != 3 || records[2].Name != "web" || !records[2].Time.Equal(start.Add(2*time.Second)) {
+		t.Errorf("Records() = %+v, want the 3 decisions of web", records)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, testRecords()); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(rows) != 7 {
+		t.Fatalf("got %d rows, want a header and 6 records", len(rows))
+	}
+	if got := strings.Join(rows[0], ","); !strings.HasPrefix(got, "time,name,stable_value") {
+		t.Errorf("header = %q", got)
+	}
+	want := []string{"2025-01-01T00:00:10Z", "web", "0", "0", "0", "4", "true", "false", "scale-up", "provisioning-rate", "2000", ""}
+	if got := rows[2]; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("row = %q, want %q", got, want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, testRecords()); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	var got Export
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode export: %v", err)
+	}
+	if len(got.Records) != 6 || got.Summary.Decisions != 6 || got.Summary.TotalDelay() != 50*time.Second {
+		t.Errorf("export = %+v, want 6 records with 50s of delays", got)
+	}
+
+	buf.Reset()
+	if err := WriteJSON(&buf, nil); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"records": []`) {
+		t.Errorf("export of no records = %s, want an empty array", buf.String())
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, "Capacity review <web>", testRecords()); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	page := buf.String()
+
+	for _, want := range []string{
+		"<title>Capacity review &lt;web&gt;</title>",
+		"<h2>api</h2>",
+		"<h2>web</h2>",
+		"Decisions:     6 (5 valid)",
+		`stroke="#d62728"`,
+		"<td>scale-up</td><td>provisioning-rate</td>",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page doesn't contain %q", want)
+		}
+	}
+	// Two charts with two series for each of the two autoscalers.
+	if got := strings.Count(page, "<polyline"); got != 8 {
+		t.Errorf("page has %d series, want 8", got)
+	}
+	if strings.Contains(page, "ZgotmplZ") {
+		t.Error("the template rejected a value as unsafe")
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fedosin/libkpa/audit"
+)
+
+// Dimensions of the charts of WriteHTML in pixels.
+const (
+	chartWidth   = 800
+	chartHeight  = 200
+	chartPadding = 40
+)
+
+// htmlTemplate is the self-contained page written by WriteHTML. The charts
+// are inline SVG, so the page can be shared without any other files.
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+svg { background: #fafafa; border: 1px solid #ccc; }
+.legend span { margin-right: 1em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<pre>{{.Summary}}</pre>
+{{range .Autoscalers}}
+<h2>{{.Name}}</h2>
+{{range .Charts}}
+<h3>{{.Title}}</h3>
+<div class="legend">{{range .Series}}<span style="color: {{.Color}}">&#9632; {{.Name}}</span>{{end}}</div>
+<svg width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}" xmlns="http://www.w3.org/2000/svg">
+<line x1="{{.Left}}" y1="{{.Bottom}}" x2="{{.Right}}" y2="{{.Bottom}}" stroke="#888"/>
+<line x1="{{.Left}}" y1="{{.Top}}" x2="{{.Left}}" y2="{{.Bottom}}" stroke="#888"/>
+<text x="4" y="{{.Top}}" font-size="12">{{.Max}}</text>
+<text x="4" y="{{.Bottom}}" font-size="12">0</text>
+{{range .Series}}<polyline fill="none" stroke="{{.Color}}" stroke-width="1.5" points="{{.Points}}"/>
+{{end}}</svg>
+{{end}}
+<table>
+<tr><th>Time</th><th>Ready</th><th>Desired</th><th>Stable</th><th>Burst</th><th>Reason</th><th>Limited by</th></tr>
+{{range .Records}}<tr><td>{{.Time.Format "2006-01-02T15:04:05Z07:00"}}</td><td>{{.ReadyPodCount}}</td><td>{{.DesiredPodCount}}</td><td>{{.StableValue}}</td><td>{{.BurstValue}}</td><td>{{.Reason}}</td><td>{{.LimitedBy}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+type htmlPage struct {
+	Title       string
+	Summary     string
+	Autoscalers []htmlAutoscaler
+}
+
+type htmlAutoscaler struct {
+	Name    string
+	Charts  []chart
+	Records []audit.Record
+}
+
+// chart is a line chart of series sharing the time and the value axes.
+type chart struct {
+	Title  string
+	Max    string
+	Series []series
+
+	Width, Height            int
+	Left, Right, Top, Bottom int
+}
+
+type series struct {
+	Name   string
+	Color  string
+	Points string
+}
+
+// point is a value of a series at a time.
+type point struct {
+	t time.Time
+	v float64
+}
+
+// WriteHTML writes the records as a self-contained HTML page for sharing
+// scaling analyses: the summary of all records, and for every autoscaler
+// charts of the ready and desired pods and of the stable and burst values
+// over time, followed by a table of its decisions.
+func WriteHTML(w io.Writer, title string, records []audit.Record) error {
+	var summary strings.Builder
+	if err := WriteText(&summary, Summarize(records)); err != nil {
+		return err
+	}
+	page := htmlPage{Title: title, Summary: summary.String()}
+
+	byName := make(map[string][]audit.Record)
+	for _, r := range records {
+		byName[r.Name] = append(byName[r.Name], r)
+	}
+	var start, end time.Time
+	for i, r := range records {
+		if i == 0 || r.Time.Before(start) {
+			start = r.Time
+		}
+		if i == 0 || r.Time.After(end) {
+			end = r.Time
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(byName)) {
+		rs := byName[name]
+		var ready, desired, stable, burst []point
+		for _, r := range rs {
+			ready = append(ready, point{r.Time, float64(r.ReadyPodCount)})
+			if r.ScaleValid {
+				desired = append(desired, point{r.Time, float64(r.DesiredPodCount)})
+			}
+			// Decisions without enough data carry negative values.
+			if r.StableValue >= 0 && r.BurstValue >= 0 {
+				stable = append(stable, point{r.Time, r.StableValue})
+				burst = append(burst, point{r.Time, r.BurstValue})
+			}
+		}
+		page.Autoscalers = append(page.Autoscalers, htmlAutoscaler{
+			Name: name,
+			Charts: []chart{
+				newChart("Pods", start, end, []string{"ready", "desired"}, []string{"#1f77b4", "#d62728"}, ready, desired),
+				newChart("Load", start, end, []string{"stable", "burst"}, []string{"#2ca02c", "#ff7f0e"}, stable, burst),
+			},
+			Records: rs,
+		})
+	}
+	return htmlTemplate.Execute(w, page)
+}
+
+// newChart lays out the series from start to end, scaled to their maximum.
+func newChart(title string, start, end time.Time, names, colors []string, data ...[]point) chart {
+	c := chart{
+		Title:  title,
+		Width:  chartWidth,
+		Height: chartHeight,
+		Left:   chartPadding,
+		Right:  chartWidth - chartPadding/2,
+		Top:    chartPadding / 2,
+		Bottom: chartHeight - chartPadding/2,
+	}
+
+	maxValue := 0.0
+	for _, points := range data {
+		for _, p := range points {
+			maxValue = max(maxValue, p.v)
+		}
+	}
+	if maxValue == 0 {
+		maxValue = 1
+	}
+	c.Max = strconv.FormatFloat(maxValue, 'g', 4, 64)
+
+	span := end.Sub(start)
+	for i, points := range data {
+		coords := make([]string, 0, len(points))
+		for _, p := range points {
+			x := float64(c.Left)
+			if span > 0 {
+				x += float64(c.Right-c.Left) * float64(p.t.Sub(start)) / float64(span)
+			}
+			y := float64(c.Bottom) - float64(c.Bottom-c.Top)*p.v/maxValue
+			coords = append(coords, fmt.Sprintf("%.1f,%.1f", x, y))
+		}
+		c.Series = append(c.Series, series{Name: names[i], Color: colors[i], Points: strings.Join(coords, " ")})
+	}
+	return c
+}
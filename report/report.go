@@ -0,0 +1,249 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package report summarizes scaling decisions, e.g. the records of an audit
+// log, into decision counts, pod count and latency statistics and the time
+// decisions were held back by constraints, optionally sliced by time. It
+// also exports decisions as CSV, JSON or a self-contained HTML page with
+// charts, e.g. for capacity reviews.
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+	"github.com/Fedosin/libkpa/audit"
+	"github.com/Fedosin/libkpa/manager"
+)
+
+// Summary is the summary of a series of scaling decisions.
+type Summary struct {
+	// Start and End bound the summarized period: the times of the first and
+	// the last decision, or the bounds of a time slice.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// Decisions is the number of decisions, Valid the number of those with
+	// a valid recommendation.
+	Decisions int `json:"decisions"`
+	Valid     int `json:"valid"`
+
+	// Reasons are the numbers of decisions by reason, see audit.Reason.
+	Reasons map[string]int `json:"reasons,omitempty"`
+
+	// MinDesired, AvgDesired and MaxDesired describe the desired pod counts
+	// of the valid decisions.
+	MinDesired int32   `json:"minDesired"`
+	AvgDesired float64 `json:"avgDesired"`
+	MaxDesired int32   `json:"maxDesired"`
+
+	// Latency describes how long the decisions took.
+	Latency Latency `json:"latency"`
+
+	// Delays are the total times decisions were held back by a constraint,
+	// from the limited decision to the next decision of the same
+	// autoscaler, by constraint.
+	Delays map[api.ScaleLimit]time.Duration `json:"delays,omitempty"`
+
+	// Self are the counters of the manager the decisions were made by, if
+	// added with AddSelfMetrics.
+	Self *manager.SelfMetrics `json:"self,omitempty"`
+}
+
+// Latency describes the durations of decisions. Decisions without a
+// recorded latency are not included.
+type Latency struct {
+	Count int           `json:"count"`
+	Min   time.Duration `json:"min"`
+	Avg   time.Duration `json:"avg"`
+	Max   time.Duration `json:"max"`
+}
+
+// TotalDelay returns the total time decisions were held back by any
+// constraint.
+func (s Summary) TotalDelay() time.Duration {
+	var total time.Duration
+	for _, d := range s.Delays {
+		total += d
+	}
+	return total
+}
+
+// AddSelfMetrics includes the counters of the manager the decisions were made
+// by, see manager.Manager.SelfMetrics.
+func (s *Summary) AddSelfMetrics(m manager.SelfMetrics) {
+	s.Self = &m
+}
+
+// Collector summarizes records as they are written, so it can be used as
+// the sink of an audit.Autoscaler, or together with other sinks. Collector
+// is safe for concurrent use.
+type Collector struct {
+	mu      sync.Mutex
+	summary Summary
+
+	desiredSum int64
+	latencySum time.Duration
+
+	// limited are the latest records by autoscaler name, if they were held
+	// back by a constraint.
+	limited map[string]audit.Record
+}
+
+var _ audit.Sink = (*Collector)(nil)
+
+// NewCollector creates an empty collector.
+func NewCollector() *Collector {
+	return &Collector{limited: make(map[string]audit.Record)}
+}
+
+// Write adds a record to the summary. Records of each autoscaler must be
+// written in chronological order. It never fails.
+func (c *Collector) Write(r audit.Record) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := &c.summary
+	if s.Decisions == 0 || r.Time.Before(s.Start) {
+		s.Start = r.Time
+	}
+	if s.Decisions == 0 || r.Time.After(s.End) {
+		s.End = r.Time
+	}
+	s.Decisions++
+
+	if s.Reasons == nil {
+		s.Reasons = make(map[string]int)
+	}
+	s.Reasons[r.Reason]++
+
+	if r.ScaleValid {
+		if s.Valid == 0 || r.DesiredPodCount < s.MinDesired {
+			s.MinDesired = r.DesiredPodCount
+		}
+		if s.Valid == 0 || r.DesiredPodCount > s.MaxDesired {
+			s.MaxDesired = r.DesiredPodCount
+		}
+		s.Valid++
+		c.desiredSum += int64(r.DesiredPodCount)
+	}
+
+	if r.Latency > 0 {
+		if s.Latency.Count == 0 || r.Latency < s.Latency.Min {
+			s.Latency.Min = r.Latency
+		}
+		s.Latency.Max = max(s.Latency.Max, r.Latency)
+		s.Latency.Count++
+		c.latencySum += r.Latency
+	}
+
+	if prev, ok := c.limited[r.Name]; ok && r.Time.After(prev.Time) {
+		if s.Delays == nil {
+			s.Delays = make(map[api.ScaleLimit]time.Duration)
+		}
+		s.Delays[prev.LimitedBy] += r.Time.Sub(prev.Time)
+	}
+	if r.LimitedBy != api.LimitNone {
+		c.limited[r.Name] = r
+	} else {
+		delete(c.limited, r.Name)
+	}
+	return nil
+}
+
+// Summary returns the summary of the records written so far.
+func (c *Collector) Summary() Summary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.summary
+	s.Reasons = maps.Clone(s.Reasons)
+	s.Delays = maps.Clone(s.Delays)
+	if s.Valid > 0 {
+		s.AvgDesired = float64(c.desiredSum) / float64(s.Valid)
+	}
+	if s.Latency.Count > 0 {
+		s.Latency.Avg = c.latencySum / time.Duration(s.Latency.Count)
+	}
+	return s
+}
+
+// Summarize returns the summary of the records.
+func Summarize(records []audit.Record) Summary {
+	c := NewCollector()
+	for _, r := range records {
+		_ = c.Write(r)
+	}
+	return c.Summary()
+}
+
+// Slice summarizes the records in slices of the given duration, aligned to
+// multiples of it since the zero time, e.g. to full minutes. Only slices
+// with records are returned, in chronological order. A delay spanning
+// slices counts towards the slice of the decision ending it.
+func Slice(records []audit.Record, d time.Duration) ([]Summary, error) {
+	if d <= 0 {
+		return nil, fmt.Errorf("slice duration = %v, must be positive", d)
+	}
+
+	collectors := make(map[time.Time]*Collector)
+	// limited carries the limited records across slices.
+	limited := make(map[string]audit.Record)
+	for _, r := range records {
+		start := r.Time.Truncate(d)
+		c, ok := collectors[start]
+		if !ok {
+			c = NewCollector()
+			collectors[start] = c
+		}
+		c.limited = limited
+		_ = c.Write(r)
+	}
+
+	starts := slices.SortedFunc(maps.Keys(collectors), time.Time.Compare)
+	summaries := make([]Summary, 0, len(starts))
+	for _, start := range starts {
+		s := collectors[start].Summary()
+		s.Start, s.End = start, start.Add(d)
+		summaries = append(summaries, s)
+	}
+	return summaries, nil
+}
+
+// ReadRecords reads the records of an audit log written by
+// audit.JSONLSink.
+func ReadRecords(r io.Reader) ([]audit.Record, error) {
+	var records []audit.Record
+	dec := json.NewDecoder(r)
+	for {
+		var rec audit.Record
+		err := dec.Decode(&rec)
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit record %d: %w", len(records)+1, err)
+		}
+		records = append(records, rec)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+	"github.com/Fedosin/libkpa/audit"
+	"github.com/Fedosin/libkpa/manager"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// testRecords are decisions of two autoscalers over 90s.
+func testRecords() []audit.Record {
+	return []audit.Record{
+		{Time: start, Name: "web", Reason: audit.ReasonInsufficientData},
+		{Time: start.Add(10 * time.Second), Name: "web", ScaleValid: true, DesiredPodCount: 4, Reason: audit.ReasonScaleUp, LimitedBy: api.LimitProvisioningRate, Latency: 2 * time.Microsecond},
+		{Time: start.Add(20 * time.Second), Name: "api", ScaleValid: true, DesiredPodCount: 2, Reason: audit.ReasonNoChange, Latency: 4 * time.Microsecond},
+		{Time: start.Add(40 * time.Second), Name: "web", ScaleValid: true, DesiredPodCount: 6, Reason: audit.ReasonBurstMode, Latency: 6 * time.Microsecond},
+		{Time: start.Add(70 * time.Second), Name: "web", ScaleValid: true, DesiredPodCount: 3, Reason: audit.ReasonScaleDown, LimitedBy: api.LimitScaleDownFraction},
+		{Time: start.Add(90 * time.Second), Name: "web", ScaleValid: true, DesiredPodCount: 1, Reason: audit.ReasonScaleDown},
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	s := Summarize(testRecords())
+
+	if !s.Start.Equal(start) || !s.End.Equal(start.Add(90*time.Second)) {
+		t.Errorf("period = %v - %v, want %v - %v", s.Start, s.End, start, start.Add(90*time.Second))
+	}
+	if s.Decisions != 6 || s.Valid != 5 {
+		t.Errorf("Decisions, Valid = %d, %d, want 6, 5", s.Decisions, s.Valid)
+	}
+	if s.Reasons[audit.ReasonScaleDown] != 2 || s.Reasons[audit.ReasonInsufficientData] != 1 {
+		t.Errorf("Reasons = %v, want 2 scale-downs and 1 insufficient-data", s.Reasons)
+	}
+	if s.MinDesired != 1 || s.AvgDesired != 3.2 || s.MaxDesired != 6 {
+		t.Errorf("desired = %d, %v, %d, want 1, 3.2, 6", s.MinDesired, s.AvgDesired, s.MaxDesired)
+	}
+	want := Latency{Count: 3, Min: 2 * time.Microsecond, Avg: 4 * time.Microsecond, Max: 6 * time.Microsecond}
+	if s.Latency != want {
+		t.Errorf("Latency = %+v, want %+v", s.Latency, want)
+	}
+
+	// The decisions of web were limited from 10s to 40s and from 70s to
+	// 90s; api's decision in between doesn't end the delay.
+	if s.Delays[api.LimitProvisioningRate] != 30*time.Second || s.Delays[api.LimitScaleDownFraction] != 20*time.Second {
+		t.Errorf("Delays = %v, want 30s of provisioning rate and 20s of scale-down fraction", s.Delays)
+	}
+	if got := s.TotalDelay(); got != 50*time.Second {
+		t.Errorf("TotalDelay() = %v, want 50s", got)
+	}
+}
+
+func TestSlice(t *testing.T) {
+	summaries, err := Slice(testRecords(), time.Minute)
+	if err != nil {
+		t.Fatalf("Slice failed: %v", err)
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("got %d slices, want 2", len(summaries))
+	}
+
+	first, second := summaries[0], summaries[1]
+	if !first.Start.Equal(start) || !second.Start.Equal(start.Add(time.Minute)) || !second.End.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("slices = %v - %v and %v - %v, want full minutes", first.Start, first.End, second.Start, second.End)
+	}
+	if first.Decisions != 4 || second.Decisions != 2 {
+		t.Errorf("decisions = %d, %d, want 4, 2", first.Decisions, second.Decisions)
+	}
+	if first.TotalDelay() != 30*time.Second || second.TotalDelay() != 20*time.Second {
+		t.Errorf("delays = %v, %v, want 30s, 20s", first.TotalDelay(), second.TotalDelay())
+	}
+
+	if _, err := Slice(testRecords(), 0); err == nil {
+		t.Error("Slice with a zero duration succeeded, want an error")
+	}
+}
+
+func TestCollectorAsAuditSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := audit.NewJSONLSink(&buf)
+	for _, r := range testRecords() {
+		if err := sink.Write(r); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	records, err := ReadRecords(&buf)
+	if err != nil {
+		t.Fatalf("ReadRecords failed: %v", err)
+	}
+	c := NewCollector()
+	for _, r := range records {
+		_ = c.Write(r)
+	}
+	got, want := c.Summary(), Summarize(testRecords())
+	if got.Decisions != want.Decisions || got.AvgDesired != want.AvgDesired || got.Latency != want.Latency || got.TotalDelay() != want.TotalDelay() {
+		t.Errorf("summary of the audit log = %+v, want %+v", got, want)
+	}
+
+	if _, err := ReadRecords(strings.NewReader("{\"name\": ")); err == nil {
+		t.Error("ReadRecords of a truncated log succeeded, want an error")
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	s := Summarize(testRecords())
+	s.AddSelfMetrics(manager.SelfMetrics{Scalers: 2, RecordsTotal: 100, RejectedRecordsTotal: 3})
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf, s); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	for _, want := range []string{
+		"Decisions:       6 (5 valid)",
+		"Reasons:         burst-mode=1 insufficient-data=1 no-change=1 scale-down=2 scale-up=1",
+		"Desired pods:    min 1, avg 3.20, max 6",
+		"Latency:         min 2µs, avg 4µs, max 6µs",
+		"Delays:          max-scale-down-fraction=20s provisioning-rate=30s",
+		"Records:         100 (3 rejected)",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report doesn't contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	summaries, err := Slice(testRecords(), time.Minute)
+	if err != nil {
+		t.Fatalf("Slice failed: %v", err)
+	}
+	buf.Reset()
+	if err := WriteTable(&buf, summaries); err != nil {
+		t.Fatalf("WriteTable failed: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 3 {
+		t.Errorf("table has %d lines, want a header and 2 slices:\n%s", len(lines), buf.String())
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+	"github.com/Fedosin/libkpa/audit"
+)
+
+// WriteText writes the summary as a human readable report.
+func WriteText(w io.Writer, s Summary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Period:\t%s - %s (%s)\n", s.Start.Format(time.RFC3339), s.End.Format(time.RFC3339), s.End.Sub(s.Start))
+	fmt.Fprintf(tw, "Decisions:\t%d (%d valid)\n", s.Decisions, s.Valid)
+	fmt.Fprintf(tw, "Reasons:\t%s\n", formatReasons(s.Reasons))
+	if s.Valid > 0 {
+		fmt.Fprintf(tw, "Desired pods:\tmin %d, avg %.2f, max %d\n", s.MinDesired, s.AvgDesired, s.MaxDesired)
+	}
+	if s.Latency.Count > 0 {
+		fmt.Fprintf(tw, "Latency:\tmin %s, avg %s, max %s\n", s.Latency.Min, s.Latency.Avg, s.Latency.Max)
+	}
+	fmt.Fprintf(tw, "Delays:\t%s\n", formatDelays(s.Delays))
+	if m := s.Self; m != nil {
+		fmt.Fprintf(tw, "Scalers:\t%d\n", m.Scalers)
+		fmt.Fprintf(tw, "Records:\t%d (%d rejected)\n", m.RecordsTotal, m.RejectedRecordsTotal)
+		fmt.Fprintf(tw, "Window resizes:\t%d\n", m.WindowResizesTotal)
+		fmt.Fprintf(tw, "Mutex wait:\t%.6fs (%d contentions)\n", m.MutexWaitSeconds, m.MutexContentions)
+	}
+	return tw.Flush()
+}
+
+// WriteTable writes time sliced summaries, as returned by Slice, as a table
+// with one row per slice.
+func WriteTable(w io.Writer, summaries []Summary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "START\tDECISIONS\tUP\tDOWN\tBURST\tINVALID\tAVG PODS\tAVG LATENCY\tDELAY")
+	for _, s := range summaries {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%.2f\t%s\t%s\n",
+			s.Start.Format(time.RFC3339),
+			s.Decisions,
+			s.Reasons[audit.ReasonScaleUp],
+			s.Reasons[audit.ReasonScaleDown],
+			s.Reasons[audit.ReasonBurstMode],
+			s.Reasons[audit.ReasonInsufficientData],
+			s.AvgDesired,
+			s.Latency.Avg,
+			s.TotalDelay(),
+		)
+	}
+	return tw.Flush()
+}
+
+// formatReasons formats the decision counts sorted by reason.
+func formatReasons(reasons map[string]int) string {
+	if len(reasons) == 0 {
+		return "none"
+	}
+	parts := make([]string, 0, len(reasons))
+	for _, reason := range slices.Sorted(maps.Keys(reasons)) {
+		parts = append(parts, fmt.Sprintf("%s=%d", reason, reasons[reason]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatDelays formats the delays sorted by constraint.
+func formatDelays(delays map[api.ScaleLimit]time.Duration) string {
+	if len(delays) == 0 {
+		return "none"
+	}
+	parts := make([]string, 0, len(delays))
+	for _, limit := range slices.Sorted(maps.Keys(delays)) {
+		parts = append(parts, fmt.Sprintf("%s=%s", limit, delays[limit]))
+	}
+	return strings.Join(parts, " ")
+}
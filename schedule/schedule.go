@@ -0,0 +1,334 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schedule implements time based minimum scale schedules, so services
+// can keep a higher floor of pods during business hours of their region and a
+// different one on holidays.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// dateLayout is the layout used for holiday dates.
+	dateLayout = "2006-01-02"
+
+	day = 24 * time.Hour
+)
+
+// Rule raises the minimum scale during a daily time range on the given days.
+type Rule struct {
+	// Days are the days of the week the rule applies to. For ranges spanning
+	// midnight, this is the day the range starts on.
+	Days []time.Weekday
+
+	// Start is the beginning of the range as an offset from midnight.
+	Start time.Duration
+
+	// End is the end of the range (exclusive) as an offset from midnight.
+	// If End is before Start, the range spans midnight.
+	End time.Duration
+
+	// MinScale is the minimum number of pods while the rule is active.
+	MinScale int32
+}
+
+// Config defines a minimum scale schedule.
+type Config struct {
+	// TimeZone is the IANA time zone name the rules and holidays are
+	// evaluated in, e.g. "Europe/Berlin". Default is "UTC".
+	TimeZone string
+
+	// DefaultMinScale is the minimum scale when no rule is active.
+	DefaultMinScale int32
+
+	// Rules are the scheduled minimum scales. If several rules are active at
+	// the same time, the highest minimum scale wins.
+	Rules []Rule
+
+	// Holidays are dates in the "2006-01-02" format on which the rules do not
+	// apply and HolidayMinScale is used instead.
+	Holidays []string
+
+	// HolidayMinScale is the minimum scale on holidays.
+	HolidayMinScale int32
+}
+
+// Schedule computes the minimum scale for a point in time.
+type Schedule struct {
+	location        *time.Location
+	defaultMinScale int32
+	rules           []Rule
+	holidays        map[string]struct{}
+	holidayMinScale int32
+}
+
+// New creates a new Schedule from the given configuration.
+func New(cfg Config) (*Schedule, error) {
+	tz := cfg.TimeZone
+	if tz == "" {
+		tz = "UTC"
+	}
+	location, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", cfg.TimeZone, err)
+	}
+
+	if cfg.DefaultMinScale < 0 {
+		return nil, fmt.Errorf("default-min-scale = %d, must be at least 0", cfg.DefaultMinScale)
+	}
+	if cfg.HolidayMinScale < 0 {
+		return nil, fmt.Errorf("holiday-min-scale = %d, must be at least 0", cfg.HolidayMinScale)
+	}
+
+	for i, r := range cfg.Rules {
+		if len(r.Days) == 0 {
+			return nil, fmt.Errorf("rule %d: at least one day is required", i)
+		}
+		if r.Start < 0 || r.Start >= day || r.End < 0 || r.End > day {
+			return nil, fmt.Errorf("rule %d: start and end must be within a day, got %v-%v", i, r.Start, r.End)
+		}
+		if r.Start == r.End {
+			return nil, fmt.Errorf("rule %d: start and end cannot be equal", i)
+		}
+		if r.MinScale < 0 {
+			return nil, fmt.Errorf("rule %d: min-scale = %d, must be at least 0", i, r.MinScale)
+		}
+	}
+
+	holidays := make(map[string]struct{}, len(cfg.Holidays))
+	for _, h := range cfg.Holidays {
+		d, err := time.Parse(dateLayout, strings.TrimSpace(h))
+		if err != nil {
+			return nil, fmt.Errorf("invalid holiday date %q, expected YYYY-MM-DD", h)
+		}
+		holidays[d.Format(dateLayout)] = struct{}{}
+	}
+
+	return &Schedule{
+		location:        location,
+		defaultMinScale: cfg.DefaultMinScale,
+		rules:           append([]Rule(nil), cfg.Rules...),
+		holidays:        holidays,
+		holidayMinScale: cfg.HolidayMinScale,
+	}, nil
+}
+
+// Location returns the time zone the schedule is evaluated in.
+func (s *Schedule) Location() *time.Location {
+	return s.location
+}
+
+// IsHoliday returns true if the given time falls on a holiday in the
+// schedule's time zone.
+func (s *Schedule) IsHoliday(now time.Time) bool {
+	_, ok := s.holidays[now.In(s.location).Format(dateLayout)]
+	return ok
+}
+
+// MinScale returns the minimum scale that applies at the given time.
+func (s *Schedule) MinScale(now time.Time) int32 {
+	if s.IsHoliday(now) {
+		return s.holidayMinScale
+	}
+
+	// The offset is the wall clock time of day, not the time elapsed since
+	// midnight, which differs by an hour on daylight saving time changes.
+	local := now.In(s.location)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second + time.Duration(local.Nanosecond())
+	weekday := local.Weekday()
+	previous := (weekday + 6) % 7
+
+	result := s.defaultMinScale
+	for _, r := range s.rules {
+		if r.active(weekday, previous, offset) && r.MinScale > result {
+			result = r.MinScale
+		}
+	}
+	return result
+}
+
+// active returns true if the rule covers the given offset from midnight on
+// the given weekday.
+func (r *Rule) active(weekday, previous time.Weekday, offset time.Duration) bool {
+	if r.Start < r.End {
+		return r.hasDay(weekday) && offset >= r.Start && offset < r.End
+	}
+	// The range spans midnight: it either started today or yesterday.
+	return (r.hasDay(weekday) && offset >= r.Start) || (r.hasDay(previous) && offset < r.End)
+}
+
+func (r *Rule) hasDay(d time.Weekday) bool {
+	for _, rd := range r.Days {
+		if rd == d {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadFromMap creates a Schedule from a map of string values.
+//
+// Supported keys are "time-zone", "default-min-scale", "holiday-min-scale",
+// "holidays" (a comma separated list of YYYY-MM-DD dates) and "rules".
+// Rules are separated by semicolons and have the "<days> <HH:MM>-<HH:MM>=<min-scale>"
+// format, where days is "*", a range like "Mon-Fri" or a list like "Sat,Sun".
+// For example: "Mon-Fri 09:00-18:00=10; Sat,Sun 10:00-16:00=4".
+func LoadFromMap(data map[string]string) (*Schedule, error) {
+	cfg := Config{
+		TimeZone: strings.TrimSpace(data["time-zone"]),
+	}
+
+	var err error
+	if cfg.DefaultMinScale, err = parseInt32(data["default-min-scale"]); err != nil {
+		return nil, fmt.Errorf("invalid default-min-scale: %w", err)
+	}
+	if cfg.HolidayMinScale, err = parseInt32(data["holiday-min-scale"]); err != nil {
+		return nil, fmt.Errorf("invalid holiday-min-scale: %w", err)
+	}
+
+	for _, h := range strings.Split(data["holidays"], ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			cfg.Holidays = append(cfg.Holidays, h)
+		}
+	}
+
+	for _, r := range strings.Split(data["rules"], ";") {
+		if r = strings.TrimSpace(r); r == "" {
+			continue
+		}
+		rule, err := parseRule(r)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Rules = append(cfg.Rules, rule)
+	}
+
+	return New(cfg)
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// parseRule parses a single "<days> <HH:MM>-<HH:MM>=<min-scale>" rule.
+func parseRule(value string) (Rule, error) {
+	spec, scale, found := strings.Cut(value, "=")
+	if !found {
+		return Rule{}, fmt.Errorf("invalid rule %q: missing min-scale", value)
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 2 {
+		return Rule{}, fmt.Errorf("invalid rule %q: expected \"<days> <HH:MM>-<HH:MM>=<min-scale>\"", value)
+	}
+
+	days, err := parseDays(fields[0])
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid rule %q: %w", value, err)
+	}
+
+	startValue, endValue, found := strings.Cut(fields[1], "-")
+	if !found {
+		return Rule{}, fmt.Errorf("invalid rule %q: expected time range HH:MM-HH:MM", value)
+	}
+	start, err := parseTimeOfDay(startValue)
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid rule %q: %w", value, err)
+	}
+	end, err := parseTimeOfDay(endValue)
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid rule %q: %w", value, err)
+	}
+
+	minScale, err := parseInt32(scale)
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid rule %q: %w", value, err)
+	}
+
+	return Rule{Days: days, Start: start, End: end, MinScale: minScale}, nil
+}
+
+// parseDays parses "*", "Mon-Fri" or "Sat,Sun" into a list of weekdays.
+func parseDays(value string) ([]time.Weekday, error) {
+	if value == "*" {
+		return []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}, nil
+	}
+
+	if from, to, found := strings.Cut(value, "-"); found {
+		first, ok := weekdays[strings.ToLower(from)]
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q", from)
+		}
+		last, ok := weekdays[strings.ToLower(to)]
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q", to)
+		}
+		var days []time.Weekday
+		for d := first; ; d = (d + 1) % 7 {
+			days = append(days, d)
+			if d == last {
+				break
+			}
+		}
+		return days, nil
+	}
+
+	var days []time.Weekday
+	for _, name := range strings.Split(value, ",") {
+		d, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q", name)
+		}
+		days = append(days, d)
+	}
+	return days, nil
+}
+
+// parseTimeOfDay parses "HH:MM" into an offset from midnight. "24:00" is
+// accepted to denote the end of the day.
+func parseTimeOfDay(value string) (time.Duration, error) {
+	if value == "24:00" {
+		return day, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func parseInt32(value string) (int32, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	i, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid int32 value: %q", value)
+	}
+	return int32(i), nil
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScheduleMinScale(t *testing.T) {
+	sched, err := LoadFromMap(map[string]string{
+		"time-zone":         "Europe/Berlin",
+		"default-min-scale": "1",
+		"holiday-min-scale": "2",
+		"holidays":          "2025-12-25, 2025-12-26",
+		"rules":             "Mon-Fri 09:00-18:00=10; Sat,Sun 10:00-16:00=4; Fri 22:00-02:00=6",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want int32
+	}{
+		{
+			name: "weekday business hours",
+			now:  time.Date(2025, 12, 3, 10, 0, 0, 0, berlin), // Wednesday
+			want: 10,
+		},
+		{
+			name: "weekday business hours expressed in UTC",
+			now:  time.Date(2025, 12, 3, 8, 30, 0, 0, time.UTC), // 09:30 in Berlin
+			want: 10,
+		},
+		{
+			name: "weekday before business hours in local time",
+			now:  time.Date(2025, 12, 3, 8, 30, 0, 0, berlin),
+			want: 1,
+		},
+		{
+			name: "end of range is exclusive",
+			now:  time.Date(2025, 12, 3, 18, 0, 0, 0, berlin),
+			want: 1,
+		},
+		{
+			name: "weekend rule",
+			now:  time.Date(2025, 12, 6, 12, 0, 0, 0, berlin), // Saturday
+			want: 4,
+		},
+		{
+			name: "overnight rule on the starting day",
+			now:  time.Date(2025, 12, 5, 23, 0, 0, 0, berlin), // Friday
+			want: 6,
+		},
+		{
+			name: "overnight rule after midnight",
+			now:  time.Date(2025, 12, 6, 1, 0, 0, 0, berlin), // Saturday
+			want: 6,
+		},
+		{
+			name: "overnight rule does not apply after midnight on other days",
+			now:  time.Date(2025, 12, 4, 1, 0, 0, 0, berlin), // Thursday
+			want: 1,
+		},
+		{
+			name: "holiday overrides rules",
+			now:  time.Date(2025, 12, 25, 10, 0, 0, 0, berlin), // Thursday
+			want: 2,
+		},
+		{
+			name: "holiday is evaluated in the schedule time zone",
+			now:  time.Date(2025, 12, 24, 23, 30, 0, 0, time.UTC), // 00:30 on Dec 25 in Berlin
+			want: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sched.MinScale(tt.now); got != tt.want {
+				t.Errorf("MinScale() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleMinScaleDaylightSavingTime(t *testing.T) {
+	sched, err := LoadFromMap(map[string]string{
+		"time-zone": "Europe/Berlin",
+		"rules":     "* 09:00-18:00=10",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+
+	// Clocks are set forward from 02:00 to 03:00 on 2026-03-29 and back from
+	// 03:00 to 02:00 on 2026-10-25, so these days have 23 and 25 hours.
+	tests := []struct {
+		name string
+		now  time.Time
+		want int32
+	}{
+		{name: "spring forward, in range", now: time.Date(2026, 3, 29, 9, 30, 0, 0, berlin), want: 10},
+		{name: "spring forward, after range", now: time.Date(2026, 3, 29, 18, 30, 0, 0, berlin), want: 0},
+		{name: "spring forward, before range", now: time.Date(2026, 3, 29, 8, 30, 0, 0, berlin), want: 0},
+		{name: "fall back, in range", now: time.Date(2026, 10, 25, 17, 30, 0, 0, berlin), want: 10},
+		{name: "fall back, before range", now: time.Date(2026, 10, 25, 8, 30, 0, 0, berlin), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sched.MinScale(tt.now); got != tt.want {
+				t.Errorf("MinScale(%v) = %d, want %d", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleOverlappingRules(t *testing.T) {
+	sched, err := New(Config{
+		Rules: []Rule{
+			{Days: []time.Weekday{time.Monday}, Start: 8 * time.Hour, End: 12 * time.Hour, MinScale: 3},
+			{Days: []time.Weekday{time.Monday}, Start: 10 * time.Hour, End: 14 * time.Hour, MinScale: 5},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	monday := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	if got := sched.MinScale(monday.Add(9 * time.Hour)); got != 3 {
+		t.Errorf("MinScale() = %d, want 3", got)
+	}
+	if got := sched.MinScale(monday.Add(11 * time.Hour)); got != 5 {
+		t.Errorf("MinScale() = %d, want 5 (highest active rule)", got)
+	}
+	if got := sched.MinScale(monday.Add(15 * time.Hour)); got != 0 {
+		t.Errorf("MinScale() = %d, want 0", got)
+	}
+}
+
+func TestLoadFromMapErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   map[string]string
+		errMsg string
+	}{
+		{
+			name:   "unknown time zone",
+			data:   map[string]string{"time-zone": "Mars/Olympus"},
+			errMsg: "invalid time zone",
+		},
+		{
+			name:   "invalid holiday",
+			data:   map[string]string{"holidays": "25.12.2025"},
+			errMsg: "invalid holiday date",
+		},
+		{
+			name:   "rule without min scale",
+			data:   map[string]string{"rules": "Mon-Fri 09:00-18:00"},
+			errMsg: "missing min-scale",
+		},
+		{
+			name:   "rule with unknown day",
+			data:   map[string]string{"rules": "Funday 09:00-18:00=3"},
+			errMsg: "unknown day",
+		},
+		{
+			name:   "rule with invalid time",
+			data:   map[string]string{"rules": "Mon 9am-18:00=3"},
+			errMsg: "invalid time of day",
+		},
+		{
+			name:   "rule with empty range",
+			data:   map[string]string{"rules": "Mon 09:00-09:00=3"},
+			errMsg: "start and end cannot be equal",
+		},
+		{
+			name:   "negative default min scale",
+			data:   map[string]string{"default-min-scale": "-1"},
+			errMsg: "default-min-scale = -1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFromMap(tt.data)
+			if err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("error = %v, should contain %q", err, tt.errMsg)
+			}
+		})
+	}
+}
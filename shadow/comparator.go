@@ -0,0 +1,174 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package shadow provides a Comparator that runs a shadow autoscaler next to
+// the primary one on the same metric stream and reports how their decisions
+// diverge, so configuration or algorithm changes can be evaluated safely
+// before they are rolled out.
+package shadow
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/api"
+	"github.com/Fedosin/libkpa/manager"
+)
+
+// Stats describes the divergence between the primary and the shadow
+// autoscaler.
+type Stats struct {
+	// Samples is the number of decisions where both autoscalers produced a
+	// valid recommendation.
+	Samples uint64
+
+	// Diverged is the number of those decisions with different pod counts.
+	Diverged uint64
+
+	// ShadowHigher is the number of decisions where the shadow recommended
+	// more pods than the primary.
+	ShadowHigher uint64
+
+	// ShadowLower is the number of decisions where the shadow recommended
+	// fewer pods than the primary.
+	ShadowLower uint64
+
+	// ValidityMismatches is the number of decisions where only one of the
+	// autoscalers produced a valid recommendation.
+	ValidityMismatches uint64
+
+	// MaxAbsDiff is the largest absolute difference in pod counts.
+	MaxAbsDiff int32
+
+	// SumAbsDiff is the sum of the absolute differences in pod counts.
+	SumAbsDiff uint64
+
+	// FirstSample and LastSample are the times of the first and the last
+	// compared decision.
+	FirstSample time.Time
+	LastSample  time.Time
+
+	// LastDivergence is the time of the last decision with different pod
+	// counts.
+	LastDivergence time.Time
+}
+
+// MeanAbsDiff returns the average absolute difference in pod counts.
+func (s Stats) MeanAbsDiff() float64 {
+	if s.Samples == 0 {
+		return 0
+	}
+	return float64(s.SumAbsDiff) / float64(s.Samples)
+}
+
+// Agreement returns the fraction of decisions with equal pod counts in [0, 1].
+// It returns 1 if there were no decisions yet.
+func (s Stats) Agreement() float64 {
+	if s.Samples == 0 {
+		return 1
+	}
+	return float64(s.Samples-s.Diverged) / float64(s.Samples)
+}
+
+// Comparator runs a primary and a shadow autoscaler on the same metrics.
+type Comparator struct {
+	primary *manager.Scaler
+	shadow  *manager.Scaler
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+var _ api.Recorder = (*Comparator)(nil)
+
+// NewComparator creates a comparator for the given autoscalers. They usually
+// differ in configuration or aggregation algorithm, e.g.:
+//
+//	primary, _ := manager.NewScaler("current", currentCfg, "linear")
+//	candidate, _ := manager.NewScaler("candidate", newCfg, "weighted")
+//	cmp, _ := shadow.NewComparator(primary, candidate)
+func NewComparator(primary, shadow *manager.Scaler) (*Comparator, error) {
+	if primary == nil || shadow == nil {
+		return nil, fmt.Errorf("primary and shadow scalers must not be nil")
+	}
+	if primary == shadow {
+		return nil, fmt.Errorf("primary and shadow scalers must be different instances")
+	}
+	return &Comparator{primary: primary, shadow: shadow}, nil
+}
+
+// Record adds a metric value to both autoscalers.
+func (c *Comparator) Record(value float64, t time.Time) {
+	c.primary.Record(value, t)
+	c.shadow.Record(value, t)
+}
+
+// Scale evaluates both autoscalers, updates the divergence statistics and
+// returns both recommendations. Only the primary recommendation should be
+// acted upon.
+func (c *Comparator) Scale(readyPods int32, now time.Time) (primary, shadow api.ScaleRecommendation) {
+	primary = c.primary.Scale(readyPods, now)
+	shadow = c.shadow.Scale(readyPods, now)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if primary.ScaleValid != shadow.ScaleValid {
+		c.stats.ValidityMismatches++
+		return primary, shadow
+	}
+	if !primary.ScaleValid {
+		return primary, shadow
+	}
+
+	if c.stats.Samples == 0 {
+		c.stats.FirstSample = now
+	}
+	c.stats.Samples++
+	c.stats.LastSample = now
+
+	diff := shadow.DesiredPodCount - primary.DesiredPodCount
+	switch {
+	case diff > 0:
+		c.stats.ShadowHigher++
+	case diff < 0:
+		c.stats.ShadowLower++
+		diff = -diff
+	default:
+		return primary, shadow
+	}
+
+	c.stats.Diverged++
+	c.stats.SumAbsDiff += uint64(diff)
+	c.stats.MaxAbsDiff = max(c.stats.MaxAbsDiff, diff)
+	c.stats.LastDivergence = now
+	return primary, shadow
+}
+
+// Stats returns the divergence statistics collected so far.
+func (c *Comparator) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Reset clears the divergence statistics.
+func (c *Comparator) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = Stats{}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shadow
+
+import (
+	"testing"
+	"time"
+
+	libkpaconfig "github.com/Fedosin/libkpa/config"
+	"github.com/Fedosin/libkpa/manager"
+)
+
+func newScaler(t *testing.T, name string, target float64) *manager.Scaler {
+	t.Helper()
+	config := libkpaconfig.NewDefaultAutoscalerConfig()
+	config.TargetValue = target
+	s, err := manager.NewScaler(name, *config, "linear")
+	if err != nil {
+		t.Fatalf("failed to create scaler: %v", err)
+	}
+	return s
+}
+
+func TestNewComparator(t *testing.T) {
+	s := newScaler(t, "a", 100)
+	if _, err := NewComparator(nil, s); err == nil {
+		t.Error("expected error for nil primary")
+	}
+	if _, err := NewComparator(s, nil); err == nil {
+		t.Error("expected error for nil shadow")
+	}
+	if _, err := NewComparator(s, s); err == nil {
+		t.Error("expected error for the same instance")
+	}
+}
+
+func TestComparator(t *testing.T) {
+	now := time.Now()
+	c, err := NewComparator(newScaler(t, "primary", 100), newScaler(t, "shadow", 50))
+	if err != nil {
+		t.Fatalf("NewComparator failed: %v", err)
+	}
+
+	// No metrics yet: both invalid, nothing compared.
+	c.Scale(1, now)
+	if got := c.Stats(); got.Samples != 0 || got.ValidityMismatches != 0 {
+		t.Errorf("Stats() = %+v, want no samples", got)
+	}
+	if got := c.Stats().Agreement(); got != 1 {
+		t.Errorf("Agreement() = %v, want 1", got)
+	}
+
+	c.Record(400, now)
+	primary, shadow := c.Scale(4, now)
+	if primary.DesiredPodCount != 4 || shadow.DesiredPodCount != 8 {
+		t.Errorf("Scale() = %d, %d, want 4, 8", primary.DesiredPodCount, shadow.DesiredPodCount)
+	}
+
+	c.Record(0, now.Add(time.Second))
+	c.Scale(4, now.Add(time.Second))
+
+	got := c.Stats()
+	if got.Samples != 2 || got.Diverged != 2 || got.ShadowHigher != 2 || got.ShadowLower != 0 {
+		t.Errorf("Stats() = %+v", got)
+	}
+	if got.MaxAbsDiff != 4 {
+		t.Errorf("MaxAbsDiff = %d, want 4", got.MaxAbsDiff)
+	}
+	if got.MeanAbsDiff() != float64(got.SumAbsDiff)/2 {
+		t.Errorf("MeanAbsDiff() = %v, want %v", got.MeanAbsDiff(), float64(got.SumAbsDiff)/2)
+	}
+	if got.Agreement() != 0 {
+		t.Errorf("Agreement() = %v, want 0", got.Agreement())
+	}
+	if !got.FirstSample.Equal(now) || !got.LastSample.Equal(now.Add(time.Second)) {
+		t.Errorf("sample times = %v, %v", got.FirstSample, got.LastSample)
+	}
+
+	c.Reset()
+	if got := c.Stats(); got != (Stats{}) {
+		t.Errorf("Stats() after Reset = %+v, want zero", got)
+	}
+}
+
+func TestComparatorAgreement(t *testing.T) {
+	now := time.Now()
+	c, err := NewComparator(newScaler(t, "primary", 100), newScaler(t, "shadow", 100))
+	if err != nil {
+		t.Fatalf("NewComparator failed: %v", err)
+	}
+
+	for i := range 5 {
+		at := now.Add(time.Duration(i) * time.Second)
+		c.Record(300, at)
+		c.Scale(3, at)
+	}
+
+	got := c.Stats()
+	if got.Samples != 5 || got.Diverged != 0 || got.Agreement() != 1 || !got.LastDivergence.IsZero() {
+		t.Errorf("Stats() = %+v, want full agreement", got)
+	}
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transmitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// flusher is implemented by transmitters buffering metrics themselves, like
+// RemoteWriteTransmitter.
+type flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// AsyncTransmitter wraps a MetricTransmitter, so that recording never blocks
+// the caller. Calls are buffered and forwarded to the wrapped transmitter by
+// a background goroutine every flush interval, or as soon as the buffer is
+// full. Calls arriving while the buffer is full are dropped and counted.
+//
+// If the wrapped transmitter has a Flush(context.Context) error method, like
+// RemoteWriteTransmitter, it is called after every forwarded batch.
+type AsyncTransmitter struct {
+	next    MetricTransmitter
+	calls   chan func()
+	full    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	onError func(error)
+
+	closed    atomic.Bool
+	closeOnce sync.Once
+	closeErr  error
+
+	dropped   atomic.Uint64
+	forwarded atomic.Uint64
+}
+
+// NewAsyncTransmitter creates a transmitter buffering up to bufferSize calls
+// and forwarding them to next every interval. Errors of the wrapped
+// transmitter's Flush are passed to onError, which may be nil.
+func NewAsyncTransmitter(next MetricTransmitter, bufferSize int, interval time.Duration, onError func(error)) (*AsyncTransmitter, error) {
+	if next == nil {
+		return nil, errors.New("wrapped transmitter cannot be nil")
+	}
+	if bufferSize <= 0 {
+		return nil, fmt.Errorf("buffer size must be positive, got %d", bufferSize)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("flush interval must be positive, got %v", interval)
+	}
+
+	t := &AsyncTransmitter{
+		next:    next,
+		calls:   make(chan func(), bufferSize),
+		full:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		onError: onError,
+	}
+	go t.run(interval)
+	return t, nil
+}
+
+// Dropped returns the number of calls dropped because the buffer was full.
+func (t *AsyncTransmitter) Dropped() uint64 {
+	return t.dropped.Load()
+}
+
+// Forwarded returns the number of calls forwarded to the wrapped transmitter.
+func (t *AsyncTransmitter) Forwarded() uint64 {
+	return t.forwarded.Load()
+}
+
+// enqueue buffers a call without blocking.
+func (t *AsyncTransmitter) enqueue(call func()) {
+	if t.closed.Load() {
+		return
+	}
+	select {
+	case t.calls <- call:
+	default:
+		t.dropped.Add(1)
+	}
+	if len(t.calls) == cap(t.calls) {
+		select {
+		case t.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// RecordDesiredPods buffers the desired pod count.
+func (t *AsyncTransmitter) RecordDesiredPods(ctx context.Context, value int32) {
+	ctx = context.WithoutCancel(ctx)
+	t.enqueue(func() { t.next.RecordDesiredPods(ctx, value) })
+}
+
+// RecordStableValue buffers the stable window metric value.
+func (t *AsyncTransmitter) RecordStableValue(ctx context.Context, metric string, value float64) {
+	ctx = context.WithoutCancel(ctx)
+	t.enqueue(func() { t.next.RecordStableValue(ctx, metric, value) })
+}
+
+// RecordBurstValue buffers the burst window metric value.
+func (t *AsyncTransmitter) RecordBurstValue(ctx context.Context, metric string, value float64) {
+	ctx = context.WithoutCancel(ctx)
+	t.enqueue(func() { t.next.RecordBurstValue(ctx, metric, value) })
+}
+
+// RecordTargetValue buffers the target metric value.
+func (t *AsyncTransmitter) RecordTargetValue(ctx context.Context, metric string, value float64) {
+	ctx = context.WithoutCancel(ctx)
+	t.enqueue(func() { t.next.RecordTargetValue(ctx, metric, value) })
+}
+
+// RecordBurstMode buffers whether the autoscaler is in burst mode.
+func (t *AsyncTransmitter) RecordBurstMode(ctx context.Context, inBurst bool) {
+	ctx = context.WithoutCancel(ctx)
+	t.enqueue(func() { t.next.RecordBurstMode(ctx, inBurst) })
+}
+
+// RecordGauge buffers an arbitrary gauge metric.
+func (t *AsyncTransmitter) RecordGauge(ctx context.Context, name string, value float64) {
+	ctx = context.WithoutCancel(ctx)
+	t.enqueue(func() { t.next.RecordGauge(ctx, name, value) })
+}
+
+// RecordHistogram buffers an observation of a histogram. It is forwarded as
+// a gauge if the wrapped transmitter doesn't record histograms.
+func (t *AsyncTransmitter) RecordHistogram(ctx context.Context, name string, value float64) {
+	ctx = context.WithoutCancel(ctx)
+	t.enqueue(func() { RecordHistogram(ctx, t.next, name, value) })
+}
+
+// run forwards the buffered calls until Close is called.
+func (t *AsyncTransmitter) run(interval time.Duration) {
+	defer close(t.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			t.flush()
+			return
+		case <-ticker.C:
+			t.flush()
+		case <-t.full:
+			t.flush()
+		}
+	}
+}
+
+// flush forwards the calls buffered so far.
+func (t *AsyncTransmitter) flush() {
+	n := len(t.calls)
+	if n == 0 {
+		return
+	}
+	for range n {
+		call := <-t.calls
+		call()
+	}
+	t.forwarded.Add(uint64(n))
+
+	if f, ok := t.next.(flusher); ok {
+		if err := f.Flush(context.Background()); err != nil && t.onError != nil {
+			t.onError(err)
+		}
+	}
+}
+
+// Close forwards the remaining buffered calls, stops the background
+// goroutine and closes the wrapped transmitter if it has a Close method.
+// Calls recorded after Close are dropped.
+func (t *AsyncTransmitter) Close() error {
+	t.closeOnce.Do(func() {
+		t.closed.Store(true)
+		close(t.stop)
+		<-t.done
+
+		if c, ok := t.next.(io.Closer); ok {
+			t.closeErr = c.Close()
+		}
+	})
+	return t.closeErr
+}
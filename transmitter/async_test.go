@@ -0,0 +1,187 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transmitter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTransmitter records the gauges it receives. If block is set, every
+// call waits until it is closed.
+type fakeTransmitter struct {
+	NoOpTransmitter
+
+	block   chan struct{}
+	mu      sync.Mutex
+	gauges  []string
+	flushes int
+	closed  bool
+}
+
+func (f *fakeTransmitter) RecordGauge(ctx context.Context, name string, value float64) {
+	if f.block != nil {
+		<-f.block
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.gauges = append(f.gauges, name)
+}
+
+func (f *fakeTransmitter) RecordDesiredPods(ctx context.Context, value int32) {
+	f.RecordGauge(ctx, "desired_pods", float64(value))
+}
+
+func (f *fakeTransmitter) Flush(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flushes++
+	return errors.New("flush failed")
+}
+
+func (f *fakeTransmitter) Close() error {
+	f.closed = true
+	return nil
+}
+
+func (f *fakeTransmitter) recorded() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.gauges...)
+}
+
+func TestNewAsyncTransmitter(t *testing.T) {
+	tests := []struct {
+		name       string
+		next       MetricTransmitter
+		bufferSize int
+		interval   time.Duration
+	}{
+		{name: "nil transmitter", next: nil, bufferSize: 1, interval: time.Second},
+		{name: "zero buffer", next: NewNoOpTransmitter(), bufferSize: 0, interval: time.Second},
+		{name: "zero interval", next: NewNoOpTransmitter(), bufferSize: 1, interval: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAsyncTransmitter(tt.next, tt.bufferSize, tt.interval, nil); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestAsyncTransmitterFlushesOnBufferSize(t *testing.T) {
+	next := &fakeTransmitter{}
+	var (
+		mu     sync.Mutex
+		errs   int
+		flushd = make(chan struct{}, 1)
+	)
+	tr, err := NewAsyncTransmitter(next, 2, time.Hour, func(err error) {
+		mu.Lock()
+		errs++
+		mu.Unlock()
+		select {
+		case flushd <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatalf("NewAsyncTransmitter failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tr.RecordDesiredPods(ctx, 3)
+	tr.RecordGauge(ctx, "queue_depth", 7)
+	cancel() // forwarded calls must not depend on the caller's context
+
+	select {
+	case <-flushd:
+	case <-time.After(5 * time.Second):
+		t.Fatal("full buffer was not flushed within 5s")
+	}
+	if got := next.recorded(); len(got) != 2 || got[0] != "desired_pods" || got[1] != "queue_depth" {
+		t.Errorf("forwarded %v, want [desired_pods queue_depth]", got)
+	}
+
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !next.closed {
+		t.Error("wrapped transmitter was not closed")
+	}
+	if got := tr.Forwarded(); got != 2 {
+		t.Errorf("Forwarded() = %d, want 2", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if errs != 1 {
+		t.Errorf("onError called %d times, want 1", errs)
+	}
+}
+
+func TestAsyncTransmitterDropsWhenFull(t *testing.T) {
+	next := &fakeTransmitter{block: make(chan struct{})}
+	tr, err := NewAsyncTransmitter(next, 2, time.Hour, nil)
+	if err != nil {
+		t.Fatalf("NewAsyncTransmitter failed: %v", err)
+	}
+
+	ctx := context.Background()
+	// The buffer fills up and the background goroutine blocks forwarding
+	// the first call.
+	tr.RecordGauge(ctx, "a", 1)
+	tr.RecordGauge(ctx, "b", 2)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(tr.calls) != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The slow backend must not block recording.
+	done := make(chan struct{})
+	go func() {
+		for range 5 {
+			tr.RecordGauge(ctx, "c", 3)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("recording blocked on a slow transmitter")
+	}
+	if got := tr.Dropped(); got != 4 {
+		t.Errorf("Dropped() = %d, want 4", got)
+	}
+
+	close(next.block)
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if got := tr.Forwarded(); got != 3 {
+		t.Errorf("Forwarded() = %d, want 3", got)
+	}
+
+	tr.RecordGauge(ctx, "d", 4)
+	if got := len(next.recorded()); got != 3 {
+		t.Errorf("got %d forwarded calls after Close, want 3", got)
+	}
+}
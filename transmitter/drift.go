@@ -0,0 +1,166 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transmitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DriftGauge is the latest difference between the recommended and the
+	// actual replica count, positive while the actual count lags behind a
+	// scale up.
+	DriftGauge = "replica_drift"
+	// SustainedDriftGauge is 1 while the actual replica count has
+	// persistently diverged from the recommendations and 0 otherwise.
+	SustainedDriftGauge = "sustained_replica_drift"
+	// SustainedDriftCounter counts the sustained drift episodes.
+	SustainedDriftCounter = "sustained_replica_drift_total"
+)
+
+// DriftConfig configures a DriftDetector.
+type DriftConfig struct {
+	// Tolerance is the difference between the recommended and the actual
+	// replica count that is not considered a divergence.
+	Tolerance int32
+	// Duration is how long the replica counts must diverge before the drift
+	// is raised. It should exceed the time the actuator normally needs to
+	// apply a recommendation.
+	Duration time.Duration
+	// ClearAfter is how long the replica counts must agree before the drift
+	// is cleared.
+	ClearAfter time.Duration
+	// OnChange is called when the drift is raised or cleared. It may be nil.
+	OnChange func(DriftEvent)
+}
+
+// DriftEvent describes a sustained drift being raised or cleared.
+type DriftEvent struct {
+	// Drifting is true when the drift is raised and false when it is
+	// cleared.
+	Drifting bool
+	// Time is when the drift was raised or cleared.
+	Time time.Time
+	// Since is when the current run of diverging or agreeing replica counts
+	// started.
+	Since time.Time
+	// Recommended is the latest recommended replica count.
+	Recommended int32
+	// Actual is the latest actual replica count.
+	Actual int32
+}
+
+// DriftDetector compares the replica counts recommended by the autoscaler
+// with the replica counts actually observed on the scale target, to detect
+// an actuator that lags behind or ignores the recommendations, e.g. because
+// of broken wiring or missing permissions. A single mismatch is normal while
+// a recommendation is being applied, so the drift is only raised once the
+// counts have diverged by more than the tolerance for the configured
+// duration, and cleared once they have agreed for the clear duration.
+//
+// With every observation, DriftGauge, SustainedDriftGauge and
+// SustainedDriftCounter are recorded with the transmitter.
+type DriftDetector struct {
+	transmitter MetricTransmitter
+	config      DriftConfig
+
+	mu        sync.Mutex
+	since     time.Time // start of the current run of diverging or agreeing counts
+	diverging bool
+	drifting  bool
+	episodes  uint64
+}
+
+// NewDriftDetector creates a detector recording the drift metrics with the
+// transmitter.
+func NewDriftDetector(transmitter MetricTransmitter, config DriftConfig) (*DriftDetector, error) {
+	if transmitter == nil {
+		return nil, errors.New("transmitter cannot be nil")
+	}
+	if config.Tolerance < 0 {
+		return nil, fmt.Errorf("drift tolerance must be non-negative, got %d", config.Tolerance)
+	}
+	if config.Duration < 0 {
+		return nil, fmt.Errorf("drift duration must be non-negative, got %v", config.Duration)
+	}
+	if config.ClearAfter < 0 {
+		return nil, fmt.Errorf("clear duration must be non-negative, got %v", config.ClearAfter)
+	}
+
+	return &DriftDetector{
+		transmitter: transmitter,
+		config:      config,
+	}, nil
+}
+
+// Observe compares the latest valid recommendation with the replica count
+// observed on the scale target at the given time.
+func (d *DriftDetector) Observe(ctx context.Context, now time.Time, recommended, actual int32) {
+	diff := recommended - actual
+
+	d.mu.Lock()
+	diverging := diff > d.config.Tolerance || -diff > d.config.Tolerance
+	if diverging != d.diverging || d.since.IsZero() {
+		d.diverging = diverging
+		d.since = now
+	}
+	elapsed := now.Sub(d.since)
+	changed := false
+	switch {
+	case !d.drifting && diverging && elapsed >= d.config.Duration:
+		d.drifting = true
+		d.episodes++
+		changed = true
+	case d.drifting && !diverging && elapsed >= d.config.ClearAfter:
+		d.drifting = false
+		changed = true
+	}
+	drifting, episodes, since := d.drifting, d.episodes, d.since
+	d.mu.Unlock()
+
+	d.transmitter.RecordGauge(ctx, DriftGauge, float64(diff))
+	d.transmitter.RecordGauge(ctx, SustainedDriftGauge, boolValue(drifting))
+	d.transmitter.RecordGauge(ctx, SustainedDriftCounter, float64(episodes))
+
+	if changed && d.config.OnChange != nil {
+		d.config.OnChange(DriftEvent{
+			Drifting:    drifting,
+			Time:        now,
+			Since:       since,
+			Recommended: recommended,
+			Actual:      actual,
+		})
+	}
+}
+
+// Drifting returns whether the replica counts have persistently diverged.
+func (d *DriftDetector) Drifting() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.drifting
+}
+
+// Episodes returns the number of sustained drift episodes so far.
+func (d *DriftDetector) Episodes() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.episodes
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transmitter
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewDriftDetector(t *testing.T) {
+	noop := NewNoOpTransmitter()
+	tests := []struct {
+		name   string
+		next   MetricTransmitter
+		config DriftConfig
+	}{
+		{name: "nil transmitter", next: nil},
+		{name: "negative tolerance", next: noop, config: DriftConfig{Tolerance: -1}},
+		{name: "negative duration", next: noop, config: DriftConfig{Duration: -time.Second}},
+		{name: "negative clear duration", next: noop, config: DriftConfig{ClearAfter: -time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewDriftDetector(tt.next, tt.config); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestDriftDetector(t *testing.T) {
+	var events []DriftEvent
+	d, err := NewDriftDetector(NewNoOpTransmitter(), DriftConfig{
+		Tolerance:  1,
+		Duration:   5 * time.Minute,
+		ClearAfter: 2 * time.Minute,
+		OnChange:   func(e DriftEvent) { events = append(events, e) },
+	})
+	if err != nil {
+		t.Fatalf("NewDriftDetector failed: %v", err)
+	}
+	start := time.Unix(1700000000, 0)
+	ctx := context.Background()
+
+	steps := []struct {
+		at          time.Duration
+		recommended int32
+		actual      int32
+		drifting    bool
+		episodes    uint64
+	}{
+		{at: 0, recommended: 5, actual: 5},
+		// The actuator applies a scale up within the duration.
+		{at: time.Minute, recommended: 10, actual: 5},
+		{at: 2 * time.Minute, recommended: 10, actual: 10},
+		// Differences within the tolerance are not a divergence.
+		{at: 3 * time.Minute, recommended: 11, actual: 10},
+		// The actuator ignores a scale down.
+		{at: 4 * time.Minute, recommended: 6, actual: 10},
+		{at: 8 * time.Minute, recommended: 6, actual: 10},
+		{at: 9 * time.Minute, recommended: 4, actual: 10, drifting: true, episodes: 1},
+		// Brief agreement doesn't clear the drift.
+		{at: 10 * time.Minute, recommended: 10, actual: 10, drifting: true, episodes: 1},
+		{at: 11 * time.Minute, recommended: 4, actual: 10, drifting: true, episodes: 1},
+		{at: 12 * time.Minute, recommended: 4, actual: 4, drifting: true, episodes: 1},
+		{at: 14 * time.Minute, recommended: 4, actual: 4, episodes: 1},
+		{at: 15 * time.Minute, recommended: 8, actual: 4, episodes: 1},
+		{at: 20 * time.Minute, recommended: 8, actual: 4, drifting: true, episodes: 2},
+	}
+
+	for _, s := range steps {
+		d.Observe(ctx, start.Add(s.at), s.recommended, s.actual)
+		if got := d.Drifting(); got != s.drifting {
+			t.Errorf("at %v: Drifting() = %v, want %v", s.at, got, s.drifting)
+		}
+		if got := d.Episodes(); got != s.episodes {
+			t.Errorf("at %v: Episodes() = %d, want %d", s.at, got, s.episodes)
+		}
+	}
+
+	want := []DriftEvent{
+		{Drifting: true, Time: start.Add(9 * time.Minute), Since: start.Add(4 * time.Minute), Recommended: 4, Actual: 10},
+		{Drifting: false, Time: start.Add(14 * time.Minute), Since: start.Add(12 * time.Minute), Recommended: 4, Actual: 4},
+		{Drifting: true, Time: start.Add(20 * time.Minute), Since: start.Add(15 * time.Minute), Recommended: 8, Actual: 4},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+}
+
+func TestDriftDetectorRecordsGauges(t *testing.T) {
+	var buf bytes.Buffer
+	d, err := NewDriftDetector(NewLogTransmitter(log.New(&buf, "", 0), Labels{"service": "web"}), DriftConfig{})
+	if err != nil {
+		t.Fatalf("NewDriftDetector failed: %v", err)
+	}
+
+	d.Observe(context.Background(), time.Unix(1700000000, 0), 3, 5)
+
+	want := []string{
+		"metric: replica_drift{service=web} = -2.00",
+		"metric: sustained_replica_drift{service=web} = 1.00",
+		"metric: sustained_replica_drift_total{service=web} = 1.00",
+	}
+	if got := strings.Split(strings.TrimSpace(buf.String()), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
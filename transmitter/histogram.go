@@ -0,0 +1,96 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transmitter
+
+import (
+	"context"
+	"slices"
+	"strconv"
+)
+
+// ScaleLatencyMetric is the name of the histogram of the durations of the
+// autoscaler's scaling decisions in seconds.
+const ScaleLatencyMetric = "scale_latency_seconds"
+
+// LatencyBuckets are the upper bounds in seconds of the histogram buckets
+// used for ScaleLatencyMetric. A scaling decision normally takes a few
+// microseconds, so the buckets range from 1µs to 100ms.
+var LatencyBuckets = []float64{1e-6, 2.5e-6, 5e-6, 1e-5, 2.5e-5, 5e-5, 1e-4, 2.5e-4, 5e-4, 1e-3, 1e-2, 1e-1}
+
+// HistogramRecorder is implemented by transmitters that can record
+// observations into histograms, rather than only the latest value of a
+// gauge.
+type HistogramRecorder interface {
+	// RecordHistogram adds an observation to the histogram with the given
+	// name.
+	RecordHistogram(ctx context.Context, name string, value float64)
+}
+
+// RecordHistogram adds an observation to the histogram with the given name
+// if t implements HistogramRecorder, and records it as a gauge otherwise.
+func RecordHistogram(ctx context.Context, t MetricTransmitter, name string, value float64) {
+	if h, ok := t.(HistogramRecorder); ok {
+		h.RecordHistogram(ctx, name, value)
+		return
+	}
+	t.RecordGauge(ctx, name, value)
+}
+
+// histogram accumulates observations into cumulative buckets, like a
+// Prometheus histogram.
+type histogram struct {
+	bounds []float64
+	counts []uint64 // counts[i] is the number of observations <= bounds[i]
+	sum    float64
+	count  uint64
+}
+
+// newHistogram creates a histogram with the given bucket upper bounds.
+func newHistogram(bounds []float64) *histogram {
+	bounds = slices.Sorted(slices.Values(bounds))
+	return &histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)),
+	}
+}
+
+// observe adds an observation.
+func (h *histogram) observe(value float64) {
+	for i := len(h.bounds) - 1; i >= 0 && value <= h.bounds[i]; i-- {
+		h.counts[i]++
+	}
+	h.sum += value
+	h.count++
+}
+
+// bucketLabel returns the "le" label of the i-th bucket, where the bucket
+// past the bounds is the +Inf one.
+func (h *histogram) bucketLabel(i int) string {
+	if i == len(h.bounds) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(h.bounds[i], 'g', -1, 64)
+}
+
+// bucketCount returns the cumulative count of the i-th bucket, where the
+// bucket past the bounds is the +Inf one.
+func (h *histogram) bucketCount(i int) uint64 {
+	if i == len(h.bounds) {
+		return h.count
+	}
+	return h.counts[i]
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transmitter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecordHistogramFallsBackToGauge(t *testing.T) {
+	fake := &fakeTransmitter{}
+	// Hide the RecordHistogram method of the embedded NoOpTransmitter.
+	gaugesOnly := struct{ MetricTransmitter }{fake}
+
+	RecordHistogram(context.Background(), gaugesOnly, ScaleLatencyMetric, 0.001)
+	if got := fake.recorded(); !slices.Equal(got, []string{ScaleLatencyMetric}) {
+		t.Errorf("recorded gauges = %v, want [%s]", got, ScaleLatencyMetric)
+	}
+}
+
+func TestHistogramObserve(t *testing.T) {
+	h := newHistogram([]float64{10, 1, 5})
+	for _, v := range []float64{0.5, 1, 3, 7, 20} {
+		h.observe(v)
+	}
+
+	wantLabels := []string{"1", "5", "10", "+Inf"}
+	wantCounts := []uint64{2, 3, 4, 5}
+	for i := range wantLabels {
+		if got := h.bucketLabel(i); got != wantLabels[i] {
+			t.Errorf("bucket %d label = %q, want %q", i, got, wantLabels[i])
+		}
+		if got := h.bucketCount(i); got != wantCounts[i] {
+			t.Errorf("bucket %d count = %d, want %d", i, got, wantCounts[i])
+		}
+	}
+	if h.sum != 31.5 || h.count != 5 {
+		t.Errorf("sum, count = %v, %d, want 31.5, 5", h.sum, h.count)
+	}
+}
+
+func TestRemoteWriteTransmitterHistogram(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	tr, err := NewRemoteWriteTransmitter(server.URL, server.Client(), KubernetesLabels("default", "web"))
+	if err != nil {
+		t.Fatalf("NewRemoteWriteTransmitter failed: %v", err)
+	}
+	tr.now = func() time.Time { return time.UnixMilli(1700000000000) }
+
+	ctx := context.Background()
+	tr.RecordHistogram(ctx, ScaleLatencyMetric, 3e-6)
+	tr.RecordHistogram(ctx, ScaleLatencyMetric, 2e-3)
+	if err := tr.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	values := map[string]float64{}
+	for _, s := range decodeWriteRequest(t, body) {
+		if s.labels["namespace"] != "default" || s.labels["service"] != "web" {
+			t.Errorf("series %v is missing the transmitter labels", s.labels)
+		}
+		values[s.labels["__name__"]+"/"+s.labels["le"]] = s.value
+	}
+	if got, want := len(values), len(LatencyBuckets)+3; got != want {
+		t.Errorf("got %d series, want %d", got, want)
+	}
+	for key, want := range map[string]float64{
+		ScaleLatencyMetric + "_bucket/2.5e-06": 0,
+		ScaleLatencyMetric + "_bucket/5e-06":   1,
+		ScaleLatencyMetric + "_bucket/0.001":   1,
+		ScaleLatencyMetric + "_bucket/0.01":    2,
+		ScaleLatencyMetric + "_bucket/+Inf":    2,
+		ScaleLatencyMetric + "_sum/":           2.003e-3,
+		ScaleLatencyMetric + "_count/":         2,
+	} {
+		if got, ok := values[key]; !ok || got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+}
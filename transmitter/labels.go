@@ -0,0 +1,96 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transmitter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Labels identify the autoscaled workload in transmitted metrics. They are
+// bound to a transmitter at construction, so that callers are not tied to
+// any particular naming scheme.
+type Labels map[string]string
+
+// KubernetesLabels returns the labels of a Kubernetes service.
+func KubernetesLabels(namespace, service string) Labels {
+	return Labels{"namespace": namespace, "service": service}
+}
+
+// Merge returns a copy of the labels with other added. Labels of other take
+// precedence.
+func (l Labels) Merge(other Labels) Labels {
+	ret := make(Labels, len(l)+len(other))
+	for k, v := range l {
+		ret[k] = v
+	}
+	for k, v := range other {
+		ret[k] = v
+	}
+	return ret
+}
+
+// Names returns the sorted label names.
+func (l Labels) Names() []string {
+	names := make([]string, 0, len(l))
+	for k := range l {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String returns the labels sorted by name, like {namespace=default,service=web}.
+func (l Labels) String() string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, k := range l.Names() {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(l[k])
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// Validate ensures the label names are valid Prometheus label names.
+func (l Labels) Validate() error {
+	for k := range l {
+		if !validName(k) || strings.HasPrefix(k, "__") {
+			return fmt.Errorf("invalid label name %q", k)
+		}
+	}
+	return nil
+}
+
+// validName returns whether name matches [a-zA-Z_][a-zA-Z0-9_]*.
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transmitter
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+)
+
+func TestLabelsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  Labels
+		wantErr bool
+	}{
+		{name: "nil", labels: nil},
+		{name: "kubernetes", labels: KubernetesLabels("default", "web")},
+		{name: "underscore and digits", labels: Labels{"_zone1": "a"}},
+		{name: "empty name", labels: Labels{"": "a"}, wantErr: true},
+		{name: "leading digit", labels: Labels{"1zone": "a"}, wantErr: true},
+		{name: "dash", labels: Labels{"cluster-name": "a"}, wantErr: true},
+		{name: "reserved", labels: Labels{"__name__": "a"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.labels.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLabelsMerge(t *testing.T) {
+	base := KubernetesLabels("default", "web")
+	merged := base.Merge(Labels{"service": "api", "zone": "a"})
+
+	if got, want := merged.String(), "{namespace=default,service=api,zone=a}"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := base.String(), "{namespace=default,service=web}"; got != want {
+		t.Errorf("Merge modified the receiver: %q, want %q", got, want)
+	}
+}
+
+func TestLogTransmitter(t *testing.T) {
+	var buf bytes.Buffer
+	tr := NewLogTransmitter(log.New(&buf, "", 0), KubernetesLabels("default", "web"))
+	ctx := context.Background()
+
+	tr.RecordDesiredPods(ctx, 3)
+	tr.WithLabels(Labels{"zone": "a"}).RecordGauge(ctx, "queue_depth", 7)
+	tr.Close()
+	tr.RecordBurstMode(ctx, true)
+
+	want := "metric: desired_pods{namespace=default,service=web} = 3\n" +
+		"metric: queue_depth{namespace=default,service=web,zone=a} = 7.00\n"
+	if got := buf.String(); got != want {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
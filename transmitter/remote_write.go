@@ -0,0 +1,319 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transmitter
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Fedosin/libkpa/internal/protowire"
+)
+
+// seriesKey identifies a time series of the remote write transmitter.
+type seriesKey struct {
+	name   string
+	labels string
+}
+
+// sample is the latest value of a time series.
+type sample struct {
+	labels    Labels
+	value     float64
+	timestamp time.Time
+}
+
+// remoteWriteBuffer holds the samples pending to be pushed, shared by all
+// transmitters derived with WithLabels.
+type remoteWriteBuffer struct {
+	mu         sync.Mutex
+	pending    map[seriesKey]sample
+	histograms map[seriesKey]*histogram
+	closed     bool
+}
+
+// RemoteWriteTransmitter buffers autoscaler metrics and pushes them to a
+// Prometheus remote write endpoint, e.g. of Prometheus, Thanos, Cortex,
+// Mimir or VictoriaMetrics. Only the latest value of each series is kept
+// between pushes. Series are named like the output of LogTransmitter and
+// carry the labels bound to the transmitter.
+type RemoteWriteTransmitter struct {
+	url    string
+	client *http.Client
+	now    func() time.Time
+	labels Labels
+	buffer *remoteWriteBuffer
+}
+
+// NewRemoteWriteTransmitter creates a transmitter pushing to the remote write
+// endpoint at endpoint, which adds the given labels to all series. If client
+// is nil, http.DefaultClient is used.
+func NewRemoteWriteTransmitter(endpoint string, client *http.Client, labels Labels) (*RemoteWriteTransmitter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote write URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid remote write URL %q: scheme must be http or https", endpoint)
+	}
+	if err := labels.Validate(); err != nil {
+		return nil, err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &RemoteWriteTransmitter{
+		url:    endpoint,
+		client: client,
+		now:    time.Now,
+		labels: labels.Merge(nil),
+		buffer: &remoteWriteBuffer{
+			pending:    make(map[seriesKey]sample),
+			histograms: make(map[seriesKey]*histogram),
+		},
+	}, nil
+}
+
+// WithLabels returns a transmitter with the given labels added, which shares
+// the endpoint and the buffer with t. This allows reporting many workloads
+// in a single push. Flush and Close of either transmitter apply to both.
+func (t *RemoteWriteTransmitter) WithLabels(labels Labels) (*RemoteWriteTransmitter, error) {
+	if err := labels.Validate(); err != nil {
+		return nil, err
+	}
+	derived := *t
+	derived.labels = t.labels.Merge(labels)
+	return &derived, nil
+}
+
+// RecordGauge buffers the latest value of an arbitrary gauge.
+func (t *RemoteWriteTransmitter) RecordGauge(ctx context.Context, name string, value float64) {
+	t.buffer.mu.Lock()
+	defer t.buffer.mu.Unlock()
+
+	if t.buffer.closed {
+		return
+	}
+	key := seriesKey{name: name, labels: t.labels.String()}
+	t.buffer.pending[key] = sample{labels: t.labels, value: value, timestamp: t.now()}
+}
+
+// RecordDesiredPods buffers the desired pod count.
+func (t *RemoteWriteTransmitter) RecordDesiredPods(ctx context.Context, value int32) {
+	t.RecordGauge(ctx, "desired_pods", float64(value))
+}
+
+// RecordStableValue buffers the stable window metric value.
+func (t *RemoteWriteTransmitter) RecordStableValue(ctx context.Context, metric string, value float64) {
+	t.RecordGauge(ctx, "stable_"+metric, value)
+}
+
+// RecordBurstValue buffers the burst window metric value.
+func (t *RemoteWriteTransmitter) RecordBurstValue(ctx context.Context, metric string, value float64) {
+	t.RecordGauge(ctx, "burst_"+metric, value)
+}
+
+// RecordTargetValue buffers the target metric value.
+func (t *RemoteWriteTransmitter) RecordTargetValue(ctx context.Context, metric string, value float64) {
+	t.RecordGauge(ctx, "target_"+metric, value)
+}
+
+// RecordBurstMode buffers whether the autoscaler is in burst mode as 0 or 1.
+func (t *RemoteWriteTransmitter) RecordBurstMode(ctx context.Context, inBurst bool) {
+	t.RecordGauge(ctx, "burst_mode", boolValue(inBurst))
+}
+
+// RecordHistogram adds an observation to a histogram with the buckets of
+// LatencyBuckets. The histogram is pushed like a Prometheus histogram, as
+// cumulative name_bucket series with an "le" label, name_sum and name_count.
+func (t *RemoteWriteTransmitter) RecordHistogram(ctx context.Context, name string, value float64) {
+	t.buffer.mu.Lock()
+	defer t.buffer.mu.Unlock()
+
+	if t.buffer.closed {
+		return
+	}
+	key := seriesKey{name: name, labels: t.labels.String()}
+	h, ok := t.buffer.histograms[key]
+	if !ok {
+		h = newHistogram(LatencyBuckets)
+		t.buffer.histograms[key] = h
+	}
+	h.observe(value)
+
+	now := t.now()
+	for i := range len(h.bounds) + 1 {
+		labels := t.labels.Merge(Labels{"le": h.bucketLabel(i)})
+		bucket := seriesKey{name: name + "_bucket", labels: labels.String()}
+		t.buffer.pending[bucket] = sample{labels: labels, value: float64(h.bucketCount(i)), timestamp: now}
+	}
+	t.buffer.pending[seriesKey{name: name + "_sum", labels: key.labels}] = sample{labels: t.labels, value: h.sum, timestamp: now}
+	t.buffer.pending[seriesKey{name: name + "_count", labels: key.labels}] = sample{labels: t.labels, value: float64(h.count), timestamp: now}
+}
+
+// Flush pushes all buffered samples to the remote write endpoint. If the push
+// fails, the samples stay buffered and are retried by the next Flush, unless
+// newer values are recorded for the same series in the meantime.
+func (t *RemoteWriteTransmitter) Flush(ctx context.Context) error {
+	t.buffer.mu.Lock()
+	batch := t.buffer.pending
+	t.buffer.pending = make(map[seriesKey]sample)
+	t.buffer.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if err := t.push(ctx, batch); err != nil {
+		t.buffer.mu.Lock()
+		for k, s := range batch {
+			if _, exists := t.buffer.pending[k]; !exists {
+				t.buffer.pending[k] = s
+			}
+		}
+		t.buffer.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes the buffered samples every interval until ctx is done.
+// Push errors are passed to onError, which may be nil. Call Close after Run
+// returns to push the remaining samples.
+func (t *RemoteWriteTransmitter) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Errors caused by the cancellation of ctx are not reported.
+			if err := t.Flush(ctx); err != nil && ctx.Err() == nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Close flushes the buffered samples and stops buffering new ones.
+func (t *RemoteWriteTransmitter) Close() error {
+	t.buffer.mu.Lock()
+	t.buffer.closed = true
+	t.buffer.mu.Unlock()
+
+	return t.Flush(context.Background())
+}
+
+// push sends the samples in a single remote write request.
+func (t *RemoteWriteTransmitter) push(ctx context.Context, batch map[seriesKey]sample) error {
+	keys := make([]seriesKey, 0, len(batch))
+	for k := range batch {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].labels < keys[j].labels
+	})
+
+	var body []byte
+	for _, k := range keys {
+		body = protowire.AppendBytesField(body, 1, encodeTimeSeries(k, batch[k]))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(snappyEncode(body)))
+	if err != nil {
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote write failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("remote write failed with status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// encodeTimeSeries encodes a prometheus.TimeSeries message with a single
+// sample. Labels are sorted by name, as required by the protocol.
+func encodeTimeSeries(k seriesKey, s sample) []byte {
+	var ts []byte
+	// __name__ sorts among the other labels, e.g. after uppercase names.
+	names := append(s.labels.Names(), "__name__")
+	sort.Strings(names)
+	for _, name := range names {
+		value := s.labels[name]
+		if name == "__name__" {
+			value = k.name
+		}
+		var label []byte
+		label = protowire.AppendBytesField(label, 1, []byte(name))
+		label = protowire.AppendBytesField(label, 2, []byte(value))
+		ts = protowire.AppendBytesField(ts, 1, label)
+	}
+
+	var smp []byte
+	smp = protowire.AppendTag(smp, 1, protowire.Fixed64)
+	smp = binary.LittleEndian.AppendUint64(smp, math.Float64bits(s.value))
+	smp = protowire.AppendTag(smp, 2, protowire.Varint)
+	smp = binary.AppendUvarint(smp, uint64(s.timestamp.UnixMilli()))
+	return protowire.AppendBytesField(ts, 2, smp)
+}
+
+// snappyEncode encodes data in the snappy block format using only literal
+// chunks. The output is not compressed, but is valid input for any snappy
+// decoder, which is all the remote write protocol requires.
+func snappyEncode(data []byte) []byte {
+	const maxChunk = 1 << 16
+
+	out := binary.AppendUvarint(make([]byte, 0, len(data)+len(data)/maxChunk*3+16), uint64(len(data)))
+	for len(data) > 0 {
+		n := min(len(data), maxChunk)
+		switch {
+		case n <= 60:
+			out = append(out, byte(n-1)<<2)
+		case n <= 256:
+			out = append(out, 60<<2, byte(n-1))
+		default:
+			out = append(out, 61<<2, byte(n-1), byte((n-1)>>8))
+		}
+		out = append(out, data[:n]...)
+		data = data[n:]
+	}
+	return out
+}
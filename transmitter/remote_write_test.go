@@ -0,0 +1,294 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transmitter
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// snappyDecode decodes snappy blocks consisting of literal chunks only.
+func snappyDecode(t *testing.T, data []byte) []byte {
+	t.Helper()
+	n, read := binary.Uvarint(data)
+	data = data[read:]
+	var out []byte
+	for len(data) > 0 {
+		tag := data[0]
+		if tag&3 != 0 {
+			t.Fatalf("unexpected non-literal chunk tag %#x", tag)
+		}
+		length := int(tag>>2) + 1
+		data = data[1:]
+		switch tag >> 2 {
+		case 60:
+			length = int(data[0]) + 1
+			data = data[1:]
+		case 61:
+			length = int(data[0]) | int(data[1])<<8 + 1
+			data = data[2:]
+		}
+		out = append(out, data[:length]...)
+		data = data[length:]
+	}
+	if uint64(len(out)) != n {
+		t.Fatalf("decoded %d bytes, preamble says %d", len(out), n)
+	}
+	return out
+}
+
+// field is a decoded protobuf field.
+type field struct {
+	num   int
+	bytes []byte
+	fixed uint64
+	varin uint64
+}
+
+// decodeFields decodes a protobuf message into its fields.
+func decodeFields(t *testing.T, data []byte) []field {
+	t.Helper()
+	var fields []field
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		data = data[n:]
+		f := field{num: int(tag >> 3)}
+		switch tag & 7 {
+		case 0:
+			f.varin, n = binary.Uvarint(data)
+			data = data[n:]
+		case 1:
+			f.fixed = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case 2:
+			l, n := binary.Uvarint(data)
+			data = data[n:]
+			f.bytes = data[:l]
+			data = data[l:]
+		default:
+			t.Fatalf("unexpected wire type %d", tag&7)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// series is a decoded time series with a single sample.
+type series struct {
+	labels    map[string]string
+	value     float64
+	timestamp int64
+}
+
+func decodeWriteRequest(t *testing.T, body []byte) []series {
+	t.Helper()
+	var result []series
+	for _, ts := range decodeFields(t, snappyDecode(t, body)) {
+		s := series{labels: map[string]string{}}
+		for _, f := range decodeFields(t, ts.bytes) {
+			switch f.num {
+			case 1:
+				label := decodeFields(t, f.bytes)
+				s.labels[string(label[0].bytes)] = string(label[1].bytes)
+			case 2:
+				smp := decodeFields(t, f.bytes)
+				s.value = math.Float64frombits(smp[0].fixed)
+				s.timestamp = int64(smp[1].varin)
+			}
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func TestSnappyEncode(t *testing.T) {
+	for _, n := range []int{0, 1, 60, 61, 256, 257, 1 << 16, 1<<16 + 1, 200000} {
+		data := bytes.Repeat([]byte{'x'}, n)
+		if got := snappyDecode(t, snappyEncode(data)); !bytes.Equal(got, data) {
+			t.Errorf("round trip of %d bytes failed", n)
+		}
+	}
+}
+
+func TestNewRemoteWriteTransmitter(t *testing.T) {
+	for _, u := range []string{"", "ftp://example.com", "://bad"} {
+		if _, err := NewRemoteWriteTransmitter(u, nil, nil); err == nil {
+			t.Errorf("expected error for URL %q", u)
+		}
+	}
+	if _, err := NewRemoteWriteTransmitter("http://example.com", nil, Labels{"__name__": "x"}); err == nil {
+		t.Error("expected error for a reserved label name")
+	}
+}
+
+func TestEncodeTimeSeriesLabelOrder(t *testing.T) {
+	s := sample{labels: Labels{"service": "web", "Zone": "a", "_tier": "gold"}, value: 1, timestamp: time.UnixMilli(1)}
+	var names []string
+	for _, f := range decodeFields(t, encodeTimeSeries(seriesKey{name: "desired_pods"}, s)) {
+		if f.num == 1 {
+			names = append(names, string(decodeFields(t, f.bytes)[0].bytes))
+		}
+	}
+	want := []string{"Zone", "__name__", "_tier", "service"}
+	if !slices.Equal(names, want) {
+		t.Errorf("label names = %v, want %v", names, want)
+	}
+}
+
+func TestRemoteWriteTransmitter(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests [][]byte
+		status   = http.StatusNoContent
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Encoding"); got != "snappy" {
+			t.Errorf("Content-Encoding = %q, want snappy", got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/x-protobuf" {
+			t.Errorf("Content-Type = %q, want application/x-protobuf", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	tr, err := NewRemoteWriteTransmitter(server.URL, server.Client(), KubernetesLabels("default", "web"))
+	if err != nil {
+		t.Fatalf("NewRemoteWriteTransmitter failed: %v", err)
+	}
+	now := time.UnixMilli(1700000000000)
+	tr.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	tr.RecordDesiredPods(ctx, 3)
+	tr.RecordStableValue(ctx, "concurrency", 1.5)
+	tr.RecordStableValue(ctx, "concurrency", 2.5) // latest value wins
+	tr.RecordBurstMode(ctx, true)
+	other, err := tr.WithLabels(Labels{"service": "api", "zone": "a"})
+	if err != nil {
+		t.Fatalf("WithLabels failed: %v", err)
+	}
+	other.RecordGauge(ctx, "queue_depth", 7)
+
+	// A failed push keeps the samples for the next flush.
+	setStatus := func(code int) {
+		mu.Lock()
+		defer mu.Unlock()
+		status = code
+	}
+	setStatus(http.StatusInternalServerError)
+	if err := tr.Flush(ctx); err == nil {
+		t.Fatal("expected error for a failed push")
+	}
+	setStatus(http.StatusNoContent)
+	if err := tr.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	got := decodeWriteRequest(t, requests[1])
+	want := []series{
+		{labels: map[string]string{"__name__": "burst_mode", "namespace": "default", "service": "web"}, value: 1},
+		{labels: map[string]string{"__name__": "desired_pods", "namespace": "default", "service": "web"}, value: 3},
+		{labels: map[string]string{"__name__": "queue_depth", "namespace": "default", "service": "api", "zone": "a"}, value: 7},
+		{labels: map[string]string{"__name__": "stable_concurrency", "namespace": "default", "service": "web"}, value: 2.5},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d series, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if len(got[i].labels) != len(want[i].labels) {
+			t.Errorf("series %d labels = %v, want %v", i, got[i].labels, want[i].labels)
+		}
+		for k, v := range want[i].labels {
+			if got[i].labels[k] != v {
+				t.Errorf("series %d label %s = %q, want %q", i, k, got[i].labels[k], v)
+			}
+		}
+		if got[i].value != want[i].value {
+			t.Errorf("series %d value = %v, want %v", i, got[i].value, want[i].value)
+		}
+		if got[i].timestamp != now.UnixMilli() {
+			t.Errorf("series %d timestamp = %d, want %d", i, got[i].timestamp, now.UnixMilli())
+		}
+	}
+
+	// Nothing buffered: no request.
+	if err := tr.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if len(requests) != 2 {
+		t.Errorf("got %d requests, want 2", len(requests))
+	}
+
+	// Close flushes and stops buffering.
+	tr.RecordTargetValue(ctx, "concurrency", 100)
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	tr.RecordDesiredPods(ctx, 5)
+	if err := tr.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if len(requests) != 3 {
+		t.Errorf("got %d requests, want 3", len(requests))
+	}
+}
+
+func TestRemoteWriteTransmitterRun(t *testing.T) {
+	pushed := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case pushed <- struct{}{}:
+		default:
+		}
+	}))
+	defer server.Close()
+
+	tr, err := NewRemoteWriteTransmitter(server.URL, server.Client(), KubernetesLabels("default", "web"))
+	if err != nil {
+		t.Fatalf("NewRemoteWriteTransmitter failed: %v", err)
+	}
+	tr.RecordDesiredPods(context.Background(), 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		tr.Run(ctx, 10*time.Millisecond, func(err error) { t.Errorf("push failed: %v", err) })
+		close(done)
+	}()
+
+	select {
+	case <-pushed:
+	case <-time.After(5 * time.Second):
+		t.Error("no push within 5s")
+	}
+	cancel()
+	<-done
+}
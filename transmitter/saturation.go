@@ -0,0 +1,141 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transmitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// SaturationGauge is 1 while the autoscaler is in sustained saturation
+	// and 0 otherwise.
+	SaturationGauge = "sustained_saturation"
+	// SaturationCounter counts the sustained saturation episodes.
+	SaturationCounter = "sustained_saturation_total"
+)
+
+// SaturationTransmitter wraps a MetricTransmitter and derives an alertable
+// signal for capacity exhaustion from the desired pod count. Recommendations
+// are clamped to the maximum scale, so a desired pod count at the maximum
+// means the demand is at or beyond capacity. The autoscaler enters sustained
+// saturation once the desired pod count has stayed at the maximum for the
+// configured duration, and leaves it once the count has stayed below the
+// maximum for the clear duration. The hysteresis prevents the signal from
+// flapping while the demand hovers around the capacity.
+//
+// Along with every desired pod count, SaturationGauge and SaturationCounter
+// are recorded with the wrapped transmitter. All other calls are passed
+// through.
+type SaturationTransmitter struct {
+	MetricTransmitter
+
+	maxScale   int32
+	duration   time.Duration
+	clearAfter time.Duration
+	now        func() time.Time
+
+	mu        sync.Mutex
+	since     time.Time // start of the current run above or below the maximum
+	atMax     bool
+	saturated bool
+	episodes  uint64
+}
+
+// NewSaturationTransmitter creates a transmitter reporting sustained
+// saturation once the desired pod count has stayed at maxScale for duration,
+// until it has stayed below maxScale for clearAfter.
+func NewSaturationTransmitter(next MetricTransmitter, maxScale int32, duration, clearAfter time.Duration) (*SaturationTransmitter, error) {
+	if next == nil {
+		return nil, errors.New("wrapped transmitter cannot be nil")
+	}
+	if maxScale <= 0 {
+		return nil, fmt.Errorf("max scale must be positive, got %d", maxScale)
+	}
+	if duration < 0 {
+		return nil, fmt.Errorf("saturation duration must be non-negative, got %v", duration)
+	}
+	if clearAfter < 0 {
+		return nil, fmt.Errorf("clear duration must be non-negative, got %v", clearAfter)
+	}
+
+	return &SaturationTransmitter{
+		MetricTransmitter: next,
+		maxScale:          maxScale,
+		duration:          duration,
+		clearAfter:        clearAfter,
+		now:               time.Now,
+	}, nil
+}
+
+// RecordDesiredPods records the desired pod count and the saturation metrics.
+func (t *SaturationTransmitter) RecordDesiredPods(ctx context.Context, value int32) {
+	now := t.now()
+
+	t.mu.Lock()
+	atMax := value >= t.maxScale
+	if atMax != t.atMax || t.since.IsZero() {
+		t.atMax = atMax
+		t.since = now
+	}
+	elapsed := now.Sub(t.since)
+	switch {
+	case !t.saturated && atMax && elapsed >= t.duration:
+		t.saturated = true
+		t.episodes++
+	case t.saturated && !atMax && elapsed >= t.clearAfter:
+		t.saturated = false
+	}
+	saturated, episodes := t.saturated, t.episodes
+	t.mu.Unlock()
+
+	t.MetricTransmitter.RecordDesiredPods(ctx, value)
+	t.MetricTransmitter.RecordGauge(ctx, SaturationGauge, boolValue(saturated))
+	t.MetricTransmitter.RecordGauge(ctx, SaturationCounter, float64(episodes))
+}
+
+// RecordHistogram passes an observation of a histogram through. It is
+// recorded as a gauge if the wrapped transmitter doesn't record histograms.
+func (t *SaturationTransmitter) RecordHistogram(ctx context.Context, name string, value float64) {
+	RecordHistogram(ctx, t.MetricTransmitter, name, value)
+}
+
+// Saturated returns whether the autoscaler is in sustained saturation.
+func (t *SaturationTransmitter) Saturated() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.saturated
+}
+
+// Episodes returns the number of sustained saturation episodes so far.
+func (t *SaturationTransmitter) Episodes() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.episodes
+}
+
+// Close closes the wrapped transmitter if it has a Close method.
+func (t *SaturationTransmitter) Close() error {
+	if c, ok := t.MetricTransmitter.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
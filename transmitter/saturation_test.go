@@ -0,0 +1,116 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transmitter
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSaturationTransmitter(t *testing.T) {
+	noop := NewNoOpTransmitter()
+	tests := []struct {
+		name       string
+		next       MetricTransmitter
+		maxScale   int32
+		duration   time.Duration
+		clearAfter time.Duration
+	}{
+		{name: "nil transmitter", next: nil, maxScale: 10},
+		{name: "zero max scale", next: noop, maxScale: 0},
+		{name: "negative duration", next: noop, maxScale: 10, duration: -time.Second},
+		{name: "negative clear duration", next: noop, maxScale: 10, clearAfter: -time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSaturationTransmitter(tt.next, tt.maxScale, tt.duration, tt.clearAfter); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestSaturationTransmitter(t *testing.T) {
+	tr, err := NewSaturationTransmitter(NewNoOpTransmitter(), 10, 5*time.Minute, 2*time.Minute)
+	if err != nil {
+		t.Fatalf("NewSaturationTransmitter failed: %v", err)
+	}
+	start := time.Unix(1700000000, 0)
+	now := start
+	tr.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	steps := []struct {
+		at        time.Duration
+		desired   int32
+		saturated bool
+		episodes  uint64
+	}{
+		{at: 0, desired: 10},
+		{at: 4 * time.Minute, desired: 10},
+		// A dip below the maximum restarts the duration.
+		{at: 5 * time.Minute, desired: 9},
+		{at: 6 * time.Minute, desired: 10},
+		{at: 10 * time.Minute, desired: 10},
+		{at: 11 * time.Minute, desired: 10, saturated: true, episodes: 1},
+		// Short dips below the maximum don't clear the saturation.
+		{at: 12 * time.Minute, desired: 8, saturated: true, episodes: 1},
+		{at: 13 * time.Minute, desired: 10, saturated: true, episodes: 1},
+		{at: 14 * time.Minute, desired: 8, saturated: true, episodes: 1},
+		{at: 16 * time.Minute, desired: 8, episodes: 1},
+		{at: 17 * time.Minute, desired: 10, episodes: 1},
+		{at: 22 * time.Minute, desired: 10, saturated: true, episodes: 2},
+	}
+
+	for _, s := range steps {
+		now = start.Add(s.at)
+		tr.RecordDesiredPods(ctx, s.desired)
+		if got := tr.Saturated(); got != s.saturated {
+			t.Errorf("at %v: Saturated() = %v, want %v", s.at, got, s.saturated)
+		}
+		if got := tr.Episodes(); got != s.episodes {
+			t.Errorf("at %v: Episodes() = %d, want %d", s.at, got, s.episodes)
+		}
+	}
+}
+
+func TestSaturationTransmitterRecordsGauges(t *testing.T) {
+	var buf bytes.Buffer
+	next := NewLogTransmitter(log.New(&buf, "", 0), Labels{"service": "web"})
+	tr, err := NewSaturationTransmitter(next, 10, 0, 0)
+	if err != nil {
+		t.Fatalf("NewSaturationTransmitter failed: %v", err)
+	}
+
+	tr.RecordDesiredPods(context.Background(), 10)
+	tr.RecordBurstMode(context.Background(), true)
+
+	want := []string{
+		"metric: desired_pods{service=web} = 10",
+		"metric: sustained_saturation{service=web} = 1.00",
+		"metric: sustained_saturation_total{service=web} = 1.00",
+		"metric: burst_mode{service=web} = 1",
+	}
+	if got := strings.Split(strings.TrimSpace(buf.String()), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
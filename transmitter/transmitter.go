@@ -20,68 +20,121 @@ package transmitter
 import (
 	"context"
 	"log"
+	"sync/atomic"
 )
 
-// MetricTransmitter defines the interface for transmitting autoscaler metrics.
+// MetricTransmitter defines the interface for transmitting autoscaler
+// metrics. The labels identifying the workload are bound when the
+// transmitter is created.
 type MetricTransmitter interface {
 	// RecordDesiredPods records the desired pod count metric.
-	RecordDesiredPods(ctx context.Context, namespace, service string, value int32)
+	RecordDesiredPods(ctx context.Context, value int32)
 
 	// RecordStableValue records the stable window metric value.
-	RecordStableValue(ctx context.Context, namespace, service string, metric string, value float64)
+	RecordStableValue(ctx context.Context, metric string, value float64)
 
 	// RecordBurstValue records the burst window metric value.
-	RecordBurstValue(ctx context.Context, namespace, service string, metric string, value float64)
+	RecordBurstValue(ctx context.Context, metric string, value float64)
 
 	// RecordTargetValue records the target metric value.
-	RecordTargetValue(ctx context.Context, namespace, service string, metric string, value float64)
+	RecordTargetValue(ctx context.Context, metric string, value float64)
 
 	// RecordBurstMode records whether the autoscaler is in burst mode.
-	RecordBurstMode(ctx context.Context, namespace, service string, inBurst bool)
+	RecordBurstMode(ctx context.Context, inBurst bool)
+
+	// RecordGauge records an arbitrary gauge metric.
+	RecordGauge(ctx context.Context, name string, value float64)
+}
+
+// boolValue converts a boolean metric to 0 or 1.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
 }
 
 // LogTransmitter is a simple transmitter that logs metrics to stdout.
 type LogTransmitter struct {
 	logger *log.Logger
+	labels Labels
+	closed *atomic.Bool
 }
 
-// NewLogTransmitter creates a new log-based metric transmitter.
-func NewLogTransmitter(logger *log.Logger) *LogTransmitter {
+// NewLogTransmitter creates a new log-based metric transmitter, which adds
+// the given labels to all metrics.
+func NewLogTransmitter(logger *log.Logger, labels Labels) *LogTransmitter {
 	if logger == nil {
 		logger = log.Default()
 	}
 	return &LogTransmitter{
 		logger: logger,
+		labels: labels.Merge(nil),
+		closed: &atomic.Bool{},
+	}
+}
+
+// WithLabels returns a transmitter logging to the same logger with the given
+// labels added. Closing either transmitter closes both.
+func (t *LogTransmitter) WithLabels(labels Labels) *LogTransmitter {
+	return &LogTransmitter{
+		logger: t.logger,
+		labels: t.labels.Merge(labels),
+		closed: t.closed,
 	}
 }
 
 // RecordDesiredPods logs the desired pod count.
-func (t *LogTransmitter) RecordDesiredPods(ctx context.Context, namespace, service string, value int32) {
-	t.logger.Printf("metric: desired_pods{namespace=%s,service=%s} = %d\n", namespace, service, value)
+func (t *LogTransmitter) RecordDesiredPods(ctx context.Context, value int32) {
+	if t.closed.Load() {
+		return
+	}
+	t.logger.Printf("metric: desired_pods%s = %d\n", t.labels, value)
 }
 
 // RecordStableValue logs the stable window metric value.
-func (t *LogTransmitter) RecordStableValue(ctx context.Context, namespace, service string, metric string, value float64) {
-	t.logger.Printf("metric: stable_%s{namespace=%s,service=%s} = %.2f\n", metric, namespace, service, value)
+func (t *LogTransmitter) RecordStableValue(ctx context.Context, metric string, value float64) {
+	t.RecordGauge(ctx, "stable_"+metric, value)
 }
 
 // RecordBurstValue logs the burst window metric value.
-func (t *LogTransmitter) RecordBurstValue(ctx context.Context, namespace, service string, metric string, value float64) {
-	t.logger.Printf("metric: burst_%s{namespace=%s,service=%s} = %.2f\n", metric, namespace, service, value)
+func (t *LogTransmitter) RecordBurstValue(ctx context.Context, metric string, value float64) {
+	t.RecordGauge(ctx, "burst_"+metric, value)
 }
 
 // RecordTargetValue logs the target metric value.
-func (t *LogTransmitter) RecordTargetValue(ctx context.Context, namespace, service string, metric string, value float64) {
-	t.logger.Printf("metric: target_%s{namespace=%s,service=%s} = %.2f\n", metric, namespace, service, value)
+func (t *LogTransmitter) RecordTargetValue(ctx context.Context, metric string, value float64) {
+	t.RecordGauge(ctx, "target_"+metric, value)
 }
 
 // RecordBurstMode logs whether the autoscaler is in burst mode.
-func (t *LogTransmitter) RecordBurstMode(ctx context.Context, namespace, service string, inBurst bool) {
-	burstValue := 0
-	if inBurst {
-		burstValue = 1
+func (t *LogTransmitter) RecordBurstMode(ctx context.Context, inBurst bool) {
+	if t.closed.Load() {
+		return
+	}
+	t.logger.Printf("metric: burst_mode%s = %d\n", t.labels, int(boolValue(inBurst)))
+}
+
+// RecordGauge logs an arbitrary gauge metric.
+func (t *LogTransmitter) RecordGauge(ctx context.Context, name string, value float64) {
+	if t.closed.Load() {
+		return
 	}
-	t.logger.Printf("metric: burst_mode{namespace=%s,service=%s} = %d\n", namespace, service, burstValue)
+	t.logger.Printf("metric: %s%s = %.2f\n", name, t.labels, value)
+}
+
+// RecordHistogram logs an observation of a histogram.
+func (t *LogTransmitter) RecordHistogram(ctx context.Context, name string, value float64) {
+	if t.closed.Load() {
+		return
+	}
+	t.logger.Printf("metric: %s%s observed %g\n", name, t.labels, value)
+}
+
+// Close stops the transmitter. Metrics recorded after Close are dropped.
+func (t *LogTransmitter) Close() error {
+	t.closed.Store(true)
+	return nil
 }
 
 // NoOpTransmitter is a transmitter that does nothing.
@@ -93,21 +146,34 @@ func NewNoOpTransmitter() *NoOpTransmitter {
 }
 
 // RecordDesiredPods does nothing.
-func (t *NoOpTransmitter) RecordDesiredPods(ctx context.Context, namespace, service string, value int32) {
+func (t *NoOpTransmitter) RecordDesiredPods(ctx context.Context, value int32) {
 }
 
 // RecordStableValue does nothing.
-func (t *NoOpTransmitter) RecordStableValue(ctx context.Context, namespace, service string, metric string, value float64) {
+func (t *NoOpTransmitter) RecordStableValue(ctx context.Context, metric string, value float64) {
 }
 
 // RecordBurstValue does nothing.
-func (t *NoOpTransmitter) RecordBurstValue(ctx context.Context, namespace, service string, metric string, value float64) {
+func (t *NoOpTransmitter) RecordBurstValue(ctx context.Context, metric string, value float64) {
 }
 
 // RecordTargetValue does nothing.
-func (t *NoOpTransmitter) RecordTargetValue(ctx context.Context, namespace, service string, metric string, value float64) {
+func (t *NoOpTransmitter) RecordTargetValue(ctx context.Context, metric string, value float64) {
 }
 
 // RecordBurstMode does nothing.
-func (t *NoOpTransmitter) RecordBurstMode(ctx context.Context, namespace, service string, inBurst bool) {
+func (t *NoOpTransmitter) RecordBurstMode(ctx context.Context, inBurst bool) {
+}
+
+// RecordGauge does nothing.
+func (t *NoOpTransmitter) RecordGauge(ctx context.Context, name string, value float64) {
+}
+
+// RecordHistogram does nothing.
+func (t *NoOpTransmitter) RecordHistogram(ctx context.Context, name string, value float64) {
+}
+
+// Close does nothing.
+func (t *NoOpTransmitter) Close() error {
+	return nil
 }
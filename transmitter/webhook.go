@@ -0,0 +1,295 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transmitter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// WebhookEventKind identifies a notable autoscaler event.
+type WebhookEventKind string
+
+const (
+	// EventBurstModeEntered is sent when the autoscaler enters burst mode.
+	EventBurstModeEntered WebhookEventKind = "burst_mode_entered"
+	// EventClampedAtMaxScale is sent when the desired pod count has stayed at
+	// the maximum scale for longer than the configured duration.
+	EventClampedAtMaxScale WebhookEventKind = "clamped_at_max_scale"
+)
+
+// Payload templates of common webhook receivers. They are rendered with a
+// WebhookEvent; the json function encodes a value as JSON.
+const (
+	// SlackTemplate renders a Slack incoming webhook message.
+	SlackTemplate = `{"text":{{json .Summary}}}`
+
+	// TeamsTemplate renders a Microsoft Teams connector card.
+	TeamsTemplate = `{"@type":"MessageCard","@context":"https://schema.org/extensions",` +
+		`"summary":{{json .Summary}},"text":{{json .Summary}}}`
+
+	// PagerDutyTemplate renders a PagerDuty Events API v2 trigger. The
+	// integration key is taken from the routing_key entry of
+	// WebhookConfig.Data.
+	PagerDutyTemplate = `{"routing_key":{{json .Data.routing_key}},"event_action":"trigger",` +
+		`"dedup_key":{{json .DedupKey}},"payload":{"summary":{{json .Summary}},` +
+		`"source":{{json .Source}},"severity":"warning","timestamp":{{json .Time}},` +
+		`"custom_details":{"desired_pods":{{.DesiredPods}},"max_scale":{{.MaxScale}}}}}`
+)
+
+// WebhookEvent is the data a payload template is rendered with.
+type WebhookEvent struct {
+	// Kind is the kind of the event.
+	Kind WebhookEventKind
+	// Labels are the labels bound to the transmitter.
+	Labels Labels
+	// Source is the string form of Labels, e.g. {namespace=default,service=web}.
+	Source string
+	// DedupKey is the same for repeated events of a workload and kind.
+	DedupKey string
+	// Summary is a human readable description of the event.
+	Summary string
+	// Time is when the event happened.
+	Time time.Time
+	// DesiredPods is the latest desired pod count.
+	DesiredPods int32
+	// MaxScale is the configured maximum scale.
+	MaxScale int32
+	// Duration is how long the desired pod count has been clamped at
+	// MaxScale. It is zero for other events.
+	Duration time.Duration
+	// Data holds WebhookConfig.Data.
+	Data map[string]string
+}
+
+// WebhookConfig configures a WebhookTransmitter.
+type WebhookConfig struct {
+	// URL is the webhook endpoint.
+	URL string
+	// Template is the text/template of the request body. Defaults to
+	// SlackTemplate.
+	Template string
+	// ContentType of the request body. Defaults to application/json.
+	ContentType string
+	// Client sends the requests. Defaults to http.DefaultClient.
+	Client *http.Client
+	// Labels identify the workload in the events.
+	Labels Labels
+	// MaxScale is the maximum scale of the autoscaler. Zero disables
+	// EventClampedAtMaxScale.
+	MaxScale int32
+	// ClampDuration is how long the desired pod count must stay at MaxScale
+	// before EventClampedAtMaxScale is sent.
+	ClampDuration time.Duration
+	// Data is passed to the template, e.g. the PagerDuty routing_key.
+	Data map[string]string
+	// OnError is called with errors sending an event. It may be nil.
+	OnError func(error)
+}
+
+// WebhookTransmitter posts notable autoscaler events to a webhook, like a
+// Slack, Microsoft Teams or PagerDuty integration. It implements
+// MetricTransmitter, but only acts on the desired pod count and the burst
+// mode; other metrics are ignored.
+//
+// Events are sent synchronously; wrap the transmitter in an AsyncTransmitter
+// to keep the webhook off the scaling path.
+type WebhookTransmitter struct {
+	config   WebhookConfig
+	template *template.Template
+	now      func() time.Time
+
+	mu           sync.Mutex
+	inBurst      bool
+	clampedSince time.Time
+	clampSent    bool
+	lastDesired  int32
+	closed       bool
+}
+
+// NewWebhookTransmitter creates a webhook transmitter from the config.
+func NewWebhookTransmitter(config WebhookConfig) (*WebhookTransmitter, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid webhook URL %q: scheme must be http or https", config.URL)
+	}
+	if err := config.Labels.Validate(); err != nil {
+		return nil, err
+	}
+	if config.MaxScale < 0 {
+		return nil, fmt.Errorf("max scale must be non-negative, got %d", config.MaxScale)
+	}
+	if config.ClampDuration < 0 {
+		return nil, fmt.Errorf("clamp duration must be non-negative, got %v", config.ClampDuration)
+	}
+	if config.Template == "" {
+		config.Template = SlackTemplate
+	}
+	if config.ContentType == "" {
+		config.ContentType = "application/json"
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	config.Labels = config.Labels.Merge(nil)
+
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": jsonValue}).Parse(config.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+
+	return &WebhookTransmitter{
+		config:   config,
+		template: tmpl,
+		now:      time.Now,
+	}, nil
+}
+
+// jsonValue encodes v as JSON for use in payload templates.
+func jsonValue(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// RecordDesiredPods sends EventClampedAtMaxScale once the desired pod count
+// has stayed at MaxScale for longer than ClampDuration. The event is sent
+// again only after the count has dropped below MaxScale in between.
+func (t *WebhookTransmitter) RecordDesiredPods(ctx context.Context, value int32) {
+	now := t.now()
+
+	t.mu.Lock()
+	t.lastDesired = value
+	if t.closed || t.config.MaxScale == 0 || value < t.config.MaxScale {
+		t.clampedSince = time.Time{}
+		t.clampSent = false
+		t.mu.Unlock()
+		return
+	}
+	if t.clampedSince.IsZero() {
+		t.clampedSince = now
+	}
+	clamped := now.Sub(t.clampedSince)
+	if t.clampSent || clamped < t.config.ClampDuration {
+		t.mu.Unlock()
+		return
+	}
+	t.clampSent = true
+	t.mu.Unlock()
+
+	t.send(ctx, WebhookEvent{
+		Kind:        EventClampedAtMaxScale,
+		Summary:     fmt.Sprintf("Autoscaler %s has been clamped at max scale %d for %v", t.config.Labels, t.config.MaxScale, clamped),
+		Time:        now,
+		DesiredPods: value,
+		Duration:    clamped,
+	})
+}
+
+// RecordBurstMode sends EventBurstModeEntered when the autoscaler enters
+// burst mode.
+func (t *WebhookTransmitter) RecordBurstMode(ctx context.Context, inBurst bool) {
+	t.mu.Lock()
+	entered := inBurst && !t.inBurst && !t.closed
+	t.inBurst = inBurst
+	desired := t.lastDesired
+	t.mu.Unlock()
+
+	if !entered {
+		return
+	}
+	t.send(ctx, WebhookEvent{
+		Kind:        EventBurstModeEntered,
+		Summary:     fmt.Sprintf("Autoscaler %s entered burst mode", t.config.Labels),
+		Time:        t.now(),
+		DesiredPods: desired,
+	})
+}
+
+// RecordStableValue does nothing.
+func (t *WebhookTransmitter) RecordStableValue(ctx context.Context, metric string, value float64) {
+}
+
+// RecordBurstValue does nothing.
+func (t *WebhookTransmitter) RecordBurstValue(ctx context.Context, metric string, value float64) {
+}
+
+// RecordTargetValue does nothing.
+func (t *WebhookTransmitter) RecordTargetValue(ctx context.Context, metric string, value float64) {
+}
+
+// RecordGauge does nothing.
+func (t *WebhookTransmitter) RecordGauge(ctx context.Context, name string, value float64) {
+}
+
+// Close stops sending events.
+func (t *WebhookTransmitter) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	return nil
+}
+
+// send renders the event and posts it to the webhook.
+func (t *WebhookTransmitter) send(ctx context.Context, event WebhookEvent) {
+	event.Labels = t.config.Labels
+	event.Source = t.config.Labels.String()
+	event.DedupKey = string(event.Kind) + event.Source
+	event.MaxScale = t.config.MaxScale
+	event.Data = t.config.Data
+
+	if err := t.post(ctx, event); err != nil && t.config.OnError != nil {
+		t.config.OnError(fmt.Errorf("failed to send %s event: %w", event.Kind, err))
+	}
+}
+
+// post sends a single webhook request.
+func (t *WebhookTransmitter) post(ctx context.Context, event WebhookEvent) error {
+	var body bytes.Buffer
+	if err := t.template.Execute(&body, event); err != nil {
+		return fmt.Errorf("failed to render payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.config.URL, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", t.config.ContentType)
+
+	resp, err := t.config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook request failed with status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The libkpa Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transmitter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewWebhookTransmitter(t *testing.T) {
+	tests := []struct {
+		name   string
+		config WebhookConfig
+	}{
+		{name: "missing URL", config: WebhookConfig{}},
+		{name: "bad scheme", config: WebhookConfig{URL: "ftp://example.com"}},
+		{name: "bad label", config: WebhookConfig{URL: "http://example.com", Labels: Labels{"a-b": "c"}}},
+		{name: "negative max scale", config: WebhookConfig{URL: "http://example.com", MaxScale: -1}},
+		{name: "negative clamp duration", config: WebhookConfig{URL: "http://example.com", ClampDuration: -time.Second}},
+		{name: "bad template", config: WebhookConfig{URL: "http://example.com", Template: "{{"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewWebhookTransmitter(tt.config); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestWebhookTransmitter(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		payloads []map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]any
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("invalid JSON payload %q: %v", body, err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	tr, err := NewWebhookTransmitter(WebhookConfig{
+		URL:           server.URL,
+		Template:      PagerDutyTemplate,
+		Client:        server.Client(),
+		Labels:        KubernetesLabels("default", "web"),
+		MaxScale:      10,
+		ClampDuration: 5 * time.Minute,
+		Data:          map[string]string{"routing_key": "key"},
+		OnError:       func(err error) { t.Errorf("unexpected error: %v", err) },
+	})
+	if err != nil {
+		t.Fatalf("NewWebhookTransmitter failed: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	tr.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// Burst mode is reported once on entry.
+	tr.RecordDesiredPods(ctx, 4)
+	tr.RecordBurstMode(ctx, true)
+	tr.RecordBurstMode(ctx, true)
+
+	// Clamped, but not long enough.
+	tr.RecordDesiredPods(ctx, 10)
+	now = now.Add(4 * time.Minute)
+	tr.RecordDesiredPods(ctx, 10)
+
+	// Dropping below MaxScale resets the clamp.
+	tr.RecordDesiredPods(ctx, 9)
+	tr.RecordDesiredPods(ctx, 10)
+	now = now.Add(5 * time.Minute)
+	tr.RecordDesiredPods(ctx, 10)
+	now = now.Add(time.Minute)
+	tr.RecordDesiredPods(ctx, 10)
+
+	tr.Close()
+	tr.RecordBurstMode(ctx, false)
+	tr.RecordBurstMode(ctx, true)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(payloads) != 2 {
+		t.Fatalf("got %d events, want 2: %v", len(payloads), payloads)
+	}
+	if got := payloads[0]["dedup_key"]; got != "burst_mode_entered{namespace=default,service=web}" {
+		t.Errorf("dedup_key = %v", got)
+	}
+	if got := payloads[0]["routing_key"]; got != "key" {
+		t.Errorf("routing_key = %v, want key", got)
+	}
+	details := payloads[1]["payload"].(map[string]any)["custom_details"].(map[string]any)
+	if details["desired_pods"] != 10.0 || details["max_scale"] != 10.0 {
+		t.Errorf("custom_details = %v", details)
+	}
+	if got, want := payloads[1]["payload"].(map[string]any)["summary"],
+		"Autoscaler {namespace=default,service=web} has been clamped at max scale 10 for 5m0s"; got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+}
+
+func TestWebhookTransmitterTemplates(t *testing.T) {
+	event := WebhookEvent{Summary: `quote " and newline` + "\n", Data: map[string]string{}}
+	for name, tmpl := range map[string]string{"slack": SlackTemplate, "teams": TeamsTemplate, "pagerduty": PagerDutyTemplate} {
+		tr, err := NewWebhookTransmitter(WebhookConfig{URL: "http://example.com", Template: tmpl})
+		if err != nil {
+			t.Fatalf("%s: NewWebhookTransmitter failed: %v", name, err)
+		}
+		var sb strings.Builder
+		if err := tr.template.Execute(&sb, event); err != nil {
+			t.Fatalf("%s: Execute failed: %v", name, err)
+		}
+		if !json.Valid([]byte(sb.String())) {
+			t.Errorf("%s: invalid JSON %q", name, sb.String())
+		}
+	}
+}